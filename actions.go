@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/config"
+	"github.com/shadow-ai-hunter/notify"
+	"github.com/shadow-ai-hunter/redact"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// actionMaxFindings is how many findings an action's webhook post or mail
+// lists; its counts cover every finding routed to it.
+const actionMaxFindings = 1000
+
+// responseAction is a configured action and the findings routed to it.
+type responseAction struct {
+	config.Action
+	matches  func(analyzer.Finding) bool
+	agg      *analyzer.Aggregator
+	findings []analyzer.Finding // the first actionMaxFindings
+}
+
+// actionPayload is the document an action posts to its webhook.
+type actionPayload struct {
+	Action   string          `json:"action"`
+	Findings int             `json:"matched_findings"`
+	Report   json.RawMessage `json:"report"` // the JSON report of the matched findings
+}
+
+// newActions prepares the configured actions in order.
+func newActions(cfg []config.Action) ([]*responseAction, error) {
+	var actions []*responseAction
+	for _, a := range cfg {
+		matches, err := a.Matcher()
+		if err != nil {
+			return nil, fmt.Errorf("action %s: %w", a.Name, err)
+		}
+		actions = append(actions, &responseAction{Action: a, matches: matches, agg: analyzer.NewAggregator()})
+	}
+	return actions, nil
+}
+
+// route hands f to the first action whose rule it matches.
+func route(actions []*responseAction, f analyzer.Finding) {
+	for _, a := range actions {
+		if !a.matches(f) {
+			continue
+		}
+		a.agg.Add(f)
+		if len(a.findings) < actionMaxFindings {
+			a.findings = append(a.findings, f)
+		}
+		return
+	}
+}
+
+// findingsOf reads findings held in memory as a FindingSource.
+func findingsOf(findings []analyzer.Finding) reporter.FindingSource {
+	return func(fn func(analyzer.Finding) error) error {
+		for _, f := range findings {
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// runActions routes the findings from src to the actions and carries out
+// each one that received any. scanned supplies the log count and sources
+// for their reports. Every action is attempted, and each failure is logged.
+func runActions(ctx context.Context, actions []*responseAction, src reporter.FindingSource, scanned analyzer.Summary,
+	mailer notify.Server, profiles map[string]redact.Profile, started time.Time) error {
+	err := src(func(f analyzer.Finding) error {
+		route(actions, f)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, a := range actions {
+		summary := a.agg.Summary(scanned.TotalLogsScanned)
+		if summary.TotalFindings == 0 {
+			continue
+		}
+		summary.Findings = a.findings
+		summary.Sources = scanned.Sources
+		summary.Partial = scanned.Partial
+		summary.Tenant = scanned.Tenant
+		summary.Scan = scanned.Scan
+		if err := a.run(ctx, summary, mailer, profiles, started); err != nil {
+			logger.Error("Action failed", "action", a.Name, "err", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d action(s) failed", failed)
+	}
+	return nil
+}
+
+// run carries out one action for the findings routed to it.
+func (a *responseAction) run(ctx context.Context, summary analyzer.Summary, mailer notify.Server, profiles map[string]redact.Profile, started time.Time) error {
+	if a.Log {
+		logger.Warn(fmt.Sprintf("Action %s: %d finding(s)", a.Name, summary.TotalFindings), "services", strings.Join(sortedKeys(summary.ByService), ","))
+	}
+	if a.Webhook != nil {
+		profile, err := redact.Lookup(a.Webhook.Profile, profiles)
+		if err != nil {
+			return err
+		}
+		var report bytes.Buffer
+		if err := reporter.Report(profile.Apply(summary), reporter.FormatJSON, &report); err != nil {
+			return err
+		}
+		body, err := json.Marshal(actionPayload{Action: a.Name, Findings: summary.TotalFindings, Report: report.Bytes()})
+		if err != nil {
+			return err
+		}
+		hook := notify.Webhook{URL: a.Webhook.URL, Headers: a.Webhook.Headers}
+		if err := hook.Post(ctx, body); err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+		logSuccess("Action webhook posted", "action", a.Name, "findings", summary.TotalFindings)
+	}
+	if a.Email != nil {
+		if err := mailReport(mailer, a.Name, *a.Email, summary, profiles, started); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+		logSuccess("Action mail sent", "action", a.Name, "to", strings.Join(a.Email.To, ","))
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// Activity classifies what a user was doing with an AI service.
+type Activity string
+
+const (
+	ActivityUnknown Activity = "" // no URL/method available (DNS, CONNECT tunnels)
+	ActivityBrowse  Activity = "browse"
+	ActivityChat    Activity = "chat"
+	ActivityUpload  Activity = "upload"
+	ActivityAPI     Activity = "api"
+	ActivityTooling Activity = "tooling" // update/telemetry check-in of installed AI software
+)
+
+// EndpointRule maps requests to a service's URL path onto an activity type.
+// Path is matched as a prefix on "/"-separated segments; a "*" segment matches
+// any single segment (e.g. "/api/organizations/*/chat_conversations").
+type EndpointRule struct {
+	Method   string   `json:"method,omitempty"` // empty matches any method
+	Path     string   `json:"path"`
+	Activity Activity `json:"activity"`
+}
+
+// classifyActivity determines the activity type of a matched entry. Service
+// endpoint rules take precedence over the generic heuristics.
+func classifyActivity(svc AIService, entry parsers.LogEntry) Activity {
+	if entry.URL == "" || !strings.Contains(entry.URL, "://") {
+		return ActivityUnknown
+	}
+	method := strings.ToUpper(entry.Method)
+	if method == "CONNECT" {
+		return ActivityUnknown
+	}
+
+	parsed, err := url.Parse(entry.URL)
+	if err != nil {
+		return ActivityUnknown
+	}
+	path := parsed.EscapedPath()
+
+	for _, rule := range svc.Endpoints {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if matchPathPrefix(rule.Path, path) {
+			return rule.Activity
+		}
+	}
+
+	lowerPath := strings.ToLower(path)
+	writes := method == "POST" || method == "PUT"
+	if writes && (strings.Contains(lowerPath, "/upload") || strings.Contains(lowerPath, "/files") ||
+		strings.Contains(lowerPath, "/attachments")) {
+		return ActivityUpload
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if strings.HasPrefix(host, "api.") || strings.HasPrefix(host, "api-") ||
+		strings.HasPrefix(lowerPath, "/v1/") || strings.HasPrefix(lowerPath, "/v1beta/") ||
+		strings.HasPrefix(lowerPath, "/v2/") || strings.HasPrefix(lowerPath, "/api/") {
+		return ActivityAPI
+	}
+	if writes {
+		return ActivityChat
+	}
+	return ActivityBrowse
+}
+
+// matchPathPrefix reports whether path starts with the segments of pattern.
+func matchPathPrefix(pattern, path string) bool {
+	patSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patSegs) > len(pathSegs) {
+		return false
+	}
+	for i, seg := range patSegs {
+		if seg != "*" && seg != pathSegs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// Adoption is a user contacting an AI service for the first time.
+type Adoption struct {
+	User      string    `json:"user"`
+	Service   string    `json:"service"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+// Baseline is the set of user/service pairs already seen in earlier scans.
+type Baseline struct {
+	seen map[[2]string]bool
+}
+
+// NewBaseline builds a baseline from previously recorded findings.
+func NewBaseline(findings []Finding) *Baseline {
+	b := &Baseline{seen: make(map[[2]string]bool)}
+	for _, f := range findings {
+		b.Add(NormalizeIP(f.Identity()), f.ServiceName) // history from before addresses were normalized
+	}
+	return b
+}
+
+// Add records a user/service pair as known.
+func (b *Baseline) Add(user, service string) {
+	b.seen[[2]string{user, service}] = true
+}
+
+// Known reports whether the pair was seen before.
+func (b *Baseline) Known(user, service string) bool {
+	return b.seen[[2]string{user, service}]
+}
+
+// Empty reports whether the baseline holds no pairs, in which case every
+// finding would look new and tagging is skipped.
+func (b *Baseline) Empty() bool {
+	return len(b.seen) == 0
+}
+
+// TagNewAdoption marks findings whose user/service pair is absent from the
+// baseline and returns the summary with aggregates recomputed.
+func TagNewAdoption(s Summary, b *Baseline) Summary {
+	if b == nil || b.Empty() {
+		return s
+	}
+	findings := make([]Finding, len(s.Findings))
+	for i, f := range s.Findings {
+		f.NewAdoption = !b.Known(f.Identity(), f.ServiceName)
+		findings[i] = f
+	}
+	return s.resummarize(findings)
+}
+
+// addAdoption notes a finding of a newly adopted pair, keeping the earliest
+// time the pair was seen.
+func addAdoption(first map[[2]string]time.Time, f Finding) {
+	key := [2]string{f.Identity(), f.ServiceName}
+	if ts, ok := first[key]; !ok || (!f.Timestamp.IsZero() && (ts.IsZero() || f.Timestamp.Before(ts))) {
+		first[key] = f.Timestamp
+	}
+}
+
+// newAdoptions lists the pairs collected by addAdoption, earliest first.
+func newAdoptions(first map[[2]string]time.Time) []Adoption {
+	adoptions := make([]Adoption, 0, len(first))
+	for key, ts := range first {
+		adoptions = append(adoptions, Adoption{User: key[0], Service: key[1], FirstSeen: ts})
+	}
+	sort.Slice(adoptions, func(i, j int) bool {
+		if !adoptions[i].FirstSeen.Equal(adoptions[j].FirstSeen) {
+			return adoptions[i].FirstSeen.Before(adoptions[j].FirstSeen)
+		}
+		if adoptions[i].User != adoptions[j].User {
+			return adoptions[i].User < adoptions[j].User
+		}
+		return adoptions[i].Service < adoptions[j].Service
+	})
+	return adoptions
+}
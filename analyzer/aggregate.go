@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+)
+
+// Aggregator builds a summary one finding at a time, for scans with more
+// findings than fit in memory. It keeps only the aggregates, so it grows
+// with the number of distinct users, services, and hosts rather than with
+// the number of findings.
+type Aggregator struct {
+	s       Summary
+	adopted map[[2]string]time.Time // new user/service pairs -> first seen
+	tooling map[[2]string]*ToolingInstall
+	doh     map[[2]string]*DoHUse
+	vpn     map[[2]string]*VPNUse
+	bursts  map[string]*Burst
+	sites   map[string]*siteTally
+}
+
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		s: Summary{
+			ByUser:            make(map[string]int),
+			ByService:         make(map[string]int),
+			ByProvider:        make(map[string]int),
+			ProviderEndpoints: make(map[string]map[string]int),
+			ByCategory:        make(map[string]int),
+			VolumeByService:   make(map[string]Volume),
+			VolumeByUser:      make(map[string]Volume),
+			ByActivity:        make(map[Activity]int),
+			OffHoursByUser:    make(map[string]int),
+			HeatmapByService:  make(map[string]*Heatmap),
+			CloudHosted:       make(map[string]int),
+		},
+		adopted: make(map[[2]string]time.Time),
+		tooling: make(map[[2]string]*ToolingInstall),
+		doh:     make(map[[2]string]*DoHUse),
+		vpn:     make(map[[2]string]*VPNUse),
+		bursts:  make(map[string]*Burst),
+		sites:   make(map[string]*siteTally),
+	}
+}
+
+// Add counts a finding. A DoH lookup counts only toward DoHClients.
+func (a *Aggregator) Add(f Finding) {
+	if f.IsDoH() {
+		addDoHUse(a.doh, f)
+		return
+	}
+	s := &a.s
+	s.TotalFindings++
+	s.ByUser[f.Identity()]++
+	s.ByService[f.ServiceName]++
+	provider := f.ProviderName()
+	s.ByProvider[provider]++
+	if s.ProviderEndpoints[provider] == nil {
+		s.ProviderEndpoints[provider] = make(map[string]int)
+	}
+	s.ProviderEndpoints[provider][strings.TrimSuffix(strings.ToLower(f.Domain), ".")]++
+	s.ByCategory[f.Category]++
+	if f.Activity != ActivityUnknown {
+		s.ByActivity[f.Activity]++
+	}
+	s.BytesSent += f.BytesSent
+	s.BytesReceived += f.BytesReceived
+	addVolume(s.VolumeByService, f.ServiceName, f)
+	addVolume(s.VolumeByUser, f.Identity(), f)
+	if f.Blocked {
+		s.BlockedFindings++
+	} else {
+		s.AllowedFindings++
+	}
+	if f.CloudHosted {
+		s.CloudHosted[f.ServiceName]++
+	}
+	if f.OffHours {
+		s.OffHoursFindings++
+		s.OffHoursByUser[f.Identity()]++
+	}
+	addHeat(s, f)
+	addSite(a.sites, f)
+	if ts := f.Timestamp; !ts.IsZero() {
+		if s.FirstSeen.IsZero() || ts.Before(s.FirstSeen) {
+			s.FirstSeen = ts
+		}
+		if ts.After(s.LastSeen) {
+			s.LastSeen = ts
+		}
+	}
+	if f.NewAdoption {
+		addAdoption(a.adopted, f)
+	}
+	if f.Automated {
+		addBurst(a.bursts, f)
+	}
+	if f.Activity == ActivityTooling {
+		addToolingInstall(a.tooling, f)
+	}
+	if f.Category == CategoryVPN {
+		addVPNUse(a.vpn, f)
+	}
+}
+
+// Summary returns the aggregates of the findings added, with Findings left
+// empty. The summary shares the aggregator's maps, so call it once every
+// finding has been added.
+func (a *Aggregator) Summary(logsScanned int) Summary {
+	s := a.s
+	s.TotalLogsScanned = logsScanned
+	s.NewAdoptions = newAdoptions(a.adopted)
+	s.ToolingInstalled = toolingInstalls(a.tooling)
+	s.DoHClients = dohUses(a.doh)
+	s.VPNUsers = vpnUses(a.vpn)
+	s.Bursts = bursts(a.bursts)
+	s.Sites = sites(a.sites)
+	s.UniqueUsers = len(s.ByUser)
+	s.UniqueServices = len(s.ByService)
+	return s
+}
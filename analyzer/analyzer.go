@@ -1,9 +1,24 @@
+// Package analyzer matches parsed log entries against a database of AI
+// services and aggregates the findings. It can be embedded in other Go
+// programs; nothing in it writes to stdout or stderr or exits the process:
+//
+//	az, err := analyzer.NewFromReader(bytes.NewReader(servicesJSON))
+//	...
+//	summary, err := az.AnalyzeReader(ctx, logs, &parsers.SquidParser{})
+//	...
+//	err = reporter.Report(summary, reporter.FormatJSON, w)
 package analyzer
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,127 +27,606 @@ import (
 
 // AIService represents a known AI service from the database.
 type AIService struct {
-	Name     string   `json:"name"`
-	Category string   `json:"category"`
-	Domains  []string `json:"domains"`
+	Name       string          `json:"name"`
+	Provider   string          `json:"provider,omitempty"` // vendor the service rolls up to; defaults to Name
+	Category   string          `json:"category"`
+	Domains    []string        `json:"domains"`
+	Endpoints  []EndpointRule  `json:"endpoints,omitempty"`
+	UserAgents []UserAgentRule `json:"user_agents,omitempty"`
+	JA3        []string        `json:"ja3,omitempty"`     // TLS fingerprints of the service's desktop apps
+	Tooling    []string        `json:"tooling,omitempty"` // update/telemetry hosts of its apps and extensions
+	// HostPatterns match hosts with a variable label, such as a region
+	// or resource name: "bedrock-runtime.*.amazonaws.com".
+	HostPatterns []string `json:"host_patterns,omitempty"`
+	// IPRanges match destinations logged as an IP address, such as a
+	// CONNECT to a literal address: "160.79.104.0/23", "2607:6bc0::/48".
+	IPRanges []string `json:"ip_ranges,omitempty"`
+	Hosting  string   `json:"hosting,omitempty"` // "cloud" for services in the customer's cloud account
+	// Source says where the entry's intel came from, such as vendor
+	// documentation or observed traffic; Reference links to it.
+	Source    string `json:"source,omitempty"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// ProviderName returns the provider the service is reported under.
+func (s AIService) ProviderName() string {
+	if s.Provider != "" {
+		return s.Provider
+	}
+	return s.Name
 }
 
 type servicesFile struct {
+	DBInfo
 	Services []AIService `json:"services"`
 }
 
 // Finding is a single matched event — a log entry that hit an AI service.
 type Finding struct {
-	Timestamp   time.Time
-	SourceIP    string
-	ServiceName string
-	Category    string
-	Domain      string
-	URL         string
-	Method      string
-	StatusCode  string
-	BytesSent   int64
+	Timestamp     time.Time `json:"timestamp"`
+	SourceIP      string    `json:"source_ip"`
+	User          string    `json:"user,omitempty"` // authenticated user name, when the log has one
+	ServiceName   string    `json:"service_name"`
+	Provider      string    `json:"provider,omitempty"`
+	Category      string    `json:"category"`
+	Domain        string    `json:"domain"`
+	URL           string    `json:"url,omitempty"`
+	Method        string    `json:"method,omitempty"`
+	StatusCode    string    `json:"status_code,omitempty"`
+	Action        string    `json:"action,omitempty"`
+	BytesSent     int64     `json:"bytes_sent,omitempty"`     // uploaded: request size
+	BytesReceived int64     `json:"bytes_received,omitempty"` // downloaded: response size
+	Activity      Activity  `json:"activity,omitempty"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+	JA3           string    `json:"ja3,omitempty"`
+	ProcessName   string    `json:"process_name,omitempty"` // executable that made the request, from endpoint logs
+	DeviceName    string    `json:"device_name,omitempty"`  // endpoint the request was made on
+	DetectedBy    string    `json:"detected_by,omitempty"`  // empty for domain matches, else "ip_range", "ja3", "user_agent", "lookalike", or "typosquat"
+	Blocked       bool      `json:"blocked,omitempty"`
+	CloudHosted   bool      `json:"cloud_hosted,omitempty"` // service runs in a cloud provider account
+	OffHours      bool      `json:"off_hours,omitempty"`
+	NewAdoption   bool      `json:"new_adoption,omitempty"`
+	Automated     bool      `json:"automation_suspected,omitempty"` // part of a burst; see TagBursts
+	Rate          float64   `json:"rate_per_minute,omitempty"`      // peak requests per minute of the burst
+	SourceFile    string    `json:"source_file,omitempty"`          // log file the finding came from
+	LineNumber    int       `json:"line_number,omitempty"`          // line in SourceFile; 0 when unknown
+	Tenant        string    `json:"tenant,omitempty"`               // business unit the scan ran for
+	Site          string    `json:"site,omitempty"`                 // report the finding came from in a merge; see Merge
 }
 
 // Summary aggregates findings for reporting.
 type Summary struct {
 	TotalLogsScanned int
 	TotalFindings    int
+	AllowedFindings  int   // requests that reached the service
+	BlockedFindings  int   // attempts stopped by the proxy/firewall
+	BytesSent        int64 // uploaded to AI services, where logs record it
+	BytesReceived    int64 // downloaded from them
 	UniqueUsers      int
 	UniqueServices   int
 	Findings         []Finding
-	ByUser           map[string]int // source_ip -> hit count
+	ByUser           map[string]int // user (or source_ip when unknown) -> hit count
 	ByService        map[string]int // service name -> hit count
+	ByProvider       map[string]int // provider -> hit count, across its services
+	// ProviderEndpoints drills down from a provider to the hosts that were hit.
+	ProviderEndpoints map[string]map[string]int
+	ByCategory        map[string]int    // category -> hit count
+	VolumeByService   map[string]Volume // service name -> bytes up and down
+	VolumeByUser      map[string]Volume // user (or source_ip) -> bytes up and down
+	ByActivity        map[Activity]int
+	OffHoursFindings  int
+	OffHoursByUser    map[string]int      // user (or source_ip) -> off-hours hit count
+	Heatmap           Heatmap             // hits by weekday and hour
+	HeatmapByService  map[string]*Heatmap // service name -> its hits by weekday and hour
+	FirstSeen         time.Time           // earliest finding timestamp; zero when none had one
+	LastSeen          time.Time
+	NewAdoptions      []Adoption // user/service pairs absent from the baseline
+	ToolingInstalled  []ToolingInstall
+	DoHClients        []DoHUse       // clients resolving names over HTTPS, out of sight of DNS logs
+	VPNUsers          []VPNUse       // clients reaching VPN and proxy services; see SetVPN
+	Bursts            []Burst        // sources suspected of automated use; see TagBursts
+	Sites             []SiteStats    // per-site breakdown of a merged summary; see Merge
+	CloudHosted       map[string]int // cloud-hosted service -> hit count
+	Sources           []SourceCoverage
+	Partial           bool      // the scan was cancelled or timed out before all input was read
+	Tenant            string    // business unit the scan ran for; empty when untagged
+	Scan              *ScanMeta // what was scanned, where, and with what; nil when unknown
+	Layout            Layout
+}
+
+// ScanMeta records what a scan read and with which tool and database, so a
+// report can show auditors exactly what it covers.
+type ScanMeta struct {
+	Version    string // tool version
+	ServicesDB string // see Analyzer.DBHash
+	DBInfo     DBInfo
+	Host       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Files      []ScannedFile
+}
+
+// ScannedFile is one input of a scan.
+type ScannedFile struct {
+	Path   string
+	Size   int64  // bytes on disk when the scan read it
+	SHA256 string // hex digest of those Size bytes; empty when not hashed
+}
+
+// Duration is how long the scan ran.
+func (m ScanMeta) Duration() time.Duration {
+	return m.FinishedAt.Sub(m.StartedAt)
+}
+
+// Layout holds presentation settings that travel with a summary to the
+// reporters. They change how results are shown, never what was found.
+type Layout struct {
+	// SessionGap, when set, groups findings into sessions split by this
+	// much idle time instead of listing each one.
+	SessionGap time.Duration
+	// Top limits each aggregate table in console output to this many rows.
+	Top int
+	// MaxFindings limits the detailed findings listed in console output.
+	MaxFindings int
+	// ShowSource adds the file and line of each finding to console output.
+	ShowSource bool
+	// Heatmap adds hit counts by weekday and hour, overall and per service.
+	Heatmap bool
 }
 
 // Analyzer matches log entries against known AI service domains.
 type Analyzer struct {
-	domainMap map[string]AIService // domain -> service
+	domainMap  map[string]AIService // domain -> service
+	byName     map[string]AIService // service name -> service, for User-Agent matches
+	uaRules    []uaMatcher          // in load order; later rules win
+	ja3Map     map[string]string    // JA3 hash -> service name
+	tooling    map[string]bool      // domains that signal installed software
+	patterns   []hostPattern        // in load order; later patterns win
+	ipRanges   []ipRange            // in load order; see matchIP
+	domains    domainTrie           // domainMap's keys and the patterns, for matching hosts
+	hours      *BusinessHours       // nil disables off-hours detection
+	lookalikes bool                 // flag hosts imitating database domains
+	vpn        bool                 // report CategoryVPN services
+	squats     typosquats           // fuzzy matching, off unless enabled
+	tenant     string               // stamped on every finding
+	scrubber   *URLScrubber         // nil keeps finding URLs as logged
+	zones      Timezones
+	info       DBInfo    // of the main database
+	dbSum      hash.Hash // over the database files read, in load order; nil until one is
 }
 
-// New creates an Analyzer loaded with AI services from a JSON file.
+// New creates an Analyzer loaded with AI services from a file in JSON,
+// YAML, or TOML, as its extension says.
 func New(servicesPath string) (*Analyzer, error) {
 	data, err := os.ReadFile(servicesPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading services file: %w", err)
 	}
+	info, services, err := ParseDatabase(servicesPath, data)
+	if err != nil {
+		return nil, err
+	}
+	a := NewWithServices(services)
+	a.info = info
+	a.sumDB(data)
+	return a, nil
+}
+
+// NewFromReader creates an Analyzer from a services database in the
+// ai_services.json format, e.g. one embedded in the calling program.
+func NewFromReader(r io.Reader) (*Analyzer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading services file: %w", err)
+	}
 
-	var sf servicesFile
-	if err := json.Unmarshal(data, &sf); err != nil {
-		return nil, fmt.Errorf("parsing services file: %w", err)
+	info, services, err := ParseDatabase("", data)
+	if err != nil {
+		return nil, err
 	}
+	a := NewWithServices(services)
+	a.info = info
+	a.sumDB(data)
+	return a, nil
+}
 
+// NewWithServices creates an Analyzer for services built in code. Where
+// two services claim a domain, the later one wins.
+func NewWithServices(services []AIService) *Analyzer {
 	a := &Analyzer{
 		domainMap: make(map[string]AIService),
+		byName:    make(map[string]AIService),
+		ja3Map:    make(map[string]string),
+		tooling:   make(map[string]bool),
 	}
+	a.AddServices(services)
+	return a
+}
 
-	for _, svc := range sf.Services {
-		for _, domain := range svc.Domains {
-			a.domainMap[strings.ToLower(domain)] = svc
-		}
-	}
-
-	return a, nil
+// ParseServices decodes and sanity-checks an AI services database in JSON.
+func ParseServices(data []byte) ([]AIService, error) {
+	return ParseServicesFile("", data)
 }
 
-// LoadCustomDomains merges additional domains from a user-provided JSON file.
-func (a *Analyzer) LoadCustomDomains(path string) error {
+// LoadCustomDomains merges additional domains from a user-provided file in
+// JSON, YAML, or TOML. Custom entries win; the domains they took over from
+// another service are returned so the caller can report them.
+func (a *Analyzer) LoadCustomDomains(path string) ([]Conflict, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("reading custom domains: %w", err)
+		return nil, fmt.Errorf("reading custom domains: %w", err)
 	}
 
-	var sf servicesFile
-	if err := json.Unmarshal(data, &sf); err != nil {
-		return fmt.Errorf("parsing custom domains: %w", err)
+	sf, err := decodeServicesFile(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing custom domains: %w", err)
+	}
+	if err := checkUserAgentRules(sf.Services); err != nil {
+		return nil, fmt.Errorf("parsing custom domains: %w", err)
+	}
+	if err := checkHostPatterns(sf.Services); err != nil {
+		return nil, fmt.Errorf("parsing custom domains: %w", err)
+	}
+	if err := checkIPRanges(sf.Services); err != nil {
+		return nil, fmt.Errorf("parsing custom domains: %w", err)
 	}
+	a.sumDB(data)
+	return a.AddServices(sf.Services), nil
+}
+
+func (a *Analyzer) sumDB(data []byte) {
+	if a.dbSum == nil {
+		a.dbSum = sha256.New()
+	}
+	a.dbSum.Write(data)
+}
+
+// DBInfo returns the version and date of the main database, as its file
+// declares them. Files merged over it do not change it.
+func (a *Analyzer) DBInfo() DBInfo {
+	return a.info
+}
+
+// DBHash returns the hex SHA-256 of the database files loaded, in load
+// order: with only ai_services.json it matches sha256sum of that file.
+// It is empty for an analyzer built only from services in code.
+func (a *Analyzer) DBHash() string {
+	if a.dbSum == nil {
+		return ""
+	}
+	return hex.EncodeToString(a.dbSum.Sum(nil))
+}
 
-	for _, svc := range sf.Services {
+// AddServices merges services over those already loaded. The new entries
+// win; the domains they took over from another service are returned.
+// Invalid User-Agent rules, host patterns, and IP ranges are skipped;
+// ParseServices reports them.
+func (a *Analyzer) AddServices(services []AIService) []Conflict {
+	var conflicts []Conflict
+	for _, svc := range services {
+		a.byName[svc.Name] = svc
+		for _, rule := range svc.UserAgents {
+			if m, err := rule.compile(svc.Name); err == nil {
+				a.uaRules = append(a.uaRules, m)
+			}
+		}
+		for _, domain := range svc.Tooling {
+			key := normalizeDomain(domain)
+			if prev, ok := a.domainMap[key]; ok && prev.Name != svc.Name {
+				conflicts = append(conflicts, Conflict{Domain: key, Previous: prev.Name, Service: svc.Name})
+			}
+			a.domainMap[key] = svc
+			a.domains.addDomain(key)
+			a.squats.addBrand(key)
+			a.tooling[key] = true
+		}
+		for _, pattern := range svc.HostPatterns {
+			if p, err := compileHostPattern(pattern, svc); err == nil {
+				a.domains.addPattern(p, len(a.patterns))
+				a.patterns = append(a.patterns, p)
+			}
+		}
+		for _, r := range svc.IPRanges {
+			if p, err := parseIPRange(r); err == nil {
+				a.ipRanges = append(a.ipRanges, ipRange{prefix: p, service: svc.Name})
+			}
+		}
+		for _, hash := range svc.JA3 {
+			a.ja3Map[strings.ToLower(strings.TrimSpace(hash))] = svc.Name
+		}
 		for _, domain := range svc.Domains {
-			a.domainMap[strings.ToLower(domain)] = svc
+			key := normalizeDomain(domain)
+			if prev, ok := a.domainMap[key]; ok && prev.Name != svc.Name {
+				conflicts = append(conflicts, Conflict{Domain: key, Previous: prev.Name, Service: svc.Name})
+			}
+			a.domainMap[key] = svc
+			a.domains.addDomain(key)
+			a.squats.addBrand(key)
+			delete(a.tooling, key)
 		}
 	}
-	return nil
+	return conflicts
+}
+
+// SetBusinessHours enables flagging of AI usage outside the given window.
+func (a *Analyzer) SetBusinessHours(bh *BusinessHours) {
+	a.hours = bh
 }
 
 // Analyze checks a slice of log entries against known AI domains.
 func (a *Analyzer) Analyze(entries []parsers.LogEntry) Summary {
-	summary := Summary{
-		TotalLogsScanned: len(entries),
-		ByUser:           make(map[string]int),
-		ByService:        make(map[string]int),
-	}
+	s, _ := a.AnalyzeContext(context.Background(), entries)
+	return s
+}
+
+// ctxCheckEntries is how many entries are matched between cancellation checks.
+const ctxCheckEntries = 4096
+
+// AnalyzeContext is Analyze for large batches: once ctx is cancelled it
+// stops and returns a partial summary of the entries checked so far, along
+// with ctx.Err().
+func (a *Analyzer) AnalyzeContext(ctx context.Context, entries []parsers.LogEntry) (Summary, error) {
+	findings, n, err := a.MatchEntries(ctx, entries)
+	s := Summarize(findings, n)
+	s.Partial = err != nil
+	return s, err
+}
 
-	for _, entry := range entries {
-		svc, found := a.matchDomain(entry.Domain)
-		if !found {
-			continue
+// MatchEntries matches entries one at a time and returns every finding, DoH
+// lookups included, for callers that summarize later. Once ctx is cancelled
+// it stops and returns the findings so far, how many entries were checked,
+// and ctx.Err().
+func (a *Analyzer) MatchEntries(ctx context.Context, entries []parsers.LogEntry) ([]Finding, int, error) {
+	var findings []Finding
+	for i, entry := range entries {
+		if i%ctxCheckEntries == 0 && i > 0 && ctx.Err() != nil {
+			return findings, i, ctx.Err()
+		}
+		if finding, ok := a.Match(entry); ok {
+			findings = append(findings, finding)
 		}
+	}
+	return findings, len(entries), nil
+}
 
-		finding := Finding{
-			Timestamp:   entry.Timestamp,
-			SourceIP:    entry.SourceIP,
-			ServiceName: svc.Name,
-			Category:    svc.Category,
-			Domain:      entry.Domain,
-			URL:         entry.URL,
-			Method:      entry.Method,
-			StatusCode:  entry.StatusCode,
-			BytesSent:   entry.BytesSent,
+// AnalyzeReader parses r line by line with p and analyzes the entries as
+// they are read, without holding them in memory. The summary's single
+// source records the lines p rejected. Once ctx is cancelled it returns a
+// partial summary along with ctx.Err().
+func (a *Analyzer) AnalyzeReader(ctx context.Context, r io.Reader, p parsers.LineParser) (Summary, error) {
+	var findings []Finding
+	cov := SourceCoverage{Format: p.Name()}
+	batch := make([]parsers.LogEntry, 0, 1)
+	malformed, err := parsers.ReadEntries(ctx, r, p, func(e parsers.LogEntry) {
+		cov.Add(append(batch[:0], e))
+		if f, ok := a.Match(e); ok {
+			findings = append(findings, f)
 		}
+	})
+	cov.Findings, cov.Malformed = CountAI(findings), malformed
 
-		summary.Findings = append(summary.Findings, finding)
-		summary.ByUser[entry.SourceIP]++
-		summary.ByService[svc.Name]++
+	summary := Summarize(findings, cov.Entries)
+	summary.Sources = []SourceCoverage{cov}
+	if err != nil && ctx.Err() != nil {
+		summary.Partial = true
+		return summary, err
+	}
+	if err != nil {
+		return Summary{}, err
+	}
+	return summary, nil
+}
+
+// Match checks a single log entry, returning the finding if it hit a known
+// AI service. An IP destination is matched by its TLS SNI; entries to
+// unknown destinations still match when their JA3 fingerprint or
+// User-Agent belongs to a service.
+func (a *Analyzer) Match(entry parsers.LogEntry) (Finding, bool) {
+	entry.Domain, entry.SNI = canonicalHost(entry.Domain), canonicalHost(entry.SNI)
+	svc, matched, found := a.matchDomain(entry.Domain)
+	detectedBy := ""
+	if !found && entry.SNI != "" && entry.SNI != entry.Domain {
+		if svc, matched, found = a.matchDomain(entry.SNI); found {
+			entry.Domain = entry.SNI
+		}
+	}
+	if !found && len(a.ipRanges) > 0 {
+		if svc, found = a.matchIP(entry.Domain); found {
+			detectedBy = DetectedByIPRange
+		}
+	}
+	if !found && a.lookalikes {
+		if svc, matched, found = a.matchLookalike(entry.Domain); found {
+			detectedBy = DetectedByLookalike
+		}
+	}
+	if !found && a.squats.enabled {
+		if svc, matched, found = a.matchTyposquat(entry.Domain); found {
+			detectedBy = DetectedByTyposquat
+		}
+	}
+	if !found {
+		if name, ok := a.ja3Map[entry.JA3]; ok && entry.JA3 != "" {
+			svc, found, detectedBy = a.serviceNamed(name), true, DetectedByJA3
+		}
+	}
+	if !found {
+		if svc, found = a.matchUserAgent(entry); !found {
+			return Finding{}, false
+		}
+		detectedBy = DetectedByUserAgent
+	}
+	if svc.Category == CategoryVPN && !a.vpn {
+		return Finding{}, false
+	}
+	if detectedBy == DetectedByLookalike || detectedBy == DetectedByTyposquat {
+		// The host only imitates svc, so it is counted apart from its use.
+		svc.Category, svc.Hosting = CategoryLookalike, ""
 	}
 
-	summary.TotalFindings = len(summary.Findings)
-	summary.UniqueUsers = len(summary.ByUser)
-	summary.UniqueServices = len(summary.ByService)
+	activity := classifyActivity(svc, entry)
+	switch {
+	case detectedBy == "" && a.tooling[matched]:
+		activity = ActivityTooling
+	case detectedBy == DetectedByUserAgent:
+		activity = ActivityAPI // SDK traffic is API use whatever the path
+	case svc.Category == CategoryDoH:
+		activity = ActivityUnknown // every query is a POST or GET of /dns-query
+	case svc.Category == CategoryVPN:
+		activity = ActivityUnknown // the tunnel hides what it carries
+	}
+	ts := a.timestamp(entry)
+	u := entry.URL
+	if a.scrubber != nil && u != "" {
+		u = a.scrubber.Scrub(u)
+	}
+	return Finding{
+		Timestamp:     ts,
+		SourceIP:      NormalizeIP(entry.SourceIP),
+		User:          entry.User,
+		ServiceName:   svc.Name,
+		Provider:      svc.ProviderName(),
+		Category:      svc.Category,
+		Domain:        entry.Domain,
+		URL:           u,
+		Method:        entry.Method,
+		StatusCode:    entry.StatusCode,
+		Action:        entry.Action,
+		BytesSent:     entry.BytesSent,
+		BytesReceived: entry.BytesReceived,
+		Activity:      activity,
+		UserAgent:     entry.UserAgent,
+		JA3:           entry.JA3,
+		ProcessName:   entry.ProcessName,
+		DeviceName:    entry.DeviceName,
+		DetectedBy:    detectedBy,
+		Blocked:       isBlocked(entry),
+		CloudHosted:   svc.Hosting == HostingCloud,
+		OffHours:      a.hours != nil && !ts.IsZero() && !a.hours.Contains(ts),
+		SourceFile:    entry.SourceFile,
+		LineNumber:    entry.LineNumber,
+		Tenant:        a.tenant,
+	}, true
+}
 
+// Summarize builds the aggregate counts for a set of findings. DoH
+// lookups among them are dropped from Findings once counted in DoHClients.
+func Summarize(findings []Finding, logsScanned int) Summary {
+	a := NewAggregator()
+	for _, f := range findings {
+		a.Add(f)
+	}
+	summary := a.Summary(logsScanned)
+	summary.Findings = findings
+	if slices.ContainsFunc(findings, Finding.IsDoH) {
+		summary.Findings = slices.DeleteFunc(slices.Clone(findings), Finding.IsDoH)
+	}
 	return summary
 }
 
+// ProviderName returns the provider a finding rolls up to. Findings recorded
+// before providers existed fall back to the service name.
+func (f Finding) ProviderName() string {
+	if f.Provider != "" {
+		return f.Provider
+	}
+	return f.ServiceName
+}
+
+// Bytes is the traffic of a finding in both directions.
+func (f Finding) Bytes() int64 {
+	return f.BytesSent + f.BytesReceived
+}
+
+// Identity is who a finding is attributed to: the authenticated user name
+// when the log recorded one, otherwise the source IP.
+func (f Finding) Identity() string {
+	if f.User != "" {
+		return f.User
+	}
+	return f.SourceIP
+}
+
+// Filter returns a new summary containing only the findings for which keep
+// returns true, with aggregates recomputed.
+func (s Summary) Filter(keep func(Finding) bool) Summary {
+	var kept []Finding
+	for _, f := range s.Findings {
+		if keep(f) {
+			kept = append(kept, f)
+		}
+	}
+	return s.resummarize(kept)
+}
+
+// resummarize recomputes the aggregates over findings, which replace s's,
+// and carries over the fields that describe the scan rather than derive
+// from its findings. Passes that rewrite findings go through it so none of
+// those fields is lost.
+func (s Summary) resummarize(findings []Finding) Summary {
+	out := Summarize(findings, s.TotalLogsScanned)
+	out.Sources = s.Sources
+	out.Partial = s.Partial
+	out.Tenant = s.Tenant
+	out.Scan = s.Scan
+	out.Layout = s.Layout
+	out.DoHClients = s.DoHClients // resolver lookups are no longer among the findings
+	return out
+}
+
+// SetTenant stamps tenant on every finding the analyzer reports, for
+// scans run on behalf of one business unit.
+func (a *Analyzer) SetTenant(tenant string) {
+	a.tenant = tenant
+}
+
+// WithTenant returns a copy of the summary with tenant stamped on it and
+// on each of its findings.
+func (s Summary) WithTenant(tenant string) Summary {
+	findings := make([]Finding, len(s.Findings))
+	for i, f := range s.Findings {
+		f.Tenant = tenant
+		findings[i] = f
+	}
+	s.Findings = findings
+	s.Tenant = tenant
+	return s
+}
+
+// OfTenant returns the findings recorded for tenant. An empty tenant keeps
+// them all, so untagged scans see every business unit.
+func OfTenant(findings []Finding, tenant string) []Finding {
+	if tenant == "" {
+		return findings
+	}
+	var out []Finding
+	for _, f := range findings {
+		if f.Tenant == tenant {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// NormalizeCategory folds a category name for comparison, so that
+// "Code Assistant", "code-assistant", and "code_assistant" are equal.
+func NormalizeCategory(c string) string {
+	c = strings.ToLower(strings.TrimSpace(c))
+	return strings.NewReplacer(" ", "-", "_", "-").Replace(c)
+}
+
+// InCategories returns a filter keeping findings whose category is in the
+// list (compared with NormalizeCategory).
+func InCategories(categories []string) func(Finding) bool {
+	want := make(map[string]bool)
+	for _, c := range categories {
+		want[NormalizeCategory(c)] = true
+	}
+	return func(f Finding) bool {
+		return want[NormalizeCategory(f.Category)]
+	}
+}
+
 // ServiceCount returns how many AI services are loaded.
 func (a *Analyzer) ServiceCount() int {
 	seen := make(map[string]bool)
@@ -142,29 +636,87 @@ func (a *Analyzer) ServiceCount() int {
 	return len(seen)
 }
 
+// Lookup reports which service, if any, a domain would be attributed to and
+// the database entry responsible.
+func (a *Analyzer) Lookup(domain string) (AIService, string, bool) {
+	return a.matchDomain(strings.TrimSuffix(domain, "."))
+}
+
+// Services returns the loaded services sorted by name, each with the domains
+// it is actually matched on after custom overrides.
+func (a *Analyzer) Services() []AIService {
+	byName := make(map[string]*AIService)
+	for domain, svc := range a.domainMap {
+		s, ok := byName[svc.Name]
+		if !ok {
+			s = &AIService{Name: svc.Name, Provider: svc.Provider, Category: svc.Category, Endpoints: svc.Endpoints, UserAgents: svc.UserAgents, JA3: svc.JA3, HostPatterns: svc.HostPatterns, IPRanges: svc.IPRanges, Hosting: svc.Hosting, Source: svc.Source, Reference: svc.Reference}
+			byName[svc.Name] = s
+		}
+		if a.tooling[domain] {
+			s.Tooling = append(s.Tooling, domain)
+		} else {
+			s.Domains = append(s.Domains, domain)
+		}
+	}
+	for name, svc := range a.byName {
+		if _, ok := byName[name]; !ok && (len(svc.UserAgents) > 0 || len(svc.JA3) > 0 || len(svc.HostPatterns) > 0 || len(svc.IPRanges) > 0) {
+			byName[name] = &AIService{Name: svc.Name, Provider: svc.Provider, Category: svc.Category, UserAgents: svc.UserAgents, JA3: svc.JA3, HostPatterns: svc.HostPatterns, IPRanges: svc.IPRanges, Hosting: svc.Hosting, Source: svc.Source, Reference: svc.Reference}
+		}
+	}
+
+	services := make([]AIService, 0, len(byName))
+	for _, s := range byName {
+		sort.Strings(s.Domains)
+		sort.Strings(s.Tooling)
+		services = append(services, *s)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return strings.ToLower(services[i].Name) < strings.ToLower(services[j].Name)
+	})
+	return services
+}
+
 // DomainCount returns how many domains are being watched.
 func (a *Analyzer) DomainCount() int {
 	return len(a.domainMap)
 }
 
-// matchDomain checks if a domain (or any parent domain) matches a known AI service.
-func (a *Analyzer) matchDomain(domain string) (AIService, bool) {
-	domain = strings.ToLower(domain)
-
-	// Exact match
-	if svc, ok := a.domainMap[domain]; ok {
-		return svc, true
-	}
+// matchDomain checks if a domain (or any parent domain) matches a known AI
+// service, returning the database entry or host pattern that matched.
+func (a *Analyzer) matchDomain(domain string) (AIService, string, bool) {
+	domain = strings.ToLower(canonicalHost(domain))
 
-	// Subdomain matching: try stripping subdomains progressively
-	// e.g., "foo.api.openai.com" -> "api.openai.com" -> "openai.com"
-	parts := strings.Split(domain, ".")
-	for i := 1; i < len(parts)-1; i++ {
-		parent := strings.Join(parts[i:], ".")
-		if svc, ok := a.domainMap[parent]; ok {
-			return svc, true
+	// Walk the host's labels from the right, e.g. "com", "openai", "api",
+	// "foo" for "foo.api.openai.com". The deepest database domain on the
+	// way wins; an entry that is a public suffix, such as "co.uk" or
+	// "azurewebsites.net", matches only itself, since the names under it
+	// belong to unrelated owners. Hosts with a variable label, such as
+	// bedrock-runtime.<region>.amazonaws.com, fall to the latest-loaded
+	// pattern anchored on the way.
+	n := &a.domains.root
+	matched, pattern := "", -1
+	rest, more := domain, true
+	for more {
+		var label string
+		rest, label, more = cutLabel(rest)
+		if n = n.children[label]; n == nil {
+			break
+		}
+		if n.domain != "" && (!n.shared || !more) {
+			matched = n.domain
+		}
+		for _, i := range n.patterns {
+			if i > pattern && a.patterns[i].match(domain) {
+				pattern = i
+			}
 		}
 	}
-
-	return AIService{}, false
+	switch {
+	case matched != "":
+		return a.domainMap[matched], matched, true
+	case pattern >= 0:
+		p := a.patterns[pattern]
+		return p.service, p.pattern, true
+	}
+	return AIService{}, "", false
 }
@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// blockedMarkers are substrings of proxy/firewall actions that mean the
+// request never reached the AI service (TCP_DENIED, BLOCK, deny, drop...).
+var blockedMarkers = []string{"DENIED", "DENY", "BLOCK", "DROP", "REJECT", "RESET"}
+
+// isBlocked reports whether the proxy or firewall stopped the request, based
+// on the action field and, failing that, a 403 status.
+func isBlocked(entry parsers.LogEntry) bool {
+	action := strings.ToUpper(entry.Action)
+	for _, marker := range blockedMarkers {
+		if strings.Contains(action, marker) {
+			return true
+		}
+	}
+	return entry.Action == "" && entry.StatusCode == "403"
+}
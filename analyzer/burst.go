@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultBurstWindow is the span requests are counted over when none is
+// given.
+const DefaultBurstWindow = 5 * time.Minute
+
+// Burst is a source whose requests came faster than a person browsing
+// makes them, which points to a script or an API client rather than a
+// chat window.
+type Burst struct {
+	User      string    `json:"user"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Hits      int       `json:"hits"` // findings inside a burst
+	Rate      float64   `json:"rate"` // peak requests per minute
+}
+
+// TagBursts marks findings as automation suspected where their source
+// made more than threshold requests to AI services within window, and
+// returns the summary with aggregates recomputed. Each tagged finding
+// carries the peak rate of its burst. Findings without a timestamp are
+// never tagged.
+func TagBursts(s Summary, threshold int, window time.Duration) Summary {
+	if threshold <= 0 {
+		return s
+	}
+	if window <= 0 {
+		window = DefaultBurstWindow
+	}
+	findings := make([]Finding, len(s.Findings))
+	copy(findings, s.Findings)
+	bySource := make(map[string][]int)
+	for i, f := range findings {
+		if !f.Timestamp.IsZero() {
+			bySource[f.Identity()] = append(bySource[f.Identity()], i)
+		}
+	}
+	for _, idx := range bySource {
+		sort.SliceStable(idx, func(i, j int) bool {
+			return findings[idx[i]].Timestamp.Before(findings[idx[j]].Timestamp)
+		})
+		markBursts(findings, idx, threshold, window)
+	}
+
+	return s.resummarize(findings)
+}
+
+// markBursts slides a window over one source's findings, given in time
+// order by idx, and tags every finding in a window holding more than
+// threshold. Overlapping windows form one burst.
+func markBursts(findings []Finding, idx []int, threshold int, window time.Duration) {
+	type run struct{ from, to, peak int }
+	var runs []run
+	left := 0
+	for right := range idx {
+		t := findings[idx[right]].Timestamp
+		for t.Sub(findings[idx[left]].Timestamp) >= window {
+			left++
+		}
+		n := right - left + 1
+		if n <= threshold {
+			continue
+		}
+		if k := len(runs) - 1; k >= 0 && left <= runs[k].to {
+			runs[k].to, runs[k].peak = right, max(runs[k].peak, n)
+		} else {
+			runs = append(runs, run{from: left, to: right, peak: n})
+		}
+	}
+	for _, r := range runs {
+		rate := float64(r.peak) / window.Minutes()
+		for _, i := range idx[r.from : r.to+1] {
+			findings[i].Automated, findings[i].Rate = true, rate
+		}
+	}
+}
+
+// addBurst counts a tagged finding against its source.
+func addBurst(bySource map[string]*Burst, f Finding) {
+	b, ok := bySource[f.Identity()]
+	if !ok {
+		b = &Burst{User: f.Identity()}
+		bySource[b.User] = b
+	}
+	b.Hits++
+	b.Rate = max(b.Rate, f.Rate)
+	if !f.Timestamp.IsZero() {
+		if b.FirstSeen.IsZero() || f.Timestamp.Before(b.FirstSeen) {
+			b.FirstSeen = f.Timestamp
+		}
+		if f.Timestamp.After(b.LastSeen) {
+			b.LastSeen = f.Timestamp
+		}
+	}
+}
+
+// bursts lists the sources collected by addBurst, fastest first.
+func bursts(bySource map[string]*Burst) []Burst {
+	out := make([]Burst, 0, len(bySource))
+	for _, b := range bySource {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Rate != out[j].Rate {
+			return out[i].Rate > out[j].Rate
+		}
+		return out[i].User < out[j].User
+	})
+	return out
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// HostingCloud marks services that run inside the customer's own cloud
+// provider account (Amazon Bedrock, Azure OpenAI, Vertex AI). Their use is
+// governed by the cloud contract rather than consumer terms of service.
+const HostingCloud = "cloud"
+
+// hostPattern is a compiled host_patterns entry: one glob per DNS label,
+// matched against the rightmost labels of a host.
+type hostPattern struct {
+	pattern string
+	labels  []string
+	service AIService
+}
+
+// compileHostPattern checks a host_patterns entry. "*" and the other
+// path.Match wildcards apply within a single label, so
+// "bedrock-runtime.*.amazonaws.com" covers every region.
+func compileHostPattern(pattern string, svc AIService) (hostPattern, error) {
+	p := strings.ToLower(canonicalHost(strings.TrimSpace(pattern)))
+	labels := strings.Split(p, ".")
+	if len(labels) < 2 || strings.ContainsAny(p, "/:") {
+		return hostPattern{}, fmt.Errorf("host pattern %q is not a host name", pattern)
+	}
+	for _, l := range labels {
+		if l == "" {
+			return hostPattern{}, fmt.Errorf("host pattern %q has an empty label", pattern)
+		}
+		if _, err := path.Match(l, ""); err != nil {
+			return hostPattern{}, fmt.Errorf("host pattern %q: %w", pattern, err)
+		}
+	}
+	if strings.ContainsAny(labels[len(labels)-1]+labels[len(labels)-2], "*?[") {
+		return hostPattern{}, fmt.Errorf("host pattern %q must end in a literal domain", pattern)
+	}
+	return hostPattern{pattern: p, labels: labels, service: svc}, nil
+}
+
+// match reports whether host, or a parent of it, fits the pattern.
+func (p hostPattern) match(host string) bool {
+	rest, more := host, true
+	for i := len(p.labels) - 1; i >= 0; i-- {
+		if !more {
+			return false
+		}
+		var label string
+		rest, label, more = cutLabel(rest)
+		if ok, _ := path.Match(p.labels[i], label); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHostPatterns reports the first invalid host pattern in services.
+func checkHostPatterns(services []AIService) error {
+	for _, svc := range services {
+		for _, pattern := range svc.HostPatterns {
+			if _, err := compileHostPattern(pattern, svc); err != nil {
+				return fmt.Errorf("service %s: %w", svc.Name, err)
+			}
+		}
+	}
+	return nil
+}
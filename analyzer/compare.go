@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// Change is a count in an earlier and a later report.
+type Change struct {
+	Old int
+	New int
+}
+
+// Diff is the difference between the reports (negative means fewer).
+func (c Change) Diff() int {
+	return c.New - c.Old
+}
+
+// Percent is the change relative to the earlier count. ok is false when
+// the earlier report had none, since any growth from zero is unbounded.
+func (c Change) Percent() (pct float64, ok bool) {
+	if c.Old == 0 {
+		return 0, false
+	}
+	return float64(c.New-c.Old) * 100 / float64(c.Old), true
+}
+
+// Comparison is the delta between two reports of the same network, such as
+// before and after a policy rollout.
+type Comparison struct {
+	OldFirstSeen, OldLastSeen time.Time
+	NewFirstSeen, NewLastSeen time.Time
+
+	Findings      Change
+	Blocked       Change
+	Users         Change
+	Services      Change
+	BytesSent     [2]int64 // earlier, later
+	BytesReceived [2]int64
+
+	ByService  map[string]Change
+	ByCategory map[string]Change
+	ByUser     map[string]Change
+
+	// Services and users seen in only one of the reports, busiest first.
+	NewServices  []string
+	GoneServices []string
+	NewUsers     []string
+	GoneUsers    []string
+}
+
+// Compare measures how AI usage moved from before to after. Only the
+// aggregates are used, so reports loaded without findings compare too.
+func Compare(before, after Summary) Comparison {
+	c := Comparison{
+		OldFirstSeen:  before.FirstSeen,
+		OldLastSeen:   before.LastSeen,
+		NewFirstSeen:  after.FirstSeen,
+		NewLastSeen:   after.LastSeen,
+		Findings:      Change{before.TotalFindings, after.TotalFindings},
+		Blocked:       Change{before.BlockedFindings, after.BlockedFindings},
+		Users:         Change{before.UniqueUsers, after.UniqueUsers},
+		Services:      Change{before.UniqueServices, after.UniqueServices},
+		BytesSent:     [2]int64{before.BytesSent, after.BytesSent},
+		BytesReceived: [2]int64{before.BytesReceived, after.BytesReceived},
+		ByService:     changes(before.ByService, after.ByService),
+		ByCategory:    changes(before.ByCategory, after.ByCategory),
+		ByUser:        changes(before.ByUser, after.ByUser),
+	}
+	c.NewServices, c.GoneServices = appeared(c.ByService)
+	c.NewUsers, c.GoneUsers = appeared(c.ByUser)
+	return c
+}
+
+// changes pairs up the counts of every key in either map.
+func changes(before, after map[string]int) map[string]Change {
+	out := make(map[string]Change, len(after))
+	for k, n := range before {
+		out[k] = Change{Old: n}
+	}
+	for k, n := range after {
+		c := out[k]
+		c.New = n
+		out[k] = c
+	}
+	return out
+}
+
+// appeared lists the keys found only in the later report and those found
+// only in the earlier one, each ordered by the hits it had.
+func appeared(m map[string]Change) (added, gone []string) {
+	for k, c := range m {
+		switch {
+		case c.Old == 0 && c.New > 0:
+			added = append(added, k)
+		case c.Old > 0 && c.New == 0:
+			gone = append(gone, k)
+		}
+	}
+	byHits := func(keys []string, hits func(Change) int) {
+		sort.Slice(keys, func(i, j int) bool {
+			if a, b := hits(m[keys[i]]), hits(m[keys[j]]); a != b {
+				return a > b
+			}
+			return keys[i] < keys[j]
+		})
+	}
+	byHits(added, func(c Change) int { return c.New })
+	byHits(gone, func(c Change) int { return c.Old })
+	return added, gone
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// SourceCoverage describes how much detection-relevant detail one log source
+// provides, so weak sources can be enriched or replaced.
+type SourceCoverage struct {
+	Source       string `json:"source"`
+	Format       string `json:"format"`
+	Entries      int    `json:"entries"`
+	WithDomain   int    `json:"with_domain"`   // destination is a hostname
+	IPOnly       int    `json:"ip_only"`       // destination is a bare IP address with no SNI
+	WithURL      int    `json:"with_url"`      // full URL available for activity classification
+	WithIdentity int    `json:"with_identity"` // a client identity is attached
+	Findings     int    `json:"findings"`
+	Malformed    int    `json:"malformed"` // lines the parser rejected
+	// FirstEntry and LastEntry bound the entries' timestamps as logged,
+	// with zoneless times read as UTC.
+	FirstEntry time.Time `json:"first_entry,omitzero"`
+	LastEntry  time.Time `json:"last_entry,omitzero"`
+}
+
+// Coverage measures the entries parsed from one source.
+func Coverage(source, format string, entries []parsers.LogEntry) SourceCoverage {
+	c := SourceCoverage{Source: source, Format: format}
+	c.Add(entries)
+	return c
+}
+
+// Add counts further entries from the same source.
+func (c *SourceCoverage) Add(entries []parsers.LogEntry) {
+	c.Entries += len(entries)
+	for _, e := range entries {
+		host := strings.Trim(e.Domain, "[]")
+		switch {
+		case host == "":
+		case net.ParseIP(host) != nil && e.SNI == "":
+			c.IPOnly++
+		default:
+			c.WithDomain++
+		}
+		if e.URL != "" {
+			c.WithURL++
+		}
+		if e.User != "" || (e.SourceIP != "" && e.SourceIP != "-") {
+			c.WithIdentity++
+		}
+		c.span(e.Timestamp, e.Timestamp)
+	}
+}
+
+// span widens the entry time range to take in first..last.
+func (c *SourceCoverage) span(first, last time.Time) {
+	if !first.IsZero() && (c.FirstEntry.IsZero() || first.Before(c.FirstEntry)) {
+		c.FirstEntry = first
+	}
+	if last.After(c.LastEntry) {
+		c.LastEntry = last
+	}
+}
+
+// Merge adds the counts of another part of the same source.
+func (c *SourceCoverage) Merge(o SourceCoverage) {
+	c.Entries += o.Entries
+	c.WithDomain += o.WithDomain
+	c.IPOnly += o.IPOnly
+	c.WithURL += o.WithURL
+	c.WithIdentity += o.WithIdentity
+	c.Findings += o.Findings
+	c.Malformed += o.Malformed
+	c.span(o.FirstEntry, o.LastEntry)
+}
+
+// Percent returns n as a whole percentage of the source's entries.
+func (c SourceCoverage) Percent(n int) int {
+	if c.Entries == 0 {
+		return 0
+	}
+	return n * 100 / c.Entries
+}
+
+// MalformedRatio is the share of the source's lines the parser rejected.
+// A high ratio usually means the wrong format was chosen.
+func (c SourceCoverage) MalformedRatio() float64 {
+	if c.Entries+c.Malformed == 0 {
+		return 0
+	}
+	return float64(c.Malformed) / float64(c.Entries+c.Malformed)
+}
+
+// MalformedLines totals the rejected lines across a summary's sources.
+func (s Summary) MalformedLines() int {
+	n := 0
+	for _, c := range s.Sources {
+		n += c.Malformed
+	}
+	return n
+}
+
+// LogRange returns the earliest and latest entry timestamps across a
+// summary's sources: the period its logs cover. Both are zero when no
+// entry had a timestamp.
+func (s Summary) LogRange() (first, last time.Time) {
+	var all SourceCoverage
+	for _, c := range s.Sources {
+		all.span(c.FirstEntry, c.LastEntry)
+	}
+	return all.FirstEntry, all.LastEntry
+}
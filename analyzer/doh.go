@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// CategoryDoH is the category of the bundled DNS over HTTPS resolvers. A
+// client that resolves names over HTTPS skips the local DNS server, so its
+// lookups of AI services never reach the DNS logs a scan reads.
+const CategoryDoH = "DNS over HTTPS"
+
+// IsDoH reports whether f is a lookup through a DNS over HTTPS resolver.
+// That is not AI use, so such findings are reported only as the summary's
+// DoHClients and left out of its findings and every count of them.
+func (f Finding) IsDoH() bool {
+	return f.Category == CategoryDoH
+}
+
+// CountAI counts the findings that are AI use, leaving out DoH lookups.
+func CountAI(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if !f.IsDoH() {
+			n++
+		}
+	}
+	return n
+}
+
+// DoHUse is a client seen talking to a DNS over HTTPS resolver.
+type DoHUse struct {
+	User      string    `json:"user"`
+	Resolver  string    `json:"resolver"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Hits      int       `json:"hits"`
+}
+
+// addDoHUse counts a DoH finding against its user/resolver pair.
+func addDoHUse(byPair map[[2]string]*DoHUse, f Finding) {
+	key := [2]string{f.Identity(), f.ServiceName}
+	d, ok := byPair[key]
+	if !ok {
+		d = &DoHUse{User: key[0], Resolver: key[1]}
+		byPair[key] = d
+	}
+	d.Hits++
+	if !f.Timestamp.IsZero() {
+		if d.FirstSeen.IsZero() || f.Timestamp.Before(d.FirstSeen) {
+			d.FirstSeen = f.Timestamp
+		}
+		if f.Timestamp.After(d.LastSeen) {
+			d.LastSeen = f.Timestamp
+		}
+	}
+}
+
+// foldDoHUse adds a pair already tallied elsewhere, such as in another
+// report, to byPair.
+func foldDoHUse(byPair map[[2]string]*DoHUse, u DoHUse) {
+	key := [2]string{u.User, u.Resolver}
+	d, ok := byPair[key]
+	if !ok {
+		byPair[key] = &u
+		return
+	}
+	d.Hits += u.Hits
+	if !u.FirstSeen.IsZero() && (d.FirstSeen.IsZero() || u.FirstSeen.Before(d.FirstSeen)) {
+		d.FirstSeen = u.FirstSeen
+	}
+	if u.LastSeen.After(d.LastSeen) {
+		d.LastSeen = u.LastSeen
+	}
+}
+
+// dohUses lists the pairs collected by addDoHUse, ordered by user then
+// resolver.
+func dohUses(byPair map[[2]string]*DoHUse) []DoHUse {
+	uses := make([]DoHUse, 0, len(byPair))
+	for _, d := range byPair {
+		uses = append(uses, *d)
+	}
+	sort.Slice(uses, func(i, j int) bool {
+		if uses[i].User != uses[j].User {
+			return uses[i].User < uses[j].User
+		}
+		return uses[i].Resolver < uses[j].Resolver
+	})
+	return uses
+}
@@ -0,0 +1,38 @@
+package analyzer
+
+import "time"
+
+// Heatmap counts hits by day of week and hour of day, in the zone findings
+// are reported in. Rows are indexed by time.Weekday, so Sunday comes first.
+type Heatmap [7][24]int
+
+// add counts a hit at t.
+func (h *Heatmap) add(t time.Time) {
+	h[t.Weekday()][t.Hour()]++
+}
+
+// Max is the count of the busiest hour.
+func (h *Heatmap) Max() int {
+	peak := 0
+	for _, day := range h {
+		for _, n := range day {
+			peak = max(peak, n)
+		}
+	}
+	return peak
+}
+
+// addHeat counts a timestamped finding in the overall heatmap and in its
+// service's.
+func addHeat(s *Summary, f Finding) {
+	if f.Timestamp.IsZero() {
+		return
+	}
+	s.Heatmap.add(f.Timestamp)
+	h, ok := s.HeatmapByService[f.ServiceName]
+	if !ok {
+		h = new(Heatmap)
+		s.HeatmapByService[f.ServiceName] = h
+	}
+	h.add(f.Timestamp)
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BusinessHours defines the working window; AI usage outside it is flagged
+// as off-hours activity.
+type BusinessHours struct {
+	Start    time.Duration // offset from local midnight
+	End      time.Duration // may be before Start for overnight shifts
+	Days     [7]bool       // indexed by time.Weekday
+	Location *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseBusinessHours builds a BusinessHours from a "08:00-18:00" window, a day
+// list such as "mon-fri" or "mon,tue,thu", and an IANA timezone name
+// (empty means local time).
+func ParseBusinessHours(window, days, tz string) (*BusinessHours, error) {
+	bh := &BusinessHours{Location: time.Local}
+
+	startStr, endStr, ok := strings.Cut(window, "-")
+	if !ok {
+		return nil, fmt.Errorf("business hours must look like 08:00-18:00, got %q", window)
+	}
+	var err error
+	if bh.Start, err = parseClock(startStr); err != nil {
+		return nil, err
+	}
+	if bh.End, err = parseClock(endStr); err != nil {
+		return nil, err
+	}
+
+	if days == "" {
+		days = "mon-fri"
+	}
+	for _, part := range strings.Split(strings.ToLower(days), ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return nil, fmt.Errorf("unknown weekday %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			bh.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("loading timezone: %w", err)
+		}
+		bh.Location = loc
+	}
+	return bh, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside business hours. For overnight
+// windows the day check applies to the day the shift started.
+func (bh *BusinessHours) Contains(t time.Time) bool {
+	// The wall clock, not the time since midnight, which is an hour off
+	// for the rest of a day on which daylight saving time starts or ends
+	local := t.In(bh.Location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+
+	if bh.Start <= bh.End {
+		return bh.Days[local.Weekday()] && offset >= bh.Start && offset < bh.End
+	}
+	if offset >= bh.Start {
+		return bh.Days[local.Weekday()]
+	}
+	if offset < bh.End {
+		return bh.Days[(local.Weekday()+6)%7]
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DetectedByLookalike marks findings whose host only imitates a database
+// domain with look-alike Unicode characters, such as a Cyrillic "о" in
+// "оpenai.com".
+const DetectedByLookalike = "lookalike"
+
+// idnaProfile converts host names to their ASCII form. It applies the
+// lookup mapping (case folding, full-width forms) but tolerates the
+// underscores and other oddities seen in real logs.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false))
+
+// canonicalHost returns host in the ASCII form used for matching:
+// internationalized labels are converted to punycode, so "ÖpenAI.com" and
+// "xn--penai-iua.com" are the same host. ASCII hosts, and hosts that are not
+// valid internationalized names, are returned unchanged.
+func canonicalHost(host string) string {
+	if isASCII(host) {
+		return host
+	}
+	ascii, err := idnaProfile.ToASCII(host)
+	if err != nil {
+		return host
+	}
+	return ascii
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// SetLookalikes enables flagging of internationalized hosts that spell a
+// database domain with look-alike characters.
+func (a *Analyzer) SetLookalikes(on bool) {
+	a.lookalikes = on
+}
+
+// matchLookalike checks a punycode host that matched nothing against the
+// database with its look-alike characters replaced by the ASCII letters
+// they imitate.
+func (a *Analyzer) matchLookalike(host string) (AIService, string, bool) {
+	host = strings.ToLower(host)
+	if !strings.Contains(host, "xn--") {
+		return AIService{}, "", false
+	}
+	unicodeHost, err := idna.Punycode.ToUnicode(host)
+	if err != nil {
+		return AIService{}, "", false
+	}
+	skeleton, ok := asciiSkeleton(unicodeHost)
+	if !ok || skeleton == host {
+		return AIService{}, "", false
+	}
+	return a.matchDomain(skeleton)
+}
+
+// asciiSkeleton spells host in ASCII by dropping accents and replacing
+// letters that look like Latin ones. It reports false when a character has
+// no ASCII look-alike, since such a host cannot pass for an ASCII domain.
+func asciiSkeleton(host string) (string, bool) {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(host) {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Mn, r):
+			// combining accent left by NFKD
+		default:
+			ascii, ok := confusables[r]
+			if !ok {
+				return "", false
+			}
+			b.WriteByte(ascii)
+		}
+	}
+	return b.String(), true
+}
+
+// confusables maps Cyrillic, Greek, and other letters to the Latin letters
+// they are rendered like in most fonts.
+var confusables = map[rune]byte{
+	// Cyrillic
+	'а': 'a', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'к': 'k', 'ӏ': 'l', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'ѵ': 'v',
+	'ԝ': 'w', 'х': 'x', 'у': 'y', 'ү': 'y',
+	// Greek
+	'α': 'a', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y',
+	// Latin letters without a decomposition
+	'ı': 'i', 'ɩ': 'i', 'ł': 'l', 'ɡ': 'g', 'ø': 'o', 'đ': 'd', 'ħ': 'h',
+}
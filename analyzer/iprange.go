@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// DetectedByIPRange marks findings whose destination was logged as an IP
+// address inside one of a service's ip_ranges.
+const DetectedByIPRange = "ip_range"
+
+// ipRange is a compiled entry of a service's ip_ranges.
+type ipRange struct {
+	prefix  netip.Prefix
+	service string
+}
+
+// parseIPRange accepts a CIDR, such as "160.79.104.0/23" or
+// "2607:6bc0::/48", or a single address.
+func parseIPRange(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("ip range %q: %w", s, err)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("ip range %q: %w", s, err)
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// NormalizeIP returns an IP address in its canonical text form: IPv6
+// lowercased and zero-compressed (RFC 5952), and an IPv4-mapped address
+// as plain IPv4, so that one host is always written one way. Brackets
+// around IPv6 are dropped. Anything that is not an IP comes back as is.
+func NormalizeIP(s string) string {
+	if !strings.ContainsAny(s, ":.") {
+		return s // a host name or "-"; skip the parse
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return s
+	}
+	return addr.Unmap().String()
+}
+
+// checkIPRanges reports the first invalid IP range in services.
+func checkIPRanges(services []AIService) error {
+	for _, svc := range services {
+		for _, r := range svc.IPRanges {
+			if _, err := parseIPRange(r); err != nil {
+				return fmt.Errorf("service %s: %w", svc.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchIP attributes a destination logged as an IP address, in IPv4,
+// IPv6, or IPv4-mapped IPv6 form, to the service whose range holds it.
+// The narrowest range wins; between equal ones, the latest loaded.
+func (a *Analyzer) matchIP(host string) (AIService, bool) {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return AIService{}, false
+	}
+	addr = addr.Unmap()
+	best := -1
+	for i, r := range a.ipRanges {
+		if r.prefix.Contains(addr) && (best < 0 || r.prefix.Bits() >= a.ipRanges[best].prefix.Bits()) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return AIService{}, false
+	}
+	return a.serviceNamed(a.ipRanges[best].service), true
+}
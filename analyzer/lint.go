@@ -0,0 +1,199 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lint severities.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue is one problem found in a services database.
+type LintIssue struct {
+	Severity string `json:"severity"`
+	Source   string `json:"source"`
+	Service  string `json:"service,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ServiceSet is a database to lint and the file it came from. When several
+// sets are linted together, later sets are treated as overrides of earlier
+// ones, as with -custom.
+type ServiceSet struct {
+	Source   string
+	Services []AIService
+}
+
+// Conflict records a custom domain that replaced another service's entry.
+type Conflict struct {
+	Domain   string
+	Previous string
+	Service  string
+}
+
+type domainOwner struct {
+	service string
+	source  string
+}
+
+// Lint checks databases for duplicate and conflicting domains, malformed
+// domains, host patterns, user-agent rules, and JA3 fingerprints, missing names or categories, subdomains
+// claimed by a different service than their parent, and public suffixes listed as domains.
+func Lint(sets ...ServiceSet) []LintIssue {
+	var issues []LintIssue
+	add := func(sev, src, svc, domain, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Severity: sev, Source: src, Service: svc, Domain: domain, Message: fmt.Sprintf(format, args...)})
+	}
+
+	owners := make(map[string]domainOwner)
+	for _, set := range sets {
+		names := make(map[string]bool)
+		for i, svc := range set.Services {
+			name := svc.Name
+			if strings.TrimSpace(name) == "" {
+				name = fmt.Sprintf("#%d", i+1)
+				add(LintError, set.Source, name, "", "service has no name")
+			} else if names[strings.ToLower(name)] {
+				add(LintError, set.Source, name, "", "duplicate service name")
+			}
+			names[strings.ToLower(name)] = true
+
+			if strings.TrimSpace(svc.Category) == "" {
+				add(LintWarning, set.Source, name, "", "empty category")
+			}
+			if len(svc.Domains) == 0 && len(svc.Tooling) == 0 && len(svc.HostPatterns) == 0 && len(svc.IPRanges) == 0 && len(svc.UserAgents) == 0 && len(svc.JA3) == 0 {
+				add(LintError, set.Source, name, "", "service has nothing to match on")
+			}
+			for _, pattern := range svc.HostPatterns {
+				if _, err := compileHostPattern(pattern, svc); err != nil {
+					add(LintError, set.Source, name, pattern, "%s", err)
+				}
+			}
+			for _, r := range svc.IPRanges {
+				if _, err := parseIPRange(r); err != nil {
+					add(LintError, set.Source, name, r, "%s", err)
+				}
+			}
+			if svc.Hosting != "" && svc.Hosting != HostingCloud {
+				add(LintWarning, set.Source, name, "", "unknown hosting %q", svc.Hosting)
+			}
+			for _, hash := range svc.JA3 {
+				if !ja3Hash.MatchString(hash) {
+					add(LintError, set.Source, name, "", "JA3 fingerprint %q is not 32 hex digits", hash)
+				}
+			}
+			for _, rule := range svc.UserAgents {
+				if _, err := rule.compile(name); err != nil {
+					add(LintError, set.Source, name, "", "%s", err)
+				}
+			}
+
+			for _, raw := range append(append([]string{}, svc.Domains...), svc.Tooling...) {
+				if msg := domainProblem(raw); msg != "" {
+					add(LintError, set.Source, name, raw, "%s", msg)
+					continue
+				}
+				domain := normalizeDomain(raw)
+				if publicSuffix(domain) {
+					add(LintWarning, set.Source, name, raw, "public suffix; matches only itself, not the domains under it")
+				}
+				if prev, ok := owners[domain]; ok {
+					switch {
+					case prev.service == svc.Name && prev.source == set.Source:
+						add(LintWarning, set.Source, name, raw, "duplicate domain (a leading \"*.\" is implied)")
+					case prev.source == set.Source:
+						add(LintError, set.Source, name, raw, "domain also listed under %s", prev.service)
+					case prev.service != svc.Name:
+						add(LintWarning, set.Source, name, raw, "overrides %s from %s", prev.service, prev.source)
+					}
+				}
+				owners[domain] = domainOwner{service: svc.Name, source: set.Source}
+			}
+		}
+	}
+
+	// Every entry also matches its subdomains, so a child listed under
+	// another service carves out an exception worth a second look.
+	domains := make([]string, 0, len(owners))
+	for d := range owners {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	for _, d := range domains {
+		parts := strings.Split(d, ".")
+		for i := 1; i < len(parts)-1; i++ {
+			parent := strings.Join(parts[i:], ".")
+			p, ok := owners[parent]
+			if !ok {
+				continue
+			}
+			if child := owners[d]; p.service != child.service {
+				add(LintWarning, child.source, child.service, d, "overlaps %s (%s); the more specific entry wins", parent, p.service)
+			}
+			break
+		}
+	}
+
+	return issues
+}
+
+// ja3Hash matches a JA3 fingerprint, the MD5 of the ClientHello summary.
+var ja3Hash = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// normalizeDomain lowercases a database entry and drops an explicit "*."
+// wildcard, which is implied for every entry.
+func normalizeDomain(domain string) string {
+	return strings.ToLower(canonicalHost(strings.TrimPrefix(strings.TrimSpace(domain), "*.")))
+}
+
+// domainProblem describes what is wrong with a database entry, or returns "".
+func domainProblem(domain string) string {
+	if domain != strings.TrimSpace(domain) {
+		return "domain has surrounding whitespace"
+	}
+	d := strings.TrimPrefix(strings.ToLower(domain), "*.")
+	switch {
+	case d == "":
+		return "empty domain"
+	case strings.Contains(d, "://"):
+		return "domain includes a URL scheme"
+	case strings.ContainsAny(d, "/?#"):
+		return "domain includes a path"
+	case strings.Contains(d, ":"):
+		return "domain includes a port"
+	case strings.Contains(d, "*"):
+		return "wildcards are only allowed as a leading \"*.\""
+	case !strings.Contains(d, "."):
+		return "domain has no dot"
+	}
+	if !isASCII(d) {
+		ascii, err := idnaProfile.ToASCII(d)
+		if err != nil {
+			return fmt.Sprintf("invalid internationalized domain: %v", err)
+		}
+		d = ascii
+	}
+	for _, label := range strings.Split(d, ".") {
+		if label == "" {
+			return "domain has an empty label"
+		}
+		if len(label) > 63 {
+			return "domain label longer than 63 characters"
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "domain label starts or ends with a hyphen"
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return fmt.Sprintf("invalid character %q in domain", r)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	a := NewWithServices([]AIService{
+		{Name: "ChatGPT", Category: "chat", Domains: []string{"openai.com", "chatgpt.com"}},
+		{Name: "OpenAI API", Category: "api", Domains: []string{"api.openai.com"}},
+		{Name: "Amazon Bedrock", Category: "api", HostPatterns: []string{"bedrock-runtime.*.amazonaws.com"}},
+		{Name: "Hosted Bot", Category: "chat", Domains: []string{"azurewebsites.net", "bot.azurewebsites.net"}},
+	})
+
+	tests := []struct {
+		host    string
+		service string // "" when nothing should match
+		matched string
+	}{
+		// Exact matches
+		{"openai.com", "ChatGPT", "openai.com"},
+		{"chatgpt.com", "ChatGPT", "chatgpt.com"},
+		{"api.openai.com", "OpenAI API", "api.openai.com"},
+		{"OpenAI.COM", "ChatGPT", "openai.com"},
+		{"openai.com.", "ChatGPT", "openai.com"},
+
+		// Suffix matches: the deepest database domain wins
+		{"cdn.openai.com", "ChatGPT", "openai.com"},
+		{"a.b.c.chatgpt.com", "ChatGPT", "chatgpt.com"},
+		{"v1.api.openai.com", "OpenAI API", "api.openai.com"},
+
+		// Host patterns, on the host itself and its subdomains
+		{"bedrock-runtime.us-east-1.amazonaws.com", "Amazon Bedrock", "bedrock-runtime.*.amazonaws.com"},
+		{"vpce.bedrock-runtime.eu-west-2.amazonaws.com", "Amazon Bedrock", "bedrock-runtime.*.amazonaws.com"},
+		{"bedrock.us-east-1.amazonaws.com", "", ""},
+		{"s3.amazonaws.com", "", ""},
+
+		// A public suffix matches only itself
+		{"azurewebsites.net", "Hosted Bot", "azurewebsites.net"},
+		{"someone-else.azurewebsites.net", "", ""},
+		{"bot.azurewebsites.net", "Hosted Bot", "bot.azurewebsites.net"},
+		{"eu.bot.azurewebsites.net", "Hosted Bot", "bot.azurewebsites.net"},
+
+		// Look-alikes are not matches
+		{"notopenai.com", "", ""},
+		{"openai.com.evil.net", "", ""},
+		{"com", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		svc, matched, ok := a.Lookup(tt.host)
+		if ok != (tt.service != "") || svc.Name != tt.service || matched != tt.matched {
+			t.Errorf("Lookup(%q) = %q, %q, %v; want %q, %q", tt.host, svc.Name, matched, ok, tt.service, tt.matched)
+		}
+	}
+}
+
+func TestLookupDoesNotAllocate(t *testing.T) {
+	a := loadTestDB(t)
+	hosts := lookupHosts(a)
+	var i int
+	allocs := testing.AllocsPerRun(1000, func() {
+		a.Lookup(hosts[i%len(hosts)])
+		i++
+	})
+	if allocs != 0 {
+		t.Errorf("Lookup made %.1f allocations per host, want 0", allocs)
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	a := loadTestDB(b)
+	hosts := lookupHosts(a)
+	b.Run("split", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			splitLookup(a, hosts[i%len(hosts)])
+		}
+	})
+	b.Run("trie", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			a.Lookup(hosts[i%len(hosts)])
+		}
+	})
+}
+
+func loadTestDB(tb testing.TB) *Analyzer {
+	tb.Helper()
+	a, err := New("../ai_services.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return a
+}
+
+// lookupHosts mixes database domains, subdomains of them, hosts fitting the
+// host patterns, and hosts that match nothing, as the bench subcommand does.
+func lookupHosts(a *Analyzer) []string {
+	var hosts []string
+	for _, svc := range a.Services() {
+		for _, d := range svc.Domains {
+			d = strings.TrimPrefix(d, "*.")
+			hosts = append(hosts, d, "edge-1.eu."+d)
+		}
+		for _, p := range svc.HostPatterns {
+			hosts = append(hosts, strings.NewReplacer("*", "us-east-1", "?", "x").Replace(p))
+		}
+	}
+	for i := range len(hosts) {
+		hosts = append(hosts, fmt.Sprintf("img%d.cdn.static-content%d.example.com", i, i%50))
+	}
+	return hosts
+}
+
+// splitLookup is the lookup the trie replaced: try the host and each parent
+// in the domain map, then every host pattern, latest first.
+func splitLookup(a *Analyzer, domain string) (AIService, string, bool) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if svc, ok := a.domainMap[domain]; ok {
+		return svc, domain, true
+	}
+	parts := strings.Split(domain, ".")
+	for i := 1; i < len(parts)-1; i++ {
+		parent := strings.Join(parts[i:], ".")
+		if svc, ok := a.domainMap[parent]; ok {
+			return svc, parent, true
+		}
+	}
+	for i := len(a.patterns) - 1; i >= 0; i-- {
+		p := a.patterns[i]
+		labels := parts
+		if len(labels) < len(p.labels) {
+			continue
+		}
+		labels = labels[len(labels)-len(p.labels):]
+		matched := true
+		for j, l := range p.labels {
+			if ok, _ := path.Match(l, labels[j]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return p.service, p.pattern, true
+		}
+	}
+	return AIService{}, "", false
+}
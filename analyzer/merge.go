@@ -0,0 +1,146 @@
+package analyzer
+
+import "sort"
+
+// SiteReport is one site's scan, ready to merge.
+type SiteReport struct {
+	Site    string
+	Summary Summary
+}
+
+// SiteStats is one site's share of a merged summary.
+type SiteStats struct {
+	Site     string         `json:"site"`
+	Findings int            `json:"findings"`
+	Blocked  int            `json:"blocked"`
+	Users    int            `json:"unique_users"`
+	Services map[string]int `json:"services"` // service name -> hit count
+}
+
+// findingKey identifies a request independently of which scanner logged
+// it, so the same proxy log read at two sites counts once.
+type findingKey struct {
+	ts                                        int64
+	source, user, domain, url, method, status string
+	sent, received                            int64
+}
+
+func keyOf(f Finding) findingKey {
+	return findingKey{
+		ts:       f.Timestamp.UnixNano(),
+		source:   f.SourceIP,
+		user:     f.User,
+		domain:   f.Domain,
+		url:      f.URL,
+		method:   f.Method,
+		status:   f.StatusCode,
+		sent:     f.BytesSent,
+		received: f.BytesReceived,
+	}
+}
+
+// Merge combines reports from several sites into one summary, keeping
+// findings in report order. Each finding is tagged with its report's site
+// unless an earlier merge already tagged it. A timestamped finding that
+// also appears in an earlier report is dropped, as many times as it
+// repeats there, so overlapping logs are not counted twice while repeated
+// requests within one report are kept. Findings without a timestamp cannot
+// be told apart and are all kept. The merged summary is partial when any
+// report was. It returns the summary and the number of duplicates dropped.
+func Merge(reports []SiteReport) (Summary, int) {
+	var (
+		findings []Finding
+		sources  []SourceCoverage
+		logs     int
+		partial  bool
+		dropped  int
+	)
+	kept := make(map[findingKey]int)
+	doh := make(map[[2]string]*DoHUse)
+	for _, r := range reports {
+		seen := make(map[findingKey]int)
+		for _, f := range r.Summary.Findings {
+			if !f.Timestamp.IsZero() {
+				k := keyOf(f)
+				seen[k]++
+				if seen[k] <= kept[k] {
+					dropped++
+					continue
+				}
+				kept[k]++
+			}
+			if f.Site == "" {
+				f.Site = r.Site
+			}
+			findings = append(findings, f)
+		}
+		for _, u := range r.Summary.DoHClients {
+			foldDoHUse(doh, u)
+		}
+		sources = append(sources, r.Summary.Sources...)
+		logs += r.Summary.TotalLogsScanned
+		partial = partial || r.Summary.Partial
+	}
+	out := Summarize(findings, logs)
+	out.Sources = sources
+	out.DoHClients = dohUses(doh)
+	out.Partial = partial
+	out.Tenant = commonTenant(reports)
+	return out, dropped
+}
+
+// commonTenant is the tenant every report was scanned for, or empty when
+// they differ.
+func commonTenant(reports []SiteReport) string {
+	if len(reports) == 0 {
+		return ""
+	}
+	tenant := reports[0].Summary.Tenant
+	for _, r := range reports[1:] {
+		if r.Summary.Tenant != tenant {
+			return ""
+		}
+	}
+	return tenant
+}
+
+// siteTally collects one site's stats while aggregating.
+type siteTally struct {
+	SiteStats
+	users map[string]bool
+}
+
+// addSite counts a finding against its site. Findings from a single scan
+// have no site and are skipped.
+func addSite(bySite map[string]*siteTally, f Finding) {
+	if f.Site == "" {
+		return
+	}
+	t, ok := bySite[f.Site]
+	if !ok {
+		t = &siteTally{SiteStats: SiteStats{Site: f.Site, Services: make(map[string]int)}, users: make(map[string]bool)}
+		bySite[f.Site] = t
+	}
+	t.Findings++
+	if f.Blocked {
+		t.Blocked++
+	}
+	t.Services[f.ServiceName]++
+	t.users[f.Identity()] = true
+}
+
+// sites lists the sites collected by addSite, busiest first.
+func sites(bySite map[string]*siteTally) []SiteStats {
+	out := make([]SiteStats, 0, len(bySite))
+	for _, t := range bySite {
+		t.Users = len(t.users)
+		out = append(out, t.SiteStats)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Findings != out[j].Findings {
+			return out[i].Findings > out[j].Findings
+		}
+		return out[i].Site < out[j].Site
+	})
+	return out
+}
@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// servicesExts are the file extensions a services database may have.
+// Anything else is read as JSON.
+var servicesExts = map[string]bool{".json": true, ".yaml": true, ".yml": true, ".toml": true}
+
+// decodeServicesFile decodes a services database in the syntax its file
+// name implies: YAML for .yaml and .yml, TOML for .toml, JSON otherwise.
+// YAML and TOML are converted to JSON first, so every syntax uses the
+// same field names as ai_services.json.
+func decodeServicesFile(name string, data []byte) (servicesFile, error) {
+	var sf servicesFile
+	var doc any
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return sf, err
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return sf, err
+		}
+	default:
+		return sf, json.Unmarshal(data, &sf)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return sf, err
+	}
+	return sf, json.Unmarshal(converted, &sf)
+}
+
+// DBInfo identifies a release of a services database, so a report can
+// show which intel produced its findings. Both fields are optional.
+type DBInfo struct {
+	Version     string `json:"version,omitempty"`
+	LastUpdated string `json:"last_updated,omitempty"` // YYYY-MM-DD
+}
+
+// String describes the release, e.g. "2025.06.1 (2025-06-10)"; it is
+// empty when the database declares neither field.
+func (d DBInfo) String() string {
+	switch {
+	case d.LastUpdated == "":
+		return d.Version
+	case d.Version == "":
+		return "updated " + d.LastUpdated
+	}
+	return d.Version + " (" + d.LastUpdated + ")"
+}
+
+// ParseServicesFile is ParseServices for a database in any supported
+// syntax, chosen by the extension of name: .yaml or .yml, .toml, or JSON.
+func ParseServicesFile(name string, data []byte) ([]AIService, error) {
+	_, services, err := ParseDatabase(name, data)
+	return services, err
+}
+
+// ParseDatabase is ParseServicesFile that also returns the database's
+// version and date.
+func ParseDatabase(name string, data []byte) (DBInfo, []AIService, error) {
+	sf, err := decodeServicesFile(name, data)
+	if err != nil {
+		return DBInfo{}, nil, fmt.Errorf("parsing services file: %w", err)
+	}
+	if len(sf.Services) == 0 {
+		return DBInfo{}, nil, fmt.Errorf("parsing services file: no services defined")
+	}
+	if sf.LastUpdated != "" {
+		// Unquoted YAML and TOML dates arrive converted to timestamps
+		if t, err := time.Parse(time.RFC3339, sf.LastUpdated); err == nil {
+			sf.LastUpdated = t.Format(time.DateOnly)
+		}
+		if _, err := time.Parse(time.DateOnly, sf.LastUpdated); err != nil {
+			return DBInfo{}, nil, fmt.Errorf("parsing services file: last_updated %q is not a YYYY-MM-DD date", sf.LastUpdated)
+		}
+	}
+	if err := checkUserAgentRules(sf.Services); err != nil {
+		return DBInfo{}, nil, fmt.Errorf("parsing services file: %w", err)
+	}
+	if err := checkHostPatterns(sf.Services); err != nil {
+		return DBInfo{}, nil, fmt.Errorf("parsing services file: %w", err)
+	}
+	if err := checkIPRanges(sf.Services); err != nil {
+		return DBInfo{}, nil, fmt.Errorf("parsing services file: %w", err)
+	}
+	return sf.DBInfo, sf.Services, nil
+}
+
+// ServicesFiles lists the services files in dir in the order they are
+// loaded: by name, skipping subdirectories and files that are not JSON,
+// YAML, or TOML.
+func ServicesFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading services directory: %w", err)
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || !servicesExts[strings.ToLower(filepath.Ext(e.Name()))] {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LoadServicesDir merges every services file in dir, in name order, the
+// way LoadCustomDomains merges one. The domains each file took over from
+// another service are returned.
+func (a *Analyzer) LoadServicesDir(dir string) ([]Conflict, error) {
+	paths, err := ServicesFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []Conflict
+	for _, p := range paths {
+		c, err := a.LoadCustomDomains(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		conflicts = append(conflicts, c...)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultSessionGap is the idle time that ends a session when none is given.
+const DefaultSessionGap = 30 * time.Minute
+
+// Session is a run of findings for one user and service with no idle gap
+// longer than the grouping threshold between them.
+type Session struct {
+	User      string    `json:"user"`
+	Service   string    `json:"service"`
+	Category  string    `json:"category"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Hits      int       `json:"hits"`
+	Blocked   int       `json:"blocked"` // hits the proxy or firewall stopped
+	Bytes     int64     `json:"bytes"`   // both directions
+}
+
+// Duration is the time between the session's first and last hit.
+func (s Session) Duration() time.Duration {
+	return s.LastSeen.Sub(s.FirstSeen)
+}
+
+// Sessions collapses findings into sessions: same user and service, split
+// wherever more than gap passes between hits. Findings without a timestamp
+// form one session per user and service. Sessions are ordered by start.
+func Sessions(findings []Finding, gap time.Duration) []Session {
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+	sorted := make([]Finding, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var sessions []Session
+	open := make(map[[2]string]int) // user/service -> index of its latest session
+	for _, f := range sorted {
+		key := [2]string{f.Identity(), f.ServiceName}
+		i, ok := open[key]
+		if !ok || (!f.Timestamp.IsZero() && f.Timestamp.Sub(sessions[i].LastSeen) > gap) {
+			sessions = append(sessions, Session{
+				User:      key[0],
+				Service:   key[1],
+				Category:  f.Category,
+				FirstSeen: f.Timestamp,
+			})
+			i = len(sessions) - 1
+			open[key] = i
+		}
+		s := &sessions[i]
+		s.Hits++
+		s.Bytes += f.Bytes()
+		s.LastSeen = f.Timestamp
+		if f.Blocked {
+			s.Blocked++
+		}
+	}
+	return sessions
+}
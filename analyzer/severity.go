@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks how much attention a finding deserves.
+type Severity int
+
+const (
+	SeverityLow    Severity = iota + 1 // blocked attempt; policy held
+	SeverityMedium                     // AI service reached
+	SeverityHigh                       // data uploaded, or a user's first use of a service
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// ParseSeverity accepts low, medium, or high.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return SeverityLow, nil
+	case "medium":
+		return SeverityMedium, nil
+	case "high":
+		return SeverityHigh, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q (use low, medium, or high)", s)
+	}
+}
+
+// Severity rates a finding.
+func (f Finding) Severity() Severity {
+	switch {
+	case f.Blocked:
+		return SeverityLow
+	case f.Activity == ActivityUpload || f.NewAdoption || f.Category == CategoryLookalike:
+		return SeverityHigh
+	default:
+		return SeverityMedium
+	}
+}
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey names an order for detailed findings.
+type SortKey string
+
+const (
+	SortNone     SortKey = ""         // log order
+	SortTime     SortKey = "time"     // oldest first
+	SortUser     SortKey = "user"     // by user or source IP
+	SortService  SortKey = "service"  // by service name
+	SortBytes    SortKey = "bytes"    // least traffic, up and down, first
+	SortSeverity SortKey = "severity" // low before high
+)
+
+// ParseSortKey accepts time, user, service, bytes, or severity.
+func ParseSortKey(s string) (SortKey, error) {
+	switch k := SortKey(strings.ToLower(strings.TrimSpace(s))); k {
+	case SortNone, SortTime, SortUser, SortService, SortBytes, SortSeverity:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unknown sort key %q (use time, user, service, bytes, or severity)", s)
+	}
+}
+
+// SortFindings orders findings in place by key, reversed when desc is set.
+// Ties fall back to timestamp order, and findings that still tie keep their
+// log order. SortNone leaves findings untouched.
+func SortFindings(findings []Finding, key SortKey, desc bool) {
+	var cmp func(a, b Finding) int
+	switch key {
+	case SortTime:
+		cmp = func(a, b Finding) int { return 0 }
+	case SortUser:
+		cmp = func(a, b Finding) int { return strings.Compare(a.Identity(), b.Identity()) }
+	case SortService:
+		cmp = func(a, b Finding) int { return strings.Compare(a.ServiceName, b.ServiceName) }
+	case SortBytes:
+		cmp = func(a, b Finding) int { return compareInt(a.Bytes(), b.Bytes()) }
+	case SortSeverity:
+		cmp = func(a, b Finding) int { return compareInt(a.Severity(), b.Severity()) }
+	default:
+		return
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		c := cmp(a, b)
+		if c == 0 {
+			c = a.Timestamp.Compare(b.Timestamp)
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+func compareInt[T ~int | ~int64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
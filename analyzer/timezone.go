@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// Timezones controls how timestamps from different logs are brought into
+// one zone. Logs that record no zone (dnsmasq, Windows DNS, many CSV
+// exports) are read in the zone of their input; everything is then
+// reported in a single zone.
+type Timezones struct {
+	Report *time.Location // zone findings are reported in; nil means UTC
+	Logs   *time.Location // zone of logs that record none; nil means local time
+	Inputs []InputZone    // per-input overrides of Logs, first match wins
+}
+
+// InputZone gives the zone of logs whose file name matches one of Files.
+type InputZone struct {
+	Files    []string // globs matched against the base name, then the full path
+	Location *time.Location
+}
+
+// LoadLocation resolves a timezone name: an IANA name, "UTC", or "Local".
+// Empty means def.
+func LoadLocation(name string, def *time.Location) (*time.Location, error) {
+	if name == "" {
+		return def, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// SetTimezones sets how timestamps are normalized.
+func (a *Analyzer) SetTimezones(tz Timezones) {
+	a.zones = tz
+}
+
+// timestamp returns the entry's time placed in its input's zone, when it
+// was logged without one, and converted to the report zone.
+func (a *Analyzer) timestamp(entry parsers.LogEntry) time.Time {
+	ts := entry.Timestamp
+	if ts.IsZero() {
+		return ts
+	}
+	if entry.Floating {
+		loc := a.inputZone(entry.SourceFile)
+		ts = time.Date(ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc)
+	}
+	if a.zones.Report != nil {
+		return ts.In(a.zones.Report)
+	}
+	return ts.UTC()
+}
+
+// inputZone returns the zone assumed for zoneless timestamps in a file.
+func (a *Analyzer) inputZone(file string) *time.Location {
+	if file != "" {
+		base := filepath.Base(file)
+		for _, in := range a.zones.Inputs {
+			for _, pattern := range in.Files {
+				if ok, _ := filepath.Match(pattern, base); ok {
+					return in.Location
+				}
+				if ok, _ := filepath.Match(pattern, file); ok {
+					return in.Location
+				}
+			}
+		}
+	}
+	if a.zones.Logs != nil {
+		return a.zones.Logs
+	}
+	return time.Local
+}
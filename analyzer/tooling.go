@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// ToolingInstall is AI software (a desktop app, IDE plugin, or browser
+// extension) seen checking in from a user's machine. Update and telemetry
+// traffic means the software is installed, whether or not it is used.
+type ToolingInstall struct {
+	User      string    `json:"user"`
+	Service   string    `json:"service"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Hits      int       `json:"hits"`
+}
+
+// addToolingInstall counts a tooling finding against its user/service pair.
+func addToolingInstall(byPair map[[2]string]*ToolingInstall, f Finding) {
+	key := [2]string{f.Identity(), f.ServiceName}
+	t, ok := byPair[key]
+	if !ok {
+		t = &ToolingInstall{User: key[0], Service: key[1]}
+		byPair[key] = t
+	}
+	t.Hits++
+	if !f.Timestamp.IsZero() {
+		if t.FirstSeen.IsZero() || f.Timestamp.Before(t.FirstSeen) {
+			t.FirstSeen = f.Timestamp
+		}
+		if f.Timestamp.After(t.LastSeen) {
+			t.LastSeen = f.Timestamp
+		}
+	}
+}
+
+// toolingInstalls lists the pairs collected by addToolingInstall, ordered
+// by user then service.
+func toolingInstalls(byPair map[[2]string]*ToolingInstall) []ToolingInstall {
+	installs := make([]ToolingInstall, 0, len(byPair))
+	for _, t := range byPair {
+		installs = append(installs, *t)
+	}
+	sort.Slice(installs, func(i, j int) bool {
+		if installs[i].User != installs[j].User {
+			return installs[i].User < installs[j].User
+		}
+		return installs[i].Service < installs[j].Service
+	})
+	return installs
+}
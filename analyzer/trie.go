@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// domainTrie indexes domains by their labels, rightmost first, so a host is
+// checked against itself and each of its parent domains in one walk,
+// without splitting or joining strings. Host patterns hang off the node of
+// their literal suffix ("amazonaws.com" for "bedrock-runtime.*.amazonaws.com"),
+// so only the patterns that could fit a host are tried.
+type domainTrie struct {
+	root trieNode
+}
+
+type trieNode struct {
+	children map[string]*trieNode
+	domain   string // the database domain ending here; "" for nodes that only lead to others
+	shared   bool   // domain is a public suffix, so it matches only itself
+	patterns []int  // indexes into Analyzer.patterns
+}
+
+// node returns the node for domain, adding the path to it as needed.
+func (t *domainTrie) node(domain string) *trieNode {
+	n := &t.root
+	for rest, label, more := cutLabel(domain); ; rest, label, more = cutLabel(rest) {
+		child, ok := n.children[label]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[string]*trieNode)
+			}
+			child = &trieNode{}
+			n.children[label] = child
+		}
+		n = child
+		if !more {
+			return n
+		}
+	}
+}
+
+// addDomain indexes a normalized database domain.
+func (t *domainTrie) addDomain(domain string) {
+	n := t.node(domain)
+	n.domain = domain
+	n.shared = publicSuffix(domain)
+}
+
+// addPattern indexes the host pattern at position i of Analyzer.patterns.
+func (t *domainTrie) addPattern(p hostPattern, i int) {
+	first := len(p.labels)
+	for first > 0 && !strings.ContainsAny(p.labels[first-1], "*?[") {
+		first--
+	}
+	n := t.node(strings.Join(p.labels[first:], "."))
+	n.patterns = append(n.patterns, i)
+}
+
+// cutLabel splits the last label off a host name: "api.openai.com" gives
+// "api.openai", "com", and true; a single label gives "", the label, and
+// false.
+func cutLabel(host string) (rest, label string, more bool) {
+	if i := strings.LastIndexByte(host, '.'); i >= 0 {
+		return host[:i], host[i+1:], true
+	}
+	return "", host, false
+}
+
+// publicSuffix reports whether domain is one under which unrelated parties
+// register names: a top-level domain, a multi-part suffix such as "co.uk",
+// or a shared hosting domain such as "azurewebsites.net", per the Public
+// Suffix List.
+func publicSuffix(domain string) bool {
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix == domain
+}
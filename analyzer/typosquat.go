@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// DetectedByTyposquat marks findings whose host misspells a database
+// domain or adds words to it, such as "chatgtp.com" or "claude-ai.net".
+const DetectedByTyposquat = "typosquat"
+
+// CategoryLookalike replaces the category of findings for hosts that only
+// imitate a service, so they are counted apart from its real use.
+const CategoryLookalike = "Suspected Lookalike"
+
+// minBrandLen is the shortest name fuzzy matching considers. Shorter ones,
+// such as "pi" or "suno", are within a typo of too many real sites.
+const minBrandLen = 5
+
+// typosquats holds what fuzzy matching needs: the registrable names of the
+// database domains, and the verdict for each site already checked, since
+// the same few unknown sites make up most of a log.
+type typosquats struct {
+	enabled bool
+	brands  map[string]string // "openai" -> "openai.com"
+	checked sync.Map          // site -> imitated database domain, or ""
+}
+
+// SetTyposquats enables fuzzy matching of hosts that misspell a database
+// domain or add words to it.
+func (a *Analyzer) SetTyposquats(on bool) {
+	a.squats.enabled = on
+}
+
+// addBrand records the registrable name of a database domain, "openai"
+// for "api.openai.com".
+func (t *typosquats) addBrand(domain string) {
+	site, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return
+	}
+	name, _, _ := strings.Cut(site, ".")
+	if len(name) < minBrandLen {
+		return
+	}
+	if t.brands == nil {
+		t.brands = make(map[string]string)
+	}
+	// Prefer the registrable domain itself, so a misspelled "openai.com"
+	// is attributed to OpenAI rather than to whisper.openai.com.
+	if _, ok := t.brands[name]; !ok || domain == site {
+		t.brands[name] = domain
+	}
+	t.checked.Clear()
+}
+
+// matchTyposquat checks a host that matched nothing against the names of
+// the database domains.
+func (a *Analyzer) matchTyposquat(host string) (AIService, string, bool) {
+	site, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(strings.TrimSuffix(host, ".")))
+	if err != nil {
+		return AIService{}, "", false
+	}
+	var domain string
+	if v, ok := a.squats.checked.Load(site); ok {
+		domain = v.(string)
+	} else {
+		domain = a.squats.imitated(site)
+		a.squats.checked.Store(site, domain)
+	}
+	if domain == "" {
+		return AIService{}, "", false
+	}
+	return a.domainMap[domain], domain, true
+}
+
+// imitated returns the database domain whose name site is closest to, or
+// "" when none is close enough. Ties go to the alphabetically first domain.
+func (t *typosquats) imitated(site string) string {
+	name, _, _ := strings.Cut(site, ".")
+	best, bestDist := "", 0
+	for brand, domain := range t.brands {
+		d, ok := squatDistance(name, brand)
+		if ok && (best == "" || d < bestDist || d == bestDist && domain < best) {
+			best, bestDist = domain, d
+		}
+	}
+	return best
+}
+
+// squatDistance reports whether name imitates brand, and how closely. A
+// name that contains brand as a hyphenated word ("chatgpt-login") scores
+// 0; otherwise it may differ by one edit, or two for brands of nine or
+// more letters, counting a swap of neighbouring letters as one. The brand
+// itself under another suffix is not a match: many services own several.
+func squatDistance(name, brand string) (int, bool) {
+	if name == brand {
+		return 0, false
+	}
+	if strings.Contains(name, "-") {
+		for _, word := range strings.Split(name, "-") {
+			if word == brand {
+				return 0, true
+			}
+		}
+	}
+	limit := 1
+	if len(brand) >= 9 {
+		limit = 2
+	}
+	if diff := len(name) - len(brand); diff > limit || -diff > limit {
+		return 0, false
+	}
+	d := editDistance(name, brand)
+	return d, d <= limit
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions, and swaps of adjacent bytes.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
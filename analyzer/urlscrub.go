@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactedSegment replaces path segments removed by a URLScrubber.
+const RedactedSegment = "REDACTED"
+
+// DefaultScrubParams are the query parameters a URLScrubber with defaults
+// removes: credentials, signatures, and contact details.
+var DefaultScrubParams = []string{
+	`(?i)^(access|id|refresh|auth|session|security)[_-]?token$`,
+	`(?i)^(token|api[_-]?key|apikey|key|secret|client[_-]?secret|password|passwd|pwd|auth|authorization|code)$`,
+	`(?i)^(sig|signature|x-amz-signature|x-amz-credential|x-amz-security-token|x-goog-signature|x-goog-credential)$`,
+	`(?i)^(e-?mail|user|username|login|phone)$`,
+}
+
+// DefaultScrubSegments are the path segments a URLScrubber with defaults
+// replaces: email addresses, JWTs, and API keys.
+var DefaultScrubSegments = []string{
+	`^[^@\s]+@[^@\s]+\.[A-Za-z]{2,}$`,
+	`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`,
+	`^(sk|pk|rk)-[A-Za-z0-9_-]{16,}$`,
+	`^[A-Za-z0-9_-]{40,}$`,
+}
+
+// URLScrubber removes sensitive parts of finding URLs before findings leave
+// the analyzer: query parameters whose names match, path segments whose
+// decoded text matches, and any user name and password.
+type URLScrubber struct {
+	params   []*regexp.Regexp
+	segments []*regexp.Regexp
+}
+
+// NewURLScrubber compiles the patterns for parameter names and path
+// segments. With defaults, DefaultScrubParams and DefaultScrubSegments are
+// added to them.
+func NewURLScrubber(params, segments []string, defaults bool) (*URLScrubber, error) {
+	if defaults {
+		params = append(append([]string{}, DefaultScrubParams...), params...)
+		segments = append(append([]string{}, DefaultScrubSegments...), segments...)
+	}
+	s := &URLScrubber{}
+	for _, p := range params {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("query parameter pattern %q: %w", p, err)
+		}
+		s.params = append(s.params, re)
+	}
+	for _, p := range segments {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("path segment pattern %q: %w", p, err)
+		}
+		s.segments = append(s.segments, re)
+	}
+	return s, nil
+}
+
+// SetURLScrubber scrubs the URL of every finding. nil keeps URLs as logged.
+func (a *Analyzer) SetURLScrubber(s *URLScrubber) {
+	a.scrubber = s
+}
+
+// Scrub returns raw with the matching query parameters removed and the
+// matching path segments replaced by RedactedSegment. CONNECT targets
+// (host:port) have neither and are returned unchanged.
+func (s *URLScrubber) Scrub(raw string) string {
+	rest := raw
+	var prefix string
+	if i := strings.Index(rest, "://"); i >= 0 {
+		authority := rest[i+3:]
+		end := strings.IndexAny(authority, "/?#")
+		if end < 0 {
+			end = len(authority)
+		}
+		host := authority[:end]
+		if at := strings.LastIndexByte(host, '@'); at >= 0 {
+			host = host[at+1:] // drop user:password@
+		}
+		prefix, rest = rest[:i+3]+host, authority[end:]
+	} else if !strings.HasPrefix(rest, "/") {
+		return raw
+	}
+
+	path, query, fragment := rest, "", ""
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path, fragment = path[:i], path[i:]
+	}
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteString(s.scrubPath(path))
+	if query = s.scrubQuery(query); query != "" {
+		b.WriteByte('?')
+		b.WriteString(query)
+	}
+	b.WriteString(fragment)
+	return b.String()
+}
+
+func (s *URLScrubber) scrubPath(path string) string {
+	if len(s.segments) == 0 || path == "" {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	for i, seg := range parts {
+		if seg == "" {
+			continue
+		}
+		decoded, err := url.PathUnescape(seg)
+		if err != nil {
+			decoded = seg
+		}
+		if matchesAny(s.segments, decoded) {
+			parts[i] = RedactedSegment
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (s *URLScrubber) scrubQuery(query string) string {
+	if len(s.params) == 0 || query == "" {
+		return query
+	}
+	var kept []string
+	for _, pair := range strings.Split(query, "&") {
+		name, _, _ := strings.Cut(pair, "=")
+		decoded, err := url.QueryUnescape(name)
+		if err != nil {
+			decoded = name
+		}
+		if !matchesAny(s.params, decoded) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
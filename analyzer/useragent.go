@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// Detection methods recorded on findings not matched by domain.
+const (
+	DetectedByJA3       = "ja3"
+	DetectedByUserAgent = "user_agent"
+)
+
+// UserAgentRule attributes requests to a service by the client that made
+// them. Contains is a case-insensitive substring; Regex is a regular
+// expression (add "(?i)" for case-insensitive matching). These rules catch
+// SDK and IDE traffic routed through gateways or internal proxies whose
+// hostname says nothing about the service behind it.
+type UserAgentRule struct {
+	Contains string `json:"contains,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+}
+
+// uaMatcher is a compiled UserAgentRule and the service it points to.
+type uaMatcher struct {
+	service  string
+	contains string // lowercased
+	re       *regexp.Regexp
+}
+
+func (r UserAgentRule) compile(service string) (uaMatcher, error) {
+	switch {
+	case r.Contains != "" && r.Regex != "":
+		return uaMatcher{}, fmt.Errorf("user-agent rule sets both contains and regex")
+	case r.Contains != "":
+		return uaMatcher{service: service, contains: strings.ToLower(r.Contains)}, nil
+	case r.Regex != "":
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return uaMatcher{}, fmt.Errorf("user-agent rule: %w", err)
+		}
+		return uaMatcher{service: service, re: re}, nil
+	default:
+		return uaMatcher{}, fmt.Errorf("user-agent rule needs contains or regex")
+	}
+}
+
+func (m uaMatcher) match(ua, lower string) bool {
+	if m.re != nil {
+		return m.re.MatchString(ua)
+	}
+	return strings.Contains(lower, m.contains)
+}
+
+// checkUserAgentRules reports the first invalid rule in services.
+func checkUserAgentRules(services []AIService) error {
+	for _, svc := range services {
+		for _, rule := range svc.UserAgents {
+			if _, err := rule.compile(svc.Name); err != nil {
+				return fmt.Errorf("service %s: %w", svc.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchUserAgent finds the service whose rules match the entry's
+// User-Agent. Rules loaded later win, as they do for domains.
+func (a *Analyzer) matchUserAgent(entry parsers.LogEntry) (AIService, bool) {
+	if entry.UserAgent == "" || len(a.uaRules) == 0 {
+		return AIService{}, false
+	}
+	lower := strings.ToLower(entry.UserAgent)
+	for i := len(a.uaRules) - 1; i >= 0; i-- {
+		if m := a.uaRules[i]; m.match(entry.UserAgent, lower) {
+			return a.serviceNamed(m.service), true
+		}
+	}
+	return AIService{}, false
+}
+
+// serviceNamed returns the loaded service with the given name.
+func (a *Analyzer) serviceNamed(name string) AIService {
+	if svc, ok := a.byName[name]; ok {
+		return svc
+	}
+	return AIService{Name: name}
+}
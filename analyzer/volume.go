@@ -0,0 +1,47 @@
+package analyzer
+
+import "sort"
+
+// Volume is traffic exchanged with AI services, as far as logs record it.
+type Volume struct {
+	Sent     int64 `json:"sent"`     // uploaded
+	Received int64 `json:"received"` // downloaded
+}
+
+// Total is the traffic in both directions.
+func (v Volume) Total() int64 {
+	return v.Sent + v.Received
+}
+
+// addVolume adds a finding's traffic to key's volume. Findings without byte
+// counts are left out, so a key appears only when its logs record sizes.
+func addVolume(m map[string]Volume, key string, f Finding) {
+	if f.BytesSent == 0 && f.BytesReceived == 0 {
+		return
+	}
+	v := m[key]
+	v.Sent += f.BytesSent
+	v.Received += f.BytesReceived
+	m[key] = v
+}
+
+// VolumeRow is one key of a volume table.
+type VolumeRow struct {
+	Key string
+	Volume
+}
+
+// SortedVolumes orders a volume table by total traffic, largest first.
+func SortedVolumes(m map[string]Volume) []VolumeRow {
+	rows := make([]VolumeRow, 0, len(m))
+	for k, v := range m {
+		rows = append(rows, VolumeRow{Key: k, Volume: v})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if a, b := rows[i].Total(), rows[j].Total(); a != b {
+			return a > b
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// CategoryVPN is the category of consumer VPN and proxy services. Users
+// who cannot reach an AI service directly often tunnel out through one,
+// so their AI use then shows only as VPN traffic. Hits on these services
+// are reported only when enabled with SetVPN, which keeps the rest of a
+// report about AI.
+const CategoryVPN = "VPN/Proxy"
+
+// SetVPN enables reporting of hits on services in CategoryVPN.
+func (a *Analyzer) SetVPN(on bool) {
+	a.vpn = on
+}
+
+// VPNUse is a client seen connecting to a VPN or proxy service.
+type VPNUse struct {
+	User      string    `json:"user"`
+	Service   string    `json:"service"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Hits      int       `json:"hits"`
+}
+
+// addVPNUse counts a VPN finding against its user/service pair.
+func addVPNUse(byPair map[[2]string]*VPNUse, f Finding) {
+	key := [2]string{f.Identity(), f.ServiceName}
+	v, ok := byPair[key]
+	if !ok {
+		v = &VPNUse{User: key[0], Service: key[1]}
+		byPair[key] = v
+	}
+	v.Hits++
+	if !f.Timestamp.IsZero() {
+		if v.FirstSeen.IsZero() || f.Timestamp.Before(v.FirstSeen) {
+			v.FirstSeen = f.Timestamp
+		}
+		if f.Timestamp.After(v.LastSeen) {
+			v.LastSeen = f.Timestamp
+		}
+	}
+}
+
+// vpnUses lists the pairs collected by addVPNUse, ordered by user then
+// service.
+func vpnUses(byPair map[[2]string]*VPNUse) []VPNUse {
+	uses := make([]VPNUse, 0, len(byPair))
+	for _, v := range byPair {
+		uses = append(uses, *v)
+	}
+	sort.Slice(uses, func(i, j int) bool {
+		if uses[i].User != uses[j].User {
+			return uses[i].User < uses[j].User
+		}
+		return uses[i].Service < uses[j].Service
+	})
+	return uses
+}
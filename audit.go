@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// auditLog is an append-only chain-of-custody log: one JSON line per scan
+// recording what the tool read, with which database and options, who ran
+// it, and what it wrote. Each line carries the SHA-256 of the line before
+// it, so a removed or edited line breaks the chain.
+type auditLog struct {
+	path     string
+	operator string
+	mu       sync.Mutex // the daemon's schedules share one log
+}
+
+// auditFile is a file a scan read or wrote, as it was when recorded.
+type auditFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	Error  string `json:"error,omitempty"` // why the file could not be hashed
+}
+
+// auditRecord is one line of the audit log.
+type auditRecord struct {
+	Time        time.Time         `json:"time"`
+	Event       string            `json:"event"` // "scan" or "scheduled_scan"
+	Schedule    string            `json:"schedule,omitempty"`
+	Operator    string            `json:"operator"`
+	Host        string            `json:"host,omitempty"`
+	PID         int               `json:"pid"`
+	Version     string            `json:"version"`
+	Args        []string          `json:"args"`
+	Options     map[string]string `json:"options"` // flags set on the command line
+	Settings    []auditFile       `json:"settings,omitempty"`
+	ServicesDB  string            `json:"services_db_sha256"`
+	DBVersion   string            `json:"services_db_version,omitempty"`
+	Inputs      []auditFile       `json:"inputs"`
+	Outputs     []auditFile       `json:"outputs"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+	LogsScanned int               `json:"logs_scanned"`
+	Findings    int               `json:"findings"`
+	Partial     bool              `json:"partial,omitempty"`
+	Prev        string            `json:"prev_sha256"` // of the previous line; empty for the first
+}
+
+// newAuditRecord starts a record of a scan with the process's identity,
+// arguments, and database; the caller adds what the scan read and wrote.
+func newAuditRecord(l *auditLog, event string, az *analyzer.Analyzer, startedAt time.Time) auditRecord {
+	host, _ := os.Hostname()
+	rec := auditRecord{
+		Event:      event,
+		Operator:   l.operator,
+		Host:       host,
+		PID:        os.Getpid(),
+		Version:    version,
+		Args:       os.Args[1:],
+		Options:    make(map[string]string),
+		ServicesDB: az.DBHash(),
+		DBVersion:  az.DBInfo().Version,
+		StartedAt:  startedAt.UTC(),
+	}
+	flag.Visit(func(f *flag.Flag) { rec.Options[f.Name] = f.Value.String() })
+	return rec
+}
+
+// operatorName returns name, or the OS user running the process.
+func operatorName(name string) string {
+	if name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// scannedFiles records the inputs with the hashes the scan took of them.
+func scannedFiles(files []reporter.ScanFile) []auditFile {
+	out := make([]auditFile, 0, len(files))
+	for _, f := range files {
+		af := auditFile{Path: f.Path, Size: f.Size, SHA256: f.SHA256}
+		if af.SHA256 == "" {
+			af.Error = cmp.Or(f.Error, "not read")
+		}
+		out = append(out, af)
+	}
+	return out
+}
+
+// hashFile records path with its size and SHA-256. A file that cannot be
+// read is recorded with the error rather than failing the record.
+func hashFile(path string) auditFile {
+	af := auditFile{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		af.Error = err.Error()
+		return af
+	}
+	defer f.Close()
+	h := sha256.New()
+	if af.Size, err = io.Copy(h, f); err != nil {
+		af.Error = err.Error()
+		return af
+	}
+	af.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return af
+}
+
+// hashingWriter passes writes through to w, hashing them, for outputs
+// that go to stdout rather than a file.
+type hashingWriter struct {
+	w io.Writer
+	h hash.Hash
+	n int64
+}
+
+func newHashingWriter(w io.Writer) *hashingWriter {
+	return &hashingWriter{w: w, h: sha256.New()}
+}
+
+func (hw *hashingWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	hw.n += int64(n)
+	return n, err
+}
+
+// file records what was written under the name path.
+func (hw *hashingWriter) file(path string) auditFile {
+	return auditFile{Path: path, Size: hw.n, SHA256: hex.EncodeToString(hw.h.Sum(nil))}
+}
+
+// append stamps rec, chains it to the last line of the log, and appends
+// it. The log is only ever opened for appending; nothing rewrites it.
+func (l *auditLog) append(rec auditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+	last, err := lastLine(f)
+	if err != nil {
+		return fmt.Errorf("reading audit log: %w", err)
+	}
+	if last != nil {
+		sum := sha256.Sum256(last)
+		rec.Prev = hex.EncodeToString(sum[:])
+	}
+	rec.Time = time.Now().UTC()
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return f.Sync()
+}
+
+// lastLine returns the last non-empty line of r without its newline, or
+// nil when there is none.
+func lastLine(r io.Reader) ([]byte, error) {
+	var last []byte
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	return last, sc.Err()
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/loggen"
+)
+
+// benchResult is one throughput measurement, also the format of a stored baseline.
+type benchResult struct {
+	Format      string    `json:"format"`
+	Lines       int       `json:"lines"`
+	Seconds     float64   `json:"seconds"`
+	LinesPerSec float64   `json:"lines_per_sec"`
+	Findings    int       `json:"findings"`
+	Workers     int       `json:"workers,omitempty"`
+	BytesPerSec float64   `json:"bytes_per_sec,omitempty"`
+	AllocsLine  float64   `json:"allocs_per_line,omitempty"` // heap allocations per line parsed and analyzed
+	BytesLine   float64   `json:"alloc_bytes_per_line,omitempty"`
+	PeakRSS     int64     `json:"peak_rss_bytes,omitempty"` // of the whole process; 0 where it cannot be read
+	LookupNs    float64   `json:"lookup_ns,omitempty"`      // domain matching alone, per host
+	LookupAlloc float64   `json:"lookup_allocs,omitempty"`  // heap allocations per host lookup
+	GoVersion   string    `json:"go_version"`
+	Platform    string    `json:"platform"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// runBench handles the "bench" subcommand: it measures parse+analyze
+// throughput on a synthetic corpus and optionally gates on a baseline.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	lines := fs.Int("lines", 1000000, "Number of synthetic log lines")
+	format := fs.String("format", "squid", "Corpus format: squid, dns, csv")
+	corpus := fs.String("corpus", "", "Corpus file; generated here if missing and kept (default: temporary file)")
+	runs := fs.Int("runs", 3, "Measured runs; the fastest is reported")
+	workers := fs.Int("workers", runtime.NumCPU(), "Goroutines parsing the corpus, as -workers for a scan (1 is sequential)")
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	baseline := fs.String("baseline", "", "Compare against this stored result and fail on regression")
+	threshold := fs.Float64("threshold", 10, "Allowed throughput regression against -baseline, in percent")
+	save := fs.String("save", "", "Store this result as a baseline")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile of the measured runs to this file")
+	memProfile := fs.String("memprofile", "", "Write a heap profile, with allocations, after the measured runs to this file")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	az, err := analyzer.New(resolveServicesPath(*servicesDB))
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+
+	path := *corpus
+	if path == "" {
+		tmp, err := os.CreateTemp("", "shadow-hunter-bench-*.log")
+		if err != nil {
+			logger.Error(err.Error())
+			return 1
+		}
+		tmp.Close()
+		path = tmp.Name()
+		defer os.Remove(path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		logger.Info("Generating corpus", "lines", *lines, "format", *format, "path", path)
+		if err := generateCorpus(path, *format, *lines, az); err != nil {
+			logger.Error("Error generating corpus", "err", err)
+			return 1
+		}
+	} else {
+		logger.Info("Using existing corpus", "path", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			logger.Error("Error creating CPU profile", "err", err)
+			return 1
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			logger.Error("Error starting CPU profile", "err", err)
+			return 1
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	// The corpus goes through the same scanner as a real scan, which
+	// parses a large file in parallel when workers allow
+	p := selectParser(*format, path, nil)
+	var best benchResult
+	for i := 0; i < *runs; i++ {
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		scanner := &fileScanner{ctx: context.Background(), az: az, format: p.Name(), workers: *workers, progress: &progressBar{w: io.Discard}}
+		out := scanner.scanAll([]string{path}, nil)
+		if len(out.sources) == 0 {
+			logger.Error("Error parsing corpus", "err", out.files[0].Error)
+			return 1
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		n := float64(max(out.logsScanned, 1))
+
+		r := benchResult{
+			Format:      p.Name(),
+			Lines:       out.logsScanned,
+			Seconds:     elapsed.Seconds(),
+			LinesPerSec: float64(out.logsScanned) / elapsed.Seconds(),
+			Findings:    len(out.findings),
+			Workers:     max(*workers, 1),
+			BytesPerSec: float64(info.Size()) / elapsed.Seconds(),
+			AllocsLine:  float64(after.Mallocs-before.Mallocs) / n,
+			BytesLine:   float64(after.TotalAlloc-before.TotalAlloc) / n,
+			GoVersion:   runtime.Version(),
+			Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+			RecordedAt:  time.Now().UTC(),
+		}
+		logger.Info(fmt.Sprintf("run %d: %d lines in %.2fs (%.0f lines/sec)", i+1, r.Lines, r.Seconds, r.LinesPerSec))
+		if r.LinesPerSec > best.LinesPerSec {
+			best = r
+		}
+	}
+	best.PeakRSS = peakRSS()
+	if *cpuProfile != "" {
+		pprof.StopCPUProfile()
+		logSuccess("CPU profile written", "path", *cpuProfile)
+	}
+	if *memProfile != "" {
+		if err := writeHeapProfile(*memProfile); err != nil {
+			logger.Error("Error writing heap profile", "err", err)
+			return 1
+		}
+		logSuccess("Heap profile written", "path", *memProfile)
+	}
+
+	hosts := lookupHosts(az)
+	best.LookupNs, best.LookupAlloc = benchLookup(az, hosts)
+	fmt.Printf("%s: %d lines, %.0f lines/sec, %d AI hits (%d worker(s), %s, %s)\n",
+		best.Format, best.Lines, best.LinesPerSec, best.Findings, best.Workers, best.GoVersion, best.Platform)
+	fmt.Printf("throughput: %.1f MB/sec, %.2f TB/day at this rate\n", best.BytesPerSec/1e6, best.BytesPerSec*86400/1e12)
+	fmt.Printf("memory: %.1f allocations and %.0f bytes per line", best.AllocsLine, best.BytesLine)
+	if best.PeakRSS > 0 {
+		fmt.Printf(", peak RSS %.1f MB", float64(best.PeakRSS)/1e6)
+	}
+	fmt.Println()
+	fmt.Printf("domain lookup: %.0f ns and %.1f allocations per host (%d hosts)\n", best.LookupNs, best.LookupAlloc, len(hosts))
+
+	if *save != "" {
+		data, _ := json.MarshalIndent(best, "", "  ")
+		if err := os.WriteFile(*save, append(data, '\n'), 0o644); err != nil {
+			logger.Error("Error saving baseline", "err", err)
+			return 1
+		}
+		logSuccess("Baseline written", "path", *save)
+	}
+
+	if *baseline == "" {
+		return 0
+	}
+	return compareBaseline(best, *baseline, *threshold)
+}
+
+// writeHeapProfile writes the heap profile after a collection, so it holds
+// what is live as well as every allocation made so far.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	err = pprof.WriteHeapProfile(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// compareBaseline fails when throughput dropped more than threshold percent
+// below the stored result.
+func compareBaseline(cur benchResult, path string, threshold float64) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Error reading baseline", "err", err)
+		return 1
+	}
+	var base benchResult
+	if err := json.Unmarshal(data, &base); err != nil {
+		logger.Error("Error parsing baseline", "path", path, "err", err)
+		return 1
+	}
+	if base.Format != cur.Format || base.LinesPerSec <= 0 {
+		logger.Error(fmt.Sprintf("Baseline is for %s format, not %s", base.Format, cur.Format))
+		return 1
+	}
+	if base.Lines != cur.Lines {
+		logger.Warn(fmt.Sprintf("Baseline measured %d lines, this run %d; results may not be comparable", base.Lines, cur.Lines))
+	}
+	if base.Workers != 0 && base.Workers != cur.Workers {
+		logger.Warn(fmt.Sprintf("Baseline used %d worker(s), this run %d", base.Workers, cur.Workers))
+	}
+	if base.Platform != cur.Platform {
+		logger.Warn(fmt.Sprintf("Baseline was recorded on %s, this run is %s", base.Platform, cur.Platform))
+	}
+
+	if base.LookupNs > 0 {
+		fmt.Printf("baseline domain lookup: %.0f ns and %.1f allocations per host\n", base.LookupNs, base.LookupAlloc)
+	}
+	if base.AllocsLine > 0 {
+		fmt.Printf("baseline memory: %.1f allocations and %.0f bytes per line\n", base.AllocsLine, base.BytesLine)
+	}
+	change := (cur.LinesPerSec - base.LinesPerSec) / base.LinesPerSec * 100
+	fmt.Printf("baseline: %.0f lines/sec, change %+.1f%% (allowed regression %.1f%%)\n", base.LinesPerSec, change, threshold)
+	if change < -threshold {
+		logger.Error(fmt.Sprintf("FAIL: throughput regressed %.1f%%", -change), "baseline", path)
+		return 1
+	}
+	logSuccess("PASS: within threshold")
+	return 0
+}
+
+// lookupRounds is how many times benchLookup goes through its hosts.
+const lookupRounds = 200
+
+// lookupHosts is a mix of hosts to time domain matching on: every database
+// domain, subdomains of them, hosts fitting the host patterns, and hosts
+// that match nothing, which walk the furthest.
+func lookupHosts(az *analyzer.Analyzer) []string {
+	var hosts []string
+	for _, svc := range az.Services() {
+		for _, d := range svc.Domains {
+			d = strings.TrimPrefix(d, "*.")
+			hosts = append(hosts, d, "edge-1.eu."+d)
+		}
+		for _, p := range svc.HostPatterns {
+			hosts = append(hosts, strings.NewReplacer("*", "us-east-1", "?", "x").Replace(p))
+		}
+	}
+	for i := range len(hosts) {
+		hosts = append(hosts, fmt.Sprintf("img%d.cdn.static-content%d.example.com", i, i%50))
+	}
+	return hosts
+}
+
+// benchLookup times az.Lookup over hosts, returning nanoseconds and heap
+// allocations per lookup.
+func benchLookup(az *analyzer.Analyzer, hosts []string) (ns, allocs float64) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for range lookupRounds {
+		for _, h := range hosts {
+			az.Lookup(h)
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	n := float64(lookupRounds * len(hosts))
+	return float64(elapsed.Nanoseconds()) / n, float64(after.Mallocs-before.Mallocs) / n
+}
+
+// generateCorpus writes a deterministic synthetic log drawing AI hits from the loaded database.
+func generateCorpus(path, format string, lines int, az *analyzer.Analyzer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var domains []string
+	for _, svc := range az.Services() {
+		domains = append(domains, svc.Domains...)
+	}
+	err = loggen.Generate(f, loggen.Options{
+		Format:    format,
+		Lines:     lines,
+		Seed:      1,
+		AIRatio:   0.05,
+		AIDomains: domains,
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
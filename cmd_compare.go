@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// runCompare reports how AI usage changed between two JSON scan reports.
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: shadow-hunter compare [-output table|json] [-out file] [-top N] <before.json> <after.json>")
+		fs.PrintDefaults()
+	}
+	outputFmt := fs.String("output", "table", "Output format: table, json (default: table)")
+	outPath := fs.String("out", "", "Write the comparison to this file instead of stdout")
+	top := fs.Int("top", 20, "Limit the per-user tables to N rows (0 for all)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+
+	before, err := reporter.LoadJSONReport(fs.Arg(0))
+	if err != nil {
+		logger.Error("Error loading report", "path", fs.Arg(0), "err", err)
+		return 1
+	}
+	after, err := reporter.LoadJSONReport(fs.Arg(1))
+	if err != nil {
+		logger.Error("Error loading report", "path", fs.Arg(1), "err", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			logger.Error("Error creating output", "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	c := analyzer.Compare(before, after)
+	if err := reporter.ReportComparison(c, *top, reporter.Format(strings.ToLower(*outputFmt)), w); err != nil {
+		logger.Error("Error generating report", "err", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// runDB handles the "db" subcommand family.
+func runDB(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintln(os.Stderr, "  shadow-hunter db lint [-services <db.json>] [-services-dir <dir>] [custom.json ...]")
+		fmt.Fprintln(os.Stderr, "  shadow-hunter db list [-services <db.json>] [-services-dir <dir>] [-custom <file>] [-category <list>]")
+		fmt.Fprintln(os.Stderr, "  shadow-hunter db search [-services <db.json>] [-services-dir <dir>] [-custom <file>] <term>")
+		return 1
+	}
+
+	switch args[0] {
+	case "lint":
+		return runDBLint(args[1:])
+	case "list":
+		return runDBList(args[1:])
+	case "search":
+		return runDBSearch(args[1:])
+	default:
+		logger.Error(fmt.Sprintf("Unknown db command %q", args[0]))
+		return 1
+	}
+}
+
+// runDBLint validates the services database and any custom databases layered
+// on top of it. It exits non-zero when errors (or, with -strict, warnings) are found.
+func runDBLint(args []string) int {
+	fs := flag.NewFlagSet("db lint", flag.ExitOnError)
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	outputFmt := fs.String("output", "table", "Output format: table, json (default: table)")
+	strict := fs.Bool("strict", false, "Treat warnings as errors")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	paths := []string{resolveServicesPath(*servicesDB)}
+	if *servicesDir != "" {
+		files, err := analyzer.ServicesFiles(*servicesDir)
+		if err != nil {
+			logger.Error("Error reading database", "path", *servicesDir, "err", err)
+			return 1
+		}
+		paths = append(paths, files...)
+	}
+	paths = append(paths, fs.Args()...)
+	var sets []analyzer.ServiceSet
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			logger.Error("Error reading database", "path", p, "err", err)
+			return 1
+		}
+		services, err := analyzer.ParseServicesFile(p, data)
+		if err != nil {
+			logger.Error(p, "err", err)
+			return 1
+		}
+		sets = append(sets, analyzer.ServiceSet{Source: p, Services: services})
+	}
+
+	issues := analyzer.Lint(sets...)
+	if err := reporter.ReportLint(issues, reporter.Format(strings.ToLower(*outputFmt)), os.Stdout); err != nil {
+		logger.Error("Error generating report", "err", err)
+		return 1
+	}
+
+	for _, is := range issues {
+		if is.Severity == analyzer.LintError || *strict {
+			return 1
+		}
+	}
+	return 0
+}
+
+// servicesDirUsage is the help text of -services-dir, shared by every
+// command that loads the database.
+const servicesDirUsage = "Directory of extra services files (JSON, YAML, or TOML) to merge in name order, before -custom"
+
+// loadDB loads the services database the same way a scan would.
+func loadDB(servicesDB, servicesDir string, customDBs []string) (*analyzer.Analyzer, error) {
+	az, err := analyzer.New(resolveServicesPath(servicesDB))
+	if err != nil {
+		return nil, err
+	}
+	if servicesDir != "" {
+		if _, err := az.LoadServicesDir(servicesDir); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range customDBs {
+		if _, err := az.LoadCustomDomains(path); err != nil {
+			return nil, err
+		}
+	}
+	return az, nil
+}
+
+// dbAttrs appends the loaded database's version and date, when it declares
+// them, to log attributes.
+func dbAttrs(az *analyzer.Analyzer, attrs ...any) []any {
+	if v := az.DBInfo().String(); v != "" {
+		attrs = append(attrs, "db_version", v)
+	}
+	return attrs
+}
+
+// runDBList prints every loaded service with its category and domains.
+func runDBList(args []string) int {
+	fs := flag.NewFlagSet("db list", flag.ExitOnError)
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	categoryFilter := fs.String("category", "", "Only list these categories (comma-separated)")
+	outputFmt := fs.String("output", "table", "Output format: table, json (default: table)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+
+	services := az.Services()
+	if *categoryFilter != "" {
+		keep := make(map[string]bool)
+		for _, c := range strings.Split(*categoryFilter, ",") {
+			keep[analyzer.NormalizeCategory(c)] = true
+		}
+		var filtered []analyzer.AIService
+		for _, svc := range services {
+			if keep[analyzer.NormalizeCategory(svc.Category)] {
+				filtered = append(filtered, svc)
+			}
+		}
+		services = filtered
+	}
+
+	if err := reporter.ReportServices(services, reporter.Format(strings.ToLower(*outputFmt)), os.Stdout); err != nil {
+		logger.Error("Error generating report", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// runDBSearch finds services whose name, category, or domains contain the
+// term, and reports whether the term itself would be detected as a domain.
+// It exits 1 when nothing matches.
+func runDBSearch(args []string) int {
+	fs := flag.NewFlagSet("db search", flag.ExitOnError)
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	outputFmt := fs.String("output", "table", "Output format: table, json (default: table)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	term := fs.Arg(0)
+	if fs.NArg() > 1 {
+		fs.Parse(fs.Args()[1:]) // allow flags after the term
+	}
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if term == "" {
+		fmt.Fprintln(os.Stderr, "Usage: shadow-hunter db search [options] <term>")
+		return 1
+	}
+
+	az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+
+	host := strings.ToLower(term)
+	if strings.Contains(host, "://") {
+		if u, err := url.Parse(host); err == nil {
+			host = u.Hostname()
+		}
+	}
+	covered := false
+	if svc, entry, ok := az.Lookup(host); ok {
+		covered = true
+		logSuccess(fmt.Sprintf("%s is detected as %s (%s) via %s", host, svc.Name, svc.Category, entry))
+	} else if strings.Contains(host, ".") {
+		logger.Warn(fmt.Sprintf("%s is not covered by the loaded database", host))
+	}
+
+	needle := strings.ToLower(term)
+	var matches []analyzer.AIService
+	for _, svc := range az.Services() {
+		if strings.Contains(strings.ToLower(svc.Name), needle) || strings.Contains(strings.ToLower(svc.Category), needle) {
+			matches = append(matches, svc)
+			continue
+		}
+		for _, d := range svc.Domains {
+			if strings.Contains(d, needle) || strings.HasSuffix(host, "."+d) || host == d {
+				matches = append(matches, svc)
+				break
+			}
+		}
+	}
+
+	if err := reporter.ReportServices(matches, reporter.Format(strings.ToLower(*outputFmt)), os.Stdout); err != nil {
+		logger.Error("Error generating report", "err", err)
+		return 1
+	}
+	if len(matches) == 0 && !covered {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/config"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/redact"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// stringList is a flag that can be given more than once.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// runExport handles the "export" subcommand family.
+func runExport(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "bundle":
+			return runExportBundle(args[1:])
+		case "stix", "misp":
+			return runExportIntel(args[0], args[1:])
+		case "sigma":
+			return runExportSigma(args[1:])
+		case "blocklist":
+			return runExportBlocklist(args[1:])
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Usage: shadow-hunter export bundle -out <bundle.zip> [-history <store>] [-from date] [-to date] [-report report.json ...]")
+	fmt.Fprintln(os.Stderr, "       shadow-hunter export stix|misp [-out file] [-history <store>] [-from date] [-to date] [-report report.json ...] [-include-db]")
+	fmt.Fprintln(os.Stderr, "       shadow-hunter export sigma [-out rules.yml | -out-dir rules/] [-logsource proxy,dns] [-category list]")
+	fmt.Fprintln(os.Stderr, "       shadow-hunter export blocklist [-format edl|squid|pfblocker|pihole] [-out file] [-category list] [-exclude services]")
+	return 1
+}
+
+// runExportBundle writes an audit bundle.
+func runExportBundle(args []string) int {
+	fs := flag.NewFlagSet("export bundle", flag.ExitOnError)
+	outPath := fs.String("out", "", "Path of the bundle to write (.zip)")
+	historyPath := fs.String("history", "", "Historical findings store to snapshot")
+	tenant := fs.String("tenant", "", "Only include history findings tagged with this tenant")
+	from := fs.String("from", "", "Start of the period, YYYY-MM-DD or RFC 3339 (default: all history)")
+	to := fs.String("to", "", "End of the period, exclusive (default: no end)")
+	var reports stringList
+	fs.Var(&reports, "report", "JSON scan report to include (repeatable)")
+	redactProfile := fs.String("redact", "", "Redaction profile for everything in the bundle")
+	configFile := fs.String("config", "", "Path to JSON config file (redaction profiles)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *outPath == "" || (*historyPath == "" && len(reports) == 0) {
+		fs.Usage()
+		return 1
+	}
+
+	var cfg config.Config
+	if *configFile != "" {
+		loaded, err := config.Load(*configFile)
+		if err != nil {
+			logger.Error("Error loading config", "err", err)
+			return 1
+		}
+		cfg = *loaded
+	}
+	profile, err := redact.Lookup(*redactProfile, cfg.RedactionProfiles)
+	if err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	b := reporter.Bundle{Version: version, GeneratedAt: time.Now().UTC(), Source: redactLocation(*historyPath), Profile: *redactProfile}
+	if b.From, err = parseDay(*from); err != nil {
+		logger.Error("Error in -from", "err", err)
+		return 1
+	}
+	if b.To, err = parseDay(*to); err != nil {
+		logger.Error("Error in -to", "err", err)
+		return 1
+	}
+
+	var findings []analyzer.Finding
+	if *historyPath != "" {
+		if findings, err = historyFindings(*historyPath, b.From, b.To, *tenant); err != nil {
+			logger.Error(err.Error())
+			return 1
+		}
+		b.Records = len(findings)
+	}
+	b.History = profile.Apply(analyzer.Summarize(findings, 0))
+
+	names := make(map[string]int)
+	for _, path := range reports {
+		s, err := reporter.LoadJSONReport(path)
+		if err != nil {
+			logger.Error("Error loading report", "err", err)
+			return 1
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, names[name])
+		}
+		b.Reports = append(b.Reports, reporter.BundleReport{Name: name, Summary: profile.Apply(s)})
+	}
+
+	if err := reporter.WriteBundleFile(b, *outPath); err != nil {
+		logger.Error("Error writing bundle", "err", err)
+		return 1
+	}
+	logSuccess("Bundle written", "path", *outPath, "reports", len(b.Reports))
+	return 0
+}
+
+// runExportIntel converts findings, and optionally the services database,
+// into detection intelligence: a STIX 2.1 bundle or a MISP event. Only
+// per-service counts leave, never users or addresses, so there is no
+// redaction profile.
+func runExportIntel(format string, args []string) int {
+	fs := flag.NewFlagSet("export "+format, flag.ExitOnError)
+	outPath := fs.String("out", "", "Write to this file instead of stdout")
+	historyPath := fs.String("history", "", "Historical findings store to read")
+	tenant := fs.String("tenant", "", "Only read history findings tagged with this tenant")
+	from := fs.String("from", "", "Start of the period, YYYY-MM-DD or RFC 3339 (default: all history)")
+	to := fs.String("to", "", "End of the period, exclusive (default: no end)")
+	var reports stringList
+	fs.Var(&reports, "report", "JSON scan report to read findings from (repeatable)")
+	includeDB := fs.Bool("include-db", false, "Also export indicators for every service in the database, seen or not")
+	servicesDB := fs.String("services", "", "Path to ai_services.json for -include-db (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge for -include-db (repeatable)")
+	tlp := fs.String("tlp", "amber", "Traffic Light Protocol marking: clear, green, amber, or red")
+	toIDS := fs.Bool("to-ids", false, "misp: flag attributes for export to detection systems (to_ids)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *historyPath == "" && len(reports) == 0 && !*includeDB {
+		fs.Usage()
+		return 1
+	}
+
+	level, err := reporter.ParseTLP(*tlp)
+	if err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	fromTime, err := parseDay(*from)
+	if err != nil {
+		logger.Error("Error in -from", "err", err)
+		return 1
+	}
+	toTime, err := parseDay(*to)
+	if err != nil {
+		logger.Error("Error in -to", "err", err)
+		return 1
+	}
+
+	var findings []analyzer.Finding
+	if *historyPath != "" {
+		if findings, err = historyFindings(*historyPath, fromTime, toTime, *tenant); err != nil {
+			logger.Error(err.Error())
+			return 1
+		}
+	}
+	for _, path := range reports {
+		s, err := reporter.LoadJSONReport(path)
+		if err != nil {
+			logger.Error("Error loading report", "err", err)
+			return 1
+		}
+		findings = append(findings, s.Findings...)
+	}
+
+	var services []analyzer.AIService
+	if *includeDB {
+		az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+		if err != nil {
+			logger.Error("Error loading AI services database", "err", err)
+			return 1
+		}
+		services = az.Services()
+	}
+
+	in := reporter.Intel{
+		Version:     version,
+		GeneratedAt: time.Now().UTC(),
+		TLP:         level,
+		Services:    reporter.BuildIntel(findings, services),
+	}
+	w := os.Stdout
+	if *outPath != "" && *outPath != "-" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			logger.Error("Error creating output", "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == "stix" {
+		err = reporter.WriteSTIX(in, w)
+	} else {
+		err = reporter.WriteMISP(in, *toIDS, w)
+	}
+	if err == nil && w != os.Stdout {
+		err = w.Close()
+	}
+	if err != nil {
+		logger.Error("Error writing "+strings.ToUpper(format), "err", err)
+		return 1
+	}
+	if w != os.Stdout {
+		logSuccess(strings.ToUpper(format)+" export written", "path", *outPath, "services", len(in.Services), "findings", len(findings))
+	}
+	return 0
+}
+
+// runExportSigma generates Sigma rules from the services database.
+func runExportSigma(args []string) int {
+	fs := flag.NewFlagSet("export sigma", flag.ExitOnError)
+	outPath := fs.String("out", "", "Write all rules to this file, as one YAML document each (default: stdout)")
+	outDir := fs.String("out-dir", "", "Write each rule to its own .yml file in this directory")
+	logsource := fs.String("logsource", "proxy,dns", "Sigma log source categories to generate rules for, comma-separated: proxy, dns")
+	categoryFilter := fs.String("category", "", "Only services in these categories, comma-separated (e.g. code-assistant,llm)")
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *outPath != "" && *outDir != "" {
+		logger.Error("Use either -out or -out-dir, not both")
+		return 1
+	}
+
+	var sources []string
+	for _, src := range strings.Split(*logsource, ",") {
+		switch src = strings.ToLower(strings.TrimSpace(src)); src {
+		case reporter.SigmaProxy, reporter.SigmaDNS:
+			sources = append(sources, src)
+		default:
+			logger.Error(fmt.Sprintf("Unknown -logsource %q (use proxy or dns)", src))
+			return 1
+		}
+	}
+
+	az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+	services := filterServices(az.Services(), *categoryFilter, "")
+	rules := reporter.SigmaRules(services, sources, time.Now().UTC())
+	if len(rules) == 0 {
+		logger.Error("No services to generate rules for")
+		return 1
+	}
+
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			logger.Error("Error creating output directory", "err", err)
+			return 1
+		}
+		for _, r := range rules {
+			if err := os.WriteFile(filepath.Join(*outDir, r.Name+".yml"), []byte(r.YAML), 0o644); err != nil {
+				logger.Error("Error writing rule", "err", err)
+				return 1
+			}
+		}
+		logSuccess("Sigma rules written", "dir", *outDir, "rules", len(rules))
+		return 0
+	}
+
+	var b strings.Builder
+	for i, r := range rules {
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		b.WriteString(r.YAML)
+	}
+	if *outPath == "" || *outPath == "-" {
+		io.WriteString(os.Stdout, b.String())
+		return 0
+	}
+	if err := os.WriteFile(*outPath, []byte(b.String()), 0o644); err != nil {
+		logger.Error("Error writing rules", "err", err)
+		return 1
+	}
+	logSuccess("Sigma rules written", "path", *outPath, "rules", len(rules))
+	return 0
+}
+
+// runExportBlocklist writes the database's domains in a format firewalls,
+// proxies, and DNS sinkholes can enforce.
+func runExportBlocklist(args []string) int {
+	fs := flag.NewFlagSet("export blocklist", flag.ExitOnError)
+	format := fs.String("format", reporter.BlocklistEDL, "List format: "+strings.Join(reporter.BlocklistFormats, ", "))
+	outPath := fs.String("out", "", "Write the list to this file (default: stdout)")
+	categoryFilter := fs.String("category", "", "Only services in these categories, comma-separated (e.g. code-assistant,llm)")
+	exclude := fs.String("exclude", "", "Leave out these services, comma-separated (e.g. sanctioned ones)")
+	includeTooling := fs.Bool("include-tooling", false, "Also block update and telemetry hosts of AI apps and extensions")
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+	services := filterServices(az.Services(), *categoryFilter, *exclude)
+	if len(services) == 0 {
+		logger.Error("No services left to list")
+		return 1
+	}
+
+	var b strings.Builder
+	skipped, err := reporter.WriteBlocklist(services, *includeTooling, strings.ToLower(*format), time.Now(), &b)
+	if err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if skipped > 0 {
+		logger.Warn(fmt.Sprintf("%d wildcard host pattern(s) cannot be expressed in %s format and were left out", skipped, *format))
+	}
+	if *outPath == "" || *outPath == "-" {
+		io.WriteString(os.Stdout, b.String())
+		return 0
+	}
+	if err := os.WriteFile(*outPath, []byte(b.String()), 0o644); err != nil {
+		logger.Error("Error writing blocklist", "err", err)
+		return 1
+	}
+	logSuccess("Blocklist written", "path", *outPath, "services", len(services))
+	return 0
+}
+
+// filterServices keeps services in the comma-separated categories and
+// drops the comma-separated excluded names. Without categories it keeps
+// all but the VPN and proxy services and the DNS over HTTPS resolvers,
+// which are not AI services and must be named to be exported.
+func filterServices(services []analyzer.AIService, categories, exclude string) []analyzer.AIService {
+	if categories != "" {
+		keep := analyzer.InCategories(strings.Split(categories, ","))
+		services = slices.DeleteFunc(services, func(svc analyzer.AIService) bool {
+			return !keep(analyzer.Finding{Category: svc.Category})
+		})
+	} else {
+		services = slices.DeleteFunc(services, func(svc analyzer.AIService) bool {
+			return svc.Category == analyzer.CategoryVPN || svc.Category == analyzer.CategoryDoH
+		})
+	}
+	if exclude != "" {
+		names := strings.Split(exclude, ",")
+		services = slices.DeleteFunc(services, func(svc analyzer.AIService) bool {
+			return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(strings.TrimSpace(n), svc.Name) })
+		})
+	}
+	return services
+}
+
+// historyFindings reads the stored findings seen in [from, to); zero
+// bounds are open. A tenant keeps only the findings tagged with it.
+func historyFindings(path string, from, to time.Time, tenant string) ([]analyzer.Finding, error) {
+	store, err := history.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening history: %w", err)
+	}
+	records, err := store.Records()
+	store.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	var findings []analyzer.Finding
+	for _, r := range records {
+		at := r.Finding.Timestamp
+		if at.IsZero() {
+			at = r.ScannedAt
+		}
+		if (!from.IsZero() && at.Before(from)) || (!to.IsZero() && !at.Before(to)) {
+			continue
+		}
+		if tenant != "" && r.Finding.Tenant != tenant {
+			continue
+		}
+		findings = append(findings, r.Finding)
+	}
+	logger.Info("Reading history", "records", len(findings), "total", len(records))
+	return findings, nil
+}
+
+// parseDay reads a -from/-to value as a UTC date or an RFC 3339 time.
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not YYYY-MM-DD or RFC 3339", s)
+	}
+	return t.UTC(), nil
+}
+
+// redactLocation hides any password in a store URL before it is recorded.
+func redactLocation(location string) string {
+	if u, err := url.Parse(location); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return location
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/loggen"
+)
+
+// runGenerate handles the "generate" subcommand: it writes a synthetic log
+// with AI hits drawn from the loaded database, for demos, training, and
+// trying out custom rules.
+func runGenerate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	format := fs.String("format", "squid", "Log format: squid, dns, csv")
+	lines := fs.Int("lines", 10000, "Number of log lines")
+	aiPercent := fs.Float64("ai-percent", 5, "Percentage of lines that hit an AI service")
+	users := fs.Int("users", 250, "Number of distinct client addresses")
+	start := fs.String("start", "", "Timestamp of the first line, RFC 3339 (default: 08:00 UTC seven days ago)")
+	interval := fs.Duration("interval", 100*time.Millisecond, "Time between lines; the average, or the average at peak for -pattern business")
+	pattern := fs.String("pattern", "even", "Time distribution: "+strings.Join(loggen.Patterns, ", "))
+	seed := fs.Int64("seed", 1, "Random seed; the same options and seed always produce the same log")
+	categoryFilter := fs.String("category", "", "Only draw AI hits from these categories (comma-separated)")
+	out := fs.String("out", "", "Write the log to this file (default: stdout)")
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *lines < 0 || *aiPercent < 0 || *aiPercent > 100 || *users < 1 {
+		logger.Error("-lines must not be negative, -ai-percent must be 0 to 100, and -users at least 1")
+		return 1
+	}
+
+	opts := loggen.Options{
+		Format:   *format,
+		Lines:    *lines,
+		Seed:     *seed,
+		AIRatio:  *aiPercent / 100,
+		Users:    *users,
+		Interval: *interval,
+		Pattern:  *pattern,
+	}
+	if *start != "" {
+		t, err := time.Parse(time.RFC3339, *start)
+		if err != nil {
+			logger.Error("-start must be an RFC 3339 timestamp, e.g. 2025-06-10T08:00:00Z")
+			return 1
+		}
+		opts.Start = t
+	} else {
+		y, m, d := time.Now().UTC().AddDate(0, 0, -7).Date()
+		opts.Start = time.Date(y, m, d, 8, 0, 0, 0, time.UTC)
+	}
+
+	az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+	keep := func(svc analyzer.AIService) bool {
+		return svc.Category != analyzer.CategoryVPN && svc.Category != analyzer.CategoryDoH
+	}
+	if *categoryFilter != "" {
+		categories := make(map[string]bool)
+		for _, c := range strings.Split(*categoryFilter, ",") {
+			categories[analyzer.NormalizeCategory(c)] = true
+		}
+		keep = func(svc analyzer.AIService) bool { return categories[analyzer.NormalizeCategory(svc.Category)] }
+	}
+	for _, svc := range az.Services() {
+		if keep(svc) {
+			opts.AIDomains = append(opts.AIDomains, svc.Domains...)
+		}
+	}
+	if len(opts.AIDomains) == 0 {
+		logger.Error("No AI service domains to draw from", "category", *categoryFilter)
+		return 1
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.Create(*out); err != nil {
+			logger.Error("Error creating output file", "err", err)
+			return 1
+		}
+	}
+	err = loggen.Generate(w, opts)
+	if *out != "" {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		logger.Error("Error generating log", "err", err)
+		return 1
+	}
+	if *out != "" {
+		logSuccess(fmt.Sprintf("Wrote %d %s line(s)", *lines, *format), "path", *out, "ai_domains", len(opts.AIDomains))
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// runMerge combines JSON reports from several sites into one.
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: shadow-hunter merge [-output format] [-out file] [site=]report.json ...")
+		fs.PrintDefaults()
+	}
+	outputFmt := fs.String("output", "table", "Output format: table, json, csv, html, pdf (default: table)")
+	outPath := fs.String("out", "", "Write the merged report to this file instead of stdout")
+	top := fs.Int("top", 0, "Limit each summary table in table output to N rows (0 for all)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	var reports []analyzer.SiteReport
+	for _, arg := range fs.Args() {
+		site, path := siteOf(arg)
+		s, err := reporter.LoadJSONReport(path)
+		if err != nil {
+			logger.Error("Error loading report", "path", path, "err", err)
+			return 1
+		}
+		if len(s.Findings) < s.TotalFindings {
+			logger.Error("Report holds totals but no findings, so it cannot be merged; save it with a redaction profile that keeps findings", "path", path)
+			return 1
+		}
+		reports = append(reports, analyzer.SiteReport{Site: site, Summary: s})
+	}
+
+	merged, dropped := analyzer.Merge(reports)
+	merged.Layout.Top = *top
+	logger.Info("Merged reports", "reports", len(reports), "findings", merged.TotalFindings, "duplicates", dropped)
+
+	format := reporter.Format(strings.ToLower(*outputFmt))
+	var err error
+	if *outPath != "" {
+		err = reporter.WriteToFile(merged, format, *outPath)
+	} else {
+		err = reporter.Report(merged, format, os.Stdout)
+	}
+	if err != nil {
+		logger.Error("Error generating report", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// siteOf splits a "site=path" argument. A bare path names its site after
+// the file.
+func siteOf(arg string) (site, path string) {
+	if site, path, ok := strings.Cut(arg, "="); ok && site != "" && !strings.ContainsAny(site, `/\`) {
+		return site, path
+	}
+	return strings.TrimSuffix(filepath.Base(arg), filepath.Ext(arg)), arg
+}
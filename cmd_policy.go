@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/policy"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// runPolicy handles the "policy" subcommand family.
+func runPolicy(args []string) int {
+	if len(args) == 0 || args[0] != "simulate" {
+		fmt.Fprintln(os.Stderr, "Usage: shadow-hunter policy simulate -history <store> -proposed <policy.json> [options]")
+		return 1
+	}
+
+	fs := flag.NewFlagSet("policy simulate", flag.ExitOnError)
+	historyPath := fs.String("history", "", "Path to the historical findings store")
+	tenant := fs.String("tenant", "", "Only re-evaluate findings tagged with this tenant")
+	currentPath := fs.String("current", "", "Path to the current policy JSON (default: no allowlist)")
+	proposedPath := fs.String("proposed", "", "Path to the proposed policy JSON")
+	outputFmt := fs.String("output", "table", "Output format: table, json (default: table)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args[1:])
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	if *historyPath == "" || *proposedPath == "" {
+		fs.Usage()
+		return 1
+	}
+
+	var current *policy.Policy
+	if *currentPath != "" {
+		p, err := policy.Load(*currentPath)
+		if err != nil {
+			logger.Error("Error loading current policy", "err", err)
+			return 1
+		}
+		current = p
+	}
+
+	proposed, err := policy.Load(*proposedPath)
+	if err != nil {
+		logger.Error("Error loading proposed policy", "err", err)
+		return 1
+	}
+
+	store, err := history.Open(*historyPath)
+	if err != nil {
+		logger.Error("Error opening history", "err", err)
+		return 1
+	}
+	findings, err := store.Findings()
+	store.Close()
+	if err != nil {
+		logger.Error("Error reading history", "err", err)
+		return 1
+	}
+	findings = analyzer.OfTenant(findings, *tenant)
+	logger.Info("Re-evaluating stored findings", "findings", len(findings))
+
+	sim := policy.Simulate(findings, current, proposed)
+	if err := reporter.ReportSimulation(sim, reporter.Format(strings.ToLower(*outputFmt)), os.Stdout); err != nil {
+		logger.Error("Error generating report", "err", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/config"
+	"github.com/shadow-ai-hunter/kafka"
+	"github.com/shadow-ai-hunter/notify"
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// replaySink is a destination replayed findings are sent to.
+type replaySink struct {
+	name  string
+	send  func(ctx context.Context, f analyzer.Finding, body []byte) error
+	close func() error
+}
+
+// runReplay handles the "replay" subcommand: it scans historical logs and
+// sends their findings to alerting sinks with the original spacing, scaled
+// by -speed, so downstream pipelines see realistic traffic.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	format := fs.String("format", "auto", "Log format, as for a scan")
+	speed := fs.Float64("speed", 1, "Replay speed: 1 keeps the logged gaps between findings, 10 is ten times faster, 0 sends without delay")
+	rate := fs.Float64("rate", 0, "Send at most this many findings per second (0 for no limit)")
+	maxGap := fs.Duration("max-gap", 0, "Wait at most this long between findings, however far apart they were logged (0 for no limit)")
+	retime := fs.Bool("retime", false, "Stamp each finding with the time it is sent instead of its logged time")
+	webhook := fs.String("webhook", "", "POST each finding as JSON to this URL")
+	var webhookHeaders stringList
+	fs.Var(&webhookHeaders, "webhook-header", "Header sent with every webhook request, as Name=value (repeatable)")
+	syslogAddr := fs.String("syslog", "", "Send each finding as an RFC 5424 message to this collector: udp://, tcp://, or tls://host:port")
+	kafkaBrokers := fs.String("kafka-brokers", "", "Publish each finding to Kafka via these brokers, comma-separated host:port (TLS and SASL from -config)")
+	kafkaTopic := fs.String("kafka-topic", "", "Kafka topic for -kafka-brokers (default: shadow-ai-findings)")
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	configFile := fs.String("config", "", "Path to JSON config file (custom parsers, multiline, kafka)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: shadow-hunter replay [-speed N] [-rate N] [-webhook url] [-syslog url] [-kafka-brokers list] <logfile> ...")
+		return 1
+	}
+	if *speed < 0 || *rate < 0 {
+		logger.Error("-speed and -rate must not be negative")
+		return 1
+	}
+
+	var cfg config.Config
+	if *configFile != "" {
+		loaded, err := config.Load(*configFile)
+		if err != nil {
+			logger.Error("Error loading config", "err", err)
+			return 1
+		}
+		cfg = *loaded
+	}
+	var custom []customParser
+	for _, def := range cfg.Parsers {
+		p, err := def.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			return 1
+		}
+		custom = append(custom, customParser{parser: p, files: def.Files})
+	}
+	var multiline *parsers.Multiline
+	if cfg.Multiline != nil {
+		ml, err := cfg.Multiline.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			return 1
+		}
+		multiline = &ml
+	}
+
+	sinks, err := replaySinks(*webhook, webhookHeaders, *syslogAddr, *kafkaBrokers, *kafkaTopic, cfg.Kafka)
+	if err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	defer func() {
+		for _, s := range sinks {
+			if s.close != nil {
+				s.close()
+			}
+		}
+	}()
+
+	az, err := loadDB(*servicesDB, *servicesDir, customDBs)
+	if err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	scanner := &fileScanner{ctx: ctx, az: az, format: *format, custom: custom, progress: &progressBar{w: io.Discard}}
+	out := scanner.scanAll(files, multiline)
+	for _, f := range out.files {
+		if f.Error != "" {
+			logger.Warn("File not scanned cleanly", "file", f.Path, "err", f.Error)
+		}
+	}
+	// Resolver lookups are reported as DoH clients, not findings, so the
+	// sinks do not get them, as in follow mode
+	findings := slices.DeleteFunc(out.findings, analyzer.Finding.IsDoH)
+	slices.SortStableFunc(findings, func(a, b analyzer.Finding) int { return a.Timestamp.Compare(b.Timestamp) })
+	logger.Info(fmt.Sprintf("Replaying %d finding(s)", len(findings)), "sinks", len(sinks), "speed", *speed)
+
+	sent, err := replay(ctx, findings, sinks, replayPacing{speed: *speed, rate: *rate, maxGap: *maxGap, retime: *retime})
+	if err != nil && ctx.Err() == nil {
+		logger.Error("Replay failed", "sent", sent, "err", err)
+		return 1
+	}
+	if ctx.Err() != nil {
+		logger.Warn(fmt.Sprintf("Replay stopped after %d of %d finding(s)", sent, len(findings)))
+		return 1
+	}
+	logSuccess(fmt.Sprintf("Replayed %d finding(s)", sent))
+	return 0
+}
+
+// replaySinks opens the sinks named by the flags. Without any, findings
+// are written to stdout as JSON lines.
+func replaySinks(webhook string, headers []string, syslogAddr, kafkaBrokers, kafkaTopic string, kafkaCfg *config.Kafka) ([]replaySink, error) {
+	var sinks []replaySink
+	if webhook != "" {
+		hook := notify.Webhook{URL: webhook, Headers: make(map[string]string)}
+		for _, h := range headers {
+			name, value, ok := strings.Cut(h, "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("-webhook-header %q must be Name=value", h)
+			}
+			hook.Headers[name] = value
+		}
+		sinks = append(sinks, replaySink{name: "webhook", send: func(ctx context.Context, _ analyzer.Finding, body []byte) error {
+			return hook.Post(ctx, body)
+		}})
+	} else if len(headers) > 0 {
+		return nil, fmt.Errorf("-webhook-header needs -webhook")
+	}
+
+	if syslogAddr != "" {
+		s, err := notify.DialSyslog(syslogAddr, "shadow-hunter")
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, replaySink{name: "syslog", send: func(_ context.Context, f analyzer.Finding, body []byte) error {
+			return s.Send(f.Timestamp, syslogSeverity(f.Severity()), body)
+		}, close: s.Close})
+	}
+
+	kc := config.Kafka{}
+	if kafkaCfg != nil {
+		kc = *kafkaCfg
+	}
+	if kafkaBrokers != "" {
+		kc.Brokers = strings.Split(kafkaBrokers, ",")
+	}
+	if kafkaTopic != "" {
+		kc.Topic = kafkaTopic
+	}
+	if len(kc.Brokers) > 0 {
+		if kc.Topic == "" {
+			kc.Topic = "shadow-ai-findings"
+		}
+		built, err := kc.Build()
+		if err != nil {
+			return nil, fmt.Errorf("kafka settings: %w", err)
+		}
+		p, err := kafka.NewProducer(built)
+		if err != nil {
+			return nil, fmt.Errorf("kafka settings: %w", err)
+		}
+		sinks = append(sinks, replaySink{name: "kafka", send: func(ctx context.Context, f analyzer.Finding, _ []byte) error {
+			return publishFindings(ctx, p, []analyzer.Finding{f})
+		}, close: p.Close})
+	} else if kafkaTopic != "" {
+		return nil, fmt.Errorf("-kafka-topic needs -kafka-brokers or brokers in -config")
+	}
+
+	if len(sinks) == 0 {
+		sinks = append(sinks, replaySink{name: "stdout", send: func(_ context.Context, _ analyzer.Finding, body []byte) error {
+			_, err := os.Stdout.Write(append(body, '\n'))
+			return err
+		}})
+	}
+	return sinks, nil
+}
+
+// syslogSeverity maps a finding's severity to a syslog one.
+func syslogSeverity(s analyzer.Severity) int {
+	switch s {
+	case analyzer.SeverityHigh:
+		return notify.SyslogWarning
+	case analyzer.SeverityMedium:
+		return notify.SyslogNotice
+	default:
+		return notify.SyslogInfo
+	}
+}
+
+// replayPacing decides when each finding is sent.
+type replayPacing struct {
+	speed  float64       // divides the logged gaps; 0 sends without delay
+	rate   float64       // findings per second at most; 0 for no limit
+	maxGap time.Duration // longest wait between two findings; 0 for no limit
+	retime bool          // stamp findings with the send time
+}
+
+// wait returns how long after the previous send the finding logged at ts
+// is due, given the previous finding was logged at prev.
+func (p replayPacing) wait(prev, ts time.Time) time.Duration {
+	var d time.Duration
+	if p.speed > 0 && !prev.IsZero() && ts.After(prev) {
+		d = time.Duration(float64(ts.Sub(prev)) / p.speed)
+	}
+	if p.maxGap > 0 {
+		d = min(d, p.maxGap)
+	}
+	if p.rate > 0 {
+		d = max(d, time.Duration(float64(time.Second)/p.rate))
+	}
+	return d
+}
+
+// replay sends the findings, in order, to every sink, pacing them as p
+// says. It stops at the first failed send, returning how many findings
+// were sent in full.
+func replay(ctx context.Context, findings []analyzer.Finding, sinks []replaySink, p replayPacing) (int, error) {
+	var prev time.Time // logged time of the previous finding
+	var last time.Time // when the previous finding was sent
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for i, f := range findings {
+		if i > 0 {
+			timer.Reset(time.Until(last.Add(p.wait(prev, f.Timestamp))))
+			select {
+			case <-ctx.Done():
+				return i, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if !f.Timestamp.IsZero() {
+			prev = f.Timestamp
+		}
+		last = time.Now()
+		if p.retime {
+			f.Timestamp = last.UTC()
+		}
+		body, err := reporter.MarshalFinding(f)
+		if err != nil {
+			return i, err
+		}
+		for _, s := range sinks {
+			if err := s.send(ctx, f, body); err != nil {
+				return i, fmt.Errorf("%s: %w", s.name, err)
+			}
+		}
+	}
+	return len(findings), nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/config"
+	"github.com/shadow-ai-hunter/dashboard"
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/policy"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// maxJobRequest bounds the body of POST /jobs.
+const maxJobRequest = 64 * 1024
+
+// runServe handles the "serve" subcommand: an HTTP API that runs scans as
+// background jobs.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:8080", "Address to listen on")
+	root := fs.String("root", ".", "Only files under this directory can be scanned")
+	maxJobs := fs.Int("max-jobs", 2, "Scans run at the same time")
+	maxQueued := fs.Int("max-queued", 16, "Scans queued or running before new jobs are refused")
+	keepJobs := fs.Int("keep-jobs", 100, "Finished jobs kept for status and results")
+	servicesDB := fs.String("services", "", "Path to ai_services.json (default: same lookup as a scan)")
+	servicesDir := fs.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	fs.Var(&customDBs, "custom", "Path to custom domains JSON to merge (repeatable)")
+	policyFile := fs.String("policy", "", "Path to policy/allowlist JSON applied to every job")
+	tenant := fs.String("tenant", "", "Business unit to tag findings with when a job names none")
+	historyFile := fs.String("history", "", "Record every job's findings in this store and show it on the dashboard (.jsonl path, or .db, postgres://, or bolt:// in tagged builds)")
+	configFile := fs.String("config", "", "Path to JSON config file (custom parsers, multiline, url_redaction)")
+	runAs := fs.String("user", "", "After binding the listener, switch to this user (Unix, started as root)")
+	chroot := fs.String("chroot", "", "After binding the listener, confine the process to this directory (Unix, needs root); -root is then inside it")
+	malformedWarn := fs.Float64("malformed-warn", 50, "Warn when more than this percentage of a file's lines cannot be parsed (0 disables)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *maxJobs < 1 || *maxQueued < *maxJobs {
+		logger.Error("-max-jobs must be at least 1 and no more than -max-queued")
+		return 1
+	}
+
+	var cfg config.Config
+	if *configFile != "" {
+		loaded, err := config.Load(*configFile)
+		if err != nil {
+			logger.Error("Error loading config", "err", err)
+			return 1
+		}
+		cfg = *loaded
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	m := newJobManager(ctx, *maxJobs, *maxQueued, *keepJobs)
+	m.warnRatio = *malformedWarn / 100
+	m.tenant = *tenant
+	for _, def := range cfg.Parsers {
+		p, err := def.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			return 1
+		}
+		m.custom = append(m.custom, customParser{parser: p, files: def.Files})
+	}
+	if cfg.Multiline != nil {
+		ml, err := cfg.Multiline.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			return 1
+		}
+		m.multiline = &ml
+	}
+
+	var err error
+	if m.az, err = loadDB(*servicesDB, *servicesDir, customDBs); err != nil {
+		logger.Error("Error loading AI services database", "err", err)
+		return 1
+	}
+	logger.Info(fmt.Sprintf("Loaded %d AI services (%d domains)", m.az.ServiceCount(), m.az.DomainCount()), dbAttrs(m.az)...)
+	if cfg.URLRedaction != nil {
+		scrubber, err := cfg.URLRedaction.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			return 1
+		}
+		m.az.SetURLScrubber(scrubber)
+	}
+	if *policyFile != "" {
+		if m.policy, err = policy.Load(*policyFile); err != nil {
+			logger.Error("Error loading policy", "err", err)
+			return 1
+		}
+	}
+
+	if *historyFile != "" {
+		store, err := history.Open(*historyFile)
+		if err != nil {
+			logger.Error("Error opening history", "err", err)
+			return 1
+		}
+		m.history = &serveHistory{store: store}
+		defer m.history.close()
+	}
+
+	// Everything privileged happens before this point: the listener may need
+	// a low port, and the database, policy, and history may live outside -chroot
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		logger.Error("Error listening", "err", err)
+		return 1
+	}
+	if err := dropPrivileges(*runAs, *chroot); err != nil {
+		logger.Error("Error dropping privileges", "err", err)
+		return 1
+	}
+	if m.root, err = rootDir(*root); err != nil {
+		logger.Error("Error in -root", "err", err)
+		return 1
+	}
+	if err := fsutil.Restrict([]string{m.root}); err != nil {
+		logger.Error("Error restricting file access", "err", err)
+		return 1
+	}
+
+	srv := &http.Server{
+		Handler:           newServeMux(m),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	logger.Info("Serving scan API and dashboard", "listen", ln.Addr().String(), "root", m.root, "max_jobs", *maxJobs)
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Error serving", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// newServeMux routes the job API:
+//
+//	POST /jobs              submit a scan, 202 with the job
+//	GET  /jobs              list jobs, newest first
+//	GET  /jobs/{id}         status and progress
+//	GET  /jobs/{id}/result  the report once done (?output=json|csv|html|pdf|table)
+//	DELETE /jobs/{id}       cancel a queued or running job
+//	GET  /metrics           Prometheus counters
+//	GET  /history/summary   totals, top users and services, findings per day
+//	GET  /history/findings  search stored findings (?q=&service=&user=&limit=)
+//	GET  /                  the dashboard
+func newServeMux(m *jobManager) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /", dashboard.Handler())
+	handleHistory(mux, m.history)
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		var req jobRequest
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobRequest))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid job request: %v", err))
+			return
+		}
+		j, err := m.submit(req)
+		if errors.Is(err, errQueueFull) {
+			w.Header().Set("Retry-After", "30")
+			writeError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Location", "/jobs/"+j.ID)
+		writeJSON(w, http.StatusAccepted, j.view())
+	})
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		views := []jobView{}
+		for _, j := range m.list() {
+			v := j.view()
+			v.Files = nil
+			views = append(views, v)
+		}
+		writeJSON(w, http.StatusOK, views)
+	})
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		j, ok := m.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "no such job")
+			return
+		}
+		writeJSON(w, http.StatusOK, j.view())
+	})
+	mux.HandleFunc("DELETE /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		j, ok := m.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "no such job")
+			return
+		}
+		j.stop()
+		writeJSON(w, http.StatusAccepted, j.view())
+	})
+	mux.Handle("GET /metrics", m.metrics.registry.Handler())
+	mux.HandleFunc("GET /jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		j, ok := m.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "no such job")
+			return
+		}
+		summary, status, ok := j.result()
+		if !ok {
+			writeError(w, http.StatusConflict, fmt.Sprintf("job is %s", status))
+			return
+		}
+		format := reporter.Format(strings.ToLower(r.URL.Query().Get("output")))
+		if format == "" {
+			format = reporter.FormatJSON
+		}
+		writeReport(w, summary, format)
+	})
+	return mux
+}
+
+// writeReport renders summary in format, buffering so a rendering error
+// can still become a proper status.
+func writeReport(w http.ResponseWriter, summary analyzer.Summary, format reporter.Format) {
+	var buf strings.Builder
+	if err := reporter.Report(summary, format, &buf); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch format {
+	case reporter.FormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+	case reporter.FormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	case reporter.FormatHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	case reporter.FormatPDF:
+		w.Header().Set("Content-Type", "application/pdf")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	io.WriteString(w, buf.String())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/shadow-ai-hunter/dbupdate"
+	"github.com/shadow-ai-hunter/seal"
+)
+
+// runSign handles the "sign" subcommand family.
+func runSign(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintln(os.Stderr, "  shadow-hunter sign keygen -out <key>")
+		fmt.Fprintln(os.Stderr, "  shadow-hunter sign verify -pubkey <base64-key> [-sig <file.sig>] <report>")
+		return 1
+	}
+
+	switch args[0] {
+	case "keygen":
+		return runSignKeygen(args[1:])
+	case "verify":
+		return runSignVerify(args[1:])
+	default:
+		logger.Error(fmt.Sprintf("Unknown sign command %q", args[0]))
+		return 1
+	}
+}
+
+// runSignKeygen creates a key for -sign-key and prints its public half.
+func runSignKeygen(args []string) int {
+	fs := flag.NewFlagSet("sign keygen", flag.ExitOnError)
+	out := fs.String("out", "", "Where to write the private key (must not exist)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *out == "" {
+		fs.Usage()
+		return 1
+	}
+
+	pub, err := seal.GenerateKey(*out)
+	if err != nil {
+		logger.Error("Error generating key", "err", err)
+		return 1
+	}
+	logSuccess("Signing key written", "path", *out)
+	fmt.Println(pub)
+	return 0
+}
+
+// runSignVerify checks a report file against its detached signature.
+func runSignVerify(args []string) int {
+	fs := flag.NewFlagSet("sign verify", flag.ExitOnError)
+	pubKey := fs.String("pubkey", "", "Base64 ed25519 public key printed by \"sign keygen\"")
+	sigPath := fs.String("sig", "", "Signature file (default: <report>.sig)")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if *pubKey == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+
+	key, err := dbupdate.ParsePublicKey(*pubKey)
+	if err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+	if err := seal.Verify(fs.Arg(0), *sigPath, key); err != nil {
+		logger.Error("Verification failed", "path", fs.Arg(0), "err", err)
+		return 1
+	}
+	logSuccess("Signature is valid", "path", fs.Arg(0))
+	return 0
+}
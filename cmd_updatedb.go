@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/shadow-ai-hunter/dbupdate"
+)
+
+// runUpdateDB handles the "update-db" subcommand.
+func runUpdateDB(args []string) int {
+	fs := flag.NewFlagSet("update-db", flag.ExitOnError)
+	srcURL := fs.String("url", dbupdate.DefaultURL, "HTTPS URL of the AI services database")
+	dest := fs.String("out", "", "Where to store the database (default: user cache directory)")
+	pubKey := fs.String("pubkey", "", "Base64 ed25519 public key; requires a valid signature at <url>.sig")
+	timeout := fs.Duration("timeout", 30*time.Second, "Download timeout")
+	logOpts := addLogFlags(fs)
+	fs.Parse(args)
+	if err := logOpts.setup(os.Stderr, slog.LevelInfo); err != nil {
+		logger.Error(err.Error())
+		return 1
+	}
+
+	path := *dest
+	if path == "" {
+		var err error
+		if path, err = dbupdate.CachePath(); err != nil {
+			logger.Error("Cannot determine cache directory", "err", err)
+			return 1
+		}
+	}
+
+	var key ed25519.PublicKey
+	if *pubKey != "" {
+		var err error
+		if key, err = dbupdate.ParsePublicKey(*pubKey); err != nil {
+			logger.Error(err.Error())
+			return 1
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	logger.Info("Checking for a database update", "url", *srcURL)
+	res, err := dbupdate.Update(ctx, dbupdate.Options{
+		URL:       *srcURL,
+		Dest:      path,
+		PublicKey: key,
+		Client:    &http.Client{},
+	})
+	if err != nil {
+		logger.Error("Update failed", "err", err)
+		return 1
+	}
+	if !res.Updated {
+		logSuccess("Database is up to date", "path", path)
+		return 0
+	}
+	attrs := []any{"services", res.Services, "path", path}
+	if v := res.Info.String(); v != "" {
+		attrs = append(attrs, "db_version", v)
+	}
+	logSuccess("Downloaded AI services database", attrs...)
+	return 0
+}
@@ -0,0 +1,588 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/kafka"
+	"github.com/shadow-ai-hunter/notify"
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/redact"
+	"github.com/shadow-ai-hunter/schedule"
+	"github.com/shadow-ai-hunter/seal"
+	"github.com/shadow-ai-hunter/sentinel"
+	"github.com/shadow-ai-hunter/workspace"
+)
+
+// Config is the optional JSON configuration file passed with -config.
+type Config struct {
+	RedactionProfiles map[string]redact.Profile `json:"redaction_profiles"`
+	URLRedaction      *URLRedaction             `json:"url_redaction,omitempty"` // scrubbing applied before findings are stored
+	Seal              *Seal                     `json:"seal,omitempty"`          // encryption and signing of report files
+	Outputs           []Output                  `json:"outputs"`
+	BusinessHours     *BusinessHours            `json:"business_hours,omitempty"`
+	Timezones         *Timezones                `json:"timezones,omitempty"`
+	Kafka             *Kafka                    `json:"kafka,omitempty"`
+	Sentinel          *Sentinel                 `json:"sentinel,omitempty"`
+	GoogleWorkspace   *GoogleWorkspace          `json:"google_workspace,omitempty"`
+	SMTP              *SMTP                     `json:"smtp,omitempty"`
+	Daemon            *Daemon                   `json:"daemon,omitempty"` // schedules for -daemon
+	Actions           []Action                  `json:"actions,omitempty"`
+	Parsers           []CustomParser            `json:"parsers"`
+	Multiline         *Multiline                `json:"multiline,omitempty"` // framing for built-in line formats
+}
+
+// CustomParser defines a parser for a log format without built-in support.
+// It is selected with -format <name>, by matching one of Files in auto mode,
+// or as a last resort when a built-in parser yields nothing.
+type CustomParser struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`                  // "regex", "grok", "squid", "delimited", or "exec"
+	Pattern    string            `json:"pattern"`               // named-capture regexp, grok expression, or Squid logformat
+	Delimiter  string            `json:"delimiter,omitempty"`   // delimited: field separator, default ","
+	Columns    map[string]int    `json:"columns,omitempty"`     // delimited: field name to 1-based column
+	Command    []string          `json:"command,omitempty"`     // exec: plugin program and arguments
+	Patterns   map[string]string `json:"patterns,omitempty"`    // extra grok pattern definitions
+	TimeLayout string            `json:"time_layout,omitempty"` // Go layout for the timestamp group
+	Files      []string          `json:"files,omitempty"`       // filename globs, e.g. "*.fw.log"
+	Multiline  *Multiline        `json:"multiline,omitempty"`
+}
+
+// Build constructs the parser described by the definition.
+func (c CustomParser) Build() (parsers.Parser, error) {
+	if c.Type == "exec" {
+		if c.Multiline != nil {
+			return nil, fmt.Errorf("parser %s: multiline does not apply to exec plugins", c.Name)
+		}
+		return parsers.NewExecParser(c.Name, c.Command)
+	}
+
+	var p parsers.LineParser
+	var err error
+	switch c.Type {
+	case "regex":
+		p, err = parsers.NewRegexParser(c.Name, c.Pattern, c.TimeLayout)
+	case "grok":
+		p, err = parsers.NewGrokParser(c.Name, c.Pattern, c.Patterns, c.TimeLayout)
+	case "squid":
+		p, err = parsers.NewSquidFormatParser(c.Name, c.Pattern)
+	case "delimited":
+		p, err = parsers.NewDelimitedParser(c.Name, c.Delimiter, c.Columns, c.TimeLayout)
+	default:
+		return nil, fmt.Errorf("parser %s: unknown type %q", c.Name, c.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Multiline == nil {
+		return p, nil
+	}
+	m, err := c.Multiline.Build()
+	if err != nil {
+		return nil, fmt.Errorf("parser %s: %w", c.Name, err)
+	}
+	return parsers.NewMultilineParser(p, m), nil
+}
+
+// Multiline reassembles records that span several lines before parsing.
+type Multiline struct {
+	Continuation string `json:"continuation"`        // regexp matching continuation lines
+	Negate       bool   `json:"negate,omitempty"`    // continuation matches record starts instead
+	MaxLines     int    `json:"max_lines,omitempty"` // default 500
+	Timeout      string `json:"timeout,omitempty"`   // follow mode flush delay, default "5s"
+}
+
+// Build validates the settings and applies defaults.
+func (m Multiline) Build() (parsers.Multiline, error) {
+	if m.Continuation == "" {
+		return parsers.Multiline{}, fmt.Errorf("multiline: missing continuation pattern")
+	}
+	re, err := regexp.Compile(m.Continuation)
+	if err != nil {
+		return parsers.Multiline{}, fmt.Errorf("multiline: bad continuation pattern: %w", err)
+	}
+
+	out := parsers.Multiline{Continuation: re, Negate: m.Negate, MaxLines: m.MaxLines, Timeout: 5 * time.Second}
+	if out.MaxLines == 0 {
+		out.MaxLines = 500
+	}
+	if m.Timeout != "" {
+		if out.Timeout, err = time.ParseDuration(m.Timeout); err != nil {
+			return parsers.Multiline{}, fmt.Errorf("multiline: bad timeout: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// BusinessHours configures off-hours detection.
+type BusinessHours struct {
+	Hours    string `json:"hours"`    // e.g. "08:00-18:00"
+	Days     string `json:"days"`     // e.g. "mon-fri"
+	Timezone string `json:"timezone"` // IANA name; empty means local time
+}
+
+// URLRedaction configures the scrubbing of finding URLs. Unlike a redaction
+// profile it applies to every finding as it is detected, so the scrubbed
+// parts never reach history, spill files, exports, or reports.
+type URLRedaction struct {
+	Defaults     bool     `json:"defaults,omitempty"`      // add the built-in patterns
+	QueryParams  []string `json:"query_params,omitempty"`  // regexps for parameter names to remove
+	PathSegments []string `json:"path_segments,omitempty"` // regexps for path segments to replace
+}
+
+// Build compiles the patterns into a scrubber.
+func (u URLRedaction) Build() (*analyzer.URLScrubber, error) {
+	s, err := analyzer.NewURLScrubber(u.QueryParams, u.PathSegments, u.Defaults)
+	if err != nil {
+		return nil, fmt.Errorf("url_redaction: %w", err)
+	}
+	return s, nil
+}
+
+// Seal configures the encryption and signing of report files.
+type Seal struct {
+	EncryptTo []string `json:"encrypt_to,omitempty"` // age recipients, or files listing them
+	SignKey   string   `json:"sign_key,omitempty"`   // ed25519 key from "sign keygen"
+}
+
+// Build loads the recipients and signing key. It returns nil when neither
+// is set.
+func (s Seal) Build() (*seal.Sealer, error) {
+	return seal.New(s.EncryptTo, s.SignKey)
+}
+
+// Timezones configures timestamp normalization.
+type Timezones struct {
+	Report string      `json:"report,omitempty"` // zone reports are written in; empty means UTC
+	Logs   string      `json:"logs,omitempty"`   // zone of logs that record none; empty means local time
+	Inputs []InputZone `json:"inputs,omitempty"`
+}
+
+// InputZone gives the zone of logs whose file names match Files.
+type InputZone struct {
+	Files    []string `json:"files"`    // filename globs, e.g. "dnsmasq*.log"
+	Timezone string   `json:"timezone"` // IANA name
+}
+
+// Build resolves the zone names.
+func (t Timezones) Build() (analyzer.Timezones, error) {
+	var out analyzer.Timezones
+	var err error
+	if out.Report, err = analyzer.LoadLocation(t.Report, time.UTC); err != nil {
+		return out, err
+	}
+	if out.Logs, err = analyzer.LoadLocation(t.Logs, time.Local); err != nil {
+		return out, err
+	}
+	for _, in := range t.Inputs {
+		if len(in.Files) == 0 || in.Timezone == "" {
+			return out, fmt.Errorf("timezones: each input needs files and a timezone")
+		}
+		loc, err := analyzer.LoadLocation(in.Timezone, nil)
+		if err != nil {
+			return out, err
+		}
+		out.Inputs = append(out.Inputs, analyzer.InputZone{Files: in.Files, Location: loc})
+	}
+	return out, nil
+}
+
+// Kafka configures publishing findings to a Kafka topic.
+type Kafka struct {
+	Brokers            []string `json:"brokers"`
+	Topic              string   `json:"topic"`
+	TLS                bool     `json:"tls,omitempty"`
+	CAFile             string   `json:"ca_file,omitempty"`   // PEM bundle; empty means the system roots
+	CertFile           string   `json:"cert_file,omitempty"` // client certificate for mutual TLS
+	KeyFile            string   `json:"key_file,omitempty"`
+	InsecureSkipVerify bool     `json:"insecure_skip_verify,omitempty"`
+	SASLMechanism      string   `json:"sasl_mechanism,omitempty"` // PLAIN, SCRAM-SHA-256, or SCRAM-SHA-512
+	Username           string   `json:"username,omitempty"`
+	Password           string   `json:"password,omitempty"` // empty reads $KAFKA_PASSWORD
+}
+
+// Build loads the TLS material and returns the producer settings.
+func (k Kafka) Build() (kafka.Config, error) {
+	out := kafka.Config{
+		Brokers:  k.Brokers,
+		Topic:    k.Topic,
+		SASL:     k.SASLMechanism,
+		Username: k.Username,
+		Password: k.Password,
+	}
+	if out.Password == "" {
+		out.Password = os.Getenv("KAFKA_PASSWORD")
+	}
+	if !k.TLS && (k.CAFile != "" || k.CertFile != "" || k.InsecureSkipVerify) {
+		return out, fmt.Errorf("kafka: TLS settings need \"tls\": true")
+	}
+	if !k.TLS {
+		return out, nil
+	}
+	out.TLS = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: k.InsecureSkipVerify}
+	if k.CAFile != "" {
+		pem, err := os.ReadFile(k.CAFile)
+		if err != nil {
+			return out, fmt.Errorf("kafka: reading CA file: %w", err)
+		}
+		out.TLS.RootCAs = x509.NewCertPool()
+		if !out.TLS.RootCAs.AppendCertsFromPEM(pem) {
+			return out, fmt.Errorf("kafka: no certificates in %s", k.CAFile)
+		}
+	}
+	if k.CertFile != "" || k.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(k.CertFile, k.KeyFile)
+		if err != nil {
+			return out, fmt.Errorf("kafka: loading client certificate: %w", err)
+		}
+		out.TLS.Certificates = []tls.Certificate{cert}
+	}
+	return out, nil
+}
+
+// Sentinel configures the Microsoft Sentinel connector: findings sent to a
+// custom table through a data collection rule, and KQL queries run with
+// -sentinel-query against the workspace. Both authenticate as an Entra ID
+// app registration.
+type Sentinel struct {
+	TenantID      string `json:"tenant_id"`
+	ClientID      string `json:"client_id"`
+	ClientSecret  string `json:"client_secret,omitempty"` // empty reads $AZURE_CLIENT_SECRET
+	Authority     string `json:"authority,omitempty"`     // Entra ID endpoint, for sovereign clouds
+	Endpoint      string `json:"endpoint,omitempty"`      // data collection endpoint findings are sent to
+	DCRID         string `json:"dcr_id,omitempty"`        // the rule's immutable ID
+	Stream        string `json:"stream,omitempty"`        // default Custom-ShadowAIFindings_CL
+	WorkspaceID   string `json:"workspace_id,omitempty"`  // for -sentinel-query
+	QueryEndpoint string `json:"query_endpoint,omitempty"`
+}
+
+// Ingestor returns the connector that sends findings, or nil when no
+// endpoint is configured.
+func (s Sentinel) Ingestor() (*sentinel.Ingestor, error) {
+	if s.Endpoint == "" && s.DCRID == "" {
+		return nil, nil
+	}
+	cred, err := s.credential()
+	if err != nil {
+		return nil, err
+	}
+	stream := s.Stream
+	if stream == "" {
+		stream = "Custom-ShadowAIFindings_CL"
+	}
+	return sentinel.NewIngestor(s.Endpoint, s.DCRID, stream, cred)
+}
+
+// Querier returns the connector that runs KQL queries.
+func (s Sentinel) Querier() (*sentinel.Querier, error) {
+	cred, err := s.credential()
+	if err != nil {
+		return nil, err
+	}
+	return sentinel.NewQuerier(s.QueryEndpoint, s.WorkspaceID, cred)
+}
+
+func (s Sentinel) credential() (*sentinel.Credential, error) {
+	cred := &sentinel.Credential{TenantID: s.TenantID, ClientID: s.ClientID, ClientSecret: s.ClientSecret, Authority: s.Authority}
+	if cred.ClientSecret == "" {
+		cred.ClientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+	}
+	if cred.TenantID == "" || cred.ClientID == "" || cred.ClientSecret == "" {
+		return nil, fmt.Errorf("sentinel: tenant_id, client_id, and a client secret are required")
+	}
+	return cred, nil
+}
+
+// GoogleWorkspace configures reading Chrome browser events from the
+// Reports API with -workspace-since.
+type GoogleWorkspace struct {
+	CredentialsFile string `json:"credentials_file"` // service account key with domain-wide delegation
+	AdminEmail      string `json:"admin_email"`      // admin the service account acts for
+	CustomerID      string `json:"customer_id,omitempty"`
+	APIURL          string `json:"api_url,omitempty"` // default https://admin.googleapis.com
+}
+
+// Build loads the service account key.
+func (g GoogleWorkspace) Build() (*workspace.Client, error) {
+	if g.CredentialsFile == "" {
+		return nil, fmt.Errorf("google_workspace: credentials_file is required")
+	}
+	return workspace.New(workspace.Options{KeyFile: g.CredentialsFile, Subject: g.AdminEmail, Customer: g.CustomerID, API: g.APIURL})
+}
+
+// SMTP configures the mail relay scheduled reports are sent through.
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"` // default 587, or 465 with "tls"
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"` // empty reads $SMTP_PASSWORD
+	From     string `json:"from"`
+	TLS      string `json:"tls,omitempty"` // "starttls" (default), "tls", or "none"
+}
+
+// Build returns the relay settings.
+func (s SMTP) Build() (notify.Server, error) {
+	out := notify.Server{Host: s.Host, Port: s.Port, Username: s.Username, Password: s.Password, From: s.From, TLS: s.TLS}
+	if out.Password == "" {
+		out.Password = os.Getenv("SMTP_PASSWORD")
+	}
+	return out, out.Validate()
+}
+
+// Daemon configures -daemon mode, which runs scans on a schedule.
+type Daemon struct {
+	LockFile  string     `json:"lock_file,omitempty"` // default shadow-hunter.lock in StateDir
+	StateDir  string     `json:"state_dir,omitempty"` // read offsets, one file per schedule; default "."
+	Timezone  string     `json:"timezone,omitempty"`  // zone cron times are in; empty means local time
+	Schedules []Schedule `json:"schedules"`
+}
+
+// Schedule is one recurring scan.
+type Schedule struct {
+	Name        string   `json:"name"`
+	Cron        string   `json:"cron"` // e.g. "0 2 * * *" for 02:00 every day
+	File        string   `json:"file,omitempty"`
+	Dir         string   `json:"dir,omitempty"`
+	Format      string   `json:"format,omitempty"` // default "auto"
+	Categories  []string `json:"categories,omitempty"`
+	OnlyAllowed bool     `json:"only_allowed,omitempty"`
+	Tenant      string   `json:"tenant,omitempty"`  // stamped on findings; default -tenant
+	Full        bool     `json:"full,omitempty"`    // rescan whole files instead of only what was added
+	History     string   `json:"history,omitempty"` // store every run's findings, as -history
+	Outputs     []Output `json:"outputs,omitempty"` // paths may contain {date} and {time}
+	Email       *Email   `json:"email,omitempty"`
+}
+
+// Email mails a schedule's report after each run.
+type Email struct {
+	To        []string `json:"to"`
+	Subject   string   `json:"subject,omitempty"` // default "Shadow AI report: <name>, <date>"
+	Format    string   `json:"format,omitempty"`  // attached report: html (default), pdf, json, or csv
+	Profile   string   `json:"profile,omitempty"` // redaction profile for the mail
+	SkipClean bool     `json:"skip_clean,omitempty"`
+}
+
+// validate checks the schedules against the rest of the configuration.
+func (d Daemon) validate(cfg *Config) error {
+	if len(d.Schedules) == 0 {
+		return fmt.Errorf("daemon: no schedules")
+	}
+	if _, err := analyzer.LoadLocation(d.Timezone, time.Local); err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+	names := make(map[string]bool)
+	for i, s := range d.Schedules {
+		if s.Name == "" {
+			return fmt.Errorf("daemon: schedule %d: missing name", i+1)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("daemon: schedule %s: duplicate name", s.Name)
+		}
+		names[s.Name] = true
+		if _, err := schedule.Parse(s.Cron); err != nil {
+			return fmt.Errorf("daemon: schedule %s: %w", s.Name, err)
+		}
+		if (s.File == "") == (s.Dir == "") {
+			return fmt.Errorf("daemon: schedule %s: exactly one of file or dir is required", s.Name)
+		}
+		if len(s.Outputs) == 0 && s.Email == nil && s.History == "" {
+			return fmt.Errorf("daemon: schedule %s: no outputs, email, or history, so results would go nowhere", s.Name)
+		}
+		for j, out := range s.Outputs {
+			if out.Format == "" || out.Path == "" || out.Path == "-" {
+				return fmt.Errorf("daemon: schedule %s: output %d needs a format and a file path", s.Name, j+1)
+			}
+			if _, err := redact.Lookup(out.Profile, cfg.RedactionProfiles); err != nil {
+				return fmt.Errorf("daemon: schedule %s: output %d: %w", s.Name, j+1, err)
+			}
+		}
+		if s.Email != nil {
+			if cfg.SMTP == nil {
+				return fmt.Errorf("daemon: schedule %s: email needs an smtp section", s.Name)
+			}
+			if len(s.Email.To) == 0 {
+				return fmt.Errorf("daemon: schedule %s: email has no recipients", s.Name)
+			}
+			switch s.Email.Format {
+			case "", "html", "pdf", "json", "csv":
+			default:
+				return fmt.Errorf("daemon: schedule %s: email format %q (use html, pdf, json, or csv)", s.Name, s.Email.Format)
+			}
+			if _, err := redact.Lookup(s.Email.Profile, cfg.RedactionProfiles); err != nil {
+				return fmt.Errorf("daemon: schedule %s: email: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Action is a response to the findings its rule selects, taken once a scan
+// has been analyzed. A finding goes to the first action whose rule it
+// matches; an empty rule matches everything.
+type Action struct {
+	Name        string   `json:"name"`
+	Services    []string `json:"services,omitempty"`     // service names, case-insensitive
+	Categories  []string `json:"categories,omitempty"`   // as for -category
+	MinSeverity string   `json:"min_severity,omitempty"` // low, medium, or high
+	Log         bool     `json:"log,omitempty"`          // log a warning with the counts
+	Webhook     *Webhook `json:"webhook,omitempty"`
+	Email       *Email   `json:"email,omitempty"` // skip_clean does not apply: actions only fire on findings
+}
+
+// Webhook posts an action's findings as a JSON report.
+type Webhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // e.g. an Authorization token
+	Profile string            `json:"profile,omitempty"` // redaction profile for the report
+}
+
+// Matcher returns a test for the findings that fall under the action's
+// rule.
+func (a Action) Matcher() (func(analyzer.Finding) bool, error) {
+	var min analyzer.Severity
+	if a.MinSeverity != "" {
+		var err error
+		if min, err = analyzer.ParseSeverity(a.MinSeverity); err != nil {
+			return nil, err
+		}
+	}
+	inCategory := func(analyzer.Finding) bool { return true }
+	if len(a.Categories) > 0 {
+		inCategory = analyzer.InCategories(a.Categories)
+	}
+	return func(f analyzer.Finding) bool {
+		if len(a.Services) > 0 && !slices.ContainsFunc(a.Services, func(s string) bool { return strings.EqualFold(s, f.ServiceName) }) {
+			return false
+		}
+		return inCategory(f) && f.Severity() >= min
+	}, nil
+}
+
+// validate checks an action against the rest of the configuration.
+func (a Action) validate(cfg *Config) error {
+	if _, err := a.Matcher(); err != nil {
+		return err
+	}
+	if !a.Log && a.Webhook == nil && a.Email == nil {
+		return fmt.Errorf("no log, webhook, or email, so it would do nothing")
+	}
+	if a.Webhook != nil {
+		u, err := url.Parse(a.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook url %q is not an http or https URL", a.Webhook.URL)
+		}
+		if _, err := redact.Lookup(a.Webhook.Profile, cfg.RedactionProfiles); err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+	}
+	if a.Email != nil {
+		if cfg.SMTP == nil {
+			return fmt.Errorf("email needs an smtp section")
+		}
+		if len(a.Email.To) == 0 {
+			return fmt.Errorf("email has no recipients")
+		}
+		switch a.Email.Format {
+		case "", "html", "pdf", "json", "csv":
+		default:
+			return fmt.Errorf("email format %q (use html, pdf, json, or csv)", a.Email.Format)
+		}
+		if _, err := redact.Lookup(a.Email.Profile, cfg.RedactionProfiles); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+	}
+	return nil
+}
+
+// Output is one report destination. A single scan can write several outputs,
+// each with its own format and redaction profile.
+type Output struct {
+	Format  string `json:"format"`
+	Path    string `json:"path"`              // empty or "-" for stdout
+	Profile string `json:"profile,omitempty"` // redaction profile name
+}
+
+// Load reads and validates a configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	for i, p := range cfg.Parsers {
+		if p.Name == "" {
+			return nil, fmt.Errorf("parser %d: missing name", i+1)
+		}
+		if _, err := p.Build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Multiline != nil {
+		if _, err := cfg.Multiline.Build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.URLRedaction != nil {
+		if _, err := cfg.URLRedaction.Build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Seal != nil {
+		if _, err := cfg.Seal.Build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.SMTP != nil {
+		if _, err := cfg.SMTP.Build(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Daemon != nil {
+		if err := cfg.Daemon.validate(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, a := range cfg.Actions {
+		if a.Name == "" {
+			return nil, fmt.Errorf("action %d: missing name", i+1)
+		}
+		if names[a.Name] {
+			return nil, fmt.Errorf("action %s: duplicate name", a.Name)
+		}
+		names[a.Name] = true
+		if err := a.validate(&cfg); err != nil {
+			return nil, fmt.Errorf("action %s: %w", a.Name, err)
+		}
+	}
+
+	for i, out := range cfg.Outputs {
+		if out.Format == "" {
+			return nil, fmt.Errorf("output %d: missing format", i+1)
+		}
+		if _, err := redact.Lookup(out.Profile, cfg.RedactionProfiles); err != nil {
+			return nil, fmt.Errorf("output %d: %w", i+1, err)
+		}
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/config"
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/kafka"
+	"github.com/shadow-ai-hunter/notify"
+	"github.com/shadow-ai-hunter/otlp"
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/policy"
+	"github.com/shadow-ai-hunter/redact"
+	"github.com/shadow-ai-hunter/reporter"
+	"github.com/shadow-ai-hunter/schedule"
+	"github.com/shadow-ai-hunter/seal"
+	"github.com/shadow-ai-hunter/sentinel"
+)
+
+// daemonPoll caps how long the daemon sleeps before checking the clock
+// again, so a clock change or a suspended host does not delay a run by
+// more than this.
+const daemonPoll = time.Minute
+
+// Mailed reports show at most this much in the message body; the
+// attachment has everything.
+const (
+	mailTop         = 10
+	mailMaxFindings = 50
+)
+
+// daemonOptions carries the settings shared by every scheduled scan.
+type daemonOptions struct {
+	cfg       config.Config
+	az        *analyzer.Analyzer
+	custom    []customParser
+	multiline *parsers.Multiline
+	policy    *policy.Policy
+	years     yearRule
+	warnRatio float64
+	workers   int
+	redact    string         // -redact, for OTLP, Kafka, and Sentinel
+	tenant    string         // -tenant, for schedules that set none
+	otlp      *otlp.Exporter // nil unless -otlp-endpoint is set
+	kafka     *kafka.Producer
+	sentinel  *sentinel.Ingestor
+	sealer    *seal.Sealer // nil writes plain report files
+	audit     *auditLog    // nil unless -audit-log is set
+}
+
+// scheduledScan is a schedule and when it next runs.
+type scheduledScan struct {
+	config.Schedule
+	cron *schedule.Schedule
+	next time.Time
+}
+
+// runDaemon runs the configured schedules one at a time until ctx is
+// cancelled. A lock file keeps a second daemon from scanning the same logs.
+func runDaemon(ctx context.Context, opts daemonOptions) int {
+	d := opts.cfg.Daemon
+	loc, err := analyzer.LoadLocation(d.Timezone, time.Local)
+	if err != nil {
+		logger.Error("Error in daemon settings", "err", err)
+		return exitError
+	}
+	stateDir := d.StateDir
+	if stateDir == "" {
+		stateDir = "."
+	}
+	lockPath := d.LockFile
+	if lockPath == "" {
+		lockPath = filepath.Join(stateDir, "shadow-hunter.lock")
+	}
+	lock, err := fsutil.AcquireLock(lockPath)
+	if errors.Is(err, fsutil.ErrLocked) {
+		logger.Error("Another daemon is already running", "lock", lockPath)
+		return exitError
+	}
+	if err != nil {
+		logger.Error("Error taking daemon lock", "err", err)
+		return exitError
+	}
+	defer lock.Unlock()
+
+	var mailer notify.Server
+	if opts.cfg.SMTP != nil {
+		if mailer, err = opts.cfg.SMTP.Build(); err != nil {
+			logger.Error("Error in SMTP settings", "err", err)
+			return exitError
+		}
+	}
+
+	now := time.Now().In(loc)
+	var scans []*scheduledScan
+	for _, s := range d.Schedules {
+		cron, err := schedule.Parse(s.Cron)
+		if err != nil {
+			logger.Error("Error in daemon settings", "err", err)
+			return exitError
+		}
+		sc := &scheduledScan{Schedule: s, cron: cron, next: cron.Next(now)}
+		if sc.next.IsZero() {
+			logger.Warn("Schedule never fires; ignoring it", "schedule", s.Name, "cron", s.Cron)
+			continue
+		}
+		logger.Info("Scheduled scan", "schedule", s.Name, "cron", s.Cron, "next", sc.next.Format(time.RFC3339))
+		scans = append(scans, sc)
+	}
+	if len(scans) == 0 {
+		logger.Error("No schedules to run")
+		return exitError
+	}
+	logSuccess(fmt.Sprintf("Daemon started with %d schedule(s)", len(scans)), "lock", lockPath)
+
+	for {
+		due := scans[0]
+		for _, sc := range scans[1:] {
+			if sc.next.Before(due.next) {
+				due = sc
+			}
+		}
+		if wait := time.Until(due.next); wait > 0 {
+			timer := time.NewTimer(min(wait, daemonPoll))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				logger.Info("Daemon stopping")
+				return exitClean
+			case <-timer.C:
+			}
+			continue
+		}
+
+		runScheduled(ctx, due.Schedule, stateDir, mailer, opts)
+		if ctx.Err() != nil {
+			logger.Info("Daemon stopping")
+			return exitClean
+		}
+		// Runs are never stacked: a schedule that came due while another
+		// ran goes next, and runs a scan overran are skipped
+		now := time.Now().In(loc)
+		if missed := due.cron.Next(due.next); !missed.IsZero() && missed.Before(now) {
+			logger.Warn("Scan took longer than its schedule's interval; skipping the runs it overlapped", "schedule", due.Name)
+		}
+		due.next = due.cron.Next(now)
+		logger.Info("Next scheduled scan", "schedule", due.Name, "next", due.next.Format(time.RFC3339))
+	}
+}
+
+// runScheduled performs one run of a schedule: scan what was added since the
+// last run, record it in history, and write and mail the reports. Read
+// offsets are saved only once the findings are in history, so a run that
+// fails before then is repeated in full by the next one.
+func runScheduled(ctx context.Context, s config.Schedule, stateDir string, mailer notify.Server, opts daemonOptions) {
+	started := time.Now()
+	logger.Info("Starting scheduled scan", "schedule", s.Name)
+
+	var files []string
+	if s.File != "" {
+		files = []string{s.File}
+	} else {
+		var err error
+		if files, err = collectFiles(s.Dir); err != nil {
+			logger.Error("Error reading directory", "schedule", s.Name, "err", err)
+			return
+		}
+	}
+
+	format := s.Format
+	if format == "" {
+		format = "auto"
+	}
+	progress := &progressBar{w: io.Discard}
+	scanner := &fileScanner{ctx: ctx, az: opts.az, format: format, custom: opts.custom, warnRatio: opts.warnRatio, years: opts.years, workers: opts.workers, progress: progress}
+	if !s.Full {
+		inc, err := loadIncremental(filepath.Join(stateDir, "daemon-"+stateName(s.Name)+".json"))
+		if err != nil {
+			logger.Error("Error loading read offsets", "schedule", s.Name, "err", err)
+			return
+		}
+		files = inc.withRotated(files)
+		scanner.incremental = inc
+	}
+	if len(files) == 0 {
+		logger.Warn("No log files found to scan", "schedule", s.Name)
+		return
+	}
+	progress.begin(files)
+	out := scanner.scanAll(files, opts.multiline)
+	if out.partial {
+		logger.Warn("Scheduled scan stopped early; reporting partial results", "schedule", s.Name)
+	}
+
+	summary := analyzer.Summarize(out.findings, out.logsScanned)
+	summary.Sources = out.sources
+	summary.Partial = out.partial
+	summary.Scan = out.meta(opts.az, started)
+	if s.Tenant != "" {
+		summary = summary.WithTenant(s.Tenant)
+	} else {
+		summary.Tenant = opts.tenant // the analyzer stamped it on the findings
+	}
+
+	if s.History != "" {
+		store, err := history.Open(s.History)
+		if err != nil {
+			logger.Error("Error opening history", "schedule", s.Name, "err", err)
+			return
+		}
+		prior, err := store.Findings()
+		if err == nil {
+			summary = analyzer.TagNewAdoption(summary, analyzer.NewBaseline(analyzer.OfTenant(prior, summary.Tenant)))
+			err = store.Append(time.Now().UTC(), summary.Findings)
+		}
+		if cerr := store.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			logger.Error("Error recording history", "schedule", s.Name, "err", err)
+			return
+		}
+	}
+	if scanner.incremental != nil {
+		if err := scanner.incremental.save(); err != nil {
+			logger.Error("Error saving read offsets", "schedule", s.Name, "err", err)
+		}
+	}
+
+	summary = opts.policy.Apply(summary)
+	if s.OnlyAllowed {
+		summary = summary.Filter(func(f analyzer.Finding) bool { return !f.Blocked })
+	}
+	if len(s.Categories) > 0 {
+		summary = summary.Filter(analyzer.InCategories(s.Categories))
+	}
+
+	var written []auditFile
+	for _, o := range s.Outputs {
+		profile, err := redact.Lookup(o.Profile, opts.cfg.RedactionProfiles)
+		if err != nil {
+			logger.Error(err.Error(), "schedule", s.Name)
+			continue
+		}
+		path := expandSchedulePath(o.Path, started)
+		redacted := profile.Apply(summary)
+		err = opts.sealer.WriteFile(path, func(w io.Writer) error {
+			return reporter.Report(redacted, reporter.Format(strings.ToLower(o.Format)), w)
+		})
+		if err != nil {
+			logger.Error("Error writing report", "schedule", s.Name, "err", err)
+			continue
+		}
+		logSuccess("Report written", "schedule", s.Name, "path", path)
+		if opts.audit != nil {
+			written = append(written, hashFile(path))
+		}
+	}
+
+	if opts.audit != nil {
+		rec := newAuditRecord(opts.audit, "scheduled_scan", opts.az, started)
+		rec.Schedule = s.Name
+		rec.Inputs = scannedFiles(out.files)
+		rec.Outputs = written
+		rec.FinishedAt = time.Now().UTC()
+		rec.LogsScanned, rec.Findings, rec.Partial = out.logsScanned, summary.TotalFindings, summary.Partial
+		if err := opts.audit.append(rec); err != nil {
+			logger.Error("Error writing audit log", "schedule", s.Name, "err", err)
+		}
+	}
+
+	if s.Email != nil {
+		if s.Email.SkipClean && summary.TotalFindings == 0 {
+			logger.Debug("clean scan; not mailing", "schedule", s.Name)
+		} else if err := mailReport(mailer, s.Name, *s.Email, summary, opts.cfg.RedactionProfiles, started); err != nil {
+			logger.Error("Error mailing report", "schedule", s.Name, "err", err)
+		} else {
+			logSuccess("Report mailed", "schedule", s.Name, "to", strings.Join(s.Email.To, ","))
+		}
+	}
+
+	if len(opts.cfg.Actions) > 0 {
+		actions, err := newActions(opts.cfg.Actions)
+		if err == nil {
+			err = runActions(context.Background(), actions, findingsOf(summary.Findings), summary, mailer, opts.cfg.RedactionProfiles, started)
+		}
+		if err != nil {
+			logger.Error("Error running actions", "schedule", s.Name, "err", err)
+		}
+	}
+
+	if opts.otlp != nil || opts.kafka != nil || opts.sentinel != nil {
+		profile, err := redact.Lookup(opts.redact, opts.cfg.RedactionProfiles)
+		if err != nil {
+			logger.Error(err.Error(), "schedule", s.Name)
+			return
+		}
+		findings := profile.Apply(summary).Findings
+		if opts.otlp != nil {
+			scan := otlp.Scan{
+				Start:    started.UTC(),
+				End:      time.Now().UTC(),
+				Files:    len(out.files),
+				Entries:  out.logsScanned,
+				Findings: len(summary.Findings),
+				Partial:  summary.Partial,
+				Tenant:   summary.Tenant,
+			}
+			if err := opts.otlp.ExportScan(context.Background(), scan, findings); err != nil {
+				logger.Error("Error exporting to OTLP", "schedule", s.Name, "err", err)
+			}
+		}
+		if opts.kafka != nil {
+			if err := publishFindings(context.Background(), opts.kafka, findings); err != nil {
+				logger.Error("Error publishing to Kafka", "schedule", s.Name, "err", err)
+			}
+		}
+		if opts.sentinel != nil {
+			if err := opts.sentinel.Send(context.Background(), findings); err != nil {
+				logger.Error("Error sending findings to Sentinel", "schedule", s.Name, "err", err)
+			}
+		}
+	}
+
+	logSuccess("Scheduled scan finished", "schedule", s.Name, "files", len(out.files), "entries", out.logsScanned,
+		"findings", summary.TotalFindings, "took", time.Since(started).Round(time.Millisecond))
+}
+
+// mailReport sends the summary as a plain-text message with the full
+// report attached. name is the schedule or action the mail is for.
+func mailReport(mailer notify.Server, name string, e config.Email, summary analyzer.Summary, profiles map[string]redact.Profile, started time.Time) error {
+	profile, err := redact.Lookup(e.Profile, profiles)
+	if err != nil {
+		return err
+	}
+	summary = profile.Apply(summary)
+
+	format := e.Format
+	if format == "" {
+		format = string(reporter.FormatHTML)
+	}
+	var attached bytes.Buffer
+	if err := reporter.Report(summary, reporter.Format(format), &attached); err != nil {
+		return err
+	}
+	brief := summary
+	brief.Layout.Top, brief.Layout.MaxFindings = mailTop, mailMaxFindings
+	var body bytes.Buffer
+	if err := reporter.Report(brief, reporter.FormatTable, &body); err != nil {
+		return err
+	}
+
+	date := started.Format("2006-01-02")
+	subject := e.Subject
+	if subject == "" {
+		subject = "Shadow AI report: {name}, {date} ({findings} findings)"
+	}
+	subject = strings.NewReplacer("{name}", name, "{date}", date, "{findings}", strconv.Itoa(summary.TotalFindings)).Replace(subject)
+
+	return mailer.Send(notify.Message{
+		To:      e.To,
+		Subject: subject,
+		Body:    body.String(),
+		Attachments: []notify.Attachment{{
+			Name:        fmt.Sprintf("shadow-ai-%s-%s.%s", stateName(name), date, format),
+			ContentType: reportContentType(format),
+			Data:        attached.Bytes(),
+		}},
+	})
+}
+
+func reportContentType(format string) string {
+	switch format {
+	case "pdf":
+		return "application/pdf"
+	case "json":
+		return "application/json"
+	case "csv":
+		return "text/csv; charset=utf-8"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// expandSchedulePath fills in the {date} and {time} of a run, so each run
+// can keep its own report.
+func expandSchedulePath(path string, t time.Time) string {
+	return strings.NewReplacer("{date}", t.Format("2006-01-02"), "{time}", t.Format("150405")).Replace(path)
+}
+
+// stateName makes a schedule name safe to use in a file name.
+func stateName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,21 @@
+// Package dashboard holds the web UI that serve mode shows at /. It is a
+// single static page; everything it displays comes from the serve API.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the dashboard's files, with index.html at the root.
+func Handler() http.Handler {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // the embedded tree always has static/
+	}
+	return http.FileServerFS(sub)
+}
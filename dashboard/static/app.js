@@ -0,0 +1,178 @@
+// Shadow AI Hunter dashboard. Reads the serve API and renders it; all text
+// from the API is set with textContent, never parsed as HTML.
+"use strict";
+
+const refreshMs = 10000;
+const topRows = 10;
+
+function el(tag, text, cls) {
+  const e = document.createElement(tag);
+  if (text !== undefined) e.textContent = text;
+  if (cls) e.className = cls;
+  return e;
+}
+
+async function getJSON(url) {
+  const res = await fetch(url);
+  if (!res.ok) {
+    const err = new Error(url + ": " + res.status);
+    err.status = res.status;
+    throw err;
+  }
+  return res.json();
+}
+
+function fmtTime(s) {
+  return s ? new Date(s).toLocaleString() : "";
+}
+
+function statsTable(table, rows) {
+  table.replaceChildren();
+  for (const [label, value] of rows) {
+    const tr = el("tr");
+    tr.append(el("td", label), el("td", String(value)));
+    table.append(tr);
+  }
+  table.hidden = false;
+}
+
+// bars draws a ranked list, from a map of name -> count or [{name, count}].
+function bars(container, data) {
+  let rows = Array.isArray(data) ? data : Object.entries(data || {}).map(([name, count]) => ({ name, count }));
+  rows = rows.sort((a, b) => b.count - a.count || a.name.localeCompare(b.name)).slice(0, topRows);
+  container.replaceChildren();
+  if (rows.length === 0) {
+    container.append(el("p", "None.", "note"));
+    return;
+  }
+  const max = rows[0].count;
+  for (const r of rows) {
+    const row = el("div", undefined, "row");
+    const name = el("span", r.name, "name");
+    name.title = r.name;
+    const bar = el("div", undefined, "bar");
+    bar.style.width = (100 * r.count / max) + "%";
+    const track = el("div");
+    track.append(bar);
+    row.append(name, track, el("span", String(r.count), "count"));
+    container.append(row);
+  }
+}
+
+async function loadJobs() {
+  const jobs = await getJSON("/jobs");
+  const body = document.querySelector("#jobs-table tbody");
+  body.replaceChildren();
+  for (const j of jobs) {
+    const tr = el("tr");
+    const target = j.request.file || j.request.dir || "";
+    const report = el("td");
+    if (j.findings !== undefined) {
+      const a = el("a", "HTML");
+      a.href = "/jobs/" + encodeURIComponent(j.id) + "/result?output=html";
+      report.append(a);
+    }
+    tr.append(el("td", fmtTime(j.created_at)), el("td", j.status), el("td", target),
+      el("td", j.findings === undefined ? "" : String(j.findings)), report);
+    body.append(tr);
+  }
+  if (jobs.length === 0) {
+    const tr = el("tr");
+    const td = el("td", "No scan jobs yet. Submit one with POST /jobs.", "note");
+    td.colSpan = 5;
+    tr.append(td);
+    body.append(tr);
+  }
+  return jobs;
+}
+
+async function loadLatest(jobs) {
+  const note = document.getElementById("latest-note");
+  const job = jobs.find(j => j.findings !== undefined);
+  if (!job) {
+    note.textContent = "No finished scan yet.";
+    return;
+  }
+  const s = await getJSON("/jobs/" + encodeURIComponent(job.id) + "/result?output=json");
+  note.textContent = (job.request.file || job.request.dir) + ", finished " + fmtTime(job.finished_at) +
+    (job.status === "cancelled" ? " (partial results)" : "");
+  statsTable(document.getElementById("latest-stats"), [
+    ["Logs scanned", s.total_logs_scanned],
+    ["AI hits found", s.total_findings + " (" + s.allowed_findings + " allowed, " + s.blocked_findings + " blocked)"],
+    ["Unique users", s.unique_users],
+    ["Unique services", s.unique_services],
+  ]);
+  bars(document.getElementById("latest-users"), s.hits_by_user);
+  bars(document.getElementById("latest-services"), s.hits_by_service);
+}
+
+async function loadHistory() {
+  let h;
+  try {
+    h = await getJSON("/history/summary");
+  } catch (err) {
+    if (err.status === 404) return; // serve was started without -history
+    throw err;
+  }
+  document.getElementById("history").hidden = false;
+  statsTable(document.getElementById("history-stats"), [
+    ["Stored findings", h.findings],
+    ["Scans", h.scans],
+    ["First seen", fmtTime(h.first_seen)],
+    ["Last seen", fmtTime(h.last_seen)],
+    ["Last scan", fmtTime(h.last_scan)],
+  ]);
+  bars(document.getElementById("history-users"), h.top_users);
+  bars(document.getElementById("history-services"), h.top_services);
+
+  const days = document.getElementById("history-days");
+  days.replaceChildren();
+  const max = Math.max(1, ...h.by_day.map(d => d.count));
+  for (const d of h.by_day) {
+    const col = el("div");
+    col.style.height = (100 * d.count / max) + "%";
+    col.title = d.name + ": " + d.count;
+    days.append(col);
+  }
+}
+
+async function search(q) {
+  const note = document.getElementById("search-note");
+  const table = document.getElementById("search-results");
+  const res = await getJSON("/history/findings?q=" + encodeURIComponent(q));
+  note.textContent = res.matched === res.records.length
+    ? res.matched + " finding(s)"
+    : res.matched + " finding(s); showing the newest " + res.records.length;
+  const body = table.querySelector("tbody");
+  body.replaceChildren();
+  for (const r of res.records) {
+    const f = r.finding;
+    const tr = el("tr", undefined, f.blocked ? "blocked" : "");
+    const url = el("td", f.url || "", "url");
+    url.title = f.url || "";
+    tr.append(el("td", fmtTime(f.timestamp || r.scanned_at)), el("td", f.user || f.source_ip),
+      el("td", f.service_name), el("td", f.domain), url, el("td", f.blocked ? "blocked" : "allowed"));
+    body.append(tr);
+  }
+  table.hidden = res.records.length === 0;
+}
+
+async function refresh() {
+  try {
+    const jobs = await loadJobs();
+    await loadLatest(jobs);
+    await loadHistory();
+  } catch (err) {
+    document.getElementById("latest-note").textContent = "Error loading data: " + err.message;
+  }
+}
+
+document.getElementById("search").addEventListener("submit", e => {
+  e.preventDefault();
+  search(e.target.elements.q.value).catch(err => {
+    document.getElementById("search-note").textContent = "Search failed: " + err.message;
+  });
+});
+
+refresh();
+setInterval(refresh, refreshMs);
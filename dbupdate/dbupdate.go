@@ -0,0 +1,193 @@
+// Package dbupdate fetches newer AI services databases over HTTPS.
+package dbupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// DefaultURL is where the maintained database is published.
+const DefaultURL = "https://raw.githubusercontent.com/TerminalsandCoffee/shadow-ai-hunter/main/ai_services.json"
+
+// maxSize bounds how much is read from the server.
+const maxSize = 16 << 20
+
+// Options configure an update.
+type Options struct {
+	URL       string
+	Dest      string            // where the database is cached
+	PublicKey ed25519.PublicKey // when set, URL+".sig" must hold a valid signature
+	Client    *http.Client
+}
+
+// Result describes the outcome of an update.
+type Result struct {
+	Updated  bool // false when the server reported no change
+	Services int
+	Info     analyzer.DBInfo // version and date the new database declares
+	ETag     string
+}
+
+// CachePath returns the default location of the downloaded database.
+func CachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "shadow-hunter", "ai_services.json"), nil
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("decoding public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Update downloads the database if it changed since the cached copy. The
+// cached ETag is sent as If-None-Match, the body is validated (and its
+// signature checked when a key is given), and only then is the cache
+// replaced atomically.
+func Update(ctx context.Context, opts Options) (Result, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return Result{}, fmt.Errorf("bad URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return Result{}, fmt.Errorf("refusing non-HTTPS URL %s", opts.URL)
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	etagPath := opts.Dest + ".etag"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := os.Stat(opts.Dest); err == nil {
+		if etag, err := os.ReadFile(etagPath); err == nil {
+			req.Header.Set("If-None-Match", strings.TrimSpace(string(etag)))
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("fetching database: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return Result{ETag: req.Header.Get("If-None-Match")}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("fetching database: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return Result{}, fmt.Errorf("reading database: %w", err)
+	}
+	if len(data) > maxSize {
+		return Result{}, fmt.Errorf("database larger than %d bytes", maxSize)
+	}
+
+	if opts.PublicKey != nil {
+		sig, err := fetch(ctx, client, opts.URL+".sig")
+		if err != nil {
+			return Result{}, fmt.Errorf("fetching signature: %w", err)
+		}
+		if err := verify(opts.PublicKey, data, sig); err != nil {
+			return Result{}, err
+		}
+	}
+
+	info, services, err := analyzer.ParseDatabase("", data)
+	if err != nil {
+		return Result{}, fmt.Errorf("downloaded database is invalid: %w", err)
+	}
+
+	if err := writeAtomic(opts.Dest, data); err != nil {
+		return Result{}, err
+	}
+	etag := resp.Header.Get("ETag")
+	if etag != "" {
+		if err := os.WriteFile(etagPath, []byte(etag+"\n"), 0o644); err != nil {
+			return Result{}, err
+		}
+	} else {
+		os.Remove(etagPath)
+	}
+	return Result{Updated: true, Services: len(services), Info: info, ETag: etag}, nil
+}
+
+// verify checks a detached ed25519 signature, raw or base64-encoded.
+func verify(key ed25519.PublicKey, data, sig []byte) error {
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("decoding signature: %w", err)
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+func fetch(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4096))
+}
+
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("writing database: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ai_services-*")
+	if err != nil {
+		return fmt.Errorf("writing database: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing database: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing database: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Exit codes let automation tell a clean scan from one with findings.
+const (
+	exitClean    = 0 // nothing reached the -fail-on threshold
+	exitError    = 1 // usage or runtime error
+	exitFindings = 2 // findings reached the -fail-on threshold
+)
+
+// failOn decides when findings turn into a non-zero exit.
+type failOn struct {
+	never    bool
+	severity analyzer.Severity // minimum severity that counts
+	count    int               // how many counted findings trigger exit 2
+}
+
+// parseFailOn accepts "any" (the default), "never", a severity (low, medium,
+// high), or a minimum number of findings.
+func parseFailOn(s string) (failOn, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "any":
+		return failOn{severity: analyzer.SeverityLow, count: 1}, nil
+	case "never", "none":
+		return failOn{never: true}, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 {
+			return failOn{}, fmt.Errorf("-fail-on count must be at least 1")
+		}
+		return failOn{severity: analyzer.SeverityLow, count: n}, nil
+	}
+	sev, err := analyzer.ParseSeverity(s)
+	if err != nil {
+		return failOn{}, fmt.Errorf("-fail-on: %w", err)
+	}
+	return failOn{severity: sev, count: 1}, nil
+}
+
+// counts reports whether a finding counts toward the threshold. DoH
+// lookups are not AI use and never do.
+func (c failOn) counts(f analyzer.Finding) bool {
+	return !c.never && !f.IsDoH() && f.Severity() >= c.severity
+}
+
+// reached reports whether n counted findings trigger exit 2.
+func (c failOn) reached(n int) bool {
+	return !c.never && n >= c.count
+}
+
+// exitCode returns the exit status for a finished scan.
+func (c failOn) exitCode(findings []analyzer.Finding) int {
+	n := 0
+	for _, f := range findings {
+		if c.counts(f) {
+			n++
+		}
+	}
+	return c.exitCodeFor(n)
+}
+
+// exitCodeFor returns the exit status for a scan with n counted findings.
+func (c failOn) exitCodeFor(n int) int {
+	if c.reached(n) {
+		return exitFindings
+	}
+	return exitClean
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/follow"
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/kafka"
+	"github.com/shadow-ai-hunter/otlp"
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/policy"
+	"github.com/shadow-ai-hunter/reporter"
+	"github.com/shadow-ai-hunter/sentinel"
+	"github.com/shadow-ai-hunter/state"
+)
+
+// followOptions carries the scan settings that apply to follow mode.
+type followOptions struct {
+	format      string
+	outputFmt   string
+	statePath   string
+	onlyAllowed bool
+	policy      *policy.Policy
+	history     *history.Store
+	baseline    *analyzer.Baseline
+	custom      []customParser
+	categories  []string
+	multiline   *parsers.Multiline
+	fail        failOn
+	years       yearRule
+	metrics     *scanMetrics   // nil unless -metrics-listen is set
+	otlp        *otlp.Exporter // nil unless -otlp-endpoint is set
+	kafka       *kafka.Producer
+	sentinel    *sentinel.Ingestor
+	listeners   []logListener // network sources, alongside the files
+	units       []string      // systemd units read from the journal
+	journal     *parsers.JournalParser
+}
+
+// followFiles tails the files and reports findings as they are written,
+// until ctx is cancelled. It returns the process exit code.
+func followFiles(ctx context.Context, files []string, az *analyzer.Analyzer, opts followOptions) int {
+	lineParsers := make(map[string]parsers.LineParser)
+	framers := make(map[string]*parsers.Framer)
+	latin1 := make(map[string]bool)
+	for _, f := range files {
+		switch enc, _ := fsutil.DetectFileEncoding(f); enc {
+		case fsutil.UTF16LE, fsutil.UTF16BE:
+			logger.Error(fmt.Sprintf("Follow mode cannot tail %s files; scan them without -follow", enc), "file", f)
+			return exitError
+		case fsutil.Latin1:
+			latin1[f] = true
+		}
+		p, ok := withMultiline(selectParser(opts.format, f, opts.custom), opts.multiline).(parsers.LineParser)
+		if !ok {
+			logger.Error("Follow mode needs a line-oriented format (squid, dns, regex)", "file", f)
+			return exitError
+		}
+		if mp, framed := p.(*parsers.MultilineParser); framed {
+			framers[f] = parsers.NewFramer(mp.Multiline)
+		}
+		lineParsers[f] = p
+	}
+
+	listenParsers := make([]parsers.LineParser, len(opts.listeners))
+	for i, l := range opts.listeners {
+		p, ok := selectParser(opts.format, "", opts.custom).(parsers.LineParser)
+		if !ok {
+			logger.Error("-listen needs a line-oriented format (squid, dns, regex)", "listen", l.String())
+			return exitError
+		}
+		listenParsers[i] = p
+	}
+
+	var st *state.Store
+	if opts.statePath != "" {
+		var err error
+		st, err = state.Load(opts.statePath)
+		if err != nil {
+			logger.Error("Error loading state", "err", err)
+			return exitError
+		}
+	}
+
+	inCategory := func(analyzer.Finding) bool { return true }
+	if len(opts.categories) > 0 {
+		inCategory = analyzer.InCategories(opts.categories)
+	}
+
+	stream, err := reporter.NewStream(reporter.Format(strings.ToLower(opts.outputFmt)), os.Stdout)
+	if err != nil {
+		logger.Error(err.Error())
+		return exitError
+	}
+
+	logger.Info("Following files, press Ctrl-C to stop", "files", len(files), "listeners", len(opts.listeners), "units", len(opts.units))
+	detections, failing := 0, 0
+	var mu sync.Mutex // files, listeners, and journals deliver records concurrently
+	handleEntry := func(path string, entry parsers.LogEntry, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if errors.Is(err, parsers.ErrSkip) {
+			return
+		}
+		if err != nil {
+			if opts.metrics != nil {
+				opts.metrics.parseErrors.Inc()
+			}
+			return
+		}
+		if opts.metrics != nil {
+			opts.metrics.entries.Inc()
+		}
+		entry.SourceFile = path
+		opts.years.apply(&entry, time.Now()) // a followed file is being written now
+		finding, ok := az.Match(entry)
+		if !ok || finding.IsDoH() || opts.policy.Allows(finding) || (opts.onlyAllowed && finding.Blocked) || !inCategory(finding) {
+			return
+		}
+
+		if !opts.baseline.Empty() && !opts.baseline.Known(finding.Identity(), finding.ServiceName) {
+			finding.NewAdoption = true
+			opts.baseline.Add(finding.Identity(), finding.ServiceName)
+		}
+
+		detections++
+		if opts.metrics != nil {
+			opts.metrics.observeFinding(finding)
+		}
+		if opts.fail.counts(finding) {
+			failing++
+		}
+		if err := stream.Write(finding); err != nil {
+			logger.Error("Error writing finding", "err", err)
+		}
+		if opts.otlp != nil {
+			if err := opts.otlp.ExportFindings(ctx, otlp.Trace{}, []analyzer.Finding{finding}); err != nil {
+				logger.Error("Error exporting to OTLP", "err", err)
+			}
+		}
+		if opts.kafka != nil {
+			if err := publishFindings(ctx, opts.kafka, []analyzer.Finding{finding}); err != nil {
+				logger.Error("Error publishing to Kafka", "err", err)
+			}
+		}
+		if opts.sentinel != nil {
+			if err := opts.sentinel.Send(ctx, []analyzer.Finding{finding}); err != nil {
+				logger.Error("Error sending finding to Sentinel", "err", err)
+			}
+		}
+		if opts.history != nil {
+			if err := opts.history.Append(time.Now().UTC(), []analyzer.Finding{finding}); err != nil {
+				logger.Error("Error recording history", "err", err)
+			}
+		}
+	}
+
+	handleWith := func(p parsers.LineParser, path, record string) {
+		entry, err := p.ParseLine(record)
+		handleEntry(path, entry, err)
+	}
+	handle := func(path, record string) {
+		handleWith(lineParsers[path], path, record)
+	}
+
+	// Listeners and journal readers stop with the follower, including when
+	// it fails.
+	listenCtx, stopListening := context.WithCancel(ctx)
+	defer stopListening()
+	var listening sync.WaitGroup
+	for i, l := range opts.listeners {
+		p, source := listenParsers[i], l.String()
+		run, err := l.bind(listenCtx, func(line string) {
+			line = parsers.CleanLine(line)
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+				handleWith(p, source, line)
+			}
+		})
+		if err != nil {
+			logger.Error("Error listening", "listen", source, "err", err)
+			return exitError
+		}
+		logger.Info("Receiving logs", "listen", source)
+		listening.Add(1)
+		go func() {
+			defer listening.Done()
+			if err := run(); err != nil {
+				logger.Error("Error receiving logs", "listen", source, "err", err)
+			}
+		}()
+	}
+
+	for _, unit := range opts.units {
+		source := journalSource(unit)
+		logger.Info("Following journal", "unit", unit)
+		listening.Add(1)
+		go func() {
+			defer listening.Done()
+			err := parsers.ReadJournalUnit(listenCtx, unit, true, func(rec parsers.JournalRecord) {
+				entry, err := opts.journal.Entry(rec)
+				handleEntry(source, entry, err)
+			})
+			if err != nil && listenCtx.Err() == nil {
+				logger.Error("Error following journal", "unit", unit, "err", err)
+			}
+		}()
+	}
+
+	follower := follow.New(files, st)
+	follower.Idle = func() {
+		now := time.Now()
+		for path, fr := range framers {
+			if fr.Expired(now) {
+				if record, ok := fr.Flush(); ok {
+					handle(path, record)
+				}
+			}
+		}
+	}
+	err = follower.Run(ctx, func(path, line string) {
+		line = parsers.CleanLine(line)
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			return
+		}
+		if latin1[path] {
+			line = fsutil.DecodeLatin1(line)
+		}
+		if fr := framers[path]; fr != nil {
+			if record, ok := fr.Push(line, time.Now()); ok {
+				handle(path, record)
+			}
+			return
+		}
+		handle(path, line)
+	})
+	for path, fr := range framers {
+		if record, ok := fr.Flush(); ok {
+			handle(path, record)
+		}
+	}
+	stopListening()
+	listening.Wait()
+	if err != nil {
+		logger.Error(err.Error())
+		return exitError
+	}
+
+	logger.Info("Stopped following", "detections", detections)
+	if opts.fail.reached(failing) {
+		return exitFindings
+	}
+	return exitClean
+}
@@ -0,0 +1,322 @@
+package follow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/state"
+)
+
+// maxPartial bounds how much of an unterminated line is buffered.
+const maxPartial = 1024 * 1024
+
+// headLen is how many leading bytes are fingerprinted to detect a file that
+// was truncated and rewritten past its previous size between polls.
+const headLen = 256
+
+// LineFunc receives each complete line read from a followed file.
+type LineFunc func(path, line string)
+
+// Follower tails a set of log files, surviving both rename-based and
+// copytruncate rotation. Offsets are recorded in the optional state store so
+// a restart resumes exactly where the previous run stopped.
+type Follower struct {
+	Interval time.Duration
+	State    *state.Store
+	Idle     func() // called after every poll cycle, e.g. to flush timed-out records
+
+	tailers []*tailer
+}
+
+// tailer tracks one followed path and the file currently open for it.
+type tailer struct {
+	path    string
+	file    *os.File
+	id      fsutil.FileID
+	offset  int64 // bytes of complete lines consumed from file
+	partial []byte
+	fp      string // checksum of the first fpLen bytes
+	fpLen   int64
+}
+
+// New returns a follower for the given paths. st may be nil.
+func New(paths []string, st *state.Store) *Follower {
+	f := &Follower{Interval: time.Second, State: st}
+	for _, p := range paths {
+		f.tailers = append(f.tailers, &tailer{path: p})
+	}
+	return f
+}
+
+// Run polls the files until ctx is cancelled, calling handle for every new
+// line. State is saved after each poll cycle that consumed data.
+func (f *Follower) Run(ctx context.Context, handle LineFunc) error {
+	defer f.close()
+
+	ticker := time.NewTicker(f.Interval)
+	defer ticker.Stop()
+
+	for {
+		changed := false
+		for _, t := range f.tailers {
+			before := t.offset
+			beforeID := t.id
+			if err := f.poll(t, handle); err != nil {
+				return fmt.Errorf("following %s: %w", t.path, err)
+			}
+			if t.offset != before || t.id != beforeID {
+				changed = true
+				if f.State != nil {
+					f.State.Set(t.path, state.FileState{ID: t.id, Offset: t.offset, Fingerprint: t.fp})
+				}
+			}
+		}
+		if changed && f.State != nil {
+			if err := f.State.Save(); err != nil {
+				return err
+			}
+		}
+		if f.Idle != nil {
+			f.Idle()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *Follower) close() {
+	for _, t := range f.tailers {
+		if t.file != nil {
+			t.file.Close()
+		}
+	}
+}
+
+// poll reads new data from one file and handles any rotation since the last poll.
+func (f *Follower) poll(t *tailer, handle LineFunc) error {
+	if t.file == nil {
+		if err := f.open(t, handle); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil // not created yet; try again next poll
+			}
+			return err
+		}
+	}
+
+	// Copytruncate rotation: the file shrank, or its head was rewritten.
+	truncated, err := t.truncated()
+	if err != nil {
+		return err
+	}
+	if truncated {
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.offset, t.partial, t.fp, t.fpLen = 0, nil, "", 0
+	}
+
+	if err := t.drain(handle); err != nil {
+		return err
+	}
+
+	pathInfo, err := fsutil.Stat(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t.updateFingerprint() // renamed away and not yet recreated; keep the old handle
+	}
+	if err != nil {
+		return err
+	}
+	openInfo, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(openInfo, pathInfo) {
+		// Rename rotation: the old file has been drained to EOF above, so
+		// finish its last unterminated line and switch to the new file.
+		t.flushPartial(handle)
+		t.file.Close()
+		t.file = nil
+		if err := t.openAt(0); err != nil {
+			return err
+		}
+		if err := t.drain(handle); err != nil {
+			return err
+		}
+	}
+	return t.updateFingerprint()
+}
+
+// truncated reports whether the open file was truncated since it was last read.
+func (t *tailer) truncated() (bool, error) {
+	info, err := t.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < t.offset {
+		return true, nil
+	}
+	if t.fpLen == 0 {
+		return false, nil
+	}
+	sum, err := t.headSum(t.fpLen)
+	if err != nil {
+		return false, err
+	}
+	return sum != t.fp, nil
+}
+
+// updateFingerprint extends the head checksum as more of the file is read.
+func (t *tailer) updateFingerprint() error {
+	n := min(t.offset, headLen)
+	if n <= t.fpLen {
+		return nil
+	}
+	sum, err := t.headSum(n)
+	if err != nil {
+		return err
+	}
+	t.fp, t.fpLen = sum, n
+	return nil
+}
+
+func (t *tailer) headSum(n int64) (string, error) {
+	buf := make([]byte, n)
+	if _, err := t.file.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// open opens the file for the first time, resuming from the saved offset. If
+// the file was rotated while we were not running, the rotated file is located
+// by identity and its unread tail is consumed first.
+func (f *Follower) open(t *tailer, handle LineFunc) error {
+	if err := t.openAt(0); err != nil {
+		return err
+	}
+	if f.State == nil {
+		return nil
+	}
+	saved, ok := f.State.Get(t.path)
+	if !ok {
+		return nil
+	}
+
+	if saved.ID == t.id {
+		info, err := t.file.Stat()
+		if err != nil {
+			return err
+		}
+		if saved.Offset > info.Size() {
+			return nil // truncated while stopped; start over
+		}
+		n := min(saved.Offset, headLen)
+		if saved.Fingerprint != "" {
+			if sum, err := t.headSum(n); err != nil || sum != saved.Fingerprint {
+				return err // rewritten while stopped; start over
+			}
+		}
+		if _, err := t.file.Seek(saved.Offset, io.SeekStart); err != nil {
+			return err
+		}
+		t.offset, t.fp, t.fpLen = saved.Offset, saved.Fingerprint, n
+		if t.fp == "" {
+			t.fpLen = 0
+		}
+		return nil
+	}
+
+	if rotated := fsutil.FindByID(t.path, saved.ID); rotated != "" {
+		old := &tailer{path: t.path}
+		if err := old.openFile(rotated, saved.Offset); err == nil {
+			err = old.drain(handle)
+			old.flushPartial(handle)
+			old.file.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *tailer) openAt(offset int64) error {
+	return t.openFile(t.path, offset)
+}
+
+func (t *tailer) openFile(name string, offset int64) error {
+	file, err := fsutil.Open(name)
+	if err != nil {
+		return err
+	}
+	id, err := fsutil.Identify(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if offset > 0 {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	t.file, t.id, t.offset, t.partial = file, id, offset, nil
+	t.fp, t.fpLen = "", 0
+	return nil
+}
+
+// drain reads to EOF, handing complete lines to handle.
+func (t *tailer) drain(handle LineFunc) error {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := t.file.Read(buf)
+		if n > 0 {
+			data := append(t.partial, buf[:n]...)
+			for {
+				i := bytes.IndexByte(data, '\n')
+				if i < 0 {
+					break
+				}
+				t.offset += int64(i + 1)
+				handle(t.path, strings.TrimRight(string(data[:i]), "\r"))
+				data = data[i+1:]
+			}
+			t.partial = append([]byte(nil), data...)
+			if len(t.partial) > maxPartial {
+				t.flushPartial(handle)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// flushPartial emits a buffered unterminated line, used once a file is known
+// to receive no more writes.
+func (t *tailer) flushPartial(handle LineFunc) {
+	if len(t.partial) == 0 {
+		return
+	}
+	t.offset += int64(len(t.partial))
+	handle(t.path, strings.TrimRight(string(t.partial), "\r"))
+	t.partial = nil
+}
@@ -0,0 +1,200 @@
+package fsutil
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a text encoding detected in a log file.
+type Encoding string
+
+const (
+	UTF8    Encoding = "UTF-8"
+	UTF16LE Encoding = "UTF-16LE"
+	UTF16BE Encoding = "UTF-16BE"
+	Latin1  Encoding = "ISO-8859-1"
+)
+
+// sniffLen is how much of a file is inspected to detect its encoding.
+const sniffLen = 4096
+
+// DetectEncoding guesses the encoding of a file from its first bytes. A
+// UTF-16 byte-order mark is trusted; otherwise mostly-ASCII text with NULs in
+// every other byte is taken as BOM-less UTF-16, and anything that is not
+// valid UTF-8 is assumed to be Latin-1.
+func DetectEncoding(sample []byte) Encoding {
+	switch {
+	case len(sample) >= 2 && sample[0] == 0xFF && sample[1] == 0xFE:
+		return UTF16LE
+	case len(sample) >= 2 && sample[0] == 0xFE && sample[1] == 0xFF:
+		return UTF16BE
+	}
+
+	var evenNUL, oddNUL int
+	for i, b := range sample {
+		if b == 0 {
+			if i%2 == 0 {
+				evenNUL++
+			} else {
+				oddNUL++
+			}
+		}
+	}
+	pairs := len(sample) / 2
+	if pairs > 0 {
+		if oddNUL*10 >= pairs*3 && evenNUL*10 < pairs {
+			return UTF16LE
+		}
+		if evenNUL*10 >= pairs*3 && oddNUL*10 < pairs {
+			return UTF16BE
+		}
+	}
+
+	// Ignore a multi-byte sequence cut off at the end of the sample
+	if len(sample) == sniffLen {
+		cut := len(sample)
+		for i := 0; i < utf8.UTFMax-1 && cut > 0 && !utf8.RuneStart(sample[cut-1]); i++ {
+			cut--
+		}
+		if cut > 0 && sample[cut-1] >= utf8.RuneSelf {
+			cut--
+		}
+		sample = sample[:cut]
+	}
+	if utf8.Valid(sample) {
+		return UTF8
+	}
+	return Latin1
+}
+
+// OpenText opens a log file and returns a reader that yields UTF-8,
+// transcoding UTF-16 and Latin-1 input. The detected encoding is returned
+// so callers can report it.
+func OpenText(path string) (io.ReadCloser, Encoding, error) {
+	file, err := Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	br := bufio.NewReaderSize(file, sniffLen)
+	sample, _ := br.Peek(sniffLen)
+	enc := DetectEncoding(sample)
+
+	var r io.Reader = br
+	switch enc {
+	case UTF8:
+		if len(sample) >= 3 && sample[0] == 0xEF && sample[1] == 0xBB && sample[2] == 0xBF {
+			br.Discard(3)
+		}
+	case UTF16LE, UTF16BE:
+		if len(sample) >= 2 && (sample[0] == 0xFF && sample[1] == 0xFE || sample[0] == 0xFE && sample[1] == 0xFF) {
+			br.Discard(2)
+		}
+		r = &decoder{src: br, next: utf16Decoder(enc == UTF16BE)}
+	case Latin1:
+		r = &decoder{src: br, next: func(src *bufio.Reader) (rune, error) {
+			b, err := src.ReadByte()
+			return rune(b), err
+		}}
+	}
+	return readCloser{r, file}, enc, nil
+}
+
+// DetectFileEncoding reports the encoding OpenText would use for a file.
+func DetectFileEncoding(path string) (Encoding, error) {
+	file, err := Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	sample := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return DetectEncoding(sample[:n]), nil
+}
+
+// DecodeLatin1 converts a Latin-1 string to UTF-8.
+func DecodeLatin1(s string) string {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return string(runes)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// decoder converts a stream of runes produced by next into UTF-8.
+type decoder struct {
+	src     *bufio.Reader
+	next    func(*bufio.Reader) (rune, error)
+	pending []byte
+	err     error
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			c := copy(p[n:], d.pending)
+			d.pending = d.pending[c:]
+			n += c
+			continue
+		}
+		if d.err != nil {
+			break
+		}
+		r, err := d.next(d.src)
+		if err != nil {
+			d.err = err
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		d.pending = buf[:utf8.EncodeRune(buf[:], r)]
+	}
+	if n == 0 && d.err != nil {
+		return 0, d.err
+	}
+	return n, nil
+}
+
+// utf16Decoder returns a rune reader for UTF-16 in the given byte order,
+// combining surrogate pairs.
+func utf16Decoder(bigEndian bool) func(*bufio.Reader) (rune, error) {
+	unit := func(src *bufio.Reader) (uint16, error) {
+		var b [2]byte
+		if _, err := io.ReadFull(src, b[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF // drop a dangling odd byte
+			}
+			return 0, err
+		}
+		if bigEndian {
+			return uint16(b[0])<<8 | uint16(b[1]), nil
+		}
+		return uint16(b[1])<<8 | uint16(b[0]), nil
+	}
+
+	return func(src *bufio.Reader) (rune, error) {
+		u, err := unit(src)
+		if err != nil {
+			return 0, err
+		}
+		if !utf16.IsSurrogate(rune(u)) {
+			return rune(u), nil
+		}
+		low, err := unit(src)
+		if err != nil {
+			return utf8.RuneError, nil
+		}
+		return utf16.DecodeRune(rune(u), rune(low)), nil
+	}
+}
@@ -0,0 +1,54 @@
+package fsutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// FileID identifies a file independent of its name, so renames and
+// replacements can be told apart from appends.
+type FileID struct {
+	Dev   uint64 `json:"dev"`
+	Inode uint64 `json:"inode"`
+}
+
+// Zero reports whether the ID is unknown (unsupported platform).
+func (id FileID) Zero() bool {
+	return id == FileID{}
+}
+
+// IdentifyPath returns the identity of the file at path.
+func IdentifyPath(path string) (FileID, error) {
+	f, err := Open(path)
+	if err != nil {
+		return FileID{}, err
+	}
+	defer f.Close()
+	return Identify(f)
+}
+
+// FindByID looks next to path for a file with the given identity, which is
+// where rename-based rotation leaves the previous generation (access.log.1).
+func FindByID(path string, id FileID) string {
+	if id.Zero() {
+		return ""
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), base) {
+			continue
+		}
+		candidate := filepath.Join(dir, e.Name())
+		if cid, err := IdentifyPath(candidate); err == nil && cid == id {
+			return candidate
+		}
+	}
+	return ""
+}
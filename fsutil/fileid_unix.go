@@ -0,0 +1,21 @@
+//go:build unix
+
+package fsutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// Identify returns the device and inode of an open file.
+func Identify(f *os.File) (FileID, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return FileID{}, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileID{}, nil
+	}
+	return FileID{Dev: uint64(st.Dev), Inode: uint64(st.Ino)}, nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// Identify returns the volume serial number and file index of an open file.
+func Identify(f *os.File) (FileID, error) {
+	var info syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(f.Fd()), &info); err != nil {
+		return FileID{}, err
+	}
+	return FileID{
+		Dev:   uint64(info.VolumeSerialNumber),
+		Inode: uint64(info.FileIndexHigh)<<32 | uint64(info.FileIndexLow),
+	}, nil
+}
@@ -0,0 +1,40 @@
+// Package fsutil holds file-system helpers shared by the scanner and parsers.
+package fsutil
+
+import (
+	"os"
+	"sort"
+)
+
+// Open opens a log file for reading, converting the path to a form the
+// platform can handle (extended-length and UNC paths on Windows). Under
+// Restrict, only files in the allowed directories can be opened.
+func Open(path string) (*os.File, error) {
+	root, rel, ok, err := sandboxed("open", path)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return root.Open(rel)
+	}
+	return os.Open(LongPath(path))
+}
+
+// ReadDir lists a directory, accepting the same paths as Open.
+func ReadDir(dir string) ([]os.DirEntry, error) {
+	root, rel, ok, err := sandboxed("readdir", dir)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return os.ReadDir(LongPath(dir))
+	}
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(-1)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, err
+}
@@ -0,0 +1,15 @@
+package fsutil
+
+import "errors"
+
+// ErrLocked is returned by AcquireLock when another process holds the lock.
+var ErrLocked = errors.New("locked by another process")
+
+// Lock is an exclusive lock on a file, held until Unlock or process exit.
+type Lock struct {
+	path string
+	lockHandle
+}
+
+// Path returns the lock file's path.
+func (l *Lock) Path() string { return l.path }
@@ -0,0 +1,33 @@
+//go:build !unix
+
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type lockHandle struct{ f *os.File }
+
+// AcquireLock creates path exclusively and writes the process ID into it.
+// Without advisory locks, an existing file means the lock is held; a
+// process that crashed leaves it behind, to be removed by hand.
+func AcquireLock(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%s: %w (delete it if no other instance is running)", path, ErrLocked)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	return &Lock{path: path, lockHandle: lockHandle{f}}, nil
+}
+
+// Unlock releases the lock by removing the file.
+func (l *Lock) Unlock() error {
+	l.f.Close()
+	return os.Remove(l.path)
+}
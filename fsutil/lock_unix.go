@@ -0,0 +1,39 @@
+//go:build unix
+
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+type lockHandle struct{ f *os.File }
+
+// AcquireLock takes an exclusive lock on path, creating it if needed, and
+// writes the process ID into it. The kernel releases the lock when the
+// process exits, so a crash leaves no stale lock behind.
+func AcquireLock(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%s: %w", path, ErrLocked)
+		}
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	f.Truncate(0)
+	f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return &Lock{path: path, lockHandle: lockHandle{f}}, nil
+}
+
+// Unlock releases the lock. The file is left in place: removing it would
+// let a waiting process lock a file that a newer one then recreates.
+func (l *Lock) Unlock() error {
+	return l.f.Close()
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package fsutil
+
+// LongPath returns the path unchanged; only Windows needs rewriting.
+func LongPath(path string) string {
+	return path
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LongPath rewrites an absolute path into its extended-length form
+// (\\?\C:\... or \\?\UNC\server\share\...) so paths longer than MAX_PATH and
+// UNC shares such as \\server\share\logs can be opened.
+func LongPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + strings.TrimPrefix(abs, `\\`)
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,96 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrOutsideSandbox is returned for paths outside the directories passed
+// to Restrict.
+var ErrOutsideSandbox = errors.New("outside the allowed directories")
+
+// allowedDir is a directory logs may be read from, held open so lookups
+// are resolved relative to it by the kernel rather than by path strings.
+type allowedDir struct {
+	path string
+	root *os.Root
+}
+
+var (
+	sandboxMu sync.RWMutex
+	allowed   []allowedDir
+)
+
+// Restrict limits Open, ReadDir, and Stat to files under dirs. Lookups go
+// through os.Root, so ".." and symlinks that lead out of a directory are
+// refused even if they change after the check. It can be called once.
+func Restrict(dirs []string) error {
+	sandboxMu.Lock()
+	defer sandboxMu.Unlock()
+	if allowed != nil {
+		return errors.New("file access is already restricted")
+	}
+	var opened []allowedDir
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		root, err := os.OpenRoot(LongPath(abs))
+		if err != nil {
+			for _, d := range opened {
+				d.root.Close()
+			}
+			return fmt.Errorf("opening allowed directory: %w", err)
+		}
+		opened = append(opened, allowedDir{path: abs, root: root})
+	}
+	allowed = opened
+	return nil
+}
+
+// Restricted reports whether Restrict is in effect.
+func Restricted() bool {
+	sandboxMu.RLock()
+	defer sandboxMu.RUnlock()
+	return allowed != nil
+}
+
+// sandboxed finds the allowed directory holding path and path's name
+// relative to it. ok is false when access is unrestricted.
+func sandboxed(op, path string) (root *os.Root, rel string, ok bool, err error) {
+	sandboxMu.RLock()
+	defer sandboxMu.RUnlock()
+	if allowed == nil {
+		return nil, "", false, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", true, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+	for _, d := range allowed {
+		rel, err := filepath.Rel(d.path, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return d.root, rel, true, nil
+	}
+	return nil, "", true, &fs.PathError{Op: op, Path: path, Err: ErrOutsideSandbox}
+}
+
+// Stat describes a file, accepting the same paths as Open.
+func Stat(path string) (os.FileInfo, error) {
+	root, rel, ok, err := sandboxed("stat", path)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return root.Stat(rel)
+	}
+	return os.Stat(LongPath(path))
+}
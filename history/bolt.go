@@ -0,0 +1,87 @@
+//go:build bolt
+
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucket = []byte("findings")
+
+// boltBackend keeps records in a BoltDB file, keyed by a sequence number so
+// iteration follows insertion order.
+type boltBackend struct {
+	db *bolt.DB
+}
+
+func init() {
+	backends["bolt"] = func(path string) (Backend, error) {
+		db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+		if err != nil {
+			return nil, err
+		}
+		err = db.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(boltBucket)
+			return err
+		})
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &boltBackend{db: db}, nil
+	}
+}
+
+func (s *boltBackend) Append(scannedAt time.Time, findings []analyzer.Finding) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucket)
+		for _, f := range findings {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(Record{ScannedAt: scannedAt, Finding: f})
+			if err != nil {
+				return err
+			}
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			if err := b.Put(key, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return nil
+}
+
+func (s *boltBackend) Records() ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).ForEach(func(_, v []byte) error {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("parsing history record: %w", err)
+			}
+			records = append(records, r)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	return records, nil
+}
+
+func (s *boltBackend) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,126 @@
+// Package history stores findings from past scans so that policy changes can
+// be simulated and new adoption detected against them.
+//
+// A store location is a file path or a URL. Plain paths are append-only JSON
+// Lines files, which need nothing beyond the standard library. SQLite (.db
+// files or sqlite://), postgres://, and bolt:// locations need a binary built
+// with the sqlite, postgres, or bolt tag.
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Record is one stored finding along with the time of the scan that produced it.
+type Record struct {
+	ScannedAt time.Time        `json:"scanned_at"`
+	Finding   analyzer.Finding `json:"finding"`
+}
+
+// Backend persists history records.
+type Backend interface {
+	// Append adds the findings of one scan.
+	Append(scannedAt time.Time, findings []analyzer.Finding) error
+	// Records returns every stored record in insertion order.
+	Records() ([]Record, error)
+	Close() error
+}
+
+// Store is a history store on any backend.
+type Store struct {
+	Backend
+}
+
+// opener creates a backend from a location with its scheme removed.
+type opener func(location string) (Backend, error)
+
+// backends maps location schemes to their implementations. Optional backends
+// register themselves from build-tagged files.
+var backends = map[string]opener{
+	"jsonl": func(path string) (Backend, error) { return &jsonlBackend{path: path}, nil },
+}
+
+// buildTags names the tag needed for backends that are not compiled in.
+var buildTags = map[string]string{
+	"sqlite":   "sqlite",
+	"postgres": "postgres",
+	"bolt":     "bolt",
+}
+
+// Open returns the store at location, creating it if needed.
+func Open(location string) (*Store, error) {
+	scheme, rest := splitLocation(location)
+	open, ok := backends[scheme]
+	if !ok {
+		if tag, optional := buildTags[scheme]; optional {
+			return nil, fmt.Errorf("history store %s: this build has no %s support (rebuild with -tags %s)", location, scheme, tag)
+		}
+		return nil, fmt.Errorf("history store %s: unknown scheme %q", location, scheme)
+	}
+	b, err := open(rest)
+	if err != nil {
+		return nil, fmt.Errorf("opening history store: %w", err)
+	}
+	return &Store{b}, nil
+}
+
+// splitLocation picks the backend for a location. Postgres keeps its whole
+// URL as the connection string; the others take a file path.
+func splitLocation(location string) (scheme, rest string) {
+	if i := strings.Index(location, "://"); i > 0 {
+		scheme, rest = strings.ToLower(location[:i]), location[i+3:]
+		switch scheme {
+		case "postgres", "postgresql":
+			return "postgres", location
+		case "bbolt":
+			return "bolt", rest
+		}
+		return scheme, rest
+	}
+
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return "sqlite", location
+	case ".bolt", ".bbolt":
+		return "bolt", location
+	}
+	if isSQLite(location) {
+		return "sqlite", location
+	}
+	return "jsonl", location
+}
+
+// isSQLite reports whether an existing file is a SQLite database, so a
+// store created under another name by a SQLite build is not taken for JSON
+// Lines and appended to.
+func isSQLite(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 16)
+	n, _ := io.ReadFull(f, head)
+	return bytes.Equal(head[:n], []byte("SQLite format 3\x00"))
+}
+
+// Findings returns every stored finding.
+func (s *Store) Findings() ([]analyzer.Finding, error) {
+	records, err := s.Records()
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]analyzer.Finding, 0, len(records))
+	for _, r := range records {
+		findings = append(findings, r.Finding)
+	}
+	return findings, nil
+}
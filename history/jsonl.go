@@ -0,0 +1,65 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// jsonlBackend is an append-only JSON Lines file, the original store format.
+type jsonlBackend struct {
+	path string
+}
+
+// Append adds the findings of one scan to the file, creating it if needed.
+func (s *jsonlBackend) Append(scannedAt time.Time, findings []analyzer.Finding) error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening history store: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, finding := range findings {
+		if err := enc.Encode(Record{ScannedAt: scannedAt, Finding: finding}); err != nil {
+			return fmt.Errorf("writing history record: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// Records returns every stored record in insertion order. A missing file
+// yields no records.
+func (s *jsonlBackend) Records() ([]Record, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening history store: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("parsing history record: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading history store: %w", err)
+	}
+	return records, nil
+}
+
+func (s *jsonlBackend) Close() error { return nil }
@@ -0,0 +1,29 @@
+//go:build postgres
+
+package history
+
+import (
+	_ "github.com/lib/pq"
+)
+
+var postgresDialect = dialect{
+	driver: "postgres",
+	schema: []string{
+		`CREATE TABLE IF NOT EXISTS findings (
+			id BIGSERIAL PRIMARY KEY,
+			scanned_at TEXT NOT NULL,
+			source_ip TEXT NOT NULL,
+			service TEXT NOT NULL,
+			finding TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS findings_scanned_at ON findings (scanned_at)`,
+		`CREATE INDEX IF NOT EXISTS findings_user_service ON findings (source_ip, service)`,
+	},
+	insert: `INSERT INTO findings (scanned_at, source_ip, service, finding) VALUES ($1, $2, $3, $4)`,
+}
+
+func init() {
+	backends["postgres"] = func(dsn string) (Backend, error) {
+		return openSQL(postgresDialect, dsn)
+	}
+}
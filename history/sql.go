@@ -0,0 +1,106 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// dialect holds what differs between SQL databases.
+type dialect struct {
+	driver string
+	schema []string
+	insert string
+}
+
+// sqlBackend stores one row per finding. The finding itself is kept as JSON
+// so new fields need no migration; the user, service, and scan time are also
+// columns so a central database can be queried and indexed directly.
+type sqlBackend struct {
+	db *sql.DB
+	d  dialect
+}
+
+func openSQL(d dialect, dsn string) (Backend, error) {
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, stmt := range d.schema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("creating schema: %w", err)
+		}
+	}
+	return &sqlBackend{db: db, d: d}, nil
+}
+
+// Append inserts the findings of one scan in a single transaction.
+func (s *sqlBackend) Append(scannedAt time.Time, findings []analyzer.Finding) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(s.d.insert)
+	if err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	defer stmt.Close()
+
+	at := scannedAt.UTC().Format(time.RFC3339Nano)
+	for _, f := range findings {
+		data, err := json.Marshal(f)
+		if err != nil {
+			return fmt.Errorf("writing history record: %w", err)
+		}
+		if _, err := stmt.Exec(at, f.SourceIP, f.ServiceName, string(data)); err != nil {
+			return fmt.Errorf("writing history record: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return nil
+}
+
+// Records returns every stored record in insertion order.
+func (s *sqlBackend) Records() ([]Record, error) {
+	rows, err := s.db.Query(`SELECT scanned_at, finding FROM findings ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var at, data string
+		if err := rows.Scan(&at, &data); err != nil {
+			return nil, fmt.Errorf("reading history: %w", err)
+		}
+		var r Record
+		if r.ScannedAt, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("parsing history record: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &r.Finding); err != nil {
+			return nil, fmt.Errorf("parsing history record: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	return records, nil
+}
+
+func (s *sqlBackend) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,36 @@
+//go:build sqlite
+
+package history
+
+import (
+	"net/url"
+
+	_ "modernc.org/sqlite" // pure Go, so the tagged build needs no cgo
+)
+
+var sqliteDialect = dialect{
+	driver: "sqlite",
+	schema: []string{
+		`CREATE TABLE IF NOT EXISTS findings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			scanned_at TEXT NOT NULL,
+			source_ip TEXT NOT NULL,
+			service TEXT NOT NULL,
+			finding TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS findings_scanned_at ON findings (scanned_at)`,
+		`CREATE INDEX IF NOT EXISTS findings_user_service ON findings (source_ip, service)`,
+	},
+	insert: `INSERT INTO findings (scanned_at, source_ip, service, finding) VALUES (?, ?, ?, ?)`,
+}
+
+func init() {
+	backends["sqlite"] = openSQLite
+}
+
+// openSQLite opens (or creates) a SQLite database file. A busy timeout lets
+// a scan and a follow-mode process share one store.
+func openSQLite(path string) (Backend, error) {
+	dsn := (&url.URL{Scheme: "file", Opaque: path, RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"}).String()
+	return openSQL(sqliteDialect, dsn)
+}
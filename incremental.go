@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/state"
+)
+
+// incrementalHead is how many leading bytes are fingerprinted to tell a file
+// that was truncated and rewritten past its old size from one appended to.
+const incrementalHead = 256
+
+// incremental makes a scan read only what was appended to each file since
+// the previous one, keeping read offsets in a state file as follow mode
+// does. Offsets are matched by file identity, so a log renamed by rotation
+// (access.log to access.log.1) continues where it left off under its old
+// name while the new access.log is read from the start.
+type incremental struct {
+	store *state.Store
+	full  bool // read every file from the start, but still record offsets
+}
+
+func loadIncremental(path string) (*incremental, error) {
+	st, err := state.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &incremental{store: st}, nil
+}
+
+// withRotated adds the rotated predecessor of each file that is not already
+// listed, ahead of it, so the lines written before rotation are not lost.
+func (in *incremental) withRotated(files []string) []string {
+	if in.full {
+		return files
+	}
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		prev, ok := in.store.Get(f)
+		if ok && !prev.ID.Zero() {
+			if id, err := fsutil.IdentifyPath(f); err == nil && id != prev.ID {
+				if old := fsutil.FindByID(f, prev.ID); old != "" && !slices.Contains(files, old) {
+					logger.Info("Reading the rest of a rotated log", "file", old, "rotated_from", f)
+					out = append(out, old)
+				}
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// start positions res where the last scan of the file's content ended. It
+// returns false when nothing was added and the file need not be read.
+// Only files parsed line by line (lineScan) can be read from the middle;
+// others are read again in full whenever they change.
+func (in *incremental) start(path string, res *fileResult, lineScan bool) bool {
+	if in.full {
+		return true
+	}
+	id, err := fsutil.IdentifyPath(path)
+	if err != nil {
+		return true // the scan reports the error
+	}
+	var prev state.FileState
+	var ok bool
+	if id.Zero() {
+		prev, ok = in.store.Get(path)
+	} else {
+		_, prev, ok = in.store.ByID(id)
+	}
+	if !ok || res.Size < prev.Offset {
+		return true // new, or truncated since
+	}
+	if sum, err := headSum(path, min(prev.Offset, incrementalHead)); err != nil || sum != prev.Fingerprint {
+		return true // rewritten since
+	}
+	if res.Size == prev.Offset {
+		return false
+	}
+	if lineScan {
+		res.Offset, res.LineNo = prev.Offset, prev.Line
+	}
+	return true
+}
+
+// record notes how far a completely scanned file has been read, moving the
+// entry of a rotated file to its new name.
+func (in *incremental) record(path string, res *fileResult, lineScan bool) {
+	id, err := fsutil.IdentifyPath(path)
+	if err != nil {
+		return
+	}
+	fs := state.FileState{ID: id, Offset: res.Size}
+	if lineScan {
+		fs.Offset, fs.Line = res.Offset, res.LineNo
+	}
+	if fs.Fingerprint, err = headSum(path, min(fs.Offset, incrementalHead)); err != nil {
+		return
+	}
+	if !id.Zero() {
+		if old, _, ok := in.store.ByID(id); ok && old != path {
+			in.store.Delete(old)
+		}
+	}
+	in.store.Set(path, fs)
+}
+
+// save writes the offsets, forgetting files that no longer exist.
+func (in *incremental) save() error {
+	for path := range in.store.Files {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			in.store.Delete(path)
+		}
+	}
+	return in.store.Save()
+}
+
+// headSum checksums the first n bytes of a file.
+func headSum(path string, n int64) (string, error) {
+	if n == 0 {
+		return "", nil
+	}
+	f, err := fsutil.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:8]), nil
+}
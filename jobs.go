@@ -0,0 +1,360 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/policy"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// Job states, in the order a job moves through them.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled" // results, if any, are partial
+)
+
+// errQueueFull is returned when a job is submitted with the queue at capacity.
+var errQueueFull = errors.New("too many scan jobs queued; try again later")
+
+// jobRequest is the body of POST /jobs.
+type jobRequest struct {
+	File        string   `json:"file,omitempty"`
+	Dir         string   `json:"dir,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	OnlyAllowed bool     `json:"only_allowed,omitempty"`
+	Tenant      string   `json:"tenant,omitempty"` // default: serve's -tenant
+}
+
+// job is one asynchronous scan. Fields after mu change while it runs.
+type job struct {
+	ID        string
+	Request   jobRequest
+	CreatedAt time.Time
+	files     []string
+	progress  *progressBar
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	mu         sync.Mutex
+	status     string
+	startedAt  time.Time
+	finishedAt time.Time
+	err        string
+	summary    *analyzer.Summary
+	scanned    []reporter.ScanFile
+}
+
+// jobView is the JSON status of a job.
+type jobView struct {
+	ID         string              `json:"id"`
+	Status     string              `json:"status"`
+	Request    jobRequest          `json:"request"`
+	CreatedAt  time.Time           `json:"created_at"`
+	StartedAt  *time.Time          `json:"started_at,omitempty"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Progress   progressSnapshot    `json:"progress"`
+	Findings   *int                `json:"findings,omitempty"`
+	Files      []reporter.ScanFile `json:"files,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+func (j *job) view() jobView {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	v := jobView{
+		ID:        j.ID,
+		Status:    j.status,
+		Request:   j.Request,
+		CreatedAt: j.CreatedAt,
+		Progress:  j.progress.snapshot(),
+		Files:     j.scanned,
+		Error:     j.err,
+	}
+	if !j.startedAt.IsZero() {
+		v.StartedAt = &j.startedAt
+	}
+	if !j.finishedAt.IsZero() {
+		v.FinishedAt = &j.finishedAt
+	}
+	if j.summary != nil {
+		v.Findings = &j.summary.TotalFindings
+	}
+	return v
+}
+
+// result returns the job's summary once it is done, or the partial summary
+// of a job cancelled while running.
+func (j *job) result() (analyzer.Summary, string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.summary == nil {
+		return analyzer.Summary{}, j.status, false
+	}
+	return *j.summary, j.status, true
+}
+
+// jobManager runs scan jobs in the background, at most slots at a time,
+// and keeps finished jobs around for their results.
+type jobManager struct {
+	ctx       context.Context // parent of every job; cancelled on shutdown
+	root      string          // requested paths must lie under it
+	az        *analyzer.Analyzer
+	custom    []customParser
+	multiline *parsers.Multiline
+	policy    *policy.Policy
+	history   *serveHistory // nil unless -history is set
+	tenant    string        // for jobs that name none
+	warnRatio float64
+	metrics   *scanMetrics
+	maxQueued int
+	keep      int // finished jobs retained
+
+	slots   chan struct{}
+	mu      sync.Mutex
+	jobs    map[string]*job
+	order   []string // submission order, for listing and eviction
+	pending int      // queued or running
+}
+
+func newJobManager(ctx context.Context, maxJobs, maxQueued, keep int) *jobManager {
+	sm := newScanMetrics()
+	sm.jobs = sm.registry.NewCounter("shadow_ai_scan_jobs_total", "Scan jobs finished, by final status.", "status")
+	return &jobManager{
+		ctx:       ctx,
+		maxQueued: maxQueued,
+		keep:      keep,
+		slots:     make(chan struct{}, maxJobs),
+		jobs:      make(map[string]*job),
+		metrics:   sm,
+	}
+}
+
+// submit validates req and queues it, returning the new job.
+func (m *jobManager) submit(req jobRequest) (*job, error) {
+	if (req.File == "") == (req.Dir == "") {
+		return nil, errors.New("exactly one of file or dir is required")
+	}
+	if req.Format == "" {
+		req.Format = "auto"
+	}
+
+	var files []string
+	if req.File != "" {
+		path, err := m.resolve(req.File)
+		if err != nil {
+			return nil, err
+		}
+		files = []string{path}
+	} else {
+		dir, err := m.resolve(req.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if files, err = collectFiles(dir); err != nil {
+			return nil, fmt.Errorf("reading directory: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, errors.New("no log files found to scan")
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending >= m.maxQueued {
+		return nil, errQueueFull
+	}
+	j := &job{
+		ID:        newJobID(),
+		Request:   req,
+		CreatedAt: time.Now().UTC(),
+		files:     files,
+		progress:  &progressBar{w: io.Discard},
+		status:    jobQueued,
+	}
+	j.ctx, j.cancel = context.WithCancel(m.ctx)
+	j.progress.begin(files)
+	m.jobs[j.ID] = j
+	m.order = append(m.order, j.ID)
+	m.pending++
+	m.evict()
+	go m.run(j)
+	return j, nil
+}
+
+// resolve maps a requested path into the server's root, refusing anything
+// that escapes it.
+func (m *jobManager) resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.root, path)
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, errors.Unwrap(err))
+	}
+	rel, err := filepath.Rel(m.root, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the scan root", path)
+	}
+	return real, nil
+}
+
+// run waits for a free slot, then scans the job's files.
+func (m *jobManager) run(j *job) {
+	defer func() {
+		j.cancel()
+		m.mu.Lock()
+		m.pending--
+		m.mu.Unlock()
+		j.mu.Lock()
+		m.metrics.jobs.Inc(j.status)
+		j.mu.Unlock()
+	}()
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-j.ctx.Done():
+		j.mu.Lock()
+		j.status, j.finishedAt = jobCancelled, time.Now().UTC()
+		j.mu.Unlock()
+		return
+	}
+
+	j.mu.Lock()
+	j.status, j.startedAt = jobRunning, time.Now().UTC()
+	j.mu.Unlock()
+	logger.Info("Starting scan job", "job", j.ID, "files", len(j.files))
+
+	scanner := &fileScanner{ctx: j.ctx, az: m.az, format: j.Request.Format, custom: m.custom, warnRatio: m.warnRatio, progress: j.progress}
+	out := scanner.scanAll(j.files, m.multiline)
+
+	summary := analyzer.Summarize(out.findings, out.logsScanned)
+	summary.Sources = out.sources
+	summary.Partial = out.partial
+	summary.Scan = out.meta(m.az, j.startedAt)
+	if tenant := cmp.Or(j.Request.Tenant, m.tenant); tenant != "" {
+		summary = summary.WithTenant(tenant)
+	}
+	// History keeps every detection, before the policy, as a scan's does
+	if m.history != nil && len(out.sources) > 0 {
+		if err := m.history.append(time.Now().UTC(), summary.Findings); err != nil {
+			logger.Error("Error recording history", "job", j.ID, "err", err)
+		}
+	}
+	summary = m.policy.Apply(summary)
+	if j.Request.OnlyAllowed {
+		summary = summary.Filter(func(f analyzer.Finding) bool { return !f.Blocked })
+	}
+	if len(j.Request.Categories) > 0 {
+		summary = summary.Filter(analyzer.InCategories(j.Request.Categories))
+	}
+
+	m.metrics.observeScan(out, summary.Findings)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finishedAt = time.Now().UTC()
+	j.scanned = out.files
+	if len(out.sources) == 0 {
+		j.status, j.err = jobFailed, "no file could be scanned"
+		logger.Warn("Scan job failed", "job", j.ID, "err", j.err)
+		return
+	}
+	if out.partial {
+		j.status, j.summary = jobCancelled, &summary
+		logger.Warn("Scan job cancelled", "job", j.ID, "findings", summary.TotalFindings)
+		return
+	}
+	j.status, j.summary = jobDone, &summary
+	logSuccess("Scan job finished", "job", j.ID, "findings", summary.TotalFindings)
+}
+
+// stop cancels a queued or running job.
+func (j *job) stop() {
+	j.cancel()
+}
+
+// get returns the job with the given ID.
+func (m *jobManager) get(id string) (*job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	return j, ok
+}
+
+// list returns every retained job, newest first.
+func (m *jobManager) list() []*job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]*job, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, m.jobs[m.order[i]])
+	}
+	return jobs
+}
+
+// evict drops the oldest finished jobs beyond the retention limit. The
+// caller holds m.mu.
+func (m *jobManager) evict() {
+	finished := len(m.order) - m.pending
+	kept := m.order[:0]
+	for _, id := range m.order {
+		j := m.jobs[id]
+		j.mu.Lock()
+		over := finished > m.keep && (j.status == jobDone || j.status == jobFailed || j.status == jobCancelled)
+		j.mu.Unlock()
+		if over {
+			delete(m.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// rootDir resolves the -root flag to the absolute, symlink-free form that
+// requested paths are compared against.
+func rootDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	return real, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// journalSource names the records of a systemd unit, as findings and scan
+// stats attribute them: journal:dnsmasq.service.
+func journalSource(unit string) string {
+	return "journal:" + unit
+}
+
+// journalParser reads journal messages in the given format, or in the one
+// each record's program implies when the format is auto or journal.
+func journalParser(format string, custom []customParser) (*parsers.JournalParser, error) {
+	switch strings.ToLower(format) {
+	case "", "auto", "journal":
+		return &parsers.JournalParser{}, nil
+	}
+	lp, ok := selectParser(format, "", custom).(parsers.LineParser)
+	if !ok {
+		return nil, fmt.Errorf("-journal-unit needs a line-oriented format (squid, dns, regex), not %s", format)
+	}
+	return &parsers.JournalParser{Inner: lp}, nil
+}
+
+// scanJournals adds each unit's records to the outcome of a file scan,
+// counting those whose message does not parse the way scanLines counts
+// malformed lines.
+func (s *fileScanner) scanJournals(out *scanOutcome, units []string, p *parsers.JournalParser) {
+	for _, unit := range units {
+		s.scanSource(out, journalSource(unit), p.Name(), func(res *fileResult) ([]parsers.LogEntry, error) {
+			logger.Info("Reading journal", "unit", unit)
+			var entries []parsers.LogEntry
+			err := parsers.ReadJournalUnit(s.ctx, unit, false, func(rec parsers.JournalRecord) {
+				entry, perr := p.Entry(rec)
+				if errors.Is(perr, parsers.ErrSkip) {
+					return
+				}
+				res.Lines++
+				if perr != nil {
+					res.reject(rec.Message())
+					return
+				}
+				entry.LineNumber = res.Lines
+				entries = append(entries, entry)
+			})
+			return entries, err
+		})
+	}
+}
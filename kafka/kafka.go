@@ -0,0 +1,393 @@
+// Package kafka is a minimal Kafka producer: enough of the wire protocol to
+// publish findings to a topic, over TLS and SASL (PLAIN or SCRAM) when the
+// cluster requires them. Messages are sent uncompressed with acks=all, and
+// partitioned by key the way the Java client does.
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	ioTimeout   = 30 * time.Second
+	ackTimeout  = 10 * time.Second // how long the leader waits for replicas
+	maxAttempts = 4
+
+	// maxBatchBytes keeps record batches under the brokers' default
+	// message.max.bytes of about 1 MiB.
+	maxBatchBytes = 512 * 1024
+)
+
+// Config describes the cluster and topic to publish to.
+type Config struct {
+	Brokers  []string // bootstrap host:port addresses
+	Topic    string
+	TLS      *tls.Config // nil for plaintext
+	SASL     string      // SASLPlain, SASLScramSHA256, SASLScramSHA512, or empty
+	Username string
+	Password string
+	ClientID string
+}
+
+// Message is one record to publish.
+type Message struct {
+	Key   []byte // selects the partition; nil spreads messages
+	Value []byte
+	Time  time.Time
+}
+
+// Producer publishes messages to one topic. It connects on first use and
+// reconnects after errors, so one producer can serve a long follow session.
+// It is safe for concurrent use.
+type Producer struct {
+	cfg Config
+
+	mu      sync.Mutex
+	addrs   map[int32]string // broker id -> host:port
+	leaders []int32          // partition -> leader broker id
+	conns   map[int32]*conn
+}
+
+// NewProducer checks cfg. It does not connect.
+func NewProducer(cfg Config) (*Producer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka: no brokers")
+	}
+	if cfg.Topic == "" {
+		return nil, errors.New("kafka: no topic")
+	}
+	switch cfg.SASL {
+	case "":
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		if cfg.Username == "" {
+			return nil, fmt.Errorf("kafka: SASL %s needs a username", cfg.SASL)
+		}
+	default:
+		return nil, fmt.Errorf("kafka: unsupported SASL mechanism %q (use %s, %s, or %s)", cfg.SASL, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "shadow-ai-hunter"
+	}
+	return &Producer{cfg: cfg, conns: make(map[int32]*conn)}, nil
+}
+
+// Send publishes msgs and waits for every in-sync replica to acknowledge
+// them. Leadership changes and dropped connections are retried, so a
+// message may occasionally be delivered twice, never silently lost.
+func (p *Producer) Send(ctx context.Context, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.leaders == nil {
+		if err := p.refresh(ctx); err != nil {
+			return err
+		}
+	}
+	byPartition := make(map[int][]Message)
+	for _, m := range msgs {
+		part := partitionFor(m.Key, len(p.leaders))
+		byPartition[part] = append(byPartition[part], m)
+	}
+	parts := make([]int, 0, len(byPartition))
+	for part := range byPartition {
+		parts = append(parts, part)
+	}
+	slices.Sort(parts)
+
+	for _, part := range parts {
+		for _, batch := range splitBatches(byPartition[part]) {
+			if err := p.sendBatch(ctx, part, batch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close drops the broker connections.
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
+	return nil
+}
+
+func (p *Producer) sendBatch(ctx context.Context, part int, batch []Message) error {
+	for attempt := 1; ; attempt++ {
+		err := p.produce(ctx, part, batch)
+		if err == nil {
+			return nil
+		}
+		var kerr KafkaError
+		if attempt == maxAttempts || (errors.As(err, &kerr) && !kerr.retriable()) {
+			return fmt.Errorf("publishing to %s: %w", p.cfg.Topic, err)
+		}
+
+		// The leader moved or the connection broke: start over from the
+		// bootstrap brokers
+		p.reset()
+		if err := p.refresh(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// produce sends one record batch to the partition's leader.
+func (p *Producer) produce(ctx context.Context, part int, batch []Message) error {
+	if part >= len(p.leaders) {
+		return KafkaError(errLeaderNotAvailable)
+	}
+	c, err := p.leader(ctx, p.leaders[part])
+	if err != nil {
+		return err
+	}
+
+	var e encoder
+	e.nullString() // transactional id
+	e.int16(-1)    // acks: all in-sync replicas
+	e.int32(int32(ackTimeout / time.Millisecond))
+	e.int32(1)
+	e.string(p.cfg.Topic)
+	e.int32(1)
+	e.int32(int32(part))
+	e.bytes(recordBatch(batch))
+	d, err := c.roundTrip(apiProduce, produceVersion, e.b)
+	if err != nil {
+		return err
+	}
+
+	code := int16(errNone)
+	for range d.arrayLen() {
+		d.string()
+		for range d.arrayLen() {
+			d.int32() // partition
+			if c := d.int16(); c != errNone {
+				code = c
+			}
+			d.int64() // base offset
+			d.int64() // log append time
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if code != errNone {
+		return KafkaError(code)
+	}
+	return nil
+}
+
+// refresh asks the bootstrap brokers for the topic's partition leaders,
+// waiting out elections and the creation of a new topic.
+func (p *Producer) refresh(ctx context.Context) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt-1) * 250 * time.Millisecond):
+			}
+		}
+		for _, addr := range p.cfg.Brokers {
+			var c *conn
+			if c, err = p.dial(ctx, addr); err != nil {
+				continue
+			}
+			err = p.metadata(c)
+			c.close()
+			if err == nil {
+				return nil
+			}
+		}
+		var kerr KafkaError
+		if errors.As(err, &kerr) && !kerr.retriable() {
+			break
+		}
+	}
+	return fmt.Errorf("kafka metadata for %s: %w", p.cfg.Topic, err)
+}
+
+func (p *Producer) metadata(c *conn) error {
+	var e encoder
+	e.int32(1)
+	e.string(p.cfg.Topic)
+	d, err := c.roundTrip(apiMetadata, metadataVersion, e.b)
+	if err != nil {
+		return err
+	}
+
+	addrs := make(map[int32]string)
+	for range d.arrayLen() {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		addrs[id] = net.JoinHostPort(host, fmt.Sprint(port))
+	}
+	d.int32() // controller
+
+	var leaders []int32
+	var topicErr int16 = errUnknownTopicOrPartition
+	for range d.arrayLen() {
+		code := d.int16()
+		name := d.string()
+		d.int8() // internal
+		for range d.arrayLen() {
+			d.int16() // partition error
+			index := d.int32()
+			leader := d.int32()
+			for range d.arrayLen() {
+				d.int32() // replicas
+			}
+			for range d.arrayLen() {
+				d.int32() // in-sync replicas
+			}
+			if name == p.cfg.Topic && index >= 0 && int(index) < 1<<16 {
+				for len(leaders) <= int(index) {
+					leaders = append(leaders, -1)
+				}
+				leaders[index] = leader
+			}
+		}
+		if name == p.cfg.Topic {
+			topicErr = code
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if topicErr != errNone {
+		return KafkaError(topicErr)
+	}
+	if len(leaders) == 0 || slices.Contains(leaders, -1) {
+		return KafkaError(errLeaderNotAvailable)
+	}
+	p.addrs, p.leaders = addrs, leaders
+	return nil
+}
+
+func (p *Producer) leader(ctx context.Context, id int32) (*conn, error) {
+	if c, ok := p.conns[id]; ok {
+		return c, nil
+	}
+	addr, ok := p.addrs[id]
+	if !ok {
+		return nil, KafkaError(errLeaderNotAvailable)
+	}
+	c, err := p.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[id] = c
+	return c, nil
+}
+
+// reset forgets connections and leaders so the next send starts afresh.
+func (p *Producer) reset() {
+	for id, c := range p.conns {
+		c.close()
+		delete(p.conns, id)
+	}
+	p.leaders = nil
+}
+
+// dial connects to a broker, with TLS and SASL as configured.
+func (p *Producer) dial(ctx context.Context, addr string) (*conn, error) {
+	d := net.Dialer{Timeout: dialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if p.cfg.TLS != nil {
+		cfg := p.cfg.TLS.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tc := tls.Client(nc, cfg)
+		hctx, cancel := context.WithTimeout(ctx, dialTimeout)
+		defer cancel()
+		if err := tc.HandshakeContext(hctx); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("TLS with %s: %w", addr, err)
+		}
+		nc = tc
+	}
+	c := &conn{nc: nc, clientID: p.cfg.ClientID}
+	if p.cfg.SASL != "" {
+		if err := c.authenticate(p.cfg.SASL, p.cfg.Username, p.cfg.Password); err != nil {
+			c.close()
+			return nil, fmt.Errorf("authenticating to %s: %w", addr, err)
+		}
+	}
+	return c, nil
+}
+
+// splitBatches cuts msgs into record batches the broker will accept.
+func splitBatches(msgs []Message) [][]Message {
+	var batches [][]Message
+	start, size := 0, 0
+	for i, m := range msgs {
+		n := len(m.Key) + len(m.Value) + 32
+		if i > start && size+n > maxBatchBytes {
+			batches = append(batches, msgs[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+	return append(batches, msgs[start:])
+}
+
+// conn is one broker connection. Requests on it are strictly sequential.
+type conn struct {
+	nc       net.Conn
+	clientID string
+	corr     int32
+}
+
+// roundTrip sends a request and returns a decoder over the response body.
+func (c *conn) roundTrip(api, version int16, body []byte) (*decoder, error) {
+	c.corr++
+	var e encoder
+	e.int32(0) // size, filled in below
+	e.int16(api)
+	e.int16(version)
+	e.int32(c.corr)
+	e.string(c.clientID)
+	e.b = append(e.b, body...)
+	size := int32(len(e.b) - 4)
+	e.b[0], e.b[1], e.b[2], e.b[3] = byte(size>>24), byte(size>>16), byte(size>>8), byte(size)
+
+	c.nc.SetDeadline(time.Now().Add(ioTimeout))
+	if _, err := c.nc.Write(e.b); err != nil {
+		return nil, err
+	}
+	var head [8]byte
+	if _, err := io.ReadFull(c.nc, head[:]); err != nil {
+		return nil, err
+	}
+	d := &decoder{b: head[:]}
+	n, corr := d.int32(), d.int32()
+	if corr != c.corr || n < 4 || n > 64<<20 {
+		return nil, errors.New("kafka: malformed response")
+	}
+	resp := make([]byte, n-4)
+	if _, err := io.ReadFull(c.nc, resp); err != nil {
+		return nil, err
+	}
+	return &decoder{b: resp}, nil
+}
+
+func (c *conn) close() { c.nc.Close() }
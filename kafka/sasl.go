@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// SASL mechanisms.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// authenticate runs the SASL exchange on a fresh connection.
+func (c *conn) authenticate(mechanism, user, password string) error {
+	var e encoder
+	e.string(mechanism)
+	d, err := c.roundTrip(apiSaslHandshake, saslHandshakeVersion, e.b)
+	if err != nil {
+		return err
+	}
+	if code := d.int16(); code != errNone {
+		return fmt.Errorf("SASL %s: %w", mechanism, KafkaError(code))
+	}
+
+	switch mechanism {
+	case SASLPlain:
+		_, err := c.saslStep([]byte("\x00" + user + "\x00" + password))
+		return err
+	case SASLScramSHA256:
+		return c.scram(sha256.New, user, password)
+	case SASLScramSHA512:
+		return c.scram(sha512.New, user, password)
+	}
+	return fmt.Errorf("unsupported SASL mechanism %q", mechanism)
+}
+
+// saslStep sends one SASL token and returns the broker's reply.
+func (c *conn) saslStep(token []byte) ([]byte, error) {
+	var e encoder
+	e.bytes(token)
+	d, err := c.roundTrip(apiSaslAuthenticate, saslAuthenticateVersion, e.b)
+	if err != nil {
+		return nil, err
+	}
+	code := d.int16()
+	msg := d.string()
+	reply := d.bytes()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if code != errNone {
+		if msg != "" {
+			return nil, fmt.Errorf("%w: %s", KafkaError(code), msg)
+		}
+		return nil, KafkaError(code)
+	}
+	return reply, nil
+}
+
+// scram performs RFC 5802 SCRAM authentication, checking the broker's
+// signature as well as proving ours.
+func (c *conn) scram(h func() hash.Hash, user, password string) error {
+	nonce := make([]byte, 18)
+	rand.Read(nonce)
+	clientNonce := base64.RawStdEncoding.EncodeToString(nonce)
+	user = strings.NewReplacer("=", "=3D", ",", "=2C").Replace(user)
+	clientFirstBare := "n=" + user + ",r=" + clientNonce
+
+	reply, err := c.saslStep([]byte("n,," + clientFirstBare))
+	if err != nil {
+		return err
+	}
+	serverFirst := string(reply)
+	attrs := scramAttrs(serverFirst)
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil {
+		return errors.New("SCRAM: bad salt from broker")
+	}
+	iterations, err := strconv.Atoi(attrs["i"])
+	if err != nil || iterations < 1 {
+		return errors.New("SCRAM: bad iteration count from broker")
+	}
+	if !strings.HasPrefix(attrs["r"], clientNonce) {
+		return errors.New("SCRAM: broker nonce does not extend ours")
+	}
+
+	salted, err := pbkdf2.Key(h, password, salt, iterations, h().Size())
+	if err != nil {
+		return err
+	}
+	clientKey := hmacSum(h, salted, "Client Key")
+	storedKey := h()
+	storedKey.Write(clientKey)
+	clientFinalBare := "c=biws,r=" + attrs["r"]
+	authMessage := clientFirstBare + "," + serverFirst + "," + clientFinalBare
+	signature := hmacSum(h, storedKey.Sum(nil), authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range proof {
+		proof[i] = clientKey[i] ^ signature[i]
+	}
+
+	reply, err = c.saslStep([]byte(clientFinalBare + ",p=" + base64.StdEncoding.EncodeToString(proof)))
+	if err != nil {
+		return err
+	}
+	final := scramAttrs(string(reply))
+	if e := final["e"]; e != "" {
+		return fmt.Errorf("SCRAM: %s", e)
+	}
+	want := hmacSum(h, hmacSum(h, salted, "Server Key"), authMessage)
+	if got, err := base64.StdEncoding.DecodeString(final["v"]); err != nil || !hmac.Equal(got, want) {
+		return errors.New("SCRAM: broker signature does not match")
+	}
+	return nil
+}
+
+func hmacSum(h func() hash.Hash, key []byte, msg string) []byte {
+	mac := hmac.New(h, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
+
+// scramAttrs splits "k=v,k=v" SCRAM messages.
+func scramAttrs(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, part := range strings.Split(msg, ",") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,252 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// API keys and the versions of them this client speaks. These are the
+// oldest versions that carry what it needs, so any broker from 0.11 on
+// accepts them.
+const (
+	apiProduce          = 0
+	apiMetadata         = 3
+	apiSaslHandshake    = 17
+	apiSaslAuthenticate = 36
+
+	produceVersion          = 3 // first to take v2 record batches
+	metadataVersion         = 1
+	saslHandshakeVersion    = 1
+	saslAuthenticateVersion = 0
+)
+
+// Broker error codes the producer acts on.
+const (
+	errNone                    = 0
+	errUnknownTopicOrPartition = 3
+	errLeaderNotAvailable      = 5
+	errNotLeaderForPartition   = 6
+)
+
+// KafkaError is an error code returned by a broker.
+type KafkaError int16
+
+func (e KafkaError) Error() string {
+	switch e {
+	case errUnknownTopicOrPartition:
+		return "kafka: unknown topic or partition"
+	case errLeaderNotAvailable:
+		return "kafka: leader not available"
+	case errNotLeaderForPartition:
+		return "kafka: broker is not the partition leader"
+	case 10:
+		return "kafka: message too large"
+	case 29:
+		return "kafka: not authorized for topic"
+	case 33:
+		return "kafka: unsupported SASL mechanism"
+	case 58:
+		return "kafka: SASL authentication failed"
+	}
+	return fmt.Sprintf("kafka: broker error %d", int16(e))
+}
+
+// retriable reports whether fresh metadata may fix the error.
+func (e KafkaError) retriable() bool {
+	return e == errUnknownTopicOrPartition || e == errLeaderNotAvailable || e == errNotLeaderForPartition
+}
+
+// encoder appends big-endian protocol primitives.
+type encoder struct{ b []byte }
+
+func (e *encoder) int8(v int8)   { e.b = append(e.b, byte(v)) }
+func (e *encoder) int16(v int16) { e.b = binary.BigEndian.AppendUint16(e.b, uint16(v)) }
+func (e *encoder) int32(v int32) { e.b = binary.BigEndian.AppendUint32(e.b, uint32(v)) }
+func (e *encoder) int64(v int64) { e.b = binary.BigEndian.AppendUint64(e.b, uint64(v)) }
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *encoder) nullString() { e.int16(-1) }
+
+func (e *encoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.b = append(e.b, b...)
+}
+
+// varint writes a zigzag varint, as used inside record batches.
+func (e *encoder) varint(v int64) { e.b = binary.AppendVarint(e.b, v) }
+
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.b = append(e.b, b...)
+}
+
+var errShort = errors.New("kafka: truncated response")
+
+// decoder reads protocol primitives, remembering the first error.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.b) < n {
+		d.err = errShort
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// arrayLen reads an array length, treating null as empty.
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 || int(n) > len(d.b) {
+		if n > 0 {
+			d.err = errShort
+		}
+		return 0
+	}
+	return int(n)
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// recordBatch encodes messages as a v2 record batch, uncompressed.
+func recordBatch(msgs []Message) []byte {
+	first := msgs[0].Time.UnixMilli()
+	maxTS := first
+	var recs encoder
+	for i, m := range msgs {
+		ts := m.Time.UnixMilli()
+		maxTS = max(maxTS, ts)
+		var r encoder
+		r.int8(0) // attributes
+		r.varint(ts - first)
+		r.varint(int64(i))
+		r.varbytes(m.Key)
+		r.varbytes(m.Value)
+		r.varint(0) // headers
+		recs.varint(int64(len(r.b)))
+		recs.b = append(recs.b, r.b...)
+	}
+
+	// Everything from attributes on is covered by the CRC
+	var body encoder
+	body.int16(0) // attributes: no compression, no transactions
+	body.int32(int32(len(msgs) - 1))
+	body.int64(first)
+	body.int64(maxTS)
+	body.int64(-1) // producer id
+	body.int16(-1) // producer epoch
+	body.int32(-1) // base sequence
+	body.int32(int32(len(msgs)))
+	body.b = append(body.b, recs.b...)
+
+	var e encoder
+	e.int64(0)                              // base offset, assigned by the broker
+	e.int32(int32(4 + 1 + 4 + len(body.b))) // bytes after this field
+	e.int32(-1)                             // partition leader epoch
+	e.int8(2)                               // magic
+	e.b = binary.BigEndian.AppendUint32(e.b, crc32.Checksum(body.b, castagnoli))
+	e.b = append(e.b, body.b...)
+	return e.b
+}
+
+// murmur2 is the hash Kafka's default partitioner applies to keys, so
+// findings land in the same partitions a Java producer would choose.
+func murmur2(data []byte) uint32 {
+	const m = 0x5bd1e995
+	h := uint32(0x9747b28c) ^ uint32(len(data))
+	n := len(data) / 4 * 4
+	for i := 0; i < n; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> 24
+		k *= m
+		h *= m
+		h ^= k
+	}
+	switch len(data) % 4 {
+	case 3:
+		h ^= uint32(data[n+2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[n+1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[n])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+// partitionFor picks a partition for key the way the Java client does.
+// Messages without a key are spread by time.
+func partitionFor(key []byte, partitions int) int {
+	if len(key) == 0 {
+		return int(time.Now().UnixNano() % int64(partitions))
+	}
+	return int(murmur2(key)&0x7fffffff) % partitions
+}
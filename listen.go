@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// maxListenLine bounds a log line received over the network.
+const maxListenLine = 64 * 1024
+
+// logListener receives log lines over the network, as Squid sends them
+// with "access_log udp://host:port" or "access_log tcp://host:port": one
+// or more newline-terminated lines per UDP datagram, or a stream of lines
+// per TCP connection.
+type logListener struct {
+	network string // "udp" or "tcp"
+	addr    string
+}
+
+// parseListenAddr parses a -listen address such as udp://0.0.0.0:5140.
+func parseListenAddr(s string) (logListener, error) {
+	network, addr, ok := strings.Cut(s, "://")
+	if !ok || (network != "udp" && network != "tcp") {
+		return logListener{}, fmt.Errorf("-listen %q: want udp://host:port or tcp://host:port", s)
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return logListener{}, fmt.Errorf("-listen %q: %w", s, err)
+	}
+	return logListener{network: network, addr: addr}, nil
+}
+
+// String returns the listener in the form it was given, which is also the
+// source its findings are attributed to.
+func (l logListener) String() string {
+	return l.network + "://" + l.addr
+}
+
+// bind opens the listener and returns a function that passes each line
+// received to emit until ctx is cancelled, so that a port that cannot be
+// bound is reported before anything runs.
+func (l logListener) bind(ctx context.Context, emit func(line string)) (func() error, error) {
+	if l.network == "udp" {
+		conn, err := net.ListenPacket("udp", l.addr)
+		if err != nil {
+			return nil, err
+		}
+		return func() error { return serveUDP(ctx, conn, emit) }, nil
+	}
+	ln, err := net.Listen("tcp", l.addr)
+	if err != nil {
+		return nil, err
+	}
+	return func() error { return serveTCP(ctx, ln, emit) }, nil
+}
+
+func serveUDP(ctx context.Context, conn net.PacketConn, emit func(string)) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	buf := make([]byte, maxListenLine)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+			if len(line) > 0 {
+				emit(string(line))
+			}
+		}
+	}
+}
+
+func serveTCP(ctx context.Context, ln net.Listener, emit func(string)) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	conns := make(map[net.Conn]bool)
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+		mu.Lock()
+		for c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	})
+	defer stop()
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		conns[conn] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				conn.Close()
+			}()
+			sc := bufio.NewScanner(conn)
+			sc.Buffer(make([]byte, 4096), maxListenLine)
+			for sc.Scan() {
+				emit(sc.Text())
+			}
+			if err := sc.Err(); err != nil && ctx.Err() == nil {
+				logger.Warn("Error reading log stream", "peer", conn.RemoteAddr().String(), "err", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,150 @@
+// Package loggen writes synthetic proxy, DNS, and firewall logs for
+// benchmarks, demos, and testing custom rules.
+package loggen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Options control the generated log.
+type Options struct {
+	Format    string    // squid, dns, or csv
+	Lines     int       // number of records to write
+	Seed      int64     // the same seed always produces the same log
+	AIRatio   float64   // fraction of records that hit an AI service
+	Users     int       // number of distinct client addresses
+	Start     time.Time // timestamp of the first record
+	Interval  time.Duration
+	Pattern   string   // how records spread over time; see Patterns
+	AIDomains []string // domains to draw AI hits from
+}
+
+// Patterns are the time distributions Options.Pattern accepts:
+//
+//   - even: one record every Interval (the default)
+//   - random: random arrivals, Interval apart on average
+//   - business: random arrivals, Interval apart on average during weekday
+//     business hours (09:00-17:00 of Start's zone) and far sparser outside
+//     them, with a little activity at night and on weekends
+var Patterns = []string{"even", "random", "business"}
+
+// intensity is the share of peak traffic at t under the business pattern.
+func intensity(t time.Time) float64 {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return 0.05
+	}
+	switch h := t.Hour(); {
+	case h >= 9 && h < 17:
+		return 1
+	case h >= 7 && h < 19:
+		return 0.3
+	default:
+		return 0.05
+	}
+}
+
+// benignDomains is ordinary traffic mixed in around the AI hits.
+var benignDomains = []string{
+	"www.google.com", "github.com", "stackoverflow.com", "news.ycombinator.com",
+	"docs.python.org", "go.dev", "en.wikipedia.org", "www.microsoft.com",
+	"login.microsoftonline.com", "outlook.office365.com", "slack.com", "zoom.us",
+	"www.amazon.com", "cdn.jsdelivr.net", "fonts.googleapis.com", "www.linkedin.com",
+}
+
+var aiPaths = []string{"/v1/chat/completions", "/v1/messages", "/", "/chat", "/api/generate", "/v1/embeddings"}
+var benignPaths = []string{"/", "/search?q=golang", "/questions/12345", "/org/repo/pull/42", "/index.html"}
+
+// Generate writes opts.Lines records in the requested format.
+func Generate(w io.Writer, opts Options) error {
+	if opts.Users <= 0 {
+		opts.Users = 250
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 100 * time.Millisecond
+	}
+	if opts.Start.IsZero() {
+		opts.Start = time.Date(2025, 6, 10, 8, 0, 0, 0, time.UTC)
+	}
+	if len(opts.AIDomains) == 0 {
+		opts.AIDomains = []string{"api.openai.com", "api.anthropic.com", "claude.ai", "chatgpt.com"}
+	}
+
+	var line func(ts time.Time, ip, domain, method, path string, status, bytes int) string
+	switch strings.ToLower(opts.Format) {
+	case "squid":
+		line = squidLine
+	case "dns":
+		line = dnsLine
+	case "csv":
+		line = csvLine
+	default:
+		return fmt.Errorf("unsupported format %q (use squid, dns, or csv)", opts.Format)
+	}
+	pattern := strings.ToLower(opts.Pattern)
+	switch pattern {
+	case "", "even", "random", "business":
+	default:
+		return fmt.Errorf("unsupported pattern %q (use %s)", opts.Pattern, strings.Join(Patterns, ", "))
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	bw := bufio.NewWriterSize(w, 1<<16)
+	if strings.EqualFold(opts.Format, "csv") {
+		fmt.Fprintln(bw, "timestamp,source_ip,destination,action,bytes,protocol")
+	}
+
+	ts := opts.Start
+	for i := 0; i < opts.Lines; i++ {
+		user := rng.Intn(opts.Users)
+		ip := fmt.Sprintf("10.%d.%d.%d", user/62500%250, user/250%250, user%250+1)
+		domain, path, method := benignDomains[rng.Intn(len(benignDomains))], benignPaths[rng.Intn(len(benignPaths))], "GET"
+		if rng.Float64() < opts.AIRatio {
+			domain, path = opts.AIDomains[rng.Intn(len(opts.AIDomains))], aiPaths[rng.Intn(len(aiPaths))]
+			if strings.HasPrefix(path, "/v1/") || strings.HasPrefix(path, "/api/") {
+				method = "POST"
+			}
+		}
+		status := 200
+		if rng.Intn(50) == 0 {
+			status = 403
+		}
+		if _, err := bw.WriteString(line(ts, ip, domain, method, path, status, 200+rng.Intn(8000))); err != nil {
+			return err
+		}
+		switch pattern {
+		case "random":
+			ts = ts.Add(time.Duration(rng.ExpFloat64() * float64(opts.Interval)))
+		case "business":
+			ts = ts.Add(time.Duration(rng.ExpFloat64() * float64(opts.Interval) / intensity(ts)))
+		default:
+			ts = ts.Add(opts.Interval)
+		}
+	}
+	return bw.Flush()
+}
+
+func squidLine(ts time.Time, ip, domain, method, path string, status, bytes int) string {
+	action := "TCP_MISS"
+	if status == 403 {
+		action = "TCP_DENIED"
+	}
+	return fmt.Sprintf("%d.000 %6d %s %s/%d %d %s https://%s%s - DIRECT/%s text/html\n",
+		ts.Unix(), 50+bytes%300, ip, action, status, bytes, method, domain, path, domain)
+}
+
+func dnsLine(ts time.Time, ip, domain, _, _ string, _, _ int) string {
+	return fmt.Sprintf("%s %s %s A\n", ts.Format(time.RFC3339), ip, domain)
+}
+
+func csvLine(ts time.Time, ip, domain, _, _ string, status, bytes int) string {
+	action := "ALLOW"
+	if status == 403 {
+		action = "DENY"
+	}
+	return fmt.Sprintf("%s,%s,%s,%s,%d,HTTPS\n", ts.Format(time.RFC3339), ip, domain, action, bytes)
+}
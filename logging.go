@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Extra levels around slog's four: trace is shown with -vv, and success is
+// an info message worth a "[+]" in the console.
+const (
+	levelTrace   = slog.LevelDebug - 4
+	levelSuccess = slog.LevelInfo + 1
+)
+
+// logger carries the tool's own diagnostics; reports and findings go to stdout.
+var logger = slog.New(newConsoleHandler(os.Stderr, slog.LevelInfo))
+
+// logFlags are the verbosity and format flags shared by every command.
+type logFlags struct {
+	v, vv  *bool
+	format *string
+}
+
+// addLogFlags registers -v, -vv, and -log-format on fs.
+func addLogFlags(fs *flag.FlagSet) logFlags {
+	return logFlags{
+		v:      fs.Bool("v", false, "Verbose: also log per-file details"),
+		vv:     fs.Bool("vv", false, "Very verbose: also log internal steps such as checkpoints"),
+		format: fs.String("log-format", "text", "Diagnostics format on stderr: text or json"),
+	}
+}
+
+// setup installs the logger the flags describe, writing to w. quietLevel is
+// the level used without -v/-vv, e.g. to keep machine mode down to errors.
+func (f logFlags) setup(w io.Writer, quietLevel slog.Level) error {
+	level := quietLevel
+	switch {
+	case *f.vv:
+		level = levelTrace
+	case *f.v:
+		level = slog.LevelDebug
+	}
+
+	switch strings.ToLower(*f.format) {
+	case "text", "":
+		logger = slog.New(newConsoleHandler(w, level))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level:       level,
+			ReplaceAttr: levelNames,
+		}))
+	default:
+		return fmt.Errorf("unknown -log-format %q (use text or json)", *f.format)
+	}
+	return nil
+}
+
+// textLogs reports whether diagnostics are for a person rather than a collector.
+func (f logFlags) textLogs() bool {
+	return !strings.EqualFold(*f.format, "json")
+}
+
+// logSuccess logs an info message marked as a success.
+func logSuccess(msg string, args ...any) {
+	logger.Log(context.Background(), levelSuccess, msg, args...)
+}
+
+// levelNames gives the extra levels readable names in JSON logs.
+func levelNames(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.LevelKey || len(groups) > 0 {
+		return a
+	}
+	switch a.Value.Any().(slog.Level) {
+	case levelTrace:
+		a.Value = slog.StringValue("TRACE")
+	case levelSuccess:
+		a.Value = slog.StringValue("INFO")
+	}
+	return a
+}
+
+// consoleHandler writes records in the tool's console style:
+//
+//	[*] Parsing access.log format=squid
+//	    -> 1200 entries parsed
+//	[!] Error loading config: open cfg.json: no such file or directory
+//
+// An "err" attribute is appended to the message after a colon.
+type consoleHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("[!] ")
+	case r.Level >= levelSuccess:
+		b.WriteString("[+] ")
+	case r.Level >= slog.LevelInfo:
+		b.WriteString("[*] ")
+	default:
+		b.WriteString("    -> ")
+	}
+	b.WriteString(r.Message)
+
+	var attrs strings.Builder
+	write := func(a slog.Attr) bool {
+		if a.Key == "err" && h.group == "" {
+			b.WriteString(": " + a.Value.String())
+			return true
+		}
+		key := a.Key
+		if h.group != "" {
+			key = h.group + "." + key
+		}
+		attrs.WriteString(" " + key + "=" + consoleValue(a.Value.Resolve().String()))
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(write)
+	line := b.String() + attrs.String()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line+"\n")
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &c
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	if c.group != "" {
+		name = c.group + "." + name
+	}
+	c.group = name
+	return &c
+}
+
+// consoleValue quotes values that would otherwise be ambiguous on one line.
+func consoleValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -1,15 +1,35 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/config"
+	"github.com/shadow-ai-hunter/dbupdate"
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/kafka"
+	"github.com/shadow-ai-hunter/notify"
+	"github.com/shadow-ai-hunter/otlp"
 	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/policy"
+	"github.com/shadow-ai-hunter/redact"
 	"github.com/shadow-ai-hunter/reporter"
+	"github.com/shadow-ai-hunter/sentinel"
+	"github.com/shadow-ai-hunter/spool"
+	"github.com/shadow-ai-hunter/workspace"
 )
 
 const version = "1.0.0"
@@ -27,26 +47,134 @@ const banner = `
 `
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "policy":
+			os.Exit(runPolicy(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "db":
+			os.Exit(runDB(os.Args[2:]))
+		case "update-db":
+			os.Exit(runUpdateDB(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "sign":
+			os.Exit(runSign(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "generate":
+			os.Exit(runGenerate(os.Args[2:]))
+		case "compare":
+			os.Exit(runCompare(os.Args[2:]))
+		case "merge":
+			os.Exit(runMerge(os.Args[2:]))
+		}
+	}
+
 	// CLI flags
-	logFile := flag.String("file", "", "Path to log file to scan")
+	var logFiles stringList
+	flag.Var(&logFiles, "file", "Path to log file to scan (repeatable)")
 	logDir := flag.String("dir", "", "Path to directory of log files to scan")
-	logFormat := flag.String("format", "auto", "Log format: squid, dns, csv, auto (default: auto)")
-	outputFmt := flag.String("output", "table", "Output format: table, json, csv (default: table)")
+	logFormat := flag.String("format", "auto", "Log format: squid, dns, windowsdns, csv, elff, suricata, evtx, mde, sysmon, journal, workspace, auto, or a parser name from -config (default: auto)")
+	outputFmt := flag.String("output", "table", "Output format: table, json, csv, html, pdf (default: table)")
 	outputFile := flag.String("out", "", "Write report to file instead of stdout")
-	servicesDB := flag.String("services", "", "Path to AI services JSON (default: bundled ai_services.json)")
-	customDB := flag.String("custom", "", "Path to additional custom AI services JSON to merge in")
+	servicesDB := flag.String("services", "", "Path to AI services database, JSON, YAML, or TOML (default: bundled ai_services.json)")
+	servicesDir := flag.String("services-dir", "", servicesDirUsage)
+	var customDBs stringList
+	flag.Var(&customDBs, "custom", "Path to additional custom AI services file (JSON, YAML, or TOML) to merge in (repeatable; later files win)")
+	policyFile := flag.String("policy", "", "Path to policy/allowlist JSON; allowed usage is not reported")
+	historyFile := flag.String("history", "", "Append findings to this historical store (.jsonl path, or .db, postgres://, or bolt:// in tagged builds)")
+	baselineFile := flag.String("baseline", "", "Previous JSON report; user/service pairs not in it are tagged as new adoption")
+	configFile := flag.String("config", "", "Path to JSON config file: outputs, redaction, custom parsers, integrations, and daemon schedules (see README)")
+	followMode := flag.Bool("follow", false, "Keep watching the files and report new findings as they are written")
+	daemonMode := flag.Bool("daemon", false, "Run the scans scheduled in -config's daemon section until stopped")
+	kafkaBrokers := flag.String("kafka-brokers", "", "Publish each finding as JSON to Kafka via these brokers, comma-separated host:port (TLS and SASL from -config)")
+	kafkaTopic := flag.String("kafka-topic", "", "Kafka topic for -kafka-brokers (default: shadow-ai-findings)")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "Send findings as OpenTelemetry logs, and the scan as a span, to this OTLP/HTTP collector (e.g. http://localhost:4318)")
+	metricsListen := flag.String("metrics-listen", "", "With -follow, serve Prometheus metrics on /metrics at this address (e.g. 127.0.0.1:9464)")
+	errorsFile := flag.String("errors", "", "Write per-file collection errors as JSON here (default: errors.json beside -out when a file has errors)")
+	resumeFile := flag.String("resume", "", "Checkpoint file: save scan progress here and continue from it after a crash")
+	var listenAddrs stringList
+	flag.Var(&listenAddrs, "listen", "With -follow, also read log lines sent here by Squid's access_log udp:// or tcp:// module (e.g. udp://0.0.0.0:5140; repeatable)")
+	var sentinelQueries stringList
+	flag.Var(&sentinelQueries, "sentinel-query", "Also scan the proxy or DNS records this KQL query returns from the Sentinel workspace in -config, or @file to read it (repeatable)")
+	workspaceSince := flag.Duration("workspace-since", 0, "Also scan Chrome browser events from this far back in the Google Workspace Reports API, using the google_workspace section of -config (e.g. 24h)")
+	var journalUnits stringList
+	flag.Var(&journalUnits, "journal-unit", "Also read this systemd unit's log from the journal via journalctl (e.g. dnsmasq.service; repeatable)")
+	stateFile := flag.String("state", "", "Path to state file recording follow-mode read offsets")
+	incrementalFile := flag.String("incremental", "", "Offset store: scan only what was added to each file since the last run that used it")
+	fullScan := flag.Bool("full", false, "With -incremental, rescan every file in full and record the new offsets")
+	spillDir := flag.String("spill", "", "Keep findings in a temporary file in this directory instead of in memory, for scans with too many to hold")
+	categoryFilter := flag.String("category", "", "Only report these categories, comma-separated (e.g. code-assistant,llm)")
+	onlyAllowed := flag.Bool("only-allowed", false, "Report only requests that reached the AI service (skip blocked attempts)")
+	lookalikes := flag.Bool("lookalikes", false, "Also report internationalized hosts that imitate a known AI domain with look-alike characters")
+	vpn := flag.Bool("vpn", false, "Also report consumer VPN and proxy services, which users may tunnel through to reach AI services")
+	typosquats := flag.Bool("typosquats", false, "Also report hosts that misspell a known AI domain or add words to it, e.g. chatgpt-login.com")
+	businessHours := flag.String("business-hours", "", "Flag AI usage outside this window, e.g. 08:00-18:00")
+	businessDays := flag.String("business-days", "", "Working days for -business-hours (default: mon-fri)")
+	businessTZ := flag.String("business-tz", "", "Timezone for -business-hours, e.g. America/New_York (default: local)")
+	reportTZ := flag.String("tz", "", "Timezone all timestamps are reported in, e.g. Europe/Berlin (default: UTC)")
+	logTZ := flag.String("log-tz", "", "Timezone of logs that record none, such as dnsmasq (default: local)")
+	logYear := flag.Int("year", 0, "Year of log timestamps that record none, such as dnsmasq (default: inferred)")
+	yearFromMtime := flag.Bool("year-from-mtime", false, "Infer missing years from each file's modification time instead of today's date")
+	auditLogFile := flag.String("audit-log", "", "Append a chain-of-custody record of every scan (inputs, hashes, options, operator, outputs) to this JSONL file")
+	operator := flag.String("operator", "", "Name recorded as the operator in -audit-log (default: the OS user)")
+	tenant := flag.String("tenant", "", "Business unit to tag every finding and report with, for multi-tenant scans")
+	redactProfile := flag.String("redact", "", "Redaction profile for the report: full, anonymous, aggregate, or one from -config")
 	showVersion := flag.Bool("version", false, "Show version")
-	quiet := flag.Bool("quiet", false, "Suppress banner")
+	quiet := flag.Bool("quiet", false, "Suppress banner and progress bar")
+	machine := flag.Bool("machine", false, "Machine mode: no banner or progress, one JSON document on stdout")
+	failOnFlag := flag.String("fail-on", "any", "Exit 2 on findings: any, never, low, medium, high, or a minimum count")
+	groupSessions := flag.Bool("sessions", false, "Group findings into sessions per user and service instead of listing each hit")
+	sessionGap := flag.Duration("session-gap", analyzer.DefaultSessionGap, "Idle time that ends a session with -sessions")
+	burst := flag.Int("burst", 0, "Tag findings as automation suspected when one source makes more than N AI requests within -burst-window (0 disables)")
+	burstWindow := flag.Duration("burst-window", analyzer.DefaultBurstWindow, "Span -burst counts requests over")
+	top := flag.Int("top", 0, "Show only the top N rows of each summary table in console output (0 shows all)")
+	maxFindings := flag.Int("max-findings", 0, "List at most N detailed findings in console output; JSON and CSV keep every one (0 shows all)")
+	heatmap := flag.Bool("heatmap", false, "Add hits by weekday and hour, overall and per service, to reports")
+	showSource := flag.Bool("show-source", false, "Show the file and line each finding came from in console output")
+	sortFlag := flag.String("sort", "", "Order detailed findings by time, user, service, bytes, or severity (default: log order)")
+	sortDesc := flag.Bool("desc", false, "Reverse the -sort order")
+	timeout := flag.Duration("timeout", 0, "Stop after this long (e.g. 30m) and report partial results; 0 means no limit")
+	workers := flag.Int("workers", runtime.NumCPU(), "Goroutines parsing each large line-oriented file (1 reads files sequentially)")
+	malformedWarn := flag.Float64("malformed-warn", 50, "Warn when more than this percentage of a file's lines cannot be parsed (0 disables)")
+	var allowDirs stringList
+	flag.Var(&allowDirs, "allow-dir", "Only read logs under this directory, refusing symlinks that lead out (repeatable)")
+	var encryptTo stringList
+	flag.Var(&encryptTo, "encrypt-to", "Encrypt report files to this age recipient, or to those in this file (repeatable)")
+	signKey := flag.String("sign-key", "", "Sign report files with this ed25519 key, writing <file>.sig (see \"sign keygen\")")
+	var otlpHeaders stringList
+	flag.Var(&otlpHeaders, "otlp-header", "Header sent with every OTLP request, as Name=value (repeatable)")
+	logOpts := addLogFlags(flag.CommandLine)
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, banner, version)
 		fmt.Fprintf(os.Stderr, "\nUsage:\n")
 		fmt.Fprintf(os.Stderr, "  shadow-hunter -file <logfile> [options]\n")
 		fmt.Fprintf(os.Stderr, "  shadow-hunter -dir <logdir> [options]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter -daemon -config <config.json>\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter policy simulate -history <store> -proposed <policy.json>\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter db lint|list|search [options]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter bench [-lines N] [-baseline bench.json] [-cpuprofile file] [-memprofile file]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter update-db [-url <https-url>] [-pubkey <key>]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter serve [-listen addr] [-root dir] [-max-jobs N] [-history <store>]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter export bundle -out <bundle.zip> [-history <store>] [-report report.json]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter sign keygen|verify [options]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter replay [-speed N] [-webhook url] [-syslog url] <logfile> ...\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter generate [-format squid|dns|csv] [-lines N] [-ai-percent P] [-pattern business] [-out file]\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter compare [-output table|json] <before.json> <after.json>\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter merge [-output format] [-out file] [site=]report.json ...\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  shadow-hunter -file /var/log/squid/access.log\n")
 		fmt.Fprintf(os.Stderr, "  shadow-hunter -dir /var/log/proxy/ -format squid -output json\n")
 		fmt.Fprintf(os.Stderr, "  shadow-hunter -file firewall.csv -format csv -out report.json -output json\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter -dir /var/log/proxy/ -history findings.jsonl -policy policy.json\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter -file /var/log/squid/access.log -follow -state follow-state.json\n")
+		fmt.Fprintf(os.Stderr, "  shadow-hunter -dir /var/log/squid/ -incremental squid-offsets.json\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
 	}
@@ -58,139 +186,987 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *logFile == "" && *logDir == "" {
+	if !*daemonMode && len(logFiles) == 0 && *logDir == "" && len(listenAddrs) == 0 && len(journalUnits) == 0 && len(sentinelQueries) == 0 && *workspaceSince == 0 {
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
-	if !*quiet {
+	// Machine mode keeps stderr down to errors unless -v asks for more
+	quietLevel := slog.LevelInfo
+	if *machine {
+		quietLevel = slog.LevelError
+	}
+	bar := newProgressBar(os.Stderr, !*quiet && !*machine && logOpts.textLogs())
+	if err := logOpts.setup(bar, quietLevel); err != nil {
+		logger.Error(err.Error())
+		os.Exit(exitError)
+	}
+
+	fail, err := parseFailOn(*failOnFlag)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(exitError)
+	}
+	sortKey, err := analyzer.ParseSortKey(*sortFlag)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(exitError)
+	}
+	if *logYear < 0 || *logYear > 9999 {
+		logger.Error(fmt.Sprintf("-year %d is not a calendar year", *logYear))
+		os.Exit(exitError)
+	}
+	years := yearRule{year: *logYear, useMtime: *yearFromMtime}
+
+	startedAt := time.Now().UTC()
+	if *resumeFile != "" && *followMode {
+		logger.Error("-resume does not apply to -follow; use -state to keep read offsets")
+		os.Exit(exitError)
+	}
+	if *incrementalFile != "" && (*followMode || *resumeFile != "") {
+		logger.Error("-incremental cannot be combined with -follow or -resume")
+		os.Exit(exitError)
+	}
+	if len(journalUnits) > 0 && *incrementalFile != "" {
+		logger.Error("-journal-unit cannot be combined with -incremental; the journal has no file offsets")
+		os.Exit(exitError)
+	}
+	if *fullScan && *incrementalFile == "" {
+		logger.Error("-full applies only to -incremental")
+		os.Exit(exitError)
+	}
+	if *spillDir != "" {
+		switch {
+		case *followMode || *daemonMode || *resumeFile != "":
+			logger.Error("-spill cannot be combined with -follow, -daemon, or -resume")
+			os.Exit(exitError)
+		case *machine || *otlpEndpoint != "":
+			logger.Error("-spill cannot be combined with -machine or -otlp-endpoint")
+			os.Exit(exitError)
+		case *groupSessions || *sortFlag != "":
+			logger.Error("-spill reports findings in log order; it cannot be combined with -sessions or -sort")
+			os.Exit(exitError)
+		}
+	}
+	var listeners []logListener
+	for _, addr := range listenAddrs {
+		l, err := parseListenAddr(addr)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(exitError)
+		}
+		listeners = append(listeners, l)
+	}
+	if len(listeners) > 0 && !*followMode {
+		logger.Error("-listen receives a stream of log lines; use it with -follow")
+		os.Exit(exitError)
+	}
+	if *metricsListen != "" && !*followMode {
+		logger.Error("-metrics-listen applies only to -follow; a one-off scan has nothing to scrape")
+		os.Exit(exitError)
+	}
+	if *burst < 0 || *burstWindow <= 0 {
+		logger.Error("-burst and -burst-window must be positive")
+		os.Exit(exitError)
+	}
+	if *burst > 0 && (*followMode || *daemonMode || *spillDir != "") {
+		logger.Error("-burst needs every finding of a scan in memory; it cannot be combined with -follow, -daemon, or -spill")
+		os.Exit(exitError)
+	}
+	if *daemonMode {
+		switch {
+		case *configFile == "":
+			logger.Error("-daemon needs -config with a daemon section")
+			os.Exit(exitError)
+		case len(logFiles) > 0 || *logDir != "":
+			logger.Error("-daemon takes its files from the config's schedules, not -file or -dir")
+			os.Exit(exitError)
+		case *followMode || *machine || *resumeFile != "":
+			logger.Error("-daemon cannot be combined with -follow, -machine, or -resume")
+			os.Exit(exitError)
+		}
+	}
+	var exporter *otlp.Exporter
+	if *otlpEndpoint != "" {
+		headers := make(map[string]string)
+		for _, h := range otlpHeaders {
+			name, value, err := otlp.ParseHeader(h)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(exitError)
+			}
+			headers[name] = value
+		}
+		var err error
+		if exporter, err = otlp.New(*otlpEndpoint, headers, version); err != nil {
+			logger.Error(err.Error())
+			os.Exit(exitError)
+		}
+	} else if len(otlpHeaders) > 0 {
+		logger.Error("-otlp-header needs -otlp-endpoint")
+		os.Exit(exitError)
+	}
+	if *machine {
+		if *followMode {
+			logger.Error("-machine cannot be combined with -follow")
+			os.Exit(exitError)
+		}
+	}
+	if !*quiet && !*machine && logOpts.textLogs() {
 		fmt.Fprintf(os.Stderr, banner, version)
 	}
 
-	// Resolve services DB path
-	svcPath := *servicesDB
-	if svcPath == "" {
-		// Look for ai_services.json next to the binary
-		exe, err := os.Executable()
+	var cfg config.Config
+	if *configFile != "" {
+		loaded, err := config.Load(*configFile)
+		if err != nil {
+			logger.Error("Error loading config", "err", err)
+			os.Exit(exitError)
+		}
+		cfg = *loaded
+	}
+	// Checked before anything is scanned or stored, so a profile that cannot
+	// be applied, such as one pseudonymizing without a salt, fails fast
+	redaction, err := redact.Lookup(*redactProfile, cfg.RedactionProfiles)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(exitError)
+	}
+
+	var custom []customParser
+	for _, def := range cfg.Parsers {
+		p, err := def.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			os.Exit(exitError)
+		}
+		custom = append(custom, customParser{parser: p, files: def.Files})
+	}
+
+	actions, err := newActions(cfg.Actions)
+	if err != nil {
+		logger.Error("Error in config", "err", err)
+		os.Exit(exitError)
+	}
+	var mailer notify.Server
+	if cfg.SMTP != nil {
+		if mailer, err = cfg.SMTP.Build(); err != nil {
+			logger.Error("Error in SMTP settings", "err", err)
+			os.Exit(exitError)
+		}
+	}
+	if *followMode && len(actions) > 0 {
+		logger.Warn("Actions run once a scan is analyzed, so -follow does not run them")
+	}
+
+	var multiline *parsers.Multiline
+	if cfg.Multiline != nil {
+		m, err := cfg.Multiline.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			os.Exit(exitError)
+		}
+		multiline = &m
+	}
+
+	// Kafka: flags override the config file
+	kafkaCfg := config.Kafka{}
+	if cfg.Kafka != nil {
+		kafkaCfg = *cfg.Kafka
+	}
+	if *kafkaBrokers != "" {
+		kafkaCfg.Brokers = strings.Split(*kafkaBrokers, ",")
+	}
+	if *kafkaTopic != "" {
+		kafkaCfg.Topic = *kafkaTopic
+	}
+	var producer *kafka.Producer
+	if len(kafkaCfg.Brokers) > 0 {
+		if kafkaCfg.Topic == "" {
+			kafkaCfg.Topic = "shadow-ai-findings"
+		}
+		kc, err := kafkaCfg.Build()
 		if err == nil {
-			candidate := filepath.Join(filepath.Dir(exe), "ai_services.json")
-			if _, err := os.Stat(candidate); err == nil {
-				svcPath = candidate
-			}
+			producer, err = kafka.NewProducer(kc)
 		}
-		// Fallback: current directory
-		if svcPath == "" {
-			svcPath = "ai_services.json"
+		if err != nil {
+			logger.Error("Error in Kafka settings", "err", err)
+			os.Exit(exitError)
 		}
+	} else if *kafkaTopic != "" {
+		logger.Error("-kafka-topic needs -kafka-brokers or brokers in -config")
+		os.Exit(exitError)
 	}
 
+	// Sentinel: findings go to the workspace when the config names a DCR
+	var ingestor *sentinel.Ingestor
+	var querier *sentinel.Querier
+	if cfg.Sentinel != nil {
+		ingestor, err = cfg.Sentinel.Ingestor()
+		if err == nil && len(sentinelQueries) > 0 {
+			querier, err = cfg.Sentinel.Querier()
+		}
+		if err != nil {
+			logger.Error("Error in Sentinel settings", "err", err)
+			os.Exit(exitError)
+		}
+	}
+	queries, err := loadSentinelQueries(sentinelQueries)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(exitError)
+	}
+	if len(queries) > 0 {
+		switch {
+		case querier == nil:
+			logger.Error("-sentinel-query needs a sentinel section with workspace_id in -config")
+			os.Exit(exitError)
+		case *followMode:
+			logger.Error("-sentinel-query runs once; it cannot be combined with -follow")
+			os.Exit(exitError)
+		}
+	}
+
+	var chrome *workspace.Client
+	if *workspaceSince != 0 {
+		switch {
+		case *workspaceSince < 0:
+			logger.Error("-workspace-since must be positive")
+			os.Exit(exitError)
+		case cfg.GoogleWorkspace == nil:
+			logger.Error("-workspace-since needs a google_workspace section in -config")
+			os.Exit(exitError)
+		case *followMode:
+			logger.Error("-workspace-since runs once; it cannot be combined with -follow")
+			os.Exit(exitError)
+		}
+		if chrome, err = cfg.GoogleWorkspace.Build(); err != nil {
+			logger.Error("Error in Google Workspace settings", "err", err)
+			os.Exit(exitError)
+		}
+	}
+
+	// Outputs from config replace the single -output/-out destination
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []config.Output{{Format: *outputFmt, Path: *outputFile, Profile: *redactProfile}}
+	}
+
+	// Sealing: flags add to the config file's recipients and replace its key
+	sealCfg := config.Seal{}
+	if cfg.Seal != nil {
+		sealCfg = *cfg.Seal
+	}
+	sealCfg.EncryptTo = append(sealCfg.EncryptTo, encryptTo...)
+	if *signKey != "" {
+		sealCfg.SignKey = *signKey
+	}
+	sealer, err := sealCfg.Build()
+	if err != nil {
+		logger.Error("Error in report sealing", "err", err)
+		os.Exit(exitError)
+	}
+	if sealer != nil && !*daemonMode && !slices.ContainsFunc(outputs, func(o config.Output) bool { return o.Path != "" && o.Path != "-" }) {
+		logger.Error("-encrypt-to and -sign-key apply to report files; set -out or outputs in -config")
+		os.Exit(exitError)
+	}
+
+	svcPath := resolveServicesPath(*servicesDB)
+
 	// Initialize analyzer
 	az, err := analyzer.New(svcPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[!] Error loading AI services database: %v\n", err)
-		os.Exit(1)
+		logger.Error("Error loading AI services database", "err", err)
+		os.Exit(exitError)
 	}
 
-	if *customDB != "" {
-		if err := az.LoadCustomDomains(*customDB); err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Error loading custom domains: %v\n", err)
-			os.Exit(1)
+	if *servicesDir != "" {
+		conflicts, err := az.LoadServicesDir(*servicesDir)
+		if err != nil {
+			logger.Error("Error loading services directory", "err", err)
+			os.Exit(exitError)
+		}
+		for _, c := range conflicts {
+			logger.Warn(fmt.Sprintf("Domain %s from %s overrides %s (now %s)", c.Domain, *servicesDir, c.Previous, c.Service))
 		}
 	}
 
-	fmt.Fprintf(os.Stderr, "[*] Loaded %d AI services (%d domains)\n", az.ServiceCount(), az.DomainCount())
+	for _, path := range customDBs {
+		conflicts, err := az.LoadCustomDomains(path)
+		if err != nil {
+			logger.Error("Error loading custom domains", "path", path, "err", err)
+			os.Exit(exitError)
+		}
+		for _, c := range conflicts {
+			logger.Warn(fmt.Sprintf("Custom domain %s overrides %s (now %s)", c.Domain, c.Previous, c.Service), "path", path)
+		}
+	}
 
-	// Collect log files to scan
-	var files []string
-	if *logFile != "" {
-		files = append(files, *logFile)
+	logger.Info(fmt.Sprintf("Loaded %d AI services (%d domains)", az.ServiceCount(), az.DomainCount()), dbAttrs(az, "services", svcPath)...)
+
+	// Business hours: flags override the config file
+	bhCfg := config.BusinessHours{}
+	if cfg.BusinessHours != nil {
+		bhCfg = *cfg.BusinessHours
+	}
+	if *businessHours != "" {
+		bhCfg.Hours = *businessHours
+	}
+	if *businessDays != "" {
+		bhCfg.Days = *businessDays
+	}
+	if *businessTZ != "" {
+		bhCfg.Timezone = *businessTZ
+	}
+	if bhCfg.Hours != "" {
+		bh, err := analyzer.ParseBusinessHours(bhCfg.Hours, bhCfg.Days, bhCfg.Timezone)
+		if err != nil {
+			logger.Error("Error in business hours", "err", err)
+			os.Exit(exitError)
+		}
+		az.SetBusinessHours(bh)
+	}
+
+	// Timezones: flags override the config file
+	tzCfg := config.Timezones{}
+	if cfg.Timezones != nil {
+		tzCfg = *cfg.Timezones
+	}
+	if *reportTZ != "" {
+		tzCfg.Report = *reportTZ
+	}
+	if *logTZ != "" {
+		tzCfg.Logs = *logTZ
 	}
+	zones, err := tzCfg.Build()
+	if err != nil {
+		logger.Error("Error in timezones", "err", err)
+		os.Exit(exitError)
+	}
+	az.SetTimezones(zones)
+	az.SetLookalikes(*lookalikes)
+	az.SetTyposquats(*typosquats)
+	az.SetVPN(*vpn)
+	az.SetTenant(*tenant)
+	if cfg.URLRedaction != nil {
+		scrubber, err := cfg.URLRedaction.Build()
+		if err != nil {
+			logger.Error("Error in config", "err", err)
+			os.Exit(exitError)
+		}
+		az.SetURLScrubber(scrubber)
+	}
+
+	var pol *policy.Policy
+	if *policyFile != "" {
+		pol, err = policy.Load(*policyFile)
+		if err != nil {
+			logger.Error("Error loading policy", "err", err)
+			os.Exit(exitError)
+		}
+		logger.Info("Applying policy", "policy", pol.Name)
+	}
+
+	if len(allowDirs) > 0 {
+		if err := fsutil.Restrict(allowDirs); err != nil {
+			logger.Error("Error restricting file access", "err", err)
+			os.Exit(exitError)
+		}
+	}
+
+	var audit *auditLog
+	if *auditLogFile != "" {
+		if *followMode {
+			logger.Error("-audit-log records scans that finish; it does not apply to -follow")
+			os.Exit(exitError)
+		}
+		audit = &auditLog{path: *auditLogFile, operator: operatorName(*operator)}
+	}
+
+	if *daemonMode {
+		if cfg.Daemon == nil {
+			logger.Error("No daemon section in config", "config", *configFile)
+			os.Exit(exitError)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		code := runDaemon(ctx, daemonOptions{
+			cfg:       cfg,
+			az:        az,
+			custom:    custom,
+			multiline: multiline,
+			policy:    pol,
+			years:     years,
+			warnRatio: *malformedWarn / 100,
+			workers:   *workers,
+			redact:    *redactProfile,
+			tenant:    *tenant,
+			otlp:      exporter,
+			kafka:     producer,
+			sentinel:  ingestor,
+			sealer:    sealer,
+			audit:     audit,
+		})
+		os.Exit(code)
+	}
+
+	// Collect log files to scan
+	files := append([]string{}, logFiles...)
 	if *logDir != "" {
 		dirFiles, err := collectFiles(*logDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Error reading directory: %v\n", err)
-			os.Exit(1)
+			logger.Error("Error reading directory", "err", err)
+			os.Exit(exitError)
 		}
 		files = append(files, dirFiles...)
 	}
+	files = uniqueFiles(files)
 
-	if len(files) == 0 {
-		fmt.Fprintln(os.Stderr, "[!] No log files found to scan.")
-		os.Exit(1)
+	if len(files) == 0 && len(listeners) == 0 && len(journalUnits) == 0 && len(queries) == 0 && chrome == nil {
+		logger.Error("No log files found to scan.")
+		os.Exit(exitError)
+	}
+	var journal *parsers.JournalParser
+	if len(journalUnits) > 0 {
+		if journal, err = journalParser(*logFormat, custom); err != nil {
+			logger.Error(err.Error())
+			os.Exit(exitError)
+		}
 	}
 
-	fmt.Fprintf(os.Stderr, "[*] Scanning %d file(s)...\n", len(files))
+	// Baseline of known user/service pairs for first-seen detection
+	var known []analyzer.Finding
+	var store *history.Store
+	if *historyFile != "" {
+		store, err = history.Open(*historyFile)
+		if err != nil {
+			logger.Error("Error opening history", "err", err)
+			os.Exit(exitError)
+		}
+		defer store.Close()
+		prior, err := store.Findings()
+		if err != nil {
+			logger.Error("Error reading history", "err", err)
+			os.Exit(exitError)
+		}
+		known = append(known, analyzer.OfTenant(prior, *tenant)...)
+	}
+	if *baselineFile != "" {
+		prev, err := reporter.LoadJSONReport(*baselineFile)
+		if err != nil {
+			logger.Error("Error loading baseline", "err", err)
+			os.Exit(exitError)
+		}
+		known = append(known, prev.Findings...)
+	}
+	baseline := analyzer.NewBaseline(known)
 
-	// Parse all files
-	var allEntries []parsers.LogEntry
-	for _, f := range files {
-		p := selectParser(*logFormat, f)
-		if p == nil {
-			fmt.Fprintf(os.Stderr, "[!] Skipping %s — could not determine format\n", f)
-			continue
+	// Ctrl-C or -timeout stops the scan; what was read so far is still reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *followMode {
+		opts := followOptions{
+			format:      *logFormat,
+			outputFmt:   *outputFmt,
+			statePath:   *stateFile,
+			onlyAllowed: *onlyAllowed,
+			policy:      pol,
+			baseline:    baseline,
+			custom:      custom,
+			multiline:   multiline,
+			fail:        fail,
+			years:       years,
+			otlp:        exporter,
+			kafka:       producer,
+			sentinel:    ingestor,
+			listeners:   listeners,
+			units:       journalUnits,
+			journal:     journal,
+		}
+		if *categoryFilter != "" {
+			opts.categories = strings.Split(*categoryFilter, ",")
+		}
+		if *historyFile != "" {
+			opts.history = store
+		}
+		if *metricsListen != "" {
+			opts.metrics = newScanMetrics()
+			if err := serveMetrics(ctx, *metricsListen, opts.metrics); err != nil {
+				logger.Error("Error serving metrics", "err", err)
+				os.Exit(exitError)
+			}
 		}
-		fmt.Fprintf(os.Stderr, "[*] Parsing %s (%s format)\n", f, p.Name())
+		code := followFiles(ctx, files, az, opts)
+		if store != nil {
+			store.Close()
+		}
+		os.Exit(code)
+	}
 
-		entries, err := p.Parse(f)
+	logger.Info(fmt.Sprintf("Scanning %d file(s)...", len(files)))
+
+	// Parse and match each file, measuring how much detail each source provides
+	scanner := &fileScanner{ctx: ctx, az: az, format: *logFormat, custom: custom, warnRatio: *malformedWarn / 100, years: years, workers: *workers, progress: bar}
+	if *resumeFile != "" {
+		cp, err := loadCheckpoint(*resumeFile, az.DBHash(), *logFormat)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Error parsing %s: %v\n", f, err)
-			continue
+			logger.Error(err.Error())
+			os.Exit(exitError)
+		}
+		if len(cp.Files) > 0 {
+			logger.Info("Resuming scan", "checkpoint", *resumeFile)
+		}
+		scanner.checkpoint = cp
+	}
+	if *incrementalFile != "" {
+		inc, err := loadIncremental(*incrementalFile)
+		if err != nil {
+			logger.Error("Error loading offsets", "err", err)
+			os.Exit(exitError)
+		}
+		inc.full = *fullScan
+		files = inc.withRotated(files)
+		scanner.incremental = inc
+	}
+	if *spillDir != "" {
+		if scanner.spool, err = spool.New(*spillDir); err != nil {
+			logger.Error(err.Error())
+			os.Exit(exitError)
 		}
-		fmt.Fprintf(os.Stderr, "    -> %d entries parsed\n", len(entries))
-		allEntries = append(allEntries, entries...)
+	}
+
+	sources := append([]string{}, files...)
+	for _, unit := range journalUnits {
+		sources = append(sources, journalSource(unit))
+	}
+	for _, q := range queries {
+		sources = append(sources, q.source)
+	}
+	if chrome != nil {
+		sources = append(sources, workspaceSource)
+	}
+	bar.begin(sources)
+	out := scanner.scanAll(files, multiline)
+	scanner.scanJournals(&out, journalUnits, journal)
+	scanner.scanSentinel(&out, querier, queries)
+	if chrome != nil {
+		scanner.scanWorkspace(&out, chrome, startedAt.Add(-*workspaceSince))
+	}
+	scanned := out.files
+	if scanner.spillErr != nil {
+		bar.finish()
+		logger.Error("Error spilling findings to disk", "err", scanner.spillErr)
+		os.Exit(exitError)
+	}
+
+	bar.finish()
+	stop() // a second Ctrl-C while reporting exits immediately
+	if out.partial {
+		logger.Warn("Scan stopped early; reporting partial results", "reason", context.Cause(ctx))
+	}
+	if !*machine && logOpts.textLogs() && logger.Enabled(context.Background(), slog.LevelInfo) {
+		reporter.ReportFileStats(scanned, os.Stderr)
 	}
 
 	// Analyze
-	fmt.Fprintln(os.Stderr, "[*] Analyzing for shadow AI activity...")
-	summary := az.Analyze(allEntries)
+	logger.Info("Analyzing for shadow AI activity...")
+	summary := analyzer.Summarize(out.findings, out.logsScanned)
+	summary.Sources = out.sources
+	summary.Partial = out.partial
+	summary.Tenant = *tenant
+	summary.Scan = out.meta(az, startedAt)
 
-	// Report
-	outFmt := reporter.Format(strings.ToLower(*outputFmt))
-	if *outputFile != "" {
-		if err := reporter.WriteToFile(summary, outFmt, *outputFile); err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Error writing report: %v\n", err)
-			os.Exit(1)
+	// Under -spill the findings stay on disk: one pass over them does what
+	// the steps below do to the in-memory summary
+	var kept *spool.Spool
+	failing := 0
+	if scanner.spool != nil {
+		pass := spillPass{baseline: baseline, history: store, fail: fail, keep: func(f analyzer.Finding) bool {
+			return !pol.Allows(f) && (!*onlyAllowed || !f.Blocked)
+		}}
+		if *categoryFilter != "" {
+			inCategories := analyzer.InCategories(strings.Split(*categoryFilter, ","))
+			allowed := pass.keep
+			pass.keep = func(f analyzer.Finding) bool { return allowed(f) && inCategories(f) }
+		}
+		summary, kept, failing, err = pass.run(scanner.spool, *spillDir, summary)
+		scanner.spool.Close()
+		if err != nil {
+			logger.Error("Error processing spilled findings", "err", err)
+			os.Exit(exitError)
+		}
+		if store != nil {
+			if err := store.Close(); err != nil {
+				logger.Error("Error recording history", "err", err)
+				os.Exit(exitError)
+			}
 		}
-		fmt.Fprintf(os.Stderr, "[+] Report written to %s\n", *outputFile)
 	} else {
-		if err := reporter.Report(summary, outFmt, os.Stdout); err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Error generating report: %v\n", err)
-			os.Exit(1)
+		summary = analyzer.TagNewAdoption(summary, baseline)
+		summary = analyzer.TagBursts(summary, *burst, *burstWindow)
+
+		// History stores every detection so policy changes can be simulated later
+		if *historyFile != "" {
+			if err := store.Append(time.Now().UTC(), summary.Findings); err != nil {
+				logger.Error("Error recording history", "err", err)
+				os.Exit(exitError)
+			}
+			if err := store.Close(); err != nil {
+				logger.Error("Error recording history", "err", err)
+				os.Exit(exitError)
+			}
+		}
+
+		summary = pol.Apply(summary)
+		if *onlyAllowed {
+			summary = summary.Filter(func(f analyzer.Finding) bool { return !f.Blocked })
+		}
+		if *categoryFilter != "" {
+			summary = summary.Filter(analyzer.InCategories(strings.Split(*categoryFilter, ",")))
+		}
+	}
+
+	analyzer.SortFindings(summary.Findings, sortKey, *sortDesc)
+	summary.Layout.Top = *top
+	summary.Layout.MaxFindings = *maxFindings
+	summary.Layout.ShowSource = *showSource
+	summary.Layout.Heatmap = *heatmap
+	if *groupSessions {
+		summary.Layout.SessionGap = *sessionGap
+	}
+
+	exitCode := fail.exitCode(summary.Findings)
+	if kept != nil {
+		exitCode = fail.exitCodeFor(failing)
+	}
+	uncollected := slices.ContainsFunc(scanned, reporter.ScanFile.Uncollected)
+	if (summary.Partial || uncollected) && exitCode == exitClean {
+		exitCode = exitError // an incomplete scan is not a clean one
+	}
+
+	// Report
+	var written []auditFile
+	for _, out := range outputs {
+		profile, err := redact.Lookup(out.Profile, cfg.RedactionProfiles)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(exitError)
+		}
+		redacted := profile.Apply(summary)
+		outFmt := reporter.Format(strings.ToLower(out.Format))
+
+		if out.Path != "" && out.Path != "-" {
+			err = sealer.WriteFile(out.Path, func(w io.Writer) error {
+				if kept != nil {
+					return reporter.ReportSpilled(redacted, spooledFindings(kept, profile), outFmt, w)
+				}
+				return reporter.Report(redacted, outFmt, w)
+			})
+			if err != nil {
+				logger.Error("Error writing report", "err", err)
+				os.Exit(exitError)
+			}
+			logSuccess("Report written", "path", out.Path)
+			if audit != nil {
+				written = append(written, hashFile(out.Path))
+			}
+		} else if !*machine {
+			stdout := newHashingWriter(os.Stdout)
+			if kept != nil {
+				err = reporter.ReportSpilled(redacted, spooledFindings(kept, profile), outFmt, stdout)
+			} else {
+				err = reporter.Report(redacted, outFmt, stdout)
+			}
+			if err != nil {
+				logger.Error("Error generating report", "err", err)
+				os.Exit(exitError)
+			}
+			written = append(written, stdout.file("-"))
+		}
+	}
+
+	if exporter != nil {
+		scan := otlp.Scan{
+			Start:    startedAt,
+			End:      time.Now().UTC(),
+			Files:    len(scanned),
+			Entries:  out.logsScanned,
+			Findings: len(summary.Findings),
+			Partial:  summary.Partial,
+			Tenant:   summary.Tenant,
+		}
+		// The scan may have been stopped by -timeout; the export gets its own
+		if err := exporter.ExportScan(context.Background(), scan, redaction.Apply(summary).Findings); err != nil {
+			logger.Error("Error exporting to OTLP", "err", err)
+			os.Exit(exitError)
+		}
+		logSuccess("Findings exported over OTLP", "endpoint", *otlpEndpoint)
+	}
+	if producer != nil {
+		var sent int
+		if kept != nil {
+			sent, err = sendSpooled(spooledFindings(kept, redaction), func(batch []analyzer.Finding) error {
+				return publishFindings(context.Background(), producer, batch)
+			})
+		} else {
+			findings := redaction.Apply(summary).Findings
+			sent, err = len(findings), publishFindings(context.Background(), producer, findings)
+		}
+		if err != nil {
+			logger.Error("Error publishing to Kafka", "err", err)
+			os.Exit(exitError)
+		}
+		logSuccess(fmt.Sprintf("Published %d finding(s) to Kafka", sent), "topic", kafkaCfg.Topic)
+	}
+	if ingestor != nil {
+		var sent int
+		if kept != nil {
+			sent, err = sendSpooled(spooledFindings(kept, redaction), func(batch []analyzer.Finding) error {
+				return ingestor.Send(context.Background(), batch)
+			})
+		} else {
+			findings := redaction.Apply(summary).Findings
+			sent, err = len(findings), ingestor.Send(context.Background(), findings)
+		}
+		if err != nil {
+			logger.Error("Error sending findings to Sentinel", "err", err)
+			os.Exit(exitError)
+		}
+		logSuccess(fmt.Sprintf("Sent %d finding(s) to Sentinel", sent), "stream", ingestor.Stream())
+	}
+	if len(actions) > 0 {
+		src := findingsOf(summary.Findings)
+		if kept != nil {
+			src = kept.Each
+		}
+		if err := runActions(context.Background(), actions, src, summary, mailer, cfg.RedactionProfiles, startedAt); err != nil {
+			logger.Error("Error running actions", "err", err)
+			os.Exit(exitError)
+		}
+	}
+
+	// Collection problems go to their own artifact, apart from findings
+	errReport := reporter.NewErrorReport(version, scanned)
+	if path := errorReportPath(*errorsFile, outputs, len(errReport.Files) > 0); path != "" {
+		if err := reporter.WriteErrorReportFile(errReport, path); err != nil {
+			logger.Error("Error writing error report", "err", err)
+			os.Exit(exitError)
+		}
+		if len(errReport.Files) > 0 {
+			logger.Warn(fmt.Sprintf("%d file(s) had collection errors", len(errReport.Files)), "details", path)
+		}
+		written = append(written, hashFile(path))
+	}
+
+	if audit != nil {
+		rec := newAuditRecord(audit, "scan", az, startedAt)
+		for _, path := range []string{*configFile, *policyFile, *baselineFile} {
+			if path != "" {
+				rec.Settings = append(rec.Settings, hashFile(path))
+			}
+		}
+		rec.Inputs = scannedFiles(scanned)
+		rec.Outputs = written
+		rec.FinishedAt = time.Now().UTC()
+		rec.LogsScanned, rec.Findings, rec.Partial = out.logsScanned, summary.TotalFindings, summary.Partial
+		if err := audit.append(rec); err != nil {
+			logger.Error("Error writing audit log", "err", err)
+			os.Exit(exitError)
 		}
 	}
 
 	if summary.TotalFindings > 0 {
-		fmt.Fprintf(os.Stderr, "[!] ALERT: %d shadow AI connections detected from %d unique users\n",
-			summary.TotalFindings, summary.UniqueUsers)
+		logger.Warn(fmt.Sprintf("ALERT: %d shadow AI connections detected (%d blocked) from %d unique users",
+			summary.TotalFindings, summary.BlockedFindings, summary.UniqueUsers))
+	} else if uncollected {
+		logger.Warn("No shadow AI activity detected, but some inputs could not be scanned")
 	} else {
-		fmt.Fprintln(os.Stderr, "[+] No shadow AI activity detected. Clean scan.")
+		logSuccess("No shadow AI activity detected. Clean scan.")
+	}
+
+	if *machine {
+		info := reporter.ScanInfo{
+			Version:    version,
+			StartedAt:  startedAt,
+			FinishedAt: time.Now().UTC(),
+			ExitCode:   exitCode,
+			Files:      scanned,
+		}
+		if err := reporter.ReportMachine(info, redaction.Apply(summary), os.Stdout); err != nil {
+			logger.Error("Error generating report", "err", err)
+			os.Exit(exitError)
+		}
+	}
+
+	// The results are out; the next -incremental run starts after them
+	if scanner.incremental != nil {
+		if err := scanner.incremental.save(); err != nil {
+			logger.Error("Error saving offsets", "err", err)
+			os.Exit(exitError)
+		}
+	}
+
+	if kept != nil {
+		kept.Close()
+	}
+
+	// The results are out; a rerun should start over rather than resume
+	if scanner.checkpoint != nil && !summary.Partial {
+		if err := scanner.checkpoint.remove(); err != nil {
+			logger.Error(err.Error())
+		}
+	} else if scanner.checkpoint != nil {
+		logger.Info("Rerun with the same -resume to continue the scan", "checkpoint", *resumeFile)
+	}
+	os.Exit(exitCode)
+}
+
+// resolveServicesPath picks the services database: the -services flag, a
+// copy fetched with update-db, one next to the binary, or the working directory.
+func resolveServicesPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if cached, err := dbupdate.CachePath(); err == nil {
+		if _, err := os.Stat(cached); err == nil {
+			return cached
+		}
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), "ai_services.json")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return "ai_services.json"
+}
+
+// errorReportPath decides where errors.json goes: an explicit -errors path
+// always gets one, otherwise it is written beside the first report file
+// only when some file had errors.
+func errorReportPath(flagValue string, outputs []config.Output, hasErrors bool) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if !hasErrors {
+		return ""
+	}
+	for _, out := range outputs {
+		if out.Path != "" && out.Path != "-" {
+			return filepath.Join(filepath.Dir(out.Path), "errors.json")
+		}
 	}
+	return ""
+}
+
+// customParser is a config-defined parser and the filename globs it claims.
+type customParser struct {
+	parser parsers.Parser
+	files  []string
 }
 
-func selectParser(format, filepath string) parsers.Parser {
+func selectParser(format, filepath string, custom []customParser) parsers.Parser {
+	for _, c := range custom {
+		if strings.EqualFold(format, c.parser.Name()) {
+			return c.parser
+		}
+	}
+	if p, ok := parsers.Lookup(format); ok {
+		return p
+	}
+
 	switch strings.ToLower(format) {
 	case "squid":
 		return &parsers.SquidParser{}
-	case "dns":
+	case "dns", "windowsdns":
 		return &parsers.DNSParser{}
 	case "csv":
 		return &parsers.CSVParser{}
+	case "elff", "w3c":
+		return &parsers.ELFFParser{}
+	case "suricata", "eve":
+		return &parsers.SuricataParser{}
+	case "evtx":
+		return &parsers.EVTXParser{}
+	case "mde", "devicenetworkevents":
+		return &parsers.MDEParser{}
+	case "sysmon":
+		return &parsers.SysmonParser{}
+	case "journal":
+		return &parsers.JournalParser{}
+	case "workspace", "chrome":
+		return &parsers.WorkspaceParser{}
 	case "auto":
-		return autoDetect(filepath)
+		return autoDetect(filepath, custom)
 	default:
-		return autoDetect(filepath)
+		return autoDetect(filepath, custom)
 	}
 }
 
-// autoDetect guesses the parser based on file extension and name.
-func autoDetect(path string) parsers.Parser {
+// autoDetect picks a parser for a file: a custom parser whose globs match
+// its name, otherwise the format that parses the most of its first lines.
+// The filename guess wins ties and covers files with nothing to sniff.
+func autoDetect(path string, custom []customParser) parsers.Parser {
+	for _, c := range custom {
+		for _, glob := range c.files {
+			if ok, _ := filepath.Match(glob, filepath.Base(path)); ok {
+				return c.parser
+			}
+		}
+	}
+
+	guess := guessByName(path)
+	if _, binary := guess.(*parsers.EVTXParser); binary {
+		return guess // there are no lines to sniff
+	}
+	candidates := []parsers.Parser{guess}
+	for _, c := range custom {
+		candidates = append(candidates, c.parser)
+	}
+	candidates = append(candidates, parsers.Registered()...)
+	candidates = append(candidates, &parsers.SquidParser{}, &parsers.DNSParser{}, &parsers.CSVParser{}, &parsers.ELFFParser{}, &parsers.SuricataParser{}, &parsers.MDEParser{}, &parsers.SysmonParser{}, &parsers.JournalParser{}, &parsers.WorkspaceParser{})
+
+	best, score, err := parsers.Sniff(path, candidates)
+	if err != nil || best == nil {
+		return guess
+	}
+	if best.Name() != guess.Name() {
+		logger.Debug(fmt.Sprintf("content looks like %s, not %s as the name suggests", best.Name(), guess.Name()),
+			"file", path, "parsed", fmt.Sprintf("%.0f%%", score*100))
+	}
+	return best
+}
+
+// guessByName guesses the parser from the file's extension and name.
+func guessByName(path string) parsers.Parser {
 	lower := strings.ToLower(path)
 	ext := strings.ToLower(filepath.Ext(path))
 	base := strings.ToLower(filepath.Base(path))
 
+	if strings.Contains(base, "devicenetworkevents") || strings.HasPrefix(base, "mde") {
+		return &parsers.MDEParser{}
+	}
 	if ext == ".csv" {
 		return &parsers.CSVParser{}
 	}
+	if ext == ".evtx" {
+		return &parsers.EVTXParser{}
+	}
+	if strings.Contains(base, "sysmon") {
+		return &parsers.SysmonParser{}
+	}
+	if strings.Contains(base, "elff") || strings.Contains(base, "w3c") || strings.HasPrefix(base, "sg_") || strings.HasPrefix(base, "u_ex") {
+		return &parsers.ELFFParser{}
+	}
+	if strings.HasPrefix(base, "eve") && (ext == ".json" || ext == ".log") || strings.Contains(base, "suricata") {
+		return &parsers.SuricataParser{}
+	}
 	if strings.Contains(base, "dns") || strings.Contains(base, "query") || strings.Contains(base, "dnsmasq") {
 		return &parsers.DNSParser{}
 	}
@@ -202,9 +1178,38 @@ func autoDetect(path string) parsers.Parser {
 	return &parsers.SquidParser{}
 }
 
+// withMultiline applies the config-wide multiline framing to a line format
+// that has none of its own.
+func withMultiline(p parsers.Parser, m *parsers.Multiline) parsers.Parser {
+	if m == nil {
+		return p
+	}
+	if _, framed := p.(*parsers.MultilineParser); framed {
+		return p
+	}
+	if lp, ok := p.(parsers.LineParser); ok {
+		return parsers.NewMultilineParser(lp, *m)
+	}
+	return p
+}
+
+// uniqueFiles drops repeats of a path, keeping the first, so a file named
+// by -file and also found in -dir is scanned once.
+func uniqueFiles(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	out := files[:0]
+	for _, f := range files {
+		if key := filepath.Clean(f); !seen[key] {
+			seen[key] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
 func collectFiles(dir string) ([]string, error) {
 	var files []string
-	entries, err := os.ReadDir(dir)
+	entries, err := fsutil.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
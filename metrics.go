@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/metrics"
+)
+
+// scanMetrics are the counters follow and serve modes expose on /metrics.
+type scanMetrics struct {
+	registry    *metrics.Registry
+	findings    *metrics.Counter
+	entries     *metrics.Counter
+	parseErrors *metrics.Counter
+	jobs        *metrics.Counter // serve mode only; see newJobManager
+}
+
+func newScanMetrics() *scanMetrics {
+	r := metrics.NewRegistry()
+	return &scanMetrics{
+		registry:    r,
+		findings:    r.NewCounter("shadow_ai_findings_total", "AI service hits reported, after policy and filters.", "service", "category"),
+		entries:     r.NewCounter("shadow_ai_entries_parsed_total", "Log records parsed."),
+		parseErrors: r.NewCounter("shadow_ai_parse_errors_total", "Log records that could not be parsed."),
+	}
+}
+
+// observeFinding counts one reported finding.
+func (m *scanMetrics) observeFinding(f analyzer.Finding) {
+	m.findings.Inc(f.ServiceName, f.Category)
+}
+
+// observeScan counts what a scan read and the findings it reported.
+func (m *scanMetrics) observeScan(out scanOutcome, reported []analyzer.Finding) {
+	m.entries.Add(float64(out.logsScanned))
+	malformed := 0
+	for _, f := range out.files {
+		malformed += f.Malformed
+	}
+	m.parseErrors.Add(float64(malformed))
+	for _, f := range reported {
+		m.observeFinding(f)
+	}
+}
+
+// serveMetrics serves /metrics on addr until ctx is cancelled.
+func serveMetrics(ctx context.Context, addr string, m *scanMetrics) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.registry.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	logger.Info("Serving metrics", "listen", ln.Addr().String())
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Error serving metrics", "err", err)
+		}
+	}()
+	return nil
+}
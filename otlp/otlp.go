@@ -0,0 +1,360 @@
+// Package otlp sends findings to an OpenTelemetry collector over OTLP/HTTP
+// with JSON encoding. Findings become log records and each scan becomes a
+// span, with the records linked to it, so shadow-AI activity lands in the
+// same pipeline as other telemetry.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// maxBatch bounds the log records sent in one request.
+const maxBatch = 1000
+
+// Exporter posts to a collector's /v1/logs and /v1/traces endpoints.
+type Exporter struct {
+	endpoint string // base URL, e.g. http://localhost:4318
+	headers  map[string]string
+	version  string
+	client   *http.Client
+}
+
+// New returns an exporter for the collector at endpoint. headers are added
+// to every request, for collectors that need an API key.
+func New(endpoint string, headers map[string]string, version string) (*Exporter, error) {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return nil, fmt.Errorf("OTLP endpoint %q must be an http:// or https:// URL", endpoint)
+	}
+	return &Exporter{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		headers:  headers,
+		version:  version,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// ParseHeader splits a "Name=value" header flag.
+func ParseHeader(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return "", "", fmt.Errorf("OTLP header %q must look like Name=value", s)
+	}
+	return strings.TrimSpace(name), value, nil
+}
+
+// Scan describes one scan run, exported as a span.
+type Scan struct {
+	Start, End time.Time
+	Files      int
+	Entries    int
+	Findings   int
+	Partial    bool
+	Tenant     string
+}
+
+// Trace identifies the span that log records are linked to.
+type Trace struct {
+	TraceID string
+	SpanID  string
+}
+
+// NewTrace returns fresh random trace and span IDs.
+func NewTrace() Trace {
+	return Trace{TraceID: randomHex(16), SpanID: randomHex(8)}
+}
+
+// ExportScan sends the scan as a span and its findings as log records
+// linked to it.
+func (e *Exporter) ExportScan(ctx context.Context, scan Scan, findings []analyzer.Finding) error {
+	trace := NewTrace()
+	if err := e.ExportFindings(ctx, trace, findings); err != nil {
+		return err
+	}
+	return e.exportSpan(ctx, trace, scan)
+}
+
+// ExportFindings sends findings as log records, in batches. A zero trace
+// leaves them unlinked.
+func (e *Exporter) ExportFindings(ctx context.Context, trace Trace, findings []analyzer.Finding) error {
+	for len(findings) > 0 {
+		n := min(len(findings), maxBatch)
+		records := make([]logRecord, n)
+		for i, f := range findings[:n] {
+			records[i] = newLogRecord(f, trace)
+		}
+		body := logsRequest{ResourceLogs: []resourceLogs{{
+			Resource:  e.resource(),
+			ScopeLogs: []scopeLogs{{Scope: e.scope(), LogRecords: records}},
+		}}}
+		if err := e.post(ctx, "/v1/logs", body); err != nil {
+			return err
+		}
+		findings = findings[n:]
+	}
+	return nil
+}
+
+func (e *Exporter) exportSpan(ctx context.Context, trace Trace, scan Scan) error {
+	s := span{
+		TraceID:           trace.TraceID,
+		SpanID:            trace.SpanID,
+		Name:              "shadow-ai-hunter scan",
+		Kind:              1, // SPAN_KIND_INTERNAL
+		StartTimeUnixNano: nanos(scan.Start),
+		EndTimeUnixNano:   nanos(scan.End),
+		Attributes: []keyValue{
+			intAttr("shadow_ai.scan.files", scan.Files),
+			intAttr("shadow_ai.scan.entries", scan.Entries),
+			intAttr("shadow_ai.scan.findings", scan.Findings),
+			boolAttr("shadow_ai.scan.partial", scan.Partial),
+		},
+	}
+	if scan.Tenant != "" {
+		s.Attributes = append(s.Attributes, stringAttr("shadow_ai.tenant", scan.Tenant))
+	}
+	if scan.Partial {
+		s.Status = &spanStatus{Code: 2, Message: "scan stopped before all input was read"} // STATUS_CODE_ERROR
+	}
+	body := tracesRequest{ResourceSpans: []resourceSpans{{
+		Resource:   e.resource(),
+		ScopeSpans: []scopeSpans{{Scope: e.scope(), Spans: []span{s}}},
+	}}}
+	return e.post(ctx, "/v1/traces", body)
+}
+
+func (e *Exporter) post(ctx context.Context, path string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s%s: %s: %s", e.endpoint, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *Exporter) resource() resource {
+	return resource{Attributes: []keyValue{
+		stringAttr("service.name", "shadow-ai-hunter"),
+		stringAttr("service.version", e.version),
+	}}
+}
+
+func (e *Exporter) scope() scope {
+	return scope{Name: "github.com/shadow-ai-hunter", Version: e.version}
+}
+
+// newLogRecord maps a finding onto OpenTelemetry semantic conventions
+// where one exists, and shadow_ai.* attributes otherwise. Severity follows
+// the finding's: low is INFO, medium WARN, high ERROR.
+func newLogRecord(f analyzer.Finding, trace Trace) logRecord {
+	rec := logRecord{
+		ObservedTimeUnixNano: nanos(time.Now()),
+		Body:                 anyValue{StringValue: ptr(fmt.Sprintf("AI service hit: %s (%s) from %s", f.ServiceName, f.Domain, f.Identity()))},
+		TraceID:              trace.TraceID,
+		SpanID:               trace.SpanID,
+	}
+	if !f.Timestamp.IsZero() {
+		rec.TimeUnixNano = nanos(f.Timestamp)
+	}
+	switch sev := f.Severity(); sev {
+	case analyzer.SeverityLow:
+		rec.SeverityNumber, rec.SeverityText = 9, "INFO"
+	case analyzer.SeverityHigh:
+		rec.SeverityNumber, rec.SeverityText = 17, "ERROR"
+	default:
+		rec.SeverityNumber, rec.SeverityText = 13, "WARN"
+	}
+
+	attrs := []keyValue{
+		stringAttr("event.name", "shadow_ai.finding"),
+		stringAttr("shadow_ai.service", f.ServiceName),
+		stringAttr("shadow_ai.category", f.Category),
+		stringAttr("shadow_ai.severity", f.Severity().String()),
+		boolAttr("shadow_ai.blocked", f.Blocked),
+		stringAttr("server.address", f.Domain),
+	}
+	optional := []struct{ key, val string }{
+		{"shadow_ai.provider", f.Provider},
+		{"shadow_ai.activity", string(f.Activity)},
+		{"shadow_ai.detected_by", f.DetectedBy},
+		{"shadow_ai.tenant", f.Tenant},
+		{"client.address", f.SourceIP},
+		{"user.name", f.User},
+		{"url.full", f.URL},
+		{"http.request.method", f.Method},
+		{"user_agent.original", f.UserAgent},
+		{"tls.client.ja3", f.JA3},
+		{"process.executable.name", f.ProcessName},
+		{"host.name", f.DeviceName},
+		{"log.file.path", f.SourceFile},
+	}
+	for _, o := range optional {
+		if o.val != "" {
+			attrs = append(attrs, stringAttr(o.key, o.val))
+		}
+	}
+	if code, err := strconv.Atoi(f.StatusCode); err == nil {
+		attrs = append(attrs, intAttr("http.response.status_code", code))
+	}
+	if f.BytesSent > 0 {
+		attrs = append(attrs, intAttr("http.request.body.size", int(f.BytesSent)))
+	}
+	if f.BytesReceived > 0 {
+		attrs = append(attrs, intAttr("http.response.body.size", int(f.BytesReceived)))
+	}
+	if f.LineNumber > 0 {
+		attrs = append(attrs, intAttr("log.record.line", f.LineNumber))
+	}
+	if f.OffHours {
+		attrs = append(attrs, boolAttr("shadow_ai.off_hours", true))
+	}
+	if f.NewAdoption {
+		attrs = append(attrs, boolAttr("shadow_ai.new_adoption", true))
+	}
+	if f.CloudHosted {
+		attrs = append(attrs, boolAttr("shadow_ai.cloud_hosted", true))
+	}
+	if f.Automated {
+		attrs = append(attrs, boolAttr("shadow_ai.automation_suspected", true), doubleAttr("shadow_ai.rate_per_minute", f.Rate))
+	}
+	rec.Attributes = attrs
+	return rec
+}
+
+// nanos formats a time as the decimal string OTLP/JSON uses for fixed64.
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func stringAttr(key, v string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: &v}}
+}
+
+func intAttr(key string, v int) keyValue {
+	s := strconv.Itoa(v)
+	return keyValue{Key: key, Value: anyValue{IntValue: &s}}
+}
+
+func boolAttr(key string, v bool) keyValue {
+	return keyValue{Key: key, Value: anyValue{BoolValue: &v}}
+}
+
+func doubleAttr(key string, v float64) keyValue {
+	return keyValue{Key: key, Value: anyValue{DoubleValue: &v}}
+}
+
+// The types below are the subset of the OTLP/JSON schema this package
+// writes. Trace and span IDs are hex strings and 64-bit integers decimal
+// strings, as the JSON mapping requires.
+
+type logsRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type logRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano,omitempty"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber"`
+	SeverityText         string     `json:"severityText"`
+	Body                 anyValue   `json:"body"`
+	Attributes           []keyValue `json:"attributes"`
+	TraceID              string     `json:"traceId,omitempty"`
+	SpanID               string     `json:"spanId,omitempty"`
+}
+
+type tracesRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type span struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []keyValue  `json:"attributes"`
+	Status            *spanStatus `json:"status,omitempty"`
+}
+
+type spanStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
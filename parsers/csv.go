@@ -1,19 +1,21 @@
 package parsers
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
 )
 
 // CSVParser handles generic CSV/firewall logs.
 // Expected columns (case-insensitive header matching):
 //
 //	timestamp, source_ip (or src_ip), destination (or dst, domain, host, url),
-//	action (optional), bytes (optional), protocol (optional)
+//	action (optional), status (optional), bytes (optional), protocol (optional)
 type CSVParser struct{}
 
 func (p *CSVParser) Name() string {
@@ -21,7 +23,11 @@ func (p *CSVParser) Name() string {
 }
 
 func (p *CSVParser) Parse(filepath string) ([]LogEntry, error) {
-	file, err := os.Open(filepath)
+	return p.ParseContext(context.Background(), filepath)
+}
+
+func (p *CSVParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("opening %s: %w", filepath, err)
 	}
@@ -40,31 +46,56 @@ func (p *CSVParser) Parse(filepath string) ([]LogEntry, error) {
 		return nil, fmt.Errorf("CSV has no data rows")
 	}
 
+	// Row numbers count the header as line 1
+	entries, err := TableEntries(ctx, records[0], records[1:], filepath, 2)
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("CSV %w", err)
+	}
+	return entries, err
+}
+
+// TableEntries maps rows with the given header onto log entries, finding
+// the columns by name as the CSV parser does. Rows are numbered from
+// firstRow; rows without a destination are dropped. It fails when no
+// column names a destination.
+func TableEntries(ctx context.Context, header []string, rows [][]string, source string, firstRow int) ([]LogEntry, error) {
 	// Map column names to indices
-	colMap := mapColumns(records[0])
+	colMap := mapColumns(header)
 
-	tsCol := findCol(colMap, "timestamp", "time", "date", "datetime")
+	tsCol := findCol(colMap, "timestamp", "time", "date", "datetime", "timegenerated")
 	srcCol := findCol(colMap, "source_ip", "src_ip", "src", "client_ip", "source")
 	dstCol := findCol(colMap, "destination", "dst", "domain", "host", "url", "dest", "dst_host")
-	bytesCol := findCol(colMap, "bytes", "bytes_sent", "size", "content_length")
-	actionCol := findCol(colMap, "action", "status", "status_code", "result")
-
-	if dstCol == -1 {
-		return nil, fmt.Errorf("CSV missing required destination/domain column")
+	sentCol := findCol(colMap, "bytes_sent", "sent_bytes", "sentbyte", "bytes_out", "bytes_toserver", "cs-bytes", "request_bytes")
+	rcvdCol := findCol(colMap, "bytes_received", "received_bytes", "rcvdbyte", "bytes_in", "bytes_toclient", "sc-bytes", "response_bytes",
+		"bytes", "size", "content_length")
+	actionCol := findCol(colMap, "action", "result", "disposition", "verdict")
+	statusCol := findCol(colMap, "status", "status_code", "http_status")
+	userCol := findCol(colMap, "user", "username", "user_name", "cs-username")
+	refererCol := findCol(colMap, "referer", "referrer", "cs(referer)")
+	uaCol := findCol(colMap, "user_agent", "useragent", "user-agent", "cs(user-agent)")
+	sniCol := findCol(colMap, "sni", "server_name", "tls_sni", "ssl_sni", "tls_server_name")
+	ja3Col := findCol(colMap, "ja3", "ja3_hash", "tls_ja3")
+
+	if dstCol == -1 && sniCol == -1 {
+		return nil, fmt.Errorf("missing required destination/domain column")
 	}
 
 	var entries []LogEntry
-	for _, row := range records[1:] {
-		entry := LogEntry{RawLine: strings.Join(row, ",")}
+	for i, row := range rows {
+		if i%ctxCheckLines == 0 && ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		// Quoted newlines inside a CSV row are not counted.
+		entry := LogEntry{RawLine: strings.Join(row, ","), SourceFile: source, LineNumber: i + firstRow}
 
 		if tsCol >= 0 && tsCol < len(row) {
-			entry.Timestamp = parseFlexibleTime(row[tsCol])
+			entry.Timestamp, entry.Floating = parseFlexibleTime(unquoteField(row[tsCol]))
 		}
 		if srcCol >= 0 && srcCol < len(row) {
-			entry.SourceIP = strings.TrimSpace(row[srcCol])
+			entry.SourceIP = unquoteField(row[srcCol])
 		}
 		if dstCol >= 0 && dstCol < len(row) {
-			val := strings.TrimSpace(row[dstCol])
+			val := unquoteField(row[dstCol])
 			entry.Domain = strings.ToLower(val)
 			// If it looks like a URL, extract domain
 			if strings.Contains(val, "://") {
@@ -72,11 +103,45 @@ func (p *CSVParser) Parse(filepath string) ([]LogEntry, error) {
 				entry.Domain = extractDomain(val)
 			}
 		}
-		if bytesCol >= 0 && bytesCol < len(row) {
-			entry.BytesSent, _ = strconv.ParseInt(strings.TrimSpace(row[bytesCol]), 10, 64)
+		if sentCol >= 0 && sentCol < len(row) {
+			entry.BytesSent, _ = strconv.ParseInt(unquoteField(row[sentCol]), 10, 64)
+		}
+		if rcvdCol >= 0 && rcvdCol < len(row) {
+			entry.BytesReceived, _ = strconv.ParseInt(unquoteField(row[rcvdCol]), 10, 64)
 		}
 		if actionCol >= 0 && actionCol < len(row) {
-			entry.StatusCode = strings.TrimSpace(row[actionCol])
+			entry.Action = unquoteField(row[actionCol])
+		}
+		if statusCol >= 0 && statusCol < len(row) {
+			entry.StatusCode = unquoteField(row[statusCol])
+		}
+		if userCol >= 0 && userCol < len(row) {
+			if user := unquoteField(row[userCol]); user != "-" {
+				entry.User = user
+			}
+		}
+		if refererCol >= 0 && refererCol < len(row) {
+			if ref := unquoteField(row[refererCol]); ref != "-" {
+				entry.Referer = ref
+			}
+		}
+		if uaCol >= 0 && uaCol < len(row) {
+			if ua := unquoteField(row[uaCol]); ua != "-" {
+				entry.UserAgent = ua
+			}
+		}
+		if sniCol >= 0 && sniCol < len(row) {
+			if sni := unquoteField(row[sniCol]); sni != "-" {
+				entry.SNI = strings.ToLower(strings.TrimSuffix(sni, "."))
+			}
+		}
+		if ja3Col >= 0 && ja3Col < len(row) {
+			if ja3 := unquoteField(row[ja3Col]); ja3 != "-" {
+				entry.JA3 = strings.ToLower(ja3)
+			}
+		}
+		if entry.Domain == "" {
+			entry.Domain = entry.SNI
 		}
 
 		if entry.Domain != "" {
@@ -90,7 +155,7 @@ func (p *CSVParser) Parse(filepath string) ([]LogEntry, error) {
 func mapColumns(header []string) map[string]int {
 	m := make(map[string]int)
 	for i, col := range header {
-		m[strings.ToLower(strings.TrimSpace(col))] = i
+		m[strings.ToLower(unquoteField(strings.TrimPrefix(col, "\ufeff")))] = i
 	}
 	return m
 }
@@ -104,8 +169,9 @@ func findCol(colMap map[string]int, names ...string) int {
 	return -1
 }
 
-// parseFlexibleTime tries multiple common timestamp formats.
-func parseFlexibleTime(s string) time.Time {
+// parseFlexibleTime tries multiple common timestamp formats. It reports
+// whether the matching format lacked a zone.
+func parseFlexibleTime(s string) (time.Time, bool) {
 	s = strings.TrimSpace(s)
 	formats := []string{
 		time.RFC3339,
@@ -118,8 +184,8 @@ func parseFlexibleTime(s string) time.Time {
 	}
 	for _, f := range formats {
 		if t, err := time.Parse(f, s); err == nil {
-			return t
+			return t, !hasZone(f)
 		}
 	}
-	return time.Time{}
+	return time.Time{}, false
 }
@@ -0,0 +1,103 @@
+package parsers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DelimitedParser splits each line on a delimiter and assigns columns to
+// LogEntry fields by position, for one-off formats that need no pattern:
+//
+//	{"delimiter": "|", "columns": {"ts": 1, "src": 3, "url": 5}}
+//
+// Field names are those RegexParser accepts for its capture groups, and
+// columns count from 1. A single-character delimiter honors CSV quoting; a
+// single space splits on runs of whitespace; anything longer is matched
+// literally.
+type DelimitedParser struct {
+	name       string
+	delimiter  string
+	columns    map[int]string // LogEntry field for each 0-based column
+	timeLayout string
+}
+
+// NewDelimitedParser builds a parser for lines split on delimiter, ","
+// when empty. timeLayout is as for NewRegexParser.
+func NewDelimitedParser(name, delimiter string, columns map[string]int, timeLayout string) (*DelimitedParser, error) {
+	if delimiter == "" {
+		delimiter = ","
+	}
+	if delimiter == `"` || strings.ContainsAny(delimiter, "\r\n") {
+		return nil, fmt.Errorf("parser %s: delimiter %q cannot separate fields", name, delimiter)
+	}
+
+	p := &DelimitedParser{name: name, delimiter: delimiter, columns: make(map[int]string), timeLayout: timeLayout}
+	hasDest := false
+	for key, col := range columns {
+		field, ok := fieldAliases[strings.ToLower(key)]
+		if !ok {
+			return nil, fmt.Errorf("parser %s: unknown field %q", name, key)
+		}
+		if col < 1 {
+			return nil, fmt.Errorf("parser %s: column for %s must be 1 or more, got %d", name, key, col)
+		}
+		if prev, dup := p.columns[col-1]; dup && prev != field {
+			return nil, fmt.Errorf("parser %s: column %d is assigned to both %s and %s", name, col, prev, field)
+		}
+		p.columns[col-1] = field
+		if field == "domain" || field == "url" || field == "sni" {
+			hasDest = true
+		}
+	}
+	if !hasDest {
+		return nil, fmt.Errorf("parser %s needs a domain, url, or sni column", name)
+	}
+	return p, nil
+}
+
+func (p *DelimitedParser) Name() string {
+	return p.name
+}
+
+func (p *DelimitedParser) Parse(filepath string) ([]LogEntry, error) {
+	return parseLines(context.Background(), filepath, p)
+}
+
+func (p *DelimitedParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	return parseLines(ctx, filepath, p)
+}
+
+// ParseLine splits one line and maps its columns.
+func (p *DelimitedParser) ParseLine(line string) (LogEntry, error) {
+	fields, err := p.split(line)
+	if err != nil {
+		return LogEntry{}, err
+	}
+
+	entry := LogEntry{RawLine: line}
+	for i, val := range fields {
+		setField(&entry, p.columns[i], unquoteField(val), p.timeLayout)
+	}
+	return withDestination(entry)
+}
+
+func (p *DelimitedParser) split(line string) ([]string, error) {
+	switch {
+	case p.delimiter == " ":
+		return strings.Fields(line), nil
+	case utf8.RuneCountInString(p.delimiter) == 1:
+		r := csv.NewReader(strings.NewReader(line))
+		r.Comma, _ = utf8.DecodeRuneInString(p.delimiter)
+		r.LazyQuotes = true
+		r.FieldsPerRecord = -1
+		fields, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("splitting line: %w", err)
+		}
+		return fields, nil
+	}
+	return strings.Split(line, p.delimiter), nil
+}
@@ -1,18 +1,20 @@
 package parsers
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // DNSParser handles common DNS query log formats.
-// Supports two formats:
-//   1. Simple: timestamp client_ip query_domain query_type
-//      Example: 2025-06-10T08:30:00Z 192.168.1.50 api.openai.com A
-//   2. Dnsmasq-style: Mon Jun 10 08:30:00 2025 query[A] api.openai.com from 192.168.1.50
+// Supports three formats:
+//  1. Simple: timestamp client_ip query_domain query_type
+//     Example: 2025-06-10T08:30:00Z 192.168.1.50 api.openai.com A
+//  2. Dnsmasq-style: Mon Jun 10 08:30:00 2025 query[A] api.openai.com from 192.168.1.50
+//  3. Windows DNS Server debug log (packet logging):
+//     6/10/2025 8:30:00 AM 0E84 PACKET  000001D2 UDP Rcv 192.168.1.50 d3f1   Q [0001   D   NOERROR] A      (3)api(6)openai(3)com(0)
 type DNSParser struct{}
 
 func (p *DNSParser) Name() string {
@@ -20,42 +22,29 @@ func (p *DNSParser) Name() string {
 }
 
 func (p *DNSParser) Parse(filepath string) ([]LogEntry, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return nil, fmt.Errorf("opening %s: %w", filepath, err)
-	}
-	defer file.Close()
-
-	var entries []LogEntry
-	scanner := bufio.NewScanner(file)
+	return parseLines(context.Background(), filepath, p)
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+func (p *DNSParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	return parseLines(ctx, filepath, p)
+}
 
-		// Try simple format first, then dnsmasq
-		entry, err := parseSimpleDNS(line)
-		if err != nil {
-			entry, err = parseDnsmasq(line)
-		}
-		if err != nil {
-			continue
-		}
-		entries = append(entries, entry)
+// ParseLine parses a single query log line in any supported format.
+func (p *DNSParser) ParseLine(line string) (LogEntry, error) {
+	// Try simple format first, then dnsmasq, then Windows DNS debug
+	entry, err := parseSimpleDNS(line)
+	if err != nil {
+		entry, err = parseDnsmasq(line)
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading %s: %w", filepath, err)
+	if err != nil {
+		entry, err = parseWindowsDNS(line)
 	}
-
-	return entries, nil
+	return entry, err
 }
 
 // parseSimpleDNS parses: 2025-06-10T08:30:00Z 192.168.1.50 api.openai.com A
 func parseSimpleDNS(line string) (LogEntry, error) {
-	fields := strings.Fields(line)
+	fields := unquoteFields(strings.Fields(line))
 	if len(fields) < 3 {
 		return LogEntry{}, fmt.Errorf("not enough fields")
 	}
@@ -113,17 +102,110 @@ func parseDnsmasq(line string) (LogEntry, error) {
 		}
 	}
 
-	ts, err := time.Parse("Jan 2 15:04:05", tsPart)
+	ts, err := parseSyslogTime(tsPart, time.Now())
 	if err != nil {
 		ts = time.Time{} // use zero time if unparseable
-	} else {
-		ts = ts.AddDate(time.Now().Year(), 0, 0)
 	}
 
 	return LogEntry{
 		Timestamp: ts,
+		Floating:  !ts.IsZero(),
+		NoYear:    !ts.IsZero(),
 		SourceIP:  sourceIP,
 		Domain:    domain,
 		RawLine:   line,
 	}, nil
 }
+
+// parseSyslogTime reads a yearless syslog stamp such as "Jun 10 08:30:00",
+// taking the year from now.
+func parseSyslogTime(s string, now time.Time) (time.Time, error) {
+	// Parse with a leap year so "Feb 29" survives until the real year is known.
+	t, err := time.Parse("2006 Jan 2 15:04:05", "2000 "+s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return InferYear(t, now), nil
+}
+
+// InferYear re-dates a timestamp logged without a year to the latest year
+// that puts it no more than a day after ref, so December lines read in
+// January are not dated in the future. Feb 29 goes to the latest leap year.
+// The scanner passes the file's modification time as ref when asked to.
+func InferYear(t, ref time.Time) time.Time {
+	limit := ref.Add(24 * time.Hour)
+	for year := ref.Year() + 1; ; year-- {
+		ts := time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		if ts.Day() == t.Day() && !ts.After(limit) {
+			return ts
+		}
+	}
+}
+
+// parseWindowsDNS parses a Windows DNS Server debug log packet line. Only
+// received queries are kept; responses and sent packets are skipped.
+func parseWindowsDNS(line string) (LogEntry, error) {
+	fields := strings.Fields(line)
+	pktIdx := -1
+	for i, f := range fields {
+		if f == "PACKET" {
+			pktIdx = i
+			break
+		}
+	}
+	// PACKET context proto dir ip xid Q|R ... type name
+	if pktIdx < 2 || len(fields) < pktIdx+9 {
+		return LogEntry{}, fmt.Errorf("not a Windows DNS packet line")
+	}
+	if fields[pktIdx+3] != "Rcv" || fields[pktIdx+6] != "Q" {
+		return LogEntry{}, fmt.Errorf("not a received query")
+	}
+
+	domain := decodeWindowsDNSName(fields[len(fields)-1])
+	if domain == "" {
+		return LogEntry{}, fmt.Errorf("malformed query name")
+	}
+
+	// Date and time are locale-formatted; 12h clocks carry an AM/PM field
+	tsPart := strings.Join(fields[:pktIdx-1], " ")
+	var ts time.Time
+	for _, layout := range []string{"1/2/2006 3:04:05 PM", "1/2/2006 15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, tsPart); err == nil {
+			ts = t
+			break
+		}
+	}
+
+	return LogEntry{
+		Timestamp: ts,
+		Floating:  !ts.IsZero(),
+		SourceIP:  fields[pktIdx+4],
+		Domain:    domain,
+		RawLine:   line,
+	}, nil
+}
+
+// decodeWindowsDNSName converts the length-prefixed form "(3)api(6)openai(3)com(0)"
+// into "api.openai.com".
+func decodeWindowsDNSName(s string) string {
+	var labels []string
+	for len(s) > 0 {
+		if s[0] != '(' {
+			return ""
+		}
+		end := strings.IndexByte(s, ')')
+		if end == -1 {
+			return ""
+		}
+		n, err := strconv.Atoi(s[1:end])
+		if err != nil || end+1+n > len(s) {
+			return ""
+		}
+		if n == 0 {
+			break
+		}
+		labels = append(labels, s[end+1:end+1+n])
+		s = s[end+1+n:]
+	}
+	return strings.ToLower(strings.Join(labels, "."))
+}
@@ -0,0 +1,230 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// ELFFParser handles the W3C Extended Log File Format written by Blue Coat /
+// Symantec ProxySG, IIS, and many other proxies. Columns are named by a
+// "#Fields:" directive, which may change partway through a file:
+//
+//	#Fields: date time c-ip cs-username cs-method cs-host cs-uri-path sc-status s-action sc-bytes
+//	2025-06-10 08:30:00 10.0.0.5 alice GET chat.openai.com / 200 TCP_MISS 5120
+type ELFFParser struct{}
+
+func (p *ELFFParser) Name() string {
+	return "elff"
+}
+
+func (p *ELFFParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+func (p *ELFFParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	var entries []LogEntry
+	var fields elffFields
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		if n%ctxCheckLines == 0 && ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		line := CleanLine(scanner.Text())
+		if f, ok := parseELFFDirective(line); ok {
+			fields = f
+			continue
+		}
+		if fields == nil || strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if entry, err := fields.parse(line); err == nil {
+			entry.SourceFile, entry.LineNumber = filepath, n
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filepath, err)
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("%s has no #Fields directive", filepath)
+	}
+	return entries, nil
+}
+
+// Sniff scores an ELFF sample: without a #Fields directive nothing counts.
+// Directive lines count as parsed, as the CSV header does.
+func (p *ELFFParser) Sniff(lines []string) float64 {
+	var fields elffFields
+	parsed := 0
+	for _, line := range lines {
+		if f, ok := parseELFFDirective(line); ok {
+			fields = f
+			parsed++
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			parsed++
+			continue
+		}
+		if fields == nil {
+			continue
+		}
+		if _, err := fields.parse(line); err == nil {
+			parsed++
+		}
+	}
+	if fields == nil {
+		return 0
+	}
+	return float64(parsed) / float64(len(lines))
+}
+
+// elffFields holds the lowercased field names of the current #Fields directive.
+type elffFields []string
+
+func parseELFFDirective(line string) (elffFields, bool) {
+	rest, ok := strings.CutPrefix(line, "#Fields:")
+	if !ok {
+		return nil, false
+	}
+	names := strings.Fields(strings.ToLower(rest))
+	if len(names) == 0 {
+		return nil, false
+	}
+	return elffFields(names), true
+}
+
+// parse maps one data line onto a LogEntry by field name.
+func (fields elffFields) parse(line string) (LogEntry, error) {
+	values := splitELFF(line)
+	if len(values) != len(fields) {
+		return LogEntry{}, fmt.Errorf("%d values for %d fields", len(values), len(fields))
+	}
+
+	entry := LogEntry{RawLine: line}
+	var date, clock, scheme, host, port, path, query, filterResult string
+	for i, name := range fields {
+		v := values[i]
+		if v == "-" || v == "" {
+			continue
+		}
+		switch name {
+		case "date":
+			date = v
+		case "time":
+			clock = v
+		case "x-timestamp-unix":
+			if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+				entry.Timestamp = time.Unix(sec, 0).UTC()
+			}
+		case "c-ip", "x-forwarded-for":
+			if entry.SourceIP == "" || name == "c-ip" {
+				entry.SourceIP = v
+			}
+		case "cs-username", "cs-userdn":
+			if entry.User == "" {
+				entry.User = v
+			}
+		case "cs-method":
+			entry.Method = v
+		case "cs-host", "cs-uri-host", "s-host", "r-host":
+			if host == "" {
+				host = v
+			}
+		case "cs-uri", "cs-url":
+			entry.URL = v
+		case "cs-uri-scheme":
+			scheme = v
+		case "cs-uri-port":
+			port = v
+		case "cs-uri-path", "cs-uri-stem":
+			path = v
+		case "cs-uri-query":
+			query = v
+		case "sc-status":
+			entry.StatusCode = v
+		case "s-action":
+			entry.Action = v
+		case "sc-filter-result":
+			filterResult = v
+		case "sc-bytes":
+			entry.BytesReceived, _ = strconv.ParseInt(v, 10, 64)
+		case "cs-bytes":
+			entry.BytesSent, _ = strconv.ParseInt(v, 10, 64)
+		case "cs(referer)":
+			entry.Referer = v
+		case "cs(user-agent)":
+			entry.UserAgent = v
+		}
+	}
+
+	if date != "" && clock != "" {
+		if ts, err := time.Parse("2006-01-02 15:04:05", date+" "+clock); err == nil {
+			entry.Timestamp = ts
+		}
+	}
+	// ProxySG records policy denials in sc-filter-result, not s-action.
+	if strings.EqualFold(filterResult, "DENIED") || entry.Action == "" {
+		entry.Action = filterResult
+	}
+
+	switch {
+	case host != "":
+		entry.Domain = strings.ToLower(strings.TrimSuffix(host, "."))
+		if entry.URL == "" && scheme != "" {
+			entry.URL = scheme + "://" + host
+			if port != "" && port != "80" && port != "443" {
+				entry.URL += ":" + port
+			}
+			entry.URL += path
+			if query != "" && query != "?" {
+				entry.URL += "?" + strings.TrimPrefix(query, "?")
+			}
+		}
+	case entry.URL != "":
+		entry.Domain = extractDomain(entry.URL)
+	}
+	if entry.Domain == "" {
+		return LogEntry{}, fmt.Errorf("no destination")
+	}
+	return entry, nil
+}
+
+// splitELFF splits a data line on spaces, keeping "quoted values" whole.
+func splitELFF(line string) []string {
+	var values []string
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case line[i] == '"':
+			end := strings.IndexByte(line[i+1:], '"')
+			if end < 0 {
+				values = append(values, line[i+1:])
+				return values
+			}
+			values = append(values, line[i+1:i+1+end])
+			i += end + 2
+		default:
+			end := strings.IndexAny(line[i:], " \t")
+			if end < 0 {
+				end = len(line) - i
+			}
+			values = append(values, line[i:i+end])
+			i += end
+		}
+	}
+	return values
+}
@@ -0,0 +1,677 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// EVTXParser reads Windows event log files (.evtx) for DNS lookups made on
+// the endpoint: Sysmon's event 22 (see SysmonParser), and event 3008 ("DNS
+// query is completed") of the Microsoft-Windows-DNS-Client/Operational
+// channel, which Windows logs once the channel is enabled:
+//
+//	wevtutil sl Microsoft-Windows-DNS-Client/Operational /e:true
+//
+// Other events are skipped. For 3008 the queried name is the destination
+// and the computer is the source, since every query in the log was made on it. The
+// event's security SID is not used as the user: lookups run in the DNS
+// Client service, so it names the service account, not who asked.
+type EVTXParser struct{}
+
+func (p *EVTXParser) Name() string {
+	return "evtx"
+}
+
+func (p *EVTXParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+func (p *EVTXParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, err := fsutil.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	header := make([]byte, evtxHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil || !bytes.HasPrefix(header, []byte("ElfFile\x00")) {
+		return nil, fmt.Errorf("%s is not an EVTX file", filepath)
+	}
+
+	var entries []LogEntry
+	chunk := make([]byte, evtxChunkSize)
+	for {
+		if ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		if _, err := io.ReadFull(file, chunk); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, fmt.Errorf("reading %s: %w", filepath, err)
+		}
+		if !bytes.HasPrefix(chunk, []byte("ElfChnk\x00")) {
+			continue // unused or damaged chunk
+		}
+		for _, ev := range evtxChunkEvents(chunk) {
+			if entry, ok := evtxEntry(ev); ok {
+				entry.SourceFile = filepath
+				entry.LineNumber = int(ev.record) // the event record ID stands in for a line
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+const (
+	evtxHeaderSize = 4096
+	evtxChunkSize  = 65536
+	evtxFirstRec   = 512 // records start after the chunk header and its string and template tables
+	evtxRecordHead = 24  // magic, size, record ID, FILETIME
+	dnsClientEvent = "3008"
+)
+
+// evtxEvent is a rendered event record.
+type evtxEvent struct {
+	record  uint64
+	written time.Time
+	root    *xmlNode
+}
+
+// xmlNode is an element of a rendered event.
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlNode
+	text     string
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	return n.attrs[name]
+}
+
+func (n *xmlNode) value() string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(n.text)
+}
+
+// evtxEntry maps a DNS-Client 3008 or Sysmon 22 event onto a LogEntry.
+func evtxEntry(ev evtxEvent) (LogEntry, bool) {
+	sys := ev.root.child("System")
+	data := make(map[string]string)
+	for _, d := range ev.root.child("EventData").children {
+		if d.name == "Data" {
+			data[d.attr("Name")] = d.value()
+		}
+	}
+	provider, id := sys.child("Provider").attr("Name"), sys.child("EventID").value()
+	switch {
+	case strings.EqualFold(provider, "Microsoft-Windows-DNS-Client") && id == dnsClientEvent:
+		return dnsClientEntry(ev, sys, data)
+	case strings.EqualFold(provider, "Microsoft-Windows-Sysmon") && id == sysmonDNSEvent:
+		return sysmonDNSEntry(data, sys.child("Computer").value(), sys.child("TimeCreated").attr("SystemTime"))
+	}
+	return LogEntry{}, false
+}
+
+// dnsClientEntry maps a DNS-Client 3008 event onto a LogEntry.
+func dnsClientEntry(ev evtxEvent, sys *xmlNode, data map[string]string) (LogEntry, bool) {
+	name := strings.ToLower(strings.TrimSuffix(data["QueryName"], "."))
+	if name == "" {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Timestamp:  ev.written,
+		SourceIP:   sys.child("Computer").value(),
+		DeviceName: sys.child("Computer").value(),
+		Domain:     name,
+		StatusCode: data["QueryStatus"],
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, sys.child("TimeCreated").attr("SystemTime")); err == nil {
+		entry.Timestamp = ts
+	}
+	entry.RawLine = fmt.Sprintf("%s EventID=%s QueryName=%s QueryType=%s QueryStatus=%s",
+		entry.Timestamp.Format(time.RFC3339), dnsClientEvent, data["QueryName"], data["QueryType"], data["QueryStatus"])
+	return entry, true
+}
+
+// evtxChunkEvents renders every record in a chunk. A record that cannot
+// be decoded is dropped; the rest of the chunk is still read.
+func evtxChunkEvents(chunk []byte) []evtxEvent {
+	free := int(binary.LittleEndian.Uint32(chunk[0x30:]))
+	if free > len(chunk) || free < evtxFirstRec {
+		free = len(chunk)
+	}
+	var events []evtxEvent
+	for off := evtxFirstRec; off+evtxRecordHead <= free; {
+		if binary.LittleEndian.Uint32(chunk[off:]) != 0x00002a2a {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(chunk[off+4:]))
+		if size < evtxRecordHead+4 || off+size > len(chunk) {
+			break
+		}
+		ev := evtxEvent{
+			record:  binary.LittleEndian.Uint64(chunk[off+8:]),
+			written: filetime(binary.LittleEndian.Uint64(chunk[off+16:])),
+		}
+		b := &binXML{chunk: chunk, pos: off + evtxRecordHead, end: off + size - 4}
+		if nodes, err := b.fragment(nil); err == nil && len(nodes) > 0 {
+			ev.root = nodes[0]
+			events = append(events, ev)
+		}
+		off += size
+	}
+	return events
+}
+
+// binXML decodes Windows binary XML from a chunk. Names and template
+// definitions are stored once per chunk and referenced by chunk offset.
+type binXML struct {
+	chunk []byte
+	pos   int
+	end   int
+	depth int
+}
+
+// evtxValue is a substitution value: text, or for an embedded BinXml
+// value, elements.
+type evtxValue struct {
+	text  string
+	nodes []*xmlNode
+	null  bool
+}
+
+var errBinXML = errors.New("malformed binary XML")
+
+const maxBinXMLDepth = 32
+
+// BinXML tokens. The 0x40 bit on some tokens flags more to follow.
+const (
+	tokEOF          = 0x00
+	tokOpenStart    = 0x01
+	tokCloseStart   = 0x02
+	tokCloseEmpty   = 0x03
+	tokEndElement   = 0x04
+	tokValue        = 0x05
+	tokAttribute    = 0x06
+	tokCDATA        = 0x07
+	tokCharRef      = 0x08
+	tokEntityRef    = 0x09
+	tokPITarget     = 0x0a
+	tokPIData       = 0x0b
+	tokTemplate     = 0x0c
+	tokSubstitution = 0x0d
+	tokOptionalSub  = 0x0e
+	tokFragment     = 0x0f
+)
+
+func (b *binXML) u8() (byte, error) {
+	if b.pos+1 > b.end {
+		return 0, errBinXML
+	}
+	v := b.chunk[b.pos]
+	b.pos++
+	return v, nil
+}
+
+func (b *binXML) u16() (uint16, error) {
+	if b.pos+2 > b.end {
+		return 0, errBinXML
+	}
+	v := binary.LittleEndian.Uint16(b.chunk[b.pos:])
+	b.pos += 2
+	return v, nil
+}
+
+func (b *binXML) u32() (uint32, error) {
+	if b.pos+4 > b.end {
+		return 0, errBinXML
+	}
+	v := binary.LittleEndian.Uint32(b.chunk[b.pos:])
+	b.pos += 4
+	return v, nil
+}
+
+func (b *binXML) bytes(n int) ([]byte, error) {
+	if n < 0 || b.pos+n > b.end {
+		return nil, errBinXML
+	}
+	v := b.chunk[b.pos : b.pos+n]
+	b.pos += n
+	return v, nil
+}
+
+// utf16String reads a length-prefixed UTF-16LE string.
+func (b *binXML) utf16String() (string, error) {
+	n, err := b.u16()
+	if err != nil {
+		return "", err
+	}
+	raw, err := b.bytes(int(n) * 2)
+	if err != nil {
+		return "", err
+	}
+	return decodeUTF16(raw), nil
+}
+
+// name reads a name reference. The name itself is stored at the offset,
+// which is where the reader stands the first time the chunk uses it.
+func (b *binXML) name() (string, error) {
+	off, err := b.u32()
+	if err != nil {
+		return "", err
+	}
+	o := int(off)
+	if o+8 > len(b.chunk) {
+		return "", errBinXML
+	}
+	n := int(binary.LittleEndian.Uint16(b.chunk[o+6:]))
+	if o+8+2*n > len(b.chunk) {
+		return "", errBinXML
+	}
+	s := decodeUTF16(b.chunk[o+8 : o+8+2*n])
+	if o == b.pos {
+		b.pos += 8 + 2*n + 2 // inline, with its terminating NUL
+	}
+	return s, nil
+}
+
+// fragment reads a fragment header and the template instance or element
+// that follows it.
+func (b *binXML) fragment(subs []evtxValue) ([]*xmlNode, error) {
+	if b.depth++; b.depth > maxBinXMLDepth {
+		return nil, errBinXML
+	}
+	defer func() { b.depth-- }()
+
+	var nodes []*xmlNode
+	for b.pos < b.end {
+		tok, err := b.u8()
+		if err != nil {
+			return nil, err
+		}
+		switch tok & 0x0f {
+		case tokEOF:
+			return nodes, nil
+		case tokFragment:
+			if _, err := b.bytes(3); err != nil {
+				return nil, err
+			}
+		case tokTemplate:
+			n, err := b.template()
+			if err != nil {
+				return nil, err
+			}
+			return append(nodes, n...), nil
+		case tokOpenStart:
+			n, err := b.element(tok, subs)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		default:
+			return nil, errBinXML
+		}
+	}
+	return nodes, nil
+}
+
+// template reads a template instance: the template reference, its
+// definition when stored here, and the substitution values that fill it.
+func (b *binXML) template() ([]*xmlNode, error) {
+	if _, err := b.bytes(5); err != nil { // unknown byte, template ID
+		return nil, err
+	}
+	off, err := b.u32()
+	if err != nil {
+		return nil, err
+	}
+	def := int(off)
+	if def+24 > len(b.chunk) {
+		return nil, errBinXML
+	}
+	size := int(binary.LittleEndian.Uint32(b.chunk[def+20:]))
+	if def+24+size > len(b.chunk) {
+		return nil, errBinXML
+	}
+	if def == b.pos {
+		b.pos += 24 + size
+	}
+
+	count, err := b.u32()
+	if err != nil {
+		return nil, err
+	}
+	if int(count)*4 > b.end-b.pos {
+		return nil, errBinXML
+	}
+	type decl struct {
+		size int
+		typ  byte
+	}
+	decls := make([]decl, count)
+	for i := range decls {
+		sz, _ := b.u16()
+		typ, _ := b.u8()
+		b.u8()
+		decls[i] = decl{int(sz), typ}
+	}
+	subs := make([]evtxValue, count)
+	for i, d := range decls {
+		raw, err := b.bytes(d.size)
+		if err != nil {
+			return nil, err
+		}
+		if subs[i], err = b.value(d.typ, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	body := &binXML{chunk: b.chunk, pos: def + 24, end: def + 24 + size, depth: b.depth}
+	return body.fragment(subs)
+}
+
+// element reads an element from its open tag to its end.
+func (b *binXML) element(tok byte, subs []evtxValue) (*xmlNode, error) {
+	if _, err := b.bytes(6); err != nil { // dependency ID, data size
+		return nil, err
+	}
+	name, err := b.name()
+	if err != nil {
+		return nil, err
+	}
+	n := &xmlNode{name: name}
+	if tok&0x40 != 0 {
+		if _, err := b.u32(); err != nil { // attribute list size
+			return nil, err
+		}
+	}
+
+	for {
+		t, err := b.u8()
+		if err != nil {
+			return nil, err
+		}
+		switch t & 0x0f {
+		case tokAttribute:
+			aname, err := b.name()
+			if err != nil {
+				return nil, err
+			}
+			v, err := b.content(subs)
+			if err != nil {
+				return nil, err
+			}
+			if v.null {
+				continue
+			}
+			if n.attrs == nil {
+				n.attrs = make(map[string]string)
+			}
+			n.attrs[aname] = v.text
+		case tokCloseEmpty:
+			return n, nil
+		case tokCloseStart:
+			return n, b.children(n, subs)
+		default:
+			return nil, errBinXML
+		}
+	}
+}
+
+// children reads an element's content up to its end tag.
+func (b *binXML) children(n *xmlNode, subs []evtxValue) error {
+	if b.depth++; b.depth > maxBinXMLDepth {
+		return errBinXML
+	}
+	defer func() { b.depth-- }()
+
+	for {
+		if b.pos >= b.end {
+			return errBinXML
+		}
+		switch b.chunk[b.pos] & 0x0f {
+		case tokEndElement:
+			b.pos++
+			return nil
+		case tokOpenStart:
+			tok, _ := b.u8()
+			c, err := b.element(tok, subs)
+			if err != nil {
+				return err
+			}
+			n.children = append(n.children, c)
+		case tokPITarget:
+			b.pos++
+			if _, err := b.name(); err != nil {
+				return err
+			}
+		case tokPIData:
+			b.pos++
+			if _, err := b.utf16String(); err != nil {
+				return err
+			}
+		default:
+			v, err := b.content(subs)
+			if err != nil {
+				return err
+			}
+			n.text += v.text
+			n.children = append(n.children, v.nodes...)
+		}
+	}
+}
+
+// content reads one text token: a value, a substitution, or a reference.
+func (b *binXML) content(subs []evtxValue) (evtxValue, error) {
+	tok, err := b.u8()
+	if err != nil {
+		return evtxValue{}, err
+	}
+	switch tok & 0x0f {
+	case tokValue:
+		if _, err := b.u8(); err != nil { // value type; always a string here
+			return evtxValue{}, err
+		}
+		s, err := b.utf16String()
+		return evtxValue{text: s}, err
+	case tokCDATA:
+		s, err := b.utf16String()
+		return evtxValue{text: s}, err
+	case tokCharRef:
+		r, err := b.u16()
+		return evtxValue{text: string(rune(r))}, err
+	case tokEntityRef:
+		name, err := b.name()
+		return evtxValue{text: xmlEntity(name)}, err
+	case tokSubstitution, tokOptionalSub:
+		id, err := b.u16()
+		if err != nil {
+			return evtxValue{}, err
+		}
+		if _, err := b.u8(); err != nil {
+			return evtxValue{}, err
+		}
+		if int(id) >= len(subs) {
+			return evtxValue{null: true}, nil
+		}
+		v := subs[id]
+		if tok&0x0f == tokOptionalSub && v.text == "" && v.nodes == nil {
+			v.null = true
+		}
+		return v, nil
+	}
+	return evtxValue{}, errBinXML
+}
+
+func xmlEntity(name string) string {
+	switch name {
+	case "lt":
+		return "<"
+	case "gt":
+		return ">"
+	case "amp":
+		return "&"
+	case "quot":
+		return `"`
+	case "apos":
+		return "'"
+	}
+	return ""
+}
+
+// value renders a substitution value of the given type as text.
+func (b *binXML) value(typ byte, raw []byte) (evtxValue, error) {
+	le := binary.LittleEndian
+	fixed := func(n int) bool { return len(raw) >= n }
+	switch typ {
+	case 0x00:
+		return evtxValue{null: true}, nil
+	case 0x01:
+		return evtxValue{text: decodeUTF16(raw)}, nil
+	case 0x02:
+		return evtxValue{text: string(bytes.TrimRight(raw, "\x00"))}, nil
+	case 0x03:
+		if fixed(1) {
+			return evtxValue{text: strconv.Itoa(int(int8(raw[0])))}, nil
+		}
+	case 0x04:
+		if fixed(1) {
+			return evtxValue{text: strconv.Itoa(int(raw[0]))}, nil
+		}
+	case 0x05:
+		if fixed(2) {
+			return evtxValue{text: strconv.Itoa(int(int16(le.Uint16(raw))))}, nil
+		}
+	case 0x06:
+		if fixed(2) {
+			return evtxValue{text: strconv.Itoa(int(le.Uint16(raw)))}, nil
+		}
+	case 0x07:
+		if fixed(4) {
+			return evtxValue{text: strconv.FormatInt(int64(int32(le.Uint32(raw))), 10)}, nil
+		}
+	case 0x08:
+		if fixed(4) {
+			return evtxValue{text: strconv.FormatUint(uint64(le.Uint32(raw)), 10)}, nil
+		}
+	case 0x09:
+		if fixed(8) {
+			return evtxValue{text: strconv.FormatInt(int64(le.Uint64(raw)), 10)}, nil
+		}
+	case 0x0a:
+		if fixed(8) {
+			return evtxValue{text: strconv.FormatUint(le.Uint64(raw), 10)}, nil
+		}
+	case 0x0d:
+		if fixed(4) {
+			return evtxValue{text: strconv.FormatBool(le.Uint32(raw) != 0)}, nil
+		}
+	case 0x0e:
+		return evtxValue{text: strings.ToUpper(hex.EncodeToString(raw))}, nil
+	case 0x0f:
+		if fixed(16) {
+			return evtxValue{text: fmt.Sprintf("{%08X-%04X-%04X-%X-%X}",
+				le.Uint32(raw), le.Uint16(raw[4:]), le.Uint16(raw[6:]), raw[8:10], raw[10:16])}, nil
+		}
+	case 0x10, 0x15:
+		if fixed(8) {
+			return evtxValue{text: fmt.Sprintf("0x%x", le.Uint64(raw))}, nil
+		}
+		if fixed(4) {
+			return evtxValue{text: fmt.Sprintf("0x%x", le.Uint32(raw))}, nil
+		}
+	case 0x14:
+		if fixed(4) {
+			return evtxValue{text: fmt.Sprintf("0x%x", le.Uint32(raw))}, nil
+		}
+	case 0x11:
+		if fixed(8) {
+			return evtxValue{text: filetime(le.Uint64(raw)).Format(time.RFC3339Nano)}, nil
+		}
+	case 0x12:
+		if fixed(16) {
+			t := time.Date(int(le.Uint16(raw)), time.Month(le.Uint16(raw[2:])), int(le.Uint16(raw[6:])),
+				int(le.Uint16(raw[8:])), int(le.Uint16(raw[10:])), int(le.Uint16(raw[12:])),
+				int(le.Uint16(raw[14:]))*int(time.Millisecond), time.UTC)
+			return evtxValue{text: t.Format(time.RFC3339Nano)}, nil
+		}
+	case 0x13:
+		return evtxValue{text: sidString(raw)}, nil
+	case 0x21:
+		sub := &binXML{chunk: b.chunk, pos: b.pos - len(raw), end: b.pos, depth: b.depth}
+		nodes, err := sub.fragment(nil)
+		return evtxValue{nodes: nodes}, err
+	case 0x81:
+		parts := strings.Split(decodeUTF16(raw), "\x00")
+		return evtxValue{text: strings.Join(parts, " ")}, nil
+	default:
+		return evtxValue{text: strings.ToUpper(hex.EncodeToString(raw))}, nil
+	}
+	return evtxValue{}, errBinXML
+}
+
+// sidString formats a binary security identifier as S-1-5-21-....
+func sidString(raw []byte) string {
+	if len(raw) < 8 || len(raw) < 8+4*int(raw[1]) {
+		return ""
+	}
+	var auth uint64
+	for _, c := range raw[2:8] {
+		auth = auth<<8 | uint64(c)
+	}
+	s := fmt.Sprintf("S-%d-%d", raw[0], auth)
+	for i := 0; i < int(raw[1]); i++ {
+		s += "-" + strconv.FormatUint(uint64(binary.LittleEndian.Uint32(raw[8+4*i:])), 10)
+	}
+	return s
+}
+
+// decodeUTF16 decodes UTF-16LE, dropping a trailing NUL.
+func decodeUTF16(raw []byte) string {
+	u := make([]uint16, len(raw)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(raw[2*i:])
+	}
+	return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+}
+
+// filetime converts a Windows FILETIME, 100ns ticks since 1601, to UTC.
+func filetime(ticks uint64) time.Time {
+	if ticks == 0 {
+		return time.Time{}
+	}
+	const epochDelta = 116444736000000000 // 1601-01-01 to 1970-01-01 in ticks
+	return time.Unix(0, (int64(ticks)-epochDelta)*100).UTC()
+}
@@ -0,0 +1,160 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxPluginLine bounds one JSON record from a plugin, and maxPluginStderr
+// how much of its stderr is kept for the error message.
+const (
+	maxPluginLine   = 1024 * 1024
+	maxPluginStderr = 4096
+)
+
+// PluginRecord is one line of an exec plugin's output. Only domain or url
+// is required; the timestamp is RFC 3339.
+type PluginRecord struct {
+	Timestamp     string `json:"timestamp,omitempty"`
+	SourceIP      string `json:"source_ip,omitempty"`
+	Domain        string `json:"domain,omitempty"`
+	URL           string `json:"url,omitempty"`
+	Method        string `json:"method,omitempty"`
+	StatusCode    string `json:"status_code,omitempty"`
+	Action        string `json:"action,omitempty"`
+	BytesSent     int64  `json:"bytes_sent,omitempty"`
+	BytesReceived int64  `json:"bytes_received,omitempty"`
+	User          string `json:"user,omitempty"`
+	Referer       string `json:"referer,omitempty"`
+	UserAgent     string `json:"user_agent,omitempty"`
+	SNI           string `json:"sni,omitempty"`
+	JA3           string `json:"ja3,omitempty"`
+	Raw           string `json:"raw,omitempty"`
+}
+
+// ExecParser hands a file to an external program, which writes one
+// PluginRecord per line to stdout. It lets proprietary formats be added
+// without changing the scanner. The file path is the program's last argument.
+type ExecParser struct {
+	name    string
+	command []string
+}
+
+// NewExecParser checks that the plugin program can be found.
+func NewExecParser(name string, command []string) (*ExecParser, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("parser %s: missing command", name)
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil, fmt.Errorf("parser %s: %w", name, err)
+	}
+	return &ExecParser{name: name, command: command}, nil
+}
+
+func (p *ExecParser) Name() string {
+	return p.name
+}
+
+// Parse runs the plugin on filepath. Output lines that aren't records with
+// a destination are skipped; a non-zero exit fails the file.
+func (p *ExecParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+// ParseContext kills the plugin when ctx is cancelled.
+func (p *ExecParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	args := append(append([]string{}, p.command[1:]...), filepath)
+	cmd := exec.CommandContext(ctx, p.command[0], args...)
+	var stderr tailBuffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxPluginLine)
+	for scanner.Scan() {
+		var rec PluginRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if entry, ok := rec.entry(); ok {
+			entry.SourceFile = filepath
+			entries = append(entries, entry)
+		}
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		cmd.Process.Kill()
+	}
+
+	if err := cmd.Wait(); ctx.Err() != nil {
+		return entries, ctx.Err()
+	} else if err != nil && scanErr == nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return nil, fmt.Errorf("plugin %s on %s: %w: %s", p.name, filepath, err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("plugin %s on %s: %w", p.name, filepath, err)
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("reading plugin %s output: %w", p.name, scanErr)
+	}
+	return entries, nil
+}
+
+// entry converts a record, reporting false when it has no destination.
+func (r PluginRecord) entry() (LogEntry, bool) {
+	e := LogEntry{
+		SourceIP:      r.SourceIP,
+		Domain:        strings.ToLower(strings.TrimSuffix(r.Domain, ".")),
+		URL:           r.URL,
+		Method:        r.Method,
+		StatusCode:    r.StatusCode,
+		Action:        r.Action,
+		BytesSent:     r.BytesSent,
+		BytesReceived: r.BytesReceived,
+		User:          r.User,
+		Referer:       r.Referer,
+		UserAgent:     r.UserAgent,
+		SNI:           strings.ToLower(strings.TrimSuffix(r.SNI, ".")),
+		JA3:           strings.ToLower(r.JA3),
+		RawLine:       r.Raw,
+	}
+	if e.Domain == "" && e.URL != "" {
+		e.Domain = extractDomain(e.URL)
+	}
+	if e.Domain == "" {
+		e.Domain = e.SNI
+	}
+	if r.Timestamp != "" {
+		e.Timestamp, _ = time.Parse(time.RFC3339, r.Timestamp)
+	}
+	return e, e.Domain != ""
+}
+
+// tailBuffer keeps the last maxPluginStderr bytes written to it.
+type tailBuffer struct {
+	bytes.Buffer
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.Buffer.Write(p)
+	if over := b.Len() - maxPluginStderr; over > 0 {
+		b.Next(over)
+	}
+	return n, nil
+}
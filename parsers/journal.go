@@ -0,0 +1,265 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// maxJournalField bounds one field of a journal record.
+const maxJournalField = 1024 * 1024
+
+// JournalRecord is one systemd journal entry, field name to value.
+type JournalRecord map[string]string
+
+// Message is the logged line.
+func (r JournalRecord) Message() string {
+	return r["MESSAGE"]
+}
+
+// Time is when journald received the record.
+func (r JournalRecord) Time() (time.Time, bool) {
+	us, err := strconv.ParseInt(r["__REALTIME_TIMESTAMP"], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(us).UTC(), true
+}
+
+// JournalParser handles systemd journal records in the export format
+// (journalctl -o export) or as JSON (journalctl -o json), which is how
+// dnsmasq and Squid log on hosts without traditional log files:
+//
+//	journalctl -u dnsmasq.service -u squid.service -o export > ai.journal
+//
+// Each record's MESSAGE is parsed with Inner, or when Inner is nil with
+// the format its SYSLOG_IDENTIFIER names: dns for dnsmasq, named, and
+// unbound, squid otherwise. A message without a timestamp of its own, as
+// dnsmasq writes to the journal, takes the time journald received it.
+type JournalParser struct {
+	Inner LineParser
+}
+
+func (p *JournalParser) Name() string {
+	return "journal"
+}
+
+func (p *JournalParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+// ParseContext reads a saved journal. Records whose message does not
+// parse are skipped.
+func (p *JournalParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, err := fsutil.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	var entries []LogEntry
+	n := 0
+	err = ReadJournal(ctx, file, func(rec JournalRecord) {
+		n++
+		if entry, err := p.Entry(rec); err == nil {
+			entry.SourceFile, entry.LineNumber = filepath, n
+			entries = append(entries, entry)
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath, err)
+	}
+	return entries, err
+}
+
+// Sniff scores a journal sample: export records carry the journal's
+// cursor and timestamp fields.
+func (p *JournalParser) Sniff(lines []string) float64 {
+	sample := strings.Join(lines, "\n")
+	if strings.Contains(sample, "__CURSOR") && strings.Contains(sample, "__REALTIME_TIMESTAMP") {
+		return 1
+	}
+	return 0
+}
+
+// Entry parses one record's message. Records without a message return
+// ErrSkip.
+func (p *JournalParser) Entry(rec JournalRecord) (LogEntry, error) {
+	msg := CleanLine(rec.Message())
+	if strings.TrimSpace(msg) == "" {
+		return LogEntry{}, ErrSkip
+	}
+	inner := p.Inner
+	if inner == nil {
+		inner = journalFormat(rec["SYSLOG_IDENTIFIER"])
+	}
+	entry, err := inner.ParseLine(msg)
+	if err != nil {
+		return LogEntry{}, err
+	}
+	if ts, ok := rec.Time(); ok && (entry.Timestamp.IsZero() || entry.NoYear) {
+		entry.Timestamp, entry.Floating, entry.NoYear = ts, false, false
+	}
+	return entry, nil
+}
+
+// journalFormat picks the format of a program's messages.
+func journalFormat(ident string) LineParser {
+	switch strings.ToLower(ident) {
+	case "dnsmasq", "named", "unbound", "systemd-resolved":
+		return &DNSParser{}
+	}
+	return &SquidParser{}
+}
+
+// ReadJournalUnit runs journalctl for a systemd unit, such as
+// dnsmasq.service, and passes each of its records to fn. With follow it
+// starts at the end of the journal and waits for new records until ctx is
+// cancelled.
+func ReadJournalUnit(ctx context.Context, unit string, follow bool, fn func(JournalRecord)) error {
+	args := []string{"--unit", unit, "--output", "export", "--no-pager", "--quiet"}
+	if follow {
+		args = append(args, "--follow", "--lines", "0")
+	}
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	var stderr tailBuffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("journal %s: %w", unit, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("journal %s: %w", unit, err)
+	}
+	readErr := ReadJournal(ctx, stdout, fn)
+	if readErr != nil {
+		cmd.Process.Kill()
+	}
+
+	if err := cmd.Wait(); ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil && readErr == nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return fmt.Errorf("journal %s: %w: %s", unit, err, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("journal %s: %w", unit, err)
+	}
+	if readErr != nil {
+		return fmt.Errorf("reading journal %s: %w", unit, readErr)
+	}
+	return nil
+}
+
+// ReadJournal passes each record of an export or JSON journal stream to
+// fn, until the stream ends or ctx is cancelled.
+func ReadJournal(ctx context.Context, r io.Reader, fn func(JournalRecord)) error {
+	br := bufio.NewReader(r)
+	if isJSONStart(br) {
+		return readJournalJSON(ctx, br, fn)
+	}
+	return readJournalExport(ctx, br, fn)
+}
+
+// readJournalExport reads the export format: "NAME=value" lines, records
+// separated by a blank line. A value that is not plain text follows its
+// name's line as a little-endian 64-bit length and the raw bytes.
+func readJournalExport(ctx context.Context, r *bufio.Reader, fn func(JournalRecord)) error {
+	rec := make(JournalRecord)
+	for n := 1; ; n++ {
+		if n%ctxCheckLines == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line, err := r.ReadString('\n')
+		if err == io.EOF && line == "" {
+			if len(rec) > 0 {
+				fn(rec)
+			}
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(rec) > 0 {
+				fn(rec)
+				rec = make(JournalRecord)
+			}
+			continue
+		}
+		if name, value, ok := strings.Cut(line, "="); ok {
+			rec[name] = value
+			continue
+		}
+		var size uint64
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return fmt.Errorf("field %s: %w", line, err)
+		}
+		if size > maxJournalField {
+			return fmt.Errorf("field %s: %d bytes is too long", line, size)
+		}
+		value := make([]byte, size+1) // and its newline
+		if _, err := io.ReadFull(r, value); err != nil {
+			return fmt.Errorf("field %s: %w", line, err)
+		}
+		rec[line] = string(bytes.TrimSuffix(value, []byte("\n")))
+	}
+}
+
+// readJournalJSON reads one JSON object per record. journalctl writes a
+// value that is not plain text as an array of bytes, and a field logged
+// more than once as an array of values, of which the last is kept.
+func readJournalJSON(ctx context.Context, r io.Reader, fn func(JournalRecord)) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var v map[string]any
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		rec := make(JournalRecord, len(v))
+		for name, value := range v {
+			rec[name] = journalJSONValue(value)
+		}
+		fn(rec)
+	}
+}
+
+func journalJSONValue(v any) string {
+	list, ok := v.([]any)
+	if !ok {
+		return jsonString(v)
+	}
+	if len(list) == 0 {
+		return ""
+	}
+	if _, raw := list[0].(json.Number); !raw {
+		return journalJSONValue(list[len(list)-1])
+	}
+	b := make([]byte, 0, len(list))
+	for _, e := range list {
+		n, err := strconv.Atoi(jsonString(e))
+		if err != nil || n < 0 || n > 255 {
+			return ""
+		}
+		b = append(b, byte(n))
+	}
+	return string(b)
+}
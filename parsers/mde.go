@@ -0,0 +1,228 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// MDEParser handles Microsoft Defender for Endpoint DeviceNetworkEvents
+// exported from advanced hunting: the portal's CSV export, the API's JSON
+// ({"Schema": ..., "Results": [...]}), a plain JSON array of rows, and the
+// streaming API's JSON lines, where each row is under "properties".
+//
+// The destination is RemoteUrl, or RemoteIP when MDE saw no host name; the
+// user is InitiatingProcessAccountName, the source and device are
+// DeviceName, and the process is InitiatingProcessFileName.
+// Inbound and listening events are skipped.
+type MDEParser struct{}
+
+func (p *MDEParser) Name() string {
+	return "mde"
+}
+
+func (p *MDEParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+func (p *MDEParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var entries []LogEntry
+	if isJSONStart(r) {
+		entries, err = parseMDEJSON(ctx, r)
+	} else {
+		entries, err = parseMDECSV(ctx, r)
+	}
+	for i := range entries {
+		entries[i].SourceFile = filepath
+	}
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath, err)
+	}
+	return entries, err
+}
+
+// Sniff scores an MDE sample: either format names DeviceName and a remote
+// column in its first record.
+func (p *MDEParser) Sniff(lines []string) float64 {
+	sample := strings.Join(lines, "\n")
+	if strings.Contains(sample, "DeviceName") && (strings.Contains(sample, "RemoteUrl") || strings.Contains(sample, "RemoteIP")) {
+		return 1
+	}
+	return 0
+}
+
+// isJSONStart reports whether the first non-blank byte opens JSON.
+func isJSONStart(r *bufio.Reader) bool {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		case '\xef': // a byte-order mark OpenText left in place
+			if bom, _ := r.Peek(3); string(bom) == "\ufeff" {
+				r.Discard(3)
+				continue
+			}
+			return false
+		default:
+			return b[0] == '{' || b[0] == '['
+		}
+	}
+}
+
+// parseMDECSV reads the portal's CSV export, whose header names the columns.
+func parseMDECSV(ctx context.Context, r io.Reader) ([]LogEntry, error) {
+	reader := csv.NewReader(r)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[unquoteField(strings.TrimPrefix(h, "\ufeff"))] = i
+	}
+	if _, ok := cols["RemoteUrl"]; !ok {
+		if _, ok := cols["RemoteIP"]; !ok {
+			return nil, fmt.Errorf("no RemoteUrl or RemoteIP column")
+		}
+	}
+
+	var entries []LogEntry
+	for n := 2; ; n++ {
+		if n%ctxCheckLines == 0 && ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entries, err
+		}
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(row) {
+				return unquoteField(row[i])
+			}
+			return ""
+		}
+		if entry, ok := mdeEntry(get); ok {
+			entry.LineNumber, entry.RawLine = n, strings.Join(row, ",")
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseMDEJSON reads a stream of JSON values holding rows in any of the
+// shapes the API, the portal, and the streaming API produce.
+func parseMDEJSON(ctx context.Context, r io.Reader) ([]LogEntry, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var entries []LogEntry
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			for _, key := range []string{"Results", "records", "properties"} {
+				if inner, ok := v[key]; ok {
+					walk(inner)
+					return
+				}
+			}
+			get := func(name string) string { return jsonString(v[name]) }
+			if entry, ok := mdeEntry(get); ok {
+				raw, _ := json.Marshal(v)
+				entry.RawLine = string(raw)
+				entries = append(entries, entry)
+			}
+		}
+	}
+	for {
+		if ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		var v any
+		if err := dec.Decode(&v); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, err
+		}
+		walk(v)
+	}
+}
+
+// jsonString renders a decoded JSON scalar as text.
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// mdeEntry maps one DeviceNetworkEvents row, read through get, onto a
+// LogEntry.
+func mdeEntry(get func(string) string) (LogEntry, bool) {
+	switch get("ActionType") {
+	case "InboundConnectionAccepted", "ListeningConnectionCreated":
+		return LogEntry{}, false
+	}
+	entry := LogEntry{
+		SourceIP:    get("DeviceName"),
+		Action:      get("ActionType"),
+		ProcessName: get("InitiatingProcessFileName"),
+		DeviceName:  get("DeviceName"),
+	}
+	if entry.SourceIP == "" {
+		entry.SourceIP = get("LocalIP")
+	}
+	if user := get("InitiatingProcessAccountName"); user != "" && !serviceAccount(user) {
+		entry.User = user
+	}
+	entry.Timestamp, entry.Floating = parseFlexibleTime(get("Timestamp"))
+
+	remote := get("RemoteUrl")
+	switch {
+	case strings.Contains(remote, "://"):
+		entry.URL, entry.Domain = remote, extractDomain(remote)
+	case remote != "":
+		host, _, _ := strings.Cut(remote, "/")
+		entry.Domain = strings.ToLower(strings.TrimSuffix(connectHost(host), "."))
+	default:
+		if addr, err := netip.ParseAddr(get("RemoteIP")); err == nil {
+			entry.Domain = addr.String()
+		}
+	}
+	if entry.Domain == "" {
+		return LogEntry{}, false
+	}
+	return entry, true
+}
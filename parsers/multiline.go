@@ -0,0 +1,131 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// Multiline describes how physical lines are framed into logical records
+// for sources that wrap one record across several lines.
+type Multiline struct {
+	// Continuation matches lines that belong to the previous record. With
+	// Negate set, lines that do NOT match are continuations instead, so the
+	// pattern can describe the start of a record (e.g. `^\{` or a timestamp).
+	Continuation *regexp.Regexp
+	Negate       bool
+	MaxLines     int           // records are cut after this many lines; 0 means no limit
+	Timeout      time.Duration // follow mode: emit a pending record after this much quiet
+}
+
+func (m Multiline) continues(line string) bool {
+	return m.Continuation.MatchString(line) != m.Negate
+}
+
+// Framer reassembles lines into records according to a Multiline setting.
+type Framer struct {
+	m     Multiline
+	lines []string
+	last  time.Time
+}
+
+// NewFramer returns an empty framer.
+func NewFramer(m Multiline) *Framer {
+	return &Framer{m: m}
+}
+
+// Push adds a line. If the line starts a new record, the previous record is
+// returned as complete.
+func (f *Framer) Push(line string, now time.Time) (string, bool) {
+	var record string
+	var done bool
+	full := f.m.MaxLines > 0 && len(f.lines) >= f.m.MaxLines
+	if len(f.lines) > 0 && (full || !f.m.continues(line)) {
+		record, done = f.Flush()
+	}
+	f.lines = append(f.lines, line)
+	f.last = now
+	return record, done
+}
+
+// Flush returns the pending record, if any, and resets the framer.
+func (f *Framer) Flush() (string, bool) {
+	if len(f.lines) == 0 {
+		return "", false
+	}
+	record := strings.Join(f.lines, "\n")
+	f.lines = f.lines[:0]
+	return record, true
+}
+
+// Expired reports whether a record is pending and no line has arrived
+// within the timeout.
+func (f *Framer) Expired(now time.Time) bool {
+	return len(f.lines) > 0 && f.m.Timeout > 0 && now.Sub(f.last) >= f.m.Timeout
+}
+
+// MultilineParser wraps a line parser so that each record it sees is a
+// reassembled multiline record. Continuation lines are joined with "\n".
+type MultilineParser struct {
+	LineParser
+	Multiline Multiline
+}
+
+// NewMultilineParser wraps p with the given framing.
+func NewMultilineParser(p LineParser, m Multiline) *MultilineParser {
+	return &MultilineParser{LineParser: p, Multiline: m}
+}
+
+func (p *MultilineParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+// ParseContext is defined here so the wrapped parser's own, which knows
+// nothing of the framing, is not promoted.
+func (p *MultilineParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	var entries []LogEntry
+	emit := func(record string, line int) {
+		if entry, err := p.ParseLine(record); err == nil {
+			entry.SourceFile, entry.LineNumber = filepath, line
+			entries = append(entries, entry)
+		}
+	}
+
+	framer := NewFramer(p.Multiline)
+	start := 0 // line the pending record began on
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		if n%ctxCheckLines == 0 && ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		line := CleanLine(scanner.Text())
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if record, ok := framer.Push(line, time.Time{}); ok {
+			emit(record, start)
+			start = n
+		} else if start == 0 {
+			start = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filepath, err)
+	}
+	if record, ok := framer.Flush(); ok {
+		emit(record, start)
+	}
+
+	return entries, nil
+}
@@ -1,17 +1,41 @@
+// Package parsers turns proxy, DNS, and firewall logs into LogEntry values.
+// Files are read with each format's Parse; logs from other sources can be
+// streamed through a LineParser with ParseReader or ReadEntries.
 package parsers
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
 
 // LogEntry is the normalized format all parsers produce.
 type LogEntry struct {
-	Timestamp   time.Time
-	SourceIP    string
-	Domain      string // destination domain or hostname
-	URL         string // full URL if available
-	Method      string // HTTP method if available
-	StatusCode  string
-	BytesSent   int64
-	RawLine     string
+	Timestamp     time.Time
+	Floating      bool // Timestamp was logged without a zone; its wall clock is held as UTC
+	NoYear        bool // Timestamp was logged without a year; see InferYear
+	SourceIP      string
+	Domain        string // destination domain or hostname
+	URL           string // full URL if available
+	Method        string // HTTP method if available
+	StatusCode    string
+	Action        string // proxy/firewall verdict if available (TCP_DENIED, ALLOW, block)
+	BytesSent     int64  // client to server: request size, upload volume
+	BytesReceived int64  // server to client: response size, download volume
+	User          string // authenticated user name if the log records one
+	Referer       string // HTTP Referer header if logged
+	UserAgent     string // HTTP User-Agent header if logged
+	SNI           string // TLS server name, when the destination is only an IP
+	JA3           string // TLS client fingerprint (MD5 hex)
+	ProcessName   string // executable that made the request, from endpoint logs
+	DeviceName    string // endpoint the request was made on, from endpoint logs
+	SourceFile    string // log file the entry was read from
+	LineNumber    int    // 1-based line where the record starts; 0 when unknown
+	RawLine       string
 }
 
 // Parser is the interface every log format must implement.
@@ -19,3 +43,96 @@ type Parser interface {
 	Name() string
 	Parse(filepath string) ([]LogEntry, error)
 }
+
+// ContextParser is implemented by formats that can stop partway through a
+// file when their context is cancelled, returning the entries read so far
+// along with ctx.Err(). Every built-in format does.
+type ContextParser interface {
+	Parser
+	ParseContext(ctx context.Context, filepath string) ([]LogEntry, error)
+}
+
+// ParseFile parses a file with p, honoring ctx when p supports it.
+func ParseFile(ctx context.Context, p Parser, filepath string) ([]LogEntry, error) {
+	if cp, ok := p.(ContextParser); ok {
+		return cp.ParseContext(ctx, filepath)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.Parse(filepath)
+}
+
+// ErrSkip is returned by ParseLine for a record the format recognizes but
+// that names no destination, such as a Suricata flow event. Such records
+// are passed over without being counted as malformed.
+var ErrSkip = errors.New("record skipped")
+
+// LineParser is implemented by formats whose records are self-contained
+// lines, which lets them be parsed incrementally (e.g. in follow mode).
+type LineParser interface {
+	Parser
+	ParseLine(line string) (LogEntry, error)
+}
+
+// CleanLine removes a UTF-8 byte-order mark and a trailing carriage return,
+// which Windows exports leave on lines after splitting on "\n".
+func CleanLine(line string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(line, "\ufeff"), "\r")
+}
+
+// hasZone reports whether a Go time layout records a zone or offset.
+func hasZone(layout string) bool {
+	return strings.Contains(layout, "Z07") || strings.Contains(layout, "-07") || strings.Contains(layout, "MST")
+}
+
+// serviceAccount reports whether a Windows account name, with or without
+// its NT AUTHORITY domain, is one Windows runs services as. Endpoint logs
+// name it for background traffic; taking it as the user would merge every
+// device's service traffic into one user.
+func serviceAccount(name string) bool {
+	if _, after, ok := strings.Cut(name, `\`); ok {
+		name = after
+	}
+	switch strings.ToLower(name) {
+	case "system", "local service", "network service", "localservice", "networkservice":
+		return true
+	}
+	return false
+}
+
+// unquoteField trims whitespace and any quotes left around a field value,
+// such as the doubled or unbalanced quotes some CSV exporters produce.
+func unquoteField(s string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(s), `"'`))
+}
+
+// unquoteFields applies unquoteField to every whitespace-separated field.
+func unquoteFields(fields []string) []string {
+	for i, f := range fields {
+		fields[i] = unquoteField(f)
+	}
+	return fields
+}
+
+// parseLines runs a line parser over every line of a file, skipping blank
+// lines, comments, and lines the parser rejects.
+func parseLines(ctx context.Context, filepath string, p LineParser) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	entries, err := ParseReader(ctx, file, p)
+	for i := range entries {
+		entries[i].SourceFile = filepath
+	}
+	if err != nil && ctx.Err() != nil {
+		return entries, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filepath, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,207 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// windowsVariants rewrites a Unix log the ways Windows tools export it.
+var windowsVariants = []struct {
+	name string
+	edit func(string) string
+}{
+	{"plain", func(s string) string { return s }},
+	{"bom", func(s string) string { return "\ufeff" + s }},
+	{"crlf", func(s string) string { return strings.ReplaceAll(s, "\n", "\r\n") }},
+	{"bom+crlf", func(s string) string { return "\ufeff" + strings.ReplaceAll(s, "\n", "\r\n") }},
+}
+
+func mustRegex(t *testing.T, pattern string) Parser {
+	t.Helper()
+	p, err := NewRegexParser("test", pattern, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func mustDelimited(t *testing.T, delim string, columns map[string]int) Parser {
+	t.Helper()
+	p, err := NewDelimitedParser("test", delim, columns, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func writeLog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParsersTolerateWindowsExports(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser func(*testing.T) Parser
+		log    string
+		want   LogEntry // only the fields set here are compared
+	}{
+		{
+			name:   "csv",
+			parser: func(*testing.T) Parser { return &CSVParser{} },
+			log:    "timestamp,source_ip,destination,action\n2025-06-10T08:30:00Z,10.0.0.5,chat.openai.com,ALLOW\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com", Action: "ALLOW"},
+		},
+		{
+			name:   "csv quoted",
+			parser: func(*testing.T) Parser { return &CSVParser{} },
+			log:    "\"timestamp\",\"source_ip\",\"destination\",\"action\"\n\"2025-06-10T08:30:00Z\",\"10.0.0.5\",\"chat.openai.com\",\"ALLOW\"\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com", Action: "ALLOW"},
+		},
+		{
+			name:   "csv doubled quotes",
+			parser: func(*testing.T) Parser { return &CSVParser{} },
+			log:    "timestamp,source_ip,destination\n2025-06-10T08:30:00Z,\"\"\"10.0.0.5\"\"\",\"\"\"chat.openai.com\"\"\"\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com"},
+		},
+		{
+			name:   "squid",
+			parser: func(*testing.T) Parser { return &SquidParser{} },
+			log:    "1718000000.000    120 10.0.0.5 TCP_MISS/200 5120 CONNECT chat.openai.com:443 alice HIER_DIRECT/1.2.3.4 -\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com", User: "alice", StatusCode: "200"},
+		},
+		{
+			name:   "squid quoted",
+			parser: func(*testing.T) Parser { return &SquidParser{} },
+			log:    "\"1718000000.000\" 120 \"10.0.0.5\" TCP_MISS/200 5120 CONNECT \"chat.openai.com:443\" alice HIER_DIRECT/1.2.3.4 -\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com", User: "alice", StatusCode: "200"},
+		},
+		{
+			name:   "elff",
+			parser: func(*testing.T) Parser { return &ELFFParser{} },
+			log:    "#Fields: date time c-ip cs-username cs-method cs-host cs-uri-path sc-status s-action sc-bytes\n2025-06-10 08:30:00 10.0.0.5 alice GET chat.openai.com / 200 TCP_MISS 5120\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com", User: "alice", Action: "TCP_MISS"},
+		},
+		{
+			name:   "elff quoted",
+			parser: func(*testing.T) Parser { return &ELFFParser{} },
+			log:    "#Fields: date time c-ip cs-username cs-host cs(user-agent)\n2025-06-10 08:30:00 10.0.0.5 alice chat.openai.com \"Mozilla/5.0 (Windows NT 10.0)\"\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com", User: "alice", UserAgent: "Mozilla/5.0 (Windows NT 10.0)"},
+		},
+		{
+			name:   "dns",
+			parser: func(*testing.T) Parser { return &DNSParser{} },
+			log:    "2025-06-10T08:30:00Z 192.168.1.50 api.openai.com A\n",
+			want:   LogEntry{SourceIP: "192.168.1.50", Domain: "api.openai.com"},
+		},
+		{
+			name:   "dns quoted",
+			parser: func(*testing.T) Parser { return &DNSParser{} },
+			log:    "\"2025-06-10T08:30:00Z\" \"192.168.1.50\" \"api.openai.com.\" A\n",
+			want:   LogEntry{SourceIP: "192.168.1.50", Domain: "api.openai.com"},
+		},
+		{
+			name:   "regex",
+			parser: func(t *testing.T) Parser { return mustRegex(t, `^(?P<ts>\S+) (?P<src>\S+) (?P<domain>\S+)$`) },
+			log:    "2025-06-10T08:30:00Z 10.0.0.5 chat.openai.com\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com"},
+		},
+		{
+			name:   "regex quoted",
+			parser: func(t *testing.T) Parser { return mustRegex(t, `^(?P<ts>\S+) (?P<src>\S+) (?P<domain>\S+)$`) },
+			log:    "'2025-06-10T08:30:00Z' \"10.0.0.5\" \"chat.openai.com\"\n",
+			want:   LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com"},
+		},
+		{
+			name: "delimited",
+			parser: func(t *testing.T) Parser {
+				return mustDelimited(t, "|", map[string]int{"ts": 1, "src": 2, "domain": 3})
+			},
+			log:  "2025-06-10T08:30:00Z|10.0.0.5|chat.openai.com\n",
+			want: LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com"},
+		},
+		{
+			name: "delimited quoted",
+			parser: func(t *testing.T) Parser {
+				return mustDelimited(t, ",", map[string]int{"ts": 1, "src": 2, "domain": 3})
+			},
+			log:  "\"2025-06-10T08:30:00Z\",\"10.0.0.5\",\"chat.openai.com\"\n",
+			want: LogEntry{SourceIP: "10.0.0.5", Domain: "chat.openai.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, v := range windowsVariants {
+			t.Run(tt.name+"/"+v.name, func(t *testing.T) {
+				entries, err := tt.parser(t).Parse(writeLog(t, v.edit(tt.log)))
+				if err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+				if len(entries) != 1 {
+					t.Fatalf("got %d entries, want 1", len(entries))
+				}
+				got := entries[0]
+				if got.Timestamp.IsZero() {
+					t.Errorf("timestamp not parsed from %q", got.RawLine)
+				}
+				check := func(field, got, want string) {
+					if want != "" && got != want {
+						t.Errorf("%s = %q, want %q", field, got, want)
+					}
+				}
+				check("SourceIP", got.SourceIP, tt.want.SourceIP)
+				check("Domain", got.Domain, tt.want.Domain)
+				check("User", got.User, tt.want.User)
+				check("Action", got.Action, tt.want.Action)
+				check("StatusCode", got.StatusCode, tt.want.StatusCode)
+				check("UserAgent", got.UserAgent, tt.want.UserAgent)
+				if strings.ContainsAny(got.RawLine, "\r\ufeff") {
+					t.Errorf("RawLine keeps a BOM or carriage return: %q", got.RawLine)
+				}
+			})
+		}
+	}
+}
+
+func TestCleanLine(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a b c", "a b c"},
+		{"a b c\r", "a b c"},
+		{"\ufeffa b c", "a b c"},
+		{"\ufeffa b c\r", "a b c"},
+		{"a\rb", "a\rb"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanLine(tt.in); got != tt.want {
+			t.Errorf("CleanLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnquoteField(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"chat.openai.com", "chat.openai.com"},
+		{`"chat.openai.com"`, "chat.openai.com"},
+		{`'chat.openai.com'`, "chat.openai.com"},
+		{`"""chat.openai.com"""`, "chat.openai.com"},
+		{`"chat.openai.com`, "chat.openai.com"},
+		{`  " chat.openai.com "  `, "chat.openai.com"},
+		{`""`, ""},
+	}
+	for _, tt := range tests {
+		if got := unquoteField(tt.in); got != tt.want {
+			t.Errorf("unquoteField(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
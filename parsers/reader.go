@@ -0,0 +1,54 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ctxCheckLines is how many lines are read between cancellation checks.
+const ctxCheckLines = 1024
+
+// ReadEntries parses r line by line with p, calling fn for each entry with
+// its line number set. Blank lines and comments are skipped, and lines p
+// rejects are counted as malformed, except for ErrSkip. It stops with ctx.Err() once ctx is
+// cancelled.
+func ReadEntries(ctx context.Context, r io.Reader, p LineParser, fn func(LogEntry)) (malformed int, err error) {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		if n%ctxCheckLines == 0 {
+			if err := ctx.Err(); err != nil {
+				return malformed, err
+			}
+		}
+		line := CleanLine(scanner.Text())
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry, err := p.ParseLine(line)
+		if errors.Is(err, ErrSkip) {
+			continue
+		}
+		if err != nil {
+			malformed++
+			continue
+		}
+		entry.LineNumber = n
+		fn(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return malformed, err
+	}
+	return malformed, ctx.Err()
+}
+
+// ParseReader parses every line of r with p. It is the entry point for
+// programs whose logs don't come from files; malformed lines are skipped.
+func ParseReader(ctx context.Context, r io.Reader, p LineParser) ([]LogEntry, error) {
+	var entries []LogEntry
+	_, err := ReadEntries(ctx, r, p, func(e LogEntry) { entries = append(entries, e) })
+	return entries, err
+}
@@ -0,0 +1,185 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldAliases maps accepted capture-group names onto LogEntry fields.
+var fieldAliases = map[string]string{
+	"ts": "timestamp", "time": "timestamp", "timestamp": "timestamp",
+	"src": "source_ip", "src_ip": "source_ip", "source_ip": "source_ip", "client": "source_ip",
+	"domain": "domain", "host": "domain", "dst": "domain",
+	"url": "url", "uri": "url",
+	"method": "method",
+	"status": "status", "status_code": "status",
+	"action": "action",
+	"bytes":  "bytes_received", "size": "bytes_received", "bytes_received": "bytes_received", "bytes_in": "bytes_received",
+	"bytes_sent": "bytes_sent", "bytes_out": "bytes_sent",
+	"user": "user", "username": "user", "ident": "user",
+	"referer": "referer", "referrer": "referer",
+	"ua": "user_agent", "user_agent": "user_agent", "useragent": "user_agent",
+	"sni": "sni", "server_name": "sni", "ja3": "ja3",
+
+	// Names used by logstash's bundled patterns, and ECS field references
+	// as grok flattens them, so published patterns work unedited.
+	"clientip": "source_ip", "verb": "method", "request": "url", "response": "status",
+	"auth": "user", "agent": "user_agent",
+	"source_address": "source_ip", "client_ip": "source_ip", "client_address": "source_ip",
+	"url_original": "url", "url_domain": "domain", "destination_domain": "domain",
+	"http_request_method": "method", "http_response_status_code": "status",
+	"http_response_body_bytes": "bytes_received", "http_response_bytes": "bytes_received",
+	"http_request_body_bytes": "bytes_sent", "http_request_bytes": "bytes_sent",
+	"user_name": "user", "user_agent_original": "user_agent", "http_request_referrer": "referer",
+	"tls_client_server_name": "sni", "tls_client_ja3": "ja3",
+}
+
+// RegexParser extracts fields from arbitrary line-based logs using a regular
+// expression with named capture groups, e.g.
+//
+//	(?P<ts>\S+) (?P<src>\S+) (?P<domain>\S+)
+//
+// Recognized group names: ts/time/timestamp, src/src_ip/source_ip/client,
+// domain/host/dst, url/uri, method, status/status_code, action,
+// bytes/size/bytes_received/bytes_in (response), bytes_sent/bytes_out,
+// user/username/ident, referer/referrer, ua/user_agent/useragent,
+// sni/server_name, ja3.
+type RegexParser struct {
+	name       string
+	re         *regexp.Regexp
+	fields     []string // LogEntry field for each subexpression, "" if unused
+	timeLayout string
+}
+
+// NewRegexParser compiles a named-capture pattern. timeLayout is a Go time
+// layout for the ts group; when empty common formats and epoch seconds are tried.
+func NewRegexParser(name, pattern, timeLayout string) (*RegexParser, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("compiling pattern for %s: %w", name, err)
+	}
+
+	p := &RegexParser{name: name, re: re, timeLayout: timeLayout}
+	hasDest := false
+	for _, group := range re.SubexpNames() {
+		field := fieldAliases[strings.ToLower(group)]
+		p.fields = append(p.fields, field)
+		if field == "domain" || field == "url" || field == "sni" {
+			hasDest = true
+		}
+	}
+	if !hasDest {
+		return nil, fmt.Errorf("pattern for %s needs a domain, url, or sni capture group", name)
+	}
+	return p, nil
+}
+
+func (p *RegexParser) Name() string {
+	return p.name
+}
+
+func (p *RegexParser) Parse(filepath string) ([]LogEntry, error) {
+	return parseLines(context.Background(), filepath, p)
+}
+
+func (p *RegexParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	return parseLines(ctx, filepath, p)
+}
+
+// ParseLine matches one line against the pattern.
+func (p *RegexParser) ParseLine(line string) (LogEntry, error) {
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return LogEntry{}, fmt.Errorf("line does not match pattern")
+	}
+
+	entry := LogEntry{RawLine: line}
+	for i, field := range p.fields {
+		setField(&entry, field, unquoteField(m[i]), p.timeLayout)
+	}
+	return withDestination(entry)
+}
+
+// setField stores a captured value in the LogEntry field it names, as
+// listed in fieldAliases. timeLayout is the Go layout of timestamps, or
+// empty to try common formats and epoch seconds.
+func setField(entry *LogEntry, field, val, timeLayout string) {
+	if field == "" || val == "" {
+		return
+	}
+	switch field {
+	case "timestamp":
+		entry.Timestamp, entry.Floating = parseFieldTime(val, timeLayout)
+		if entry.Timestamp.Year() == 0 { // layout without a year
+			entry.Timestamp, entry.NoYear = InferYear(entry.Timestamp, time.Now()), true
+		}
+	case "source_ip":
+		entry.SourceIP = val
+	case "domain":
+		entry.Domain = strings.ToLower(strings.TrimSuffix(val, "."))
+	case "url":
+		entry.URL = val
+	case "method":
+		entry.Method = val
+	case "status":
+		entry.StatusCode = val
+	case "action":
+		entry.Action = val
+	case "bytes_sent":
+		entry.BytesSent, _ = strconv.ParseInt(val, 10, 64)
+	case "bytes_received":
+		entry.BytesReceived, _ = strconv.ParseInt(val, 10, 64)
+	case "user":
+		if val != "-" {
+			entry.User = val
+		}
+	case "referer":
+		if val != "-" {
+			entry.Referer = val
+		}
+	case "user_agent":
+		if val != "-" {
+			entry.UserAgent = val
+		}
+	case "sni":
+		if val != "-" {
+			entry.SNI = strings.ToLower(strings.TrimSuffix(val, "."))
+		}
+	case "ja3":
+		if val != "-" {
+			entry.JA3 = strings.ToLower(val)
+		}
+	}
+}
+
+// withDestination fills the domain from the URL or SNI, failing when the
+// entry has none.
+func withDestination(entry LogEntry) (LogEntry, error) {
+	if entry.Domain == "" && entry.URL != "" {
+		entry.Domain = extractDomain(entry.URL)
+	}
+	if entry.Domain == "" {
+		entry.Domain = entry.SNI
+	}
+	if entry.Domain == "" {
+		return LogEntry{}, fmt.Errorf("no destination captured")
+	}
+	return entry, nil
+}
+
+// parseFieldTime reads a timestamp field, reporting whether it lacked a
+// zone.
+func parseFieldTime(s, layout string) (time.Time, bool) {
+	if layout != "" {
+		t, _ := time.Parse(layout, s)
+		return t, !t.IsZero() && !hasZone(layout)
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(int64(secs), 0).UTC(), false
+	}
+	return parseFlexibleTime(s)
+}
@@ -0,0 +1,51 @@
+package parsers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Parser)
+)
+
+// Register makes a parser available by name, to -format and to auto mode's
+// content sniffing. Programs that embed the scanner call it from init to
+// add their own formats. Registering a name twice, or a built-in name, panics.
+func Register(p Parser) {
+	name := strings.ToLower(p.Name())
+	switch name {
+	case "", "auto", "squid", "dns", "windowsdns", "csv", "suricata", "eve", "evtx", "mde", "sysmon", "journal", "workspace", "chrome":
+		panic(fmt.Sprintf("parsers: cannot register parser named %q", p.Name()))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("parsers: Register called twice for %s", p.Name()))
+	}
+	registry[name] = p
+}
+
+// Lookup returns the registered parser with the given name.
+func Lookup(name string) (Parser, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[strings.ToLower(name)]
+	return p, ok
+}
+
+// Registered returns every registered parser, sorted by name.
+func Registered() []Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]Parser, 0, len(registry))
+	for _, p := range registry {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
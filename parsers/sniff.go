@@ -0,0 +1,117 @@
+package parsers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// SniffLines is how many leading records Sniff samples from a file.
+const SniffLines = 50
+
+// Sniffer is implemented by formats that can't be judged line by line,
+// such as CSV with its header. It returns the share of sample lines the
+// format would turn into entries.
+type Sniffer interface {
+	Sniff(lines []string) float64
+}
+
+// Sniff reads the first records of a file and scores each candidate by the
+// share of them it parses. It returns the best candidate and its score, or
+// nil when none parses anything. Earlier candidates win ties.
+func Sniff(path string, candidates []Parser) (Parser, float64, error) {
+	lines, err := sampleLines(path, SniffLines)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(lines) == 0 {
+		return nil, 0, nil
+	}
+
+	var best Parser
+	bestScore := 0.0
+	for _, p := range candidates {
+		if score := SniffScore(p, lines); score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, bestScore, nil
+}
+
+// SniffScore is the share of lines p accepts or knowingly skips. Formats that are neither
+// line parsers nor Sniffers score zero.
+func SniffScore(p Parser, lines []string) float64 {
+	if len(lines) == 0 {
+		return 0
+	}
+	if s, ok := p.(Sniffer); ok {
+		return s.Sniff(lines)
+	}
+	lp, ok := p.(LineParser)
+	if !ok {
+		return 0
+	}
+	parsed := 0
+	for _, line := range lines {
+		if _, err := lp.ParseLine(line); err == nil || errors.Is(err, ErrSkip) {
+			parsed++
+		}
+	}
+	return float64(parsed) / float64(len(lines))
+}
+
+// sampleLines returns up to n non-blank lines from the start of a file. A
+// leading "#" line is kept since it may be a CSV header, as are ELFF
+// "#Fields:" directives; other comments are skipped as the line parsers do.
+func sampleLines(path string, n int) ([]string, error) {
+	file, _, err := fsutil.OpenText(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() && len(lines) < n {
+		line := CleanLine(scanner.Text())
+		if strings.TrimSpace(line) == "" || (len(lines) > 0 && strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#Fields:")) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// Sniff scores a CSV sample: the header must name a destination column, and
+// each data row counts when it has a value there. The header itself counts
+// as parsed so a one-row sample can still win.
+func (p *CSVParser) Sniff(lines []string) float64 {
+	reader := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil || len(records) < 2 {
+		return 0
+	}
+	dstCol := findCol(mapColumns(records[0]), "destination", "dst", "domain", "host", "url", "dest", "dst_host")
+	if dstCol == -1 {
+		return 0
+	}
+
+	parsed := 1
+	for _, row := range records[1:] {
+		if dstCol < len(row) && unquoteField(row[dstCol]) != "" {
+			parsed++
+		}
+	}
+	return float64(parsed) / float64(len(records))
+}
@@ -1,10 +1,9 @@
 package parsers
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -20,37 +19,20 @@ func (p *SquidParser) Name() string {
 }
 
 func (p *SquidParser) Parse(filepath string) ([]LogEntry, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return nil, fmt.Errorf("opening %s: %w", filepath, err)
-	}
-	defer file.Close()
-
-	var entries []LogEntry
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		entry, err := parseSquidLine(line)
-		if err != nil {
-			continue // skip malformed lines
-		}
-		entries = append(entries, entry)
-	}
+	return parseLines(context.Background(), filepath, p)
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading %s: %w", filepath, err)
-	}
+func (p *SquidParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	return parseLines(ctx, filepath, p)
+}
 
-	return entries, nil
+// ParseLine parses a single access.log line.
+func (p *SquidParser) ParseLine(line string) (LogEntry, error) {
+	return parseSquidLine(line)
 }
 
 func parseSquidLine(line string) (LogEntry, error) {
-	fields := strings.Fields(line)
+	fields := unquoteFields(strings.Fields(line))
 	if len(fields) < 8 {
 		return LogEntry{}, fmt.Errorf("not enough fields")
 	}
@@ -66,13 +48,13 @@ func parseSquidLine(line string) (LogEntry, error) {
 	sourceIP := fields[2]
 
 	// Action/status code is field 3 (e.g., TCP_MISS/200)
-	statusCode := ""
+	action, statusCode := fields[3], ""
 	if parts := strings.SplitN(fields[3], "/", 2); len(parts) == 2 {
-		statusCode = parts[1]
+		action, statusCode = parts[0], parts[1]
 	}
 
-	// Bytes is field 4
-	bytesSent, _ := strconv.ParseInt(fields[4], 10, 64)
+	// Bytes is field 4: the size of the reply delivered to the client
+	bytesReceived, _ := strconv.ParseInt(fields[4], 10, 64)
 
 	// Method is field 5
 	method := fields[5]
@@ -83,23 +65,30 @@ func parseSquidLine(line string) (LogEntry, error) {
 	// Extract domain from URL
 	domain := extractDomain(rawURL)
 
+	// Ident/username is field 7, "-" when the request was not authenticated
+	user := ""
+	if fields[7] != "-" {
+		user = squidUnescape(fields[7])
+	}
+
 	return LogEntry{
-		Timestamp:  ts,
-		SourceIP:   sourceIP,
-		Domain:     domain,
-		URL:        rawURL,
-		Method:     method,
-		StatusCode: statusCode,
-		BytesSent:  bytesSent,
-		RawLine:    line,
+		Timestamp:     ts,
+		SourceIP:      sourceIP,
+		Domain:        domain,
+		URL:           rawURL,
+		Method:        method,
+		StatusCode:    statusCode,
+		Action:        action,
+		BytesReceived: bytesReceived,
+		User:          user,
+		RawLine:       line,
 	}, nil
 }
 
 func extractDomain(rawURL string) string {
 	// Handle CONNECT method URLs (just host:port)
 	if !strings.Contains(rawURL, "://") {
-		host := strings.SplitN(rawURL, ":", 2)[0]
-		return strings.ToLower(host)
+		return strings.ToLower(connectHost(rawURL))
 	}
 
 	parsed, err := url.Parse(rawURL)
@@ -110,3 +99,20 @@ func extractDomain(rawURL string) string {
 	host := parsed.Hostname()
 	return strings.ToLower(host)
 }
+
+// connectHost returns the host of a CONNECT target: "host:443",
+// "[2606:4700::1]:443", a bare "2606:4700::1", or a host with no port.
+// A bare IPv6 address has no port, since its last group could be one.
+func connectHost(target string) string {
+	if strings.HasPrefix(target, "[") {
+		if end := strings.IndexByte(target, ']'); end > 0 {
+			return target[1:end]
+		}
+		return strings.TrimPrefix(target, "[")
+	}
+	if strings.Count(target, ":") > 1 {
+		return target
+	}
+	host, _, _ := strings.Cut(target, ":")
+	return host
+}
@@ -0,0 +1,243 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Squid's predefined logformat names and what they expand to.
+var squidFormats = map[string]string{
+	"squid":    `%ts.%03tu %6tr %>a %Ss/%03>Hs %<st %rm %ru %[un %Sh/%<a %mt`,
+	"common":   `%>a %[ui %[un [%tl] "%rm %ru HTTP/%rv" %>Hs %<st %Ss:%Sh`,
+	"combined": `%>a %[ui %[un [%tl] "%rm %ru HTTP/%rv" %>Hs %<st "%{Referer}>h" "%{User-Agent}>h" %Ss:%Sh`,
+}
+
+// squidCodes are the logformat codes the parser recognizes, longest first
+// so that e.g. ">Hs" is not read as ">H" followed by "s". Codes not listed
+// are still matched, then ignored.
+var squidCodes = []string{
+	">Hs", "<Hs", ">rm", ">ru", ">rd", ">rv", ">st", "<st", ">la", ">lp",
+	">a", ">A", ">p", "<a", "<A", "<p", ">h", "<h",
+	"ts", "tu", "tl", "tg", "tr", "un", "ul", "ui", "us", "ue",
+	"rm", "ru", "rd", "rp", "rv", "Ss", "Sh", "Hs", "st", "mt", "la", "lp",
+}
+
+// squidToken matches one %code, with Squid's optional modifiers: quoting
+// and escaping flags, alignment, width, precision, and a {argument}.
+var squidToken = regexp.MustCompile(`^%([-"\[#'/]*)(\d*)(?:\.\d+)?(?:\{([^}]*)\})?`)
+
+var (
+	squidDirective = regexp.MustCompile(`^logformat\s+\S+\s+(.+)$`)
+	squidOtherCode = regexp.MustCompile(`^[<>]?[A-Za-z]{1,2}`)
+	squidSpace     = regexp.MustCompile(`\s+`)
+)
+
+// squidField is what one captured value of a logformat means.
+type squidField struct {
+	code string
+	arg  string // header name for %{...}>h
+}
+
+// SquidFormatParser reads Squid access logs written with a custom logformat
+// directive, mapping each %code to its column. The predefined names squid,
+// common, and combined may be given instead of a format string.
+type SquidFormatParser struct {
+	name   string
+	re     *regexp.Regexp
+	fields []squidField
+}
+
+// NewSquidFormatParser compiles a logformat. A full squid.conf line
+// ("logformat name ...") is accepted as well as the bare format.
+func NewSquidFormatParser(name, format string) (*SquidFormatParser, error) {
+	format = strings.TrimSpace(format)
+	if m := squidDirective.FindStringSubmatch(format); m != nil {
+		format = m[1]
+	}
+	if predefined, ok := squidFormats[format]; ok {
+		format = predefined
+	}
+
+	p := &SquidFormatParser{name: name}
+	var pattern strings.Builder
+	pattern.WriteString(`^\s*`)
+	hasDest := false
+	for rest := format; rest != ""; {
+		if strings.HasPrefix(rest, "%%") {
+			pattern.WriteString("%")
+			rest = rest[2:]
+			continue
+		}
+		if rest[0] != '%' {
+			i := strings.IndexByte(rest, '%')
+			if i < 0 {
+				i = len(rest)
+			}
+			pattern.WriteString(squidLiteral(rest[:i]))
+			rest = rest[i:]
+			continue
+		}
+
+		m := squidToken.FindStringSubmatch(rest)
+		rest = rest[len(m[0]):]
+		code := ""
+		for _, c := range squidCodes {
+			if strings.HasPrefix(rest, c) {
+				code = c
+				break
+			}
+		}
+		if code == "" {
+			code = squidOtherCode.FindString(rest)
+		}
+		if code == "" {
+			return nil, fmt.Errorf("logformat for %s: bad token near %q", name, "%"+rest)
+		}
+		rest = rest[len(code):]
+
+		switch {
+		case strings.Contains(m[1], `"`):
+			pattern.WriteString(`"((?:[^"\\]|\\.)*)"`)
+		case rest == "":
+			pattern.WriteString(`(.*?)`)
+		case rest[0] == '"' || rest[0] == ']':
+			pattern.WriteString(`([^` + regexp.QuoteMeta(rest[:1]) + `]*)`)
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '%':
+			pattern.WriteString(`(\S*)`)
+		default:
+			pattern.WriteString(`([^\s` + regexp.QuoteMeta(rest[:1]) + `]*)`)
+		}
+		p.fields = append(p.fields, squidField{code: code, arg: strings.ToLower(m[3])})
+		if code == "ru" || code == ">ru" || code == "rd" || code == ">rd" {
+			hasDest = true
+		}
+	}
+	pattern.WriteString(`\s*$`)
+	if !hasDest {
+		return nil, fmt.Errorf("logformat for %s needs %%ru or %%rd", name)
+	}
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("logformat for %s: %w", name, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// squidLiteral turns literal format text into a pattern; runs of spaces
+// also absorb the padding of width-aligned values.
+func squidLiteral(s string) string {
+	parts := squidSpace.Split(s, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, `\s+`)
+}
+
+func (p *SquidFormatParser) Name() string {
+	return p.name
+}
+
+func (p *SquidFormatParser) Parse(filepath string) ([]LogEntry, error) {
+	return parseLines(context.Background(), filepath, p)
+}
+
+func (p *SquidFormatParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	return parseLines(ctx, filepath, p)
+}
+
+// ParseLine matches one line against the logformat.
+func (p *SquidFormatParser) ParseLine(line string) (LogEntry, error) {
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return LogEntry{}, fmt.Errorf("line does not match the %s logformat", p.name)
+	}
+
+	entry := LogEntry{RawLine: line}
+	var secs, millis string
+	for i, f := range p.fields {
+		v := m[i+1]
+		if v == "-" {
+			continue
+		}
+		switch f.code {
+		case "ts":
+			secs = v
+		case "tu":
+			millis = v
+		case "tl", "tg":
+			if t, err := time.Parse("02/Jan/2006:15:04:05 -0700", v); err == nil {
+				entry.Timestamp = t.UTC()
+			}
+		case ">a", ">A":
+			entry.SourceIP = v
+		case "un", "ul", "ui", "us", "ue":
+			if entry.User == "" {
+				entry.User = squidUnescape(v)
+			}
+		case "rm", ">rm":
+			entry.Method = v
+		case "ru", ">ru":
+			entry.URL = v
+			if entry.Domain == "" {
+				entry.Domain = extractDomain(v)
+			}
+		case "rd", ">rd":
+			entry.Domain = strings.ToLower(v)
+		case "Ss":
+			entry.Action = v
+		case ">Hs", "Hs", "<Hs":
+			entry.StatusCode = v
+		case "<st", "st": // st adds the request to the reply; the reply dominates
+			entry.BytesReceived, _ = strconv.ParseInt(v, 10, 64)
+		case ">st":
+			entry.BytesSent, _ = strconv.ParseInt(v, 10, 64)
+		case ">h":
+			switch f.arg {
+			case "referer":
+				entry.Referer = v
+			case "user-agent":
+				entry.UserAgent = v
+			}
+		}
+	}
+	if secs != "" {
+		if i := strings.IndexByte(secs, '.'); i >= 0 && millis == "" {
+			secs, millis = secs[:i], secs[i+1:]
+		}
+		sec, err := strconv.ParseInt(secs, 10, 64)
+		if err != nil {
+			return LogEntry{}, fmt.Errorf("bad timestamp: %w", err)
+		}
+		ms, _ := strconv.Atoi((millis + "000")[:3])
+		entry.Timestamp = time.Unix(sec, int64(ms)*int64(time.Millisecond)).UTC()
+	}
+	if entry.Domain == "" {
+		return LogEntry{}, fmt.Errorf("no destination")
+	}
+	return entry, nil
+}
+
+// squidUnescape decodes the %xx escapes Squid writes into user names.
+func squidUnescape(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
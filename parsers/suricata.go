@@ -0,0 +1,155 @@
+package parsers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SuricataParser handles Suricata's EVE JSON output (eve.json), one event
+// per line. Only dns, tls, and http events name a destination; flow,
+// alert, stats, and the rest are skipped:
+//
+//	{"timestamp":"2025-06-10T08:30:00.123456+0000","event_type":"tls","src_ip":"10.0.0.5","dest_ip":"104.18.32.47","tls":{"sni":"chat.openai.com","ja3":{"hash":"e7d705a3286e19ea42f587b344ee6865"}}}
+//
+// A tls event's destination is dest_ip with the server name as SNI; an
+// http event's is http.hostname; a dns event's is the queried rrname.
+// DNS answers are skipped so each lookup counts once.
+type SuricataParser struct{}
+
+func (p *SuricataParser) Name() string {
+	return "suricata"
+}
+
+func (p *SuricataParser) Parse(filepath string) ([]LogEntry, error) {
+	return parseLines(context.Background(), filepath, p)
+}
+
+func (p *SuricataParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	return parseLines(ctx, filepath, p)
+}
+
+// eveEvent holds the parts of an EVE record the parser reads.
+type eveEvent struct {
+	Timestamp string `json:"timestamp"`
+	EventType string `json:"event_type"`
+	SrcIP     string `json:"src_ip"`
+	DestIP    string `json:"dest_ip"`
+	DestPort  int    `json:"dest_port"`
+	DNS       *struct {
+		Type    string `json:"type"`
+		RRName  string `json:"rrname"`
+		Queries []struct {
+			RRName string `json:"rrname"`
+		} `json:"queries"` // EVE version 3 (Suricata 8) requests
+	} `json:"dns"`
+	TLS *struct {
+		SNI string `json:"sni"`
+		JA3 struct {
+			Hash string `json:"hash"`
+		} `json:"ja3"`
+	} `json:"tls"`
+	HTTP *struct {
+		Hostname  string          `json:"hostname"`
+		URL       string          `json:"url"`
+		Method    string          `json:"http_method"`
+		Status    json.RawMessage `json:"status"` // a number, or a string in old versions
+		Length    int64           `json:"length"`
+		Referer   string          `json:"http_refer"`
+		UserAgent string          `json:"http_user_agent"`
+	} `json:"http"`
+}
+
+// ParseLine parses a single EVE record. Other event types and dns answers
+// return ErrSkip.
+func (p *SuricataParser) ParseLine(line string) (LogEntry, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return LogEntry{}, fmt.Errorf("not a JSON object")
+	}
+	var ev eveEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil {
+		return LogEntry{}, fmt.Errorf("bad EVE record: %w", err)
+	}
+
+	entry := LogEntry{SourceIP: ev.SrcIP, RawLine: line}
+	switch ev.EventType {
+	case "dns":
+		if ev.DNS == nil {
+			return LogEntry{}, fmt.Errorf("dns event without dns object")
+		}
+		switch ev.DNS.Type {
+		case "query", "request":
+		default:
+			return LogEntry{}, ErrSkip
+		}
+		name := ev.DNS.RRName
+		if name == "" && len(ev.DNS.Queries) > 0 {
+			name = ev.DNS.Queries[0].RRName
+		}
+		entry.Domain = name
+	case "tls":
+		if ev.TLS == nil {
+			return LogEntry{}, fmt.Errorf("tls event without tls object")
+		}
+		entry.Domain, entry.SNI, entry.JA3 = ev.DestIP, ev.TLS.SNI, ev.TLS.JA3.Hash
+	case "http":
+		if ev.HTTP == nil {
+			return LogEntry{}, fmt.Errorf("http event without http object")
+		}
+		h := ev.HTTP
+		entry.Domain = h.Hostname
+		if entry.Domain == "" {
+			entry.Domain = ev.DestIP
+		}
+		entry.URL = eveURL(entry.Domain, ev.DestPort, h.URL)
+		entry.Method = h.Method
+		entry.StatusCode = strings.Trim(string(h.Status), `"`)
+		entry.BytesReceived = h.Length // response body
+		entry.Referer = h.Referer
+		entry.UserAgent = h.UserAgent
+	default:
+		if ev.EventType == "" {
+			return LogEntry{}, fmt.Errorf("no event_type")
+		}
+		return LogEntry{}, ErrSkip
+	}
+	entry.Domain = strings.ToLower(strings.TrimSuffix(entry.Domain, "."))
+	entry.SNI = strings.ToLower(entry.SNI)
+	if entry.Domain == "" && entry.SNI == "" {
+		return LogEntry{}, fmt.Errorf("no destination")
+	}
+
+	ts, err := parseEVETime(ev.Timestamp)
+	if err != nil {
+		return LogEntry{}, err
+	}
+	entry.Timestamp = ts
+	return entry, nil
+}
+
+// eveURL rebuilds the request URL from the host and the logged path. EVE
+// records the path alone unless the request was made to a proxy.
+func eveURL(host string, port int, path string) string {
+	if path == "" || strings.Contains(path, "://") {
+		return path
+	}
+	if port != 0 && port != 80 {
+		host += ":" + strconv.Itoa(port)
+	}
+	return "http://" + host + path
+}
+
+// parseEVETime parses EVE's timestamp, which has a numeric offset without
+// a colon (2025-06-10T08:30:00.123456+0000).
+func parseEVETime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999-0700", time.RFC3339Nano} {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad timestamp %q", s)
+}
@@ -0,0 +1,224 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// SysmonParser handles Sysmon DNS query events (event ID 22) exported from
+// the Microsoft-Windows-Sysmon/Operational log, as XML or as JSON:
+//
+//	wevtutil qe Microsoft-Windows-Sysmon/Operational /q:"*[System[EventID=22]]" /f:xml > sysmon.xml
+//
+// XML may be a bare run of <Event> elements or wrapped in <Events>, as
+// Event Viewer saves it. JSON may be one event per line or an array, with
+// the event data at the top level (NXLog) or under EventData, event_data,
+// or winlog.event_data (Winlogbeat). Other event IDs are skipped.
+//
+// QueryName is the destination, Image the process, and User the user; the
+// computer is the source and device.
+type SysmonParser struct{}
+
+func (p *SysmonParser) Name() string {
+	return "sysmon"
+}
+
+func (p *SysmonParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+func (p *SysmonParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var entries []LogEntry
+	if isJSONStart(r) {
+		entries, err = parseSysmonJSON(ctx, r)
+	} else {
+		entries, err = parseSysmonXML(ctx, r)
+	}
+	for i := range entries {
+		entries[i].SourceFile = filepath
+	}
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath, err)
+	}
+	return entries, err
+}
+
+// Sniff scores a Sysmon sample: it must name the Sysmon provider, or hold
+// the QueryName and Image fields of a DNS query event.
+func (p *SysmonParser) Sniff(lines []string) float64 {
+	sample := strings.Join(lines, "\n")
+	if strings.Contains(sample, "Microsoft-Windows-Sysmon") || (strings.Contains(sample, "QueryName") && strings.Contains(sample, "Image")) {
+		return 1
+	}
+	return 0
+}
+
+const sysmonDNSEvent = "22"
+
+// sysmonXMLEvent is the part of an <Event> the parser reads.
+type sysmonXMLEvent struct {
+	System struct {
+		EventID     string `xml:"EventID"`
+		Computer    string `xml:"Computer"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+	} `xml:"System"`
+	Data []struct {
+		Name  string `xml:"Name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"EventData>Data"`
+}
+
+// parseSysmonXML reads every <Event> element, wherever it is nested.
+func parseSysmonXML(ctx context.Context, r io.Reader) ([]LogEntry, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil // OpenText has already decoded UTF-16 and Latin-1
+	}
+	var entries []LogEntry
+	for n := 0; ; n++ {
+		if n%ctxCheckLines == 0 && ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		line, _ := dec.InputPos()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Event" {
+			continue
+		}
+		var ev sysmonXMLEvent
+		if err := dec.DecodeElement(&ev, &start); err != nil {
+			return entries, err
+		}
+		if strings.TrimSpace(ev.System.EventID) != sysmonDNSEvent {
+			continue
+		}
+		data := make(map[string]string, len(ev.Data))
+		for _, d := range ev.Data {
+			data[d.Name] = strings.TrimSpace(d.Value)
+		}
+		if entry, ok := sysmonDNSEntry(data, ev.System.Computer, ev.System.TimeCreated.SystemTime); ok {
+			entry.LineNumber = line
+			entries = append(entries, entry)
+		}
+	}
+}
+
+// parseSysmonJSON reads a stream of JSON events, or arrays of them.
+func parseSysmonJSON(ctx context.Context, r io.Reader) ([]LogEntry, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var entries []LogEntry
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			winlog, _ := v["winlog"].(map[string]any)
+			if first(jsonString(v["EventID"]), jsonString(v["event_id"]), jsonString(winlog["event_id"])) != sysmonDNSEvent {
+				return
+			}
+			data := make(map[string]string)
+			for _, m := range []any{v, v["EventData"], v["event_data"], winlog["event_data"]} {
+				if m, ok := m.(map[string]any); ok {
+					for k, val := range m {
+						if s := jsonString(val); s != "" {
+							data[k] = s
+						}
+					}
+				}
+			}
+			host, _ := v["host"].(map[string]any)
+			computer := first(data["Computer"], data["Hostname"], jsonString(winlog["computer_name"]), jsonString(host["name"]))
+			if entry, ok := sysmonDNSEntry(data, computer, first(jsonString(v["@timestamp"]), data["EventTime"])); ok {
+				raw, _ := json.Marshal(v)
+				entry.RawLine = string(raw)
+				entries = append(entries, entry)
+			}
+		}
+	}
+	for {
+		if ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		var v any
+		if err := dec.Decode(&v); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, err
+		}
+		walk(v)
+	}
+}
+
+// first returns the first non-empty string.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// sysmonDNSEntry maps the event data of a Sysmon event 22 onto a LogEntry.
+// Sysmon's UtcTime gives the time; fallback is the time the event was
+// logged.
+func sysmonDNSEntry(data map[string]string, computer, logged string) (LogEntry, bool) {
+	name := strings.ToLower(strings.TrimSuffix(data["QueryName"], "."))
+	if name == "" {
+		return LogEntry{}, false
+	}
+	entry := LogEntry{
+		SourceIP:    computer,
+		Domain:      name,
+		StatusCode:  data["QueryStatus"],
+		ProcessName: windowsBase(data["Image"]),
+		DeviceName:  computer,
+	}
+	if user := data["User"]; user != "" && !serviceAccount(user) {
+		entry.User = user
+	}
+	if ts, err := time.Parse("2006-01-02 15:04:05.999", data["UtcTime"]); err == nil {
+		entry.Timestamp = ts
+	} else {
+		entry.Timestamp, _ = parseFlexibleTime(logged)
+	}
+	entry.RawLine = fmt.Sprintf("%s EventID=%s Image=%s User=%s QueryName=%s QueryStatus=%s",
+		data["UtcTime"], sysmonDNSEvent, data["Image"], data["User"], data["QueryName"], data["QueryStatus"])
+	return entry, true
+}
+
+// windowsBase returns the file name of a Windows path.
+func windowsBase(path string) string {
+	if i := strings.LastIndexAny(path, `\/`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
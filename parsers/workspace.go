@@ -0,0 +1,179 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// WorkspaceParser handles Chrome browser events from the Google Workspace
+// Admin SDK Reports API (applicationName "chrome"), as saved from
+//
+//	GET https://admin.googleapis.com/admin/reports/v1/activity/users/all/applications/chrome
+//
+// The file may hold one response or several, an array of activities, or
+// one activity per line. Every event that names a URL is used; Chrome
+// Enterprise reporting sends them for visits, interstitials, and content
+// transfers. The actor's email is the user, the URL the destination,
+// EVENT_RESULT the action, and DEVICE_NAME the device.
+type WorkspaceParser struct{}
+
+func (p *WorkspaceParser) Name() string {
+	return "workspace"
+}
+
+func (p *WorkspaceParser) Parse(filepath string) ([]LogEntry, error) {
+	return p.ParseContext(context.Background(), filepath)
+}
+
+func (p *WorkspaceParser) ParseContext(ctx context.Context, filepath string) ([]LogEntry, error) {
+	file, _, err := fsutil.OpenText(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath, err)
+	}
+	defer file.Close()
+
+	entries, err := ParseWorkspaceActivities(ctx, file)
+	for i := range entries {
+		entries[i].SourceFile = filepath
+	}
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath, err)
+	}
+	return entries, err
+}
+
+// Sniff scores a Reports API sample by its kind or application markers.
+func (p *WorkspaceParser) Sniff(lines []string) float64 {
+	sample := strings.Join(lines, "\n")
+	if strings.Contains(sample, "admin#reports#activit") || strings.Contains(sample, `"applicationName": "chrome"`) || strings.Contains(sample, `"applicationName":"chrome"`) {
+		return 1
+	}
+	return 0
+}
+
+// workspaceActivity is the part of a Reports API activity the parser reads.
+type workspaceActivity struct {
+	ID struct {
+		Time string `json:"time"`
+	} `json:"id"`
+	Actor struct {
+		Email string `json:"email"`
+	} `json:"actor"`
+	IPAddress string `json:"ipAddress"`
+	Events    []struct {
+		Name       string `json:"name"`
+		Parameters []struct {
+			Name       string   `json:"name"`
+			Value      string   `json:"value"`
+			MultiValue []string `json:"multiValue"`
+		} `json:"parameters"`
+	} `json:"events"`
+}
+
+// ParseWorkspaceActivities reads a stream of Reports API responses or
+// activities, as WorkspaceParser does for a file.
+func ParseWorkspaceActivities(ctx context.Context, r io.Reader) ([]LogEntry, error) {
+	br := bufio.NewReader(r)
+	if !isJSONStart(br) {
+		return nil, fmt.Errorf("not a Reports API JSON document")
+	}
+	dec := json.NewDecoder(br)
+	var entries []LogEntry
+	var walk func(raw json.RawMessage) error
+	walk = func(raw json.RawMessage) error {
+		raw = json.RawMessage(strings.TrimSpace(string(raw)))
+		if len(raw) > 0 && raw[0] == '[' {
+			var list []json.RawMessage
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return err
+			}
+			for _, item := range list {
+				if err := walk(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var page struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		if page.Items != nil {
+			for _, item := range page.Items {
+				if err := walk(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var act workspaceActivity
+		if err := json.Unmarshal(raw, &act); err != nil {
+			return err
+		}
+		entries = append(entries, workspaceEntries(act, string(raw))...)
+		return nil
+	}
+	for {
+		if ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, err
+		}
+		if err := walk(raw); err != nil {
+			return entries, err
+		}
+	}
+}
+
+// workspaceEntries maps each event of an activity that names a URL.
+func workspaceEntries(act workspaceActivity, raw string) []LogEntry {
+	var entries []LogEntry
+	for _, ev := range act.Events {
+		params := make(map[string]string, len(ev.Parameters))
+		for _, p := range ev.Parameters {
+			v := p.Value
+			if v == "" && len(p.MultiValue) > 0 {
+				v = p.MultiValue[0]
+			}
+			params[p.Name] = strings.TrimSpace(v)
+		}
+		url := params["URL"]
+		if url == "" {
+			continue
+		}
+		entry := LogEntry{
+			SourceIP:   act.IPAddress,
+			User:       first(act.Actor.Email, params["PROFILE_USER_NAME"], params["DEVICE_USER"]),
+			Action:     params["EVENT_RESULT"],
+			DeviceName: params["DEVICE_NAME"],
+			RawLine:    raw,
+		}
+		if strings.Contains(url, "://") {
+			entry.URL, entry.Domain = url, extractDomain(url)
+		} else {
+			host, _, _ := strings.Cut(url, "/")
+			entry.Domain = strings.ToLower(connectHost(host))
+		}
+		if entry.Domain == "" {
+			continue
+		}
+		if entry.SourceIP == "" {
+			entry.SourceIP = entry.DeviceName
+		}
+		entry.Timestamp, entry.Floating = parseFlexibleTime(act.ID.Time)
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,107 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Policy describes which AI usage is sanctioned. Findings matched by any
+// allow rule are not counted as violations.
+type Policy struct {
+	Name            string   `json:"name"`
+	AllowServices   []string `json:"allow_services"`
+	AllowCategories []string `json:"allow_categories"`
+	AllowDomains    []string `json:"allow_domains"`
+	AllowSources    []string `json:"allow_sources"` // source IPs, CIDRs, or users exempt from the policy
+}
+
+// Load reads a policy/allowlist JSON file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file: %w", err)
+	}
+
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing policy file: %w", err)
+	}
+	for _, src := range p.AllowSources {
+		if strings.Contains(src, "/") {
+			if _, err := netip.ParsePrefix(src); err != nil {
+				return nil, fmt.Errorf("parsing policy file: allow_sources: %w", err)
+			}
+		}
+	}
+	if p.Name == "" {
+		p.Name = path
+	}
+	return &p, nil
+}
+
+// Allows reports whether the finding is sanctioned by the policy.
+// A nil policy allows nothing, so every finding is a violation, and
+// lookalike hosts are never allowed.
+func (p *Policy) Allows(f analyzer.Finding) bool {
+	if p == nil {
+		return false
+	}
+	if f.Category == analyzer.CategoryLookalike {
+		return false // imitates the service it names, so never sanctioned by it
+	}
+	if containsFold(p.AllowServices, f.ServiceName) || containsFold(p.AllowCategories, f.Category) {
+		return true
+	}
+	for _, src := range p.AllowSources {
+		if sourceMatches(src, f) {
+			return true
+		}
+	}
+	domain := strings.ToLower(f.Domain)
+	for _, d := range p.AllowDomains {
+		d = strings.ToLower(d)
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the summary with allowed findings removed.
+func (p *Policy) Apply(s analyzer.Summary) analyzer.Summary {
+	if p == nil {
+		return s
+	}
+	return s.Filter(func(f analyzer.Finding) bool { return !p.Allows(f) })
+}
+
+// sourceMatches reports whether an allow_sources entry covers the
+// finding's source: a CIDR holding its address, the same address in any
+// notation, or its user name.
+func sourceMatches(src string, f analyzer.Finding) bool {
+	if f.User != "" && strings.EqualFold(src, f.User) {
+		return true
+	}
+	addr, err := netip.ParseAddr(analyzer.NormalizeIP(f.SourceIP))
+	if err != nil {
+		return src == f.SourceIP
+	}
+	if prefix, err := netip.ParsePrefix(src); err == nil {
+		return prefix.Contains(addr)
+	}
+	return analyzer.NormalizeIP(src) == addr.String()
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
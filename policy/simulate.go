@@ -0,0 +1,62 @@
+package policy
+
+import "github.com/shadow-ai-hunter/analyzer"
+
+// Simulation compares violation counts under the current and a proposed policy.
+type Simulation struct {
+	CurrentPolicy  string
+	ProposedPolicy string
+	TotalFindings  int
+	Current        int
+	Proposed       int
+	ByService      map[string]Delta
+	ByCategory     map[string]Delta
+}
+
+// Delta holds violation counts for one service or category.
+type Delta struct {
+	Current  int
+	Proposed int
+}
+
+// Change is the difference in violations (negative means fewer).
+func (d Delta) Change() int {
+	return d.Proposed - d.Current
+}
+
+// Simulate re-evaluates stored findings against both policies. current may be
+// nil, meaning no findings are currently allowed.
+func Simulate(findings []analyzer.Finding, current, proposed *Policy) Simulation {
+	sim := Simulation{
+		CurrentPolicy:  "(none)",
+		ProposedPolicy: "(none)",
+		TotalFindings:  len(findings),
+		ByService:      make(map[string]Delta),
+		ByCategory:     make(map[string]Delta),
+	}
+	if current != nil {
+		sim.CurrentPolicy = current.Name
+	}
+	if proposed != nil {
+		sim.ProposedPolicy = proposed.Name
+	}
+
+	for _, f := range findings {
+		svc := sim.ByService[f.ServiceName]
+		cat := sim.ByCategory[f.Category]
+		if !current.Allows(f) {
+			sim.Current++
+			svc.Current++
+			cat.Current++
+		}
+		if !proposed.Allows(f) {
+			sim.Proposed++
+			svc.Proposed++
+			cat.Proposed++
+		}
+		sim.ByService[f.ServiceName] = svc
+		sim.ByCategory[f.Category] = cat
+	}
+
+	return sim
+}
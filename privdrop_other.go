@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import "errors"
+
+// dropPrivileges is only supported on Unix; run the service under a
+// restricted account instead.
+func dropPrivileges(username, chroot string) error {
+	if username != "" || chroot != "" {
+		return errors.New("-user and -chroot are only supported on Unix")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// dropPrivileges confines the process to chroot (if set) and then switches
+// to the named user and that user's groups. It must run after any
+// privileged setup, such as binding a low port, and before reading logs.
+func dropPrivileges(username, chroot string) error {
+	var uid, gid int
+	var groups []int
+	if username != "" {
+		// Resolve the user before chroot, while /etc/passwd is still visible
+		u, err := user.Lookup(username)
+		if err != nil {
+			return err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return fmt.Errorf("user %s: uid %q is not numeric", username, u.Uid)
+		}
+		if gid, err = strconv.Atoi(u.Gid); err != nil {
+			return fmt.Errorf("user %s: gid %q is not numeric", username, u.Gid)
+		}
+		gids, err := u.GroupIds()
+		if err != nil {
+			return fmt.Errorf("user %s: %w", username, err)
+		}
+		for _, g := range gids {
+			if n, err := strconv.Atoi(g); err == nil {
+				groups = append(groups, n)
+			}
+		}
+	}
+
+	if chroot != "" {
+		if err := syscall.Chroot(chroot); err != nil {
+			return fmt.Errorf("chroot %s: %w", chroot, err)
+		}
+		if err := os.Chdir("/"); err != nil {
+			return err
+		}
+	}
+
+	if username == "" {
+		return nil
+	}
+	if err := syscall.Setgroups(groups); err != nil {
+		return fmt.Errorf("setting groups: %w", err)
+	}
+	if err := syscall.Setgid(gid); err != nil {
+		return fmt.Errorf("setting gid %d: %w", gid, err)
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return fmt.Errorf("setting uid %d: %w", uid, err)
+	}
+	// Confirm there is no way back
+	if uid != 0 && syscall.Setuid(0) == nil {
+		return fmt.Errorf("privileges were not dropped: could regain root")
+	}
+	return nil
+}
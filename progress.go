@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// progressInterval limits how often the progress line is redrawn.
+const progressInterval = 250 * time.Millisecond
+
+// progressBar draws a single self-overwriting status line on a terminal:
+// files done, overall percentage, lines/sec, and an ETA based on bytes read.
+// It is also the writer for console logs, clearing the line before a log
+// message so the two don't interleave.
+type progressBar struct {
+	mu      sync.Mutex
+	w       io.Writer
+	enabled bool
+	drawn   bool
+
+	files     int
+	doneFiles int
+	total     int64 // bytes across all files
+	doneBytes int64 // bytes in finished files
+	curBytes  int64 // bytes read in the current file
+	lines     int
+	start     time.Time
+	last      time.Time
+}
+
+// newProgressBar returns a bar writing to w. It only draws when show is set
+// and w is a terminal.
+func newProgressBar(w *os.File, show bool) *progressBar {
+	p := &progressBar{w: w}
+	if info, err := w.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		p.enabled = show
+	}
+	return p
+}
+
+// begin sizes the scan and starts the clock.
+func (p *progressBar) begin(files []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files, p.start = len(files), time.Now()
+	for _, f := range files {
+		if info, err := fsutil.Stat(f); err == nil {
+			p.total += info.Size()
+		}
+	}
+}
+
+// Write passes log output through, clearing the progress line first.
+func (p *progressBar) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	return p.w.Write(b)
+}
+
+// advance records progress within the current file.
+func (p *progressBar) advance(offset int64, lines int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.curBytes = offset
+	p.lines += lines
+	p.draw(false)
+}
+
+// fileDone records a finished file. lines is the count not already passed
+// to advance.
+func (p *progressBar) fileDone(size int64, lines int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.doneFiles++
+	p.doneBytes += size
+	p.curBytes = 0
+	p.lines += lines
+	p.draw(true)
+}
+
+// finish removes the progress line.
+func (p *progressBar) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	p.enabled = false
+}
+
+func (p *progressBar) clear() {
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.drawn = false
+	}
+}
+
+func (p *progressBar) draw(force bool) {
+	if !p.enabled || (!force && time.Since(p.last) < progressInterval) {
+		return
+	}
+	p.last = time.Now()
+
+	elapsed := time.Since(p.start).Seconds()
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.lines) / elapsed
+	}
+	line := fmt.Sprintf("[*] %d/%d files", p.doneFiles, p.files)
+	if done := p.doneBytes + p.curBytes; p.total > 0 && done > 0 {
+		line += fmt.Sprintf("  %3.0f%%", float64(done)/float64(p.total)*100)
+		remaining := time.Duration(elapsed * float64(p.total-done) / float64(done) * float64(time.Second))
+		line += fmt.Sprintf("  %.0f lines/sec  ETA %s", rate, remaining.Round(time.Second))
+	}
+	fmt.Fprint(p.w, "\r\033[K"+line)
+	p.drawn = true
+}
+
+// progressSnapshot is the bar's state for callers that show progress some
+// other way, such as serve-mode job status.
+type progressSnapshot struct {
+	Files     int     `json:"files"`
+	FilesDone int     `json:"files_done"`
+	Bytes     int64   `json:"bytes"`
+	BytesDone int64   `json:"bytes_done"`
+	Lines     int     `json:"lines"`
+	Percent   float64 `json:"percent"`
+}
+
+func (p *progressBar) snapshot() progressSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := progressSnapshot{
+		Files:     p.files,
+		FilesDone: p.doneFiles,
+		Bytes:     p.total,
+		BytesDone: p.doneBytes + p.curBytes,
+		Lines:     p.lines,
+	}
+	if s.Bytes > 0 {
+		s.Percent = float64(s.BytesDone) / float64(s.Bytes) * 100
+	} else if s.Files > 0 {
+		s.Percent = float64(s.FilesDone) / float64(s.Files) * 100
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/kafka"
+	"github.com/shadow-ai-hunter/reporter"
+)
+
+// publishFindings sends findings to Kafka as JSON messages keyed by user,
+// so one user's activity stays in order on one partition.
+func publishFindings(ctx context.Context, p *kafka.Producer, findings []analyzer.Finding) error {
+	msgs := make([]kafka.Message, 0, len(findings))
+	for _, f := range findings {
+		value, err := reporter.MarshalFinding(f)
+		if err != nil {
+			return err
+		}
+		ts := f.Timestamp
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		msgs = append(msgs, kafka.Message{Key: []byte(f.Identity()), Value: value, Time: ts})
+	}
+	return p.Send(ctx, msgs)
+}
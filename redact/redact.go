@@ -0,0 +1,207 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// User handling modes.
+const (
+	UsersKeep         = "keep"
+	UsersPseudonymize = "pseudonymize"
+	UsersRemove       = "remove"
+)
+
+// URL handling modes.
+const (
+	URLsKeep       = "keep"
+	URLsStripQuery = "strip-query"
+	URLsRemove     = "remove"
+)
+
+// Profile describes how much detail a report may reveal about individuals.
+type Profile struct {
+	Users         string `json:"users"`          // keep, pseudonymize, remove
+	URLs          string `json:"urls"`           // keep, strip-query, remove
+	AggregateOnly bool   `json:"aggregate_only"` // omit individual findings
+	Salt          string `json:"salt,omitempty"` // key for stable pseudonyms
+}
+
+// SaltEnv names the environment variable holding the pseudonym key for
+// profiles that set no salt of their own, such as the built-in "anonymous".
+const SaltEnv = "SHADOW_HUNTER_SALT"
+
+// Builtin profiles are always available and can be overridden by config.
+// "anonymous" takes its salt from SaltEnv and cannot be used without it.
+var Builtin = map[string]Profile{
+	"full":      {Users: UsersKeep, URLs: URLsKeep},
+	"anonymous": {Users: UsersPseudonymize, URLs: URLsStripQuery},
+	"aggregate": {Users: UsersRemove, URLs: URLsRemove, AggregateOnly: true},
+}
+
+// Lookup resolves a profile name against the configured profiles, falling
+// back to the built-in ones. An empty name means no redaction.
+func Lookup(name string, configured map[string]Profile) (Profile, error) {
+	if name == "" {
+		return Builtin["full"], nil
+	}
+	p, ok := configured[name]
+	if !ok {
+		if p, ok = Builtin[name]; !ok {
+			return Profile{}, fmt.Errorf("unknown redaction profile: %s", name)
+		}
+	}
+	if p.Salt == "" {
+		p.Salt = os.Getenv(SaltEnv)
+	}
+	if err := p.validate(); err != nil {
+		return Profile{}, fmt.Errorf("redaction profile %s: %w", name, err)
+	}
+	return p, nil
+}
+
+func (p Profile) validate() error {
+	switch p.Users {
+	case "", UsersKeep, UsersPseudonymize, UsersRemove:
+	default:
+		return fmt.Errorf("invalid users mode: %s", p.Users)
+	}
+	switch p.URLs {
+	case "", URLsKeep, URLsStripQuery, URLsRemove:
+	default:
+		return fmt.Errorf("invalid urls mode: %s", p.URLs)
+	}
+	if p.Users == UsersPseudonymize && p.Salt == "" {
+		// An unkeyed HMAC lets anyone recompute the pseudonym of every user
+		return errors.New("pseudonymized users need a salt; set \"salt\" in the profile or " + SaltEnv)
+	}
+	return nil
+}
+
+// Apply returns a copy of the summary redacted according to the profile.
+// Totals and unique counts are preserved so aggregate numbers stay accurate.
+func (p Profile) Apply(s analyzer.Summary) analyzer.Summary {
+	out := s
+
+	out.ByUser = p.userCounts(s.ByUser)
+	out.OffHoursByUser = p.userCounts(s.OffHoursByUser)
+	out.VolumeByUser = p.userVolumes(s.VolumeByUser)
+
+	out.NewAdoptions = make([]analyzer.Adoption, 0, len(s.NewAdoptions))
+	for _, a := range s.NewAdoptions {
+		a.User = p.user(a.User)
+		out.NewAdoptions = append(out.NewAdoptions, a)
+	}
+	out.ToolingInstalled = make([]analyzer.ToolingInstall, 0, len(s.ToolingInstalled))
+	for _, t := range s.ToolingInstalled {
+		t.User = p.user(t.User)
+		out.ToolingInstalled = append(out.ToolingInstalled, t)
+	}
+	out.DoHClients = make([]analyzer.DoHUse, 0, len(s.DoHClients))
+	for _, d := range s.DoHClients {
+		d.User = p.user(d.User)
+		out.DoHClients = append(out.DoHClients, d)
+	}
+	out.VPNUsers = make([]analyzer.VPNUse, 0, len(s.VPNUsers))
+	for _, v := range s.VPNUsers {
+		v.User = p.user(v.User)
+		out.VPNUsers = append(out.VPNUsers, v)
+	}
+	out.Bursts = make([]analyzer.Burst, 0, len(s.Bursts))
+	for _, b := range s.Bursts {
+		b.User = p.user(b.User)
+		out.Bursts = append(out.Bursts, b)
+	}
+
+	out.Findings = nil
+	if !p.AggregateOnly {
+		out.Findings = make([]analyzer.Finding, 0, len(s.Findings))
+		for _, f := range s.Findings {
+			out.Findings = append(out.Findings, p.Finding(f))
+		}
+	}
+
+	return out
+}
+
+// Finding redacts a single finding, for findings reported apart from their
+// summary. AggregateOnly is left to the caller.
+func (p Profile) Finding(f analyzer.Finding) analyzer.Finding {
+	f.SourceIP = p.user(f.SourceIP)
+	if f.User != "" {
+		f.User = p.user(f.User)
+	}
+	if f.DeviceName != "" {
+		f.DeviceName = p.user(f.DeviceName)
+	}
+	f.URL = p.url(f.URL)
+	return f
+}
+
+// userCounts rekeys a per-user count map; removed users yield an empty map.
+func (p Profile) userCounts(m map[string]int) map[string]int {
+	out := make(map[string]int)
+	if p.Users == UsersRemove {
+		return out
+	}
+	for user, n := range m {
+		out[p.user(user)] += n
+	}
+	return out
+}
+
+func (p Profile) userVolumes(m map[string]analyzer.Volume) map[string]analyzer.Volume {
+	out := make(map[string]analyzer.Volume)
+	if p.Users == UsersRemove {
+		return out
+	}
+	for user, v := range m {
+		sum := out[p.user(user)]
+		sum.Sent += v.Sent
+		sum.Received += v.Received
+		out[p.user(user)] = sum
+	}
+	return out
+}
+
+func (p Profile) user(id string) string {
+	switch p.Users {
+	case UsersPseudonymize:
+		mac := hmac.New(sha256.New, []byte(p.Salt))
+		mac.Write([]byte(id))
+		return "user-" + hex.EncodeToString(mac.Sum(nil))[:12]
+	case UsersRemove:
+		return "redacted"
+	default:
+		return id
+	}
+}
+
+func (p Profile) url(raw string) string {
+	switch p.URLs {
+	case URLsRemove:
+		return ""
+	case URLsStripQuery:
+		if !strings.Contains(raw, "://") {
+			return raw // CONNECT host:port carries no query
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return ""
+		}
+		u.RawQuery = ""
+		u.Fragment = ""
+		u.User = nil
+		return u.String()
+	default:
+		return raw
+	}
+}
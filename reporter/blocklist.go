@@ -0,0 +1,100 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Blocklist formats.
+const (
+	BlocklistEDL       = "edl"       // Palo Alto External Dynamic List (URL list)
+	BlocklistSquid     = "squid"     // Squid dstdomain ACL file
+	BlocklistPfBlocker = "pfblocker" // pfBlockerNG DNSBL custom list
+	BlocklistPiHole    = "pihole"    // Pi-hole adlist in hosts format
+)
+
+// BlocklistFormats lists the supported formats, for usage messages.
+var BlocklistFormats = []string{BlocklistEDL, BlocklistSquid, BlocklistPfBlocker, BlocklistPiHole}
+
+// WriteBlocklist writes the services' domains as a blocklist. Formats
+// that match subdomains (EDL, Squid) leave out domains already covered by
+// a listed parent; Pi-hole and pfBlocker match names exactly and get every
+// domain. Host patterns are kept only by EDL, the one format with
+// wildcards; skipped reports how many were dropped for the others.
+func WriteBlocklist(services []analyzer.AIService, includeTooling bool, format string, generated time.Time, w io.Writer) (skipped int, err error) {
+	var domains, patterns []string
+	for _, svc := range services {
+		domains = append(domains, svc.Domains...)
+		if includeTooling {
+			domains = append(domains, svc.Tooling...)
+		}
+		patterns = append(patterns, svc.HostPatterns...)
+	}
+	slices.Sort(domains)
+	domains = slices.Compact(domains)
+	if format == BlocklistEDL || format == BlocklistSquid {
+		listed := make(map[string]bool, len(domains))
+		for _, d := range domains {
+			listed[d] = true
+		}
+		domains = slices.DeleteFunc(domains, func(d string) bool {
+			return coveredByParent(d, listed)
+		})
+	}
+	slices.Sort(patterns)
+	patterns = slices.Compact(patterns)
+
+	bw := bufio.NewWriter(w)
+	comment := func(prefix string) {
+		fmt.Fprintf(bw, "%s Shadow AI domains from shadow-hunter, %d service(s), generated %s\n", prefix, len(services), generated.UTC().Format(time.RFC3339))
+	}
+	switch format {
+	case BlocklistEDL:
+		// No comments: firewalls treat every line as an entry
+		for _, d := range domains {
+			fmt.Fprintf(bw, "%s\n*.%s\n", d, d)
+		}
+		for _, p := range patterns {
+			fmt.Fprintln(bw, p)
+		}
+		patterns = nil
+	case BlocklistSquid:
+		comment("#")
+		for _, d := range domains {
+			fmt.Fprintf(bw, ".%s\n", d) // the leading dot covers subdomains
+		}
+	case BlocklistPfBlocker:
+		comment("#")
+		for _, d := range domains {
+			fmt.Fprintln(bw, d)
+		}
+	case BlocklistPiHole:
+		comment("#")
+		for _, d := range domains {
+			fmt.Fprintf(bw, "0.0.0.0 %s\n", d)
+		}
+	default:
+		return 0, fmt.Errorf("unknown blocklist format %q (use %s)", format, strings.Join(BlocklistFormats, ", "))
+	}
+	return len(patterns), bw.Flush()
+}
+
+// coveredByParent reports whether a parent domain of d is also listed.
+func coveredByParent(d string, listed map[string]bool) bool {
+	for {
+		i := strings.IndexByte(d, '.')
+		if i < 0 {
+			return false
+		}
+		d = d[i+1:]
+		if listed[d] {
+			return true
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package reporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// BundleReport is a scan report included in an export bundle.
+type BundleReport struct {
+	Name    string // file name stem, e.g. "2025-06-10-proxy"
+	Summary analyzer.Summary
+}
+
+// Bundle is a read-only snapshot for auditors: the stored history for a
+// period plus any scan reports, viewable offline in a browser.
+type Bundle struct {
+	Version     string
+	GeneratedAt time.Time
+	From, To    time.Time // zero for an open end
+	Source      string    // history store location, for the record
+	Profile     string    // redaction profile applied
+	Records     int       // history records in the period
+	History     analyzer.Summary
+	Reports     []BundleReport
+}
+
+// bundleManifest lists the bundle's contents with checksums so auditors can
+// check nothing was changed after export.
+type bundleManifest struct {
+	Version     string         `json:"version"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	From        *time.Time     `json:"from,omitempty"`
+	To          *time.Time     `json:"to,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Profile     string         `json:"profile,omitempty"`
+	Records     int            `json:"records"`
+	Files       []manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// WriteBundle writes the bundle as a zip archive. Every page is static
+// HTML with no external references, so it opens from a local directory.
+func WriteBundle(b Bundle, w io.Writer) error {
+	type file struct {
+		path string
+		data []byte
+	}
+	var files []file
+	add := func(path string, render func(io.Writer) error) error {
+		var buf bytes.Buffer
+		if err := render(&buf); err != nil {
+			return fmt.Errorf("rendering %s: %w", path, err)
+		}
+		files = append(files, file{path, buf.Bytes()})
+		return nil
+	}
+
+	if err := add("history.html", func(w io.Writer) error { return reportHTML(b.History, w) }); err != nil {
+		return err
+	}
+	if err := add("data/history.json", func(w io.Writer) error { return reportJSON(b.History, w) }); err != nil {
+		return err
+	}
+	for _, r := range b.Reports {
+		s := r.Summary
+		if err := add("reports/"+r.Name+".html", func(w io.Writer) error { return reportHTML(s, w) }); err != nil {
+			return err
+		}
+		if err := add("reports/"+r.Name+".json", func(w io.Writer) error { return reportJSON(s, w) }); err != nil {
+			return err
+		}
+	}
+	if err := add("index.html", func(w io.Writer) error { return bundleIndex.Execute(w, b) }); err != nil {
+		return err
+	}
+
+	m := bundleManifest{
+		Version:     b.Version,
+		GeneratedAt: b.GeneratedAt,
+		Source:      b.Source,
+		Profile:     b.Profile,
+		Records:     b.Records,
+	}
+	if !b.From.IsZero() {
+		m.From = &b.From
+	}
+	if !b.To.IsZero() {
+		m.To = &b.To
+	}
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		m.Files = append(m.Files, manifestFile{Path: f.path, Size: len(f.data), SHA256: hex.EncodeToString(sum[:])})
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append(files, file{"manifest.json", append(manifest, '\n')})
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.path, Method: zip.Deflate, Modified: b.GeneratedAt})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// WriteBundleFile writes the bundle to path, replacing it only once the
+// archive is complete.
+func WriteBundleFile(b Bundle, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".bundle-*")
+	if err != nil {
+		return fmt.Errorf("creating bundle: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := WriteBundle(b, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+var bundleIndex = template.Must(template.New("bundle").Funcs(template.FuncMap{
+	"day": func(t time.Time) string {
+		if t.IsZero() {
+			return "open"
+		}
+		return t.Format("2006-01-02")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Shadow AI Hunter - Audit Bundle</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { border-bottom: 3px solid #333; padding-bottom: .3em; }
+h2 { margin-top: 1.6em; border-bottom: 1px solid #ccc; }
+table { border-collapse: collapse; margin-top: .5em; }
+th, td { text-align: left; padding: .25em .9em; border-bottom: 1px solid #eee; }
+th { background: #f4f4f4; }
+.stats td:first-child { font-weight: bold; }
+.note { color: #666; }
+</style>
+</head>
+<body>
+<h1>Shadow AI Hunter - Audit Bundle</h1>
+<table class="stats">
+<tr><td>Period</td><td>{{day .From}} to {{day .To}}</td></tr>
+<tr><td>Exported</td><td>{{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}} by v{{.Version}}</td></tr>
+{{with .Profile}}<tr><td>Redaction</td><td>{{.}}</td></tr>{{end}}
+<tr><td>History records</td><td>{{.Records}}</td></tr>
+<tr><td>AI hits</td><td>{{.History.TotalFindings}} ({{.History.AllowedFindings}} allowed, {{.History.BlockedFindings}} blocked)</td></tr>
+<tr><td>Unique users</td><td>{{.History.UniqueUsers}}</td></tr>
+</table>
+
+<h2>History</h2>
+<p><a href="history.html">Findings for the period</a> (<a href="data/history.json">JSON</a>)</p>
+
+{{if .Reports}}<h2>Scan Reports</h2>
+<table>
+<tr><th>Report</th><th>AI hits</th><th>Users</th><th>Data</th></tr>
+{{range .Reports}}<tr><td><a href="reports/{{.Name}}.html">{{.Name}}</a></td><td>{{.Summary.TotalFindings}}</td><td>{{.Summary.UniqueUsers}}</td><td><a href="reports/{{.Name}}.json">JSON</a></td></tr>
+{{end}}</table>
+{{end}}
+<p class="note">This bundle is a read-only snapshot. manifest.json lists every file with its SHA-256 checksum.</p>
+</body>
+</html>
+`))
@@ -0,0 +1,221 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// ReportComparison outputs the delta between two reports in the requested
+// format. top limits the per-user table in console output.
+func ReportComparison(c analyzer.Comparison, top int, format Format, w io.Writer) error {
+	switch format {
+	case FormatTable:
+		return comparisonTable(c, top, w)
+	case FormatJSON:
+		return comparisonJSON(c, w)
+	default:
+		return fmt.Errorf("unsupported format for comparison: %s", format)
+	}
+}
+
+func comparisonTable(c analyzer.Comparison, top int, w io.Writer) error {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  SHADOW AI HUNTER - Report Comparison")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "  Before:              %s\n", period(c.OldFirstSeen, c.OldLastSeen))
+	fmt.Fprintf(w, "  After:               %s\n", period(c.NewFirstSeen, c.NewLastSeen))
+	fmt.Fprintf(w, "  AI findings:         %s\n", changeLine(c.Findings))
+	fmt.Fprintf(w, "  Blocked:             %s\n", changeLine(c.Blocked))
+	fmt.Fprintf(w, "  Unique users:        %s\n", changeLine(c.Users))
+	fmt.Fprintf(w, "  AI services:         %s\n", changeLine(c.Services))
+	if c.BytesSent != [2]int64{} || c.BytesReceived != [2]int64{} {
+		fmt.Fprintf(w, "  Uploaded:            %s -> %s\n", byteSize(c.BytesSent[0]), byteSize(c.BytesSent[1]))
+		fmt.Fprintf(w, "  Downloaded:          %s -> %s\n", byteSize(c.BytesReceived[0]), byteSize(c.BytesReceived[1]))
+	}
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	writeAppeared(w, "NEW SERVICES", c.NewServices, c.ByService, false, 0)
+	writeAppeared(w, "DISAPPEARED SERVICES", c.GoneServices, c.ByService, true, 0)
+	writeAppeared(w, "NEW USERS", c.NewUsers, c.ByUser, false, top)
+	writeAppeared(w, "DISAPPEARED USERS", c.GoneUsers, c.ByUser, true, top)
+
+	writeChanges(w, "HITS BY SERVICE", c.ByService, 0)
+	writeChanges(w, "HITS BY CATEGORY", c.ByCategory, 0)
+	writeChanges(w, "HITS BY USER", c.ByUser, top)
+	fmt.Fprintln(w)
+	return nil
+}
+
+// period describes the span a report covers.
+func period(first, last time.Time) string {
+	if first.IsZero() {
+		return "(no timestamps)"
+	}
+	return fmt.Sprintf("%s to %s", first.Format("2006-01-02 15:04"), last.Format("2006-01-02 15:04"))
+}
+
+// changeLine reads as "120 -> 80 (-40, -33.3%)".
+func changeLine(c analyzer.Change) string {
+	return fmt.Sprintf("%d -> %d (%+d, %s)", c.Old, c.New, c.Diff(), percent(c))
+}
+
+// percent formats the relative change, or "new" when there was nothing to
+// measure it against.
+func percent(c analyzer.Change) string {
+	pct, ok := c.Percent()
+	switch {
+	case !ok && c.New > 0:
+		return "new"
+	case !ok:
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", pct)
+}
+
+// writeAppeared lists keys seen in only one report with the hits they had
+// there. Empty lists are left out.
+func writeAppeared(w io.Writer, title string, keys []string, m map[string]analyzer.Change, gone bool, top int) {
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n  %s (%d)\n", title, len(keys))
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	for i, k := range keys {
+		if more(tw, i, len(keys), top) {
+			break
+		}
+		hits := m[k].New
+		if gone {
+			hits = m[k].Old
+		}
+		fmt.Fprintf(tw, "  %s\t%d hits\n", k, hits)
+	}
+	tw.Flush()
+}
+
+func writeChanges(w io.Writer, title string, m map[string]analyzer.Change, top int) {
+	if len(m) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n  %s\n", title)
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  NAME\tBEFORE\tAFTER\tCHANGE\tPERCENT\n")
+	keys := sortedChangeKeys(m)
+	for i, k := range keys {
+		if more(tw, i, len(keys), top) {
+			break
+		}
+		c := m[k]
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%+d\t%s\n", k, c.Old, c.New, c.Diff(), percent(c))
+	}
+	tw.Flush()
+}
+
+// sortedChangeKeys orders names by the size of the change, largest first.
+func sortedChangeKeys(m map[string]analyzer.Change) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := abs(m[keys[i]].Diff()), abs(m[keys[j]].Diff())
+		if ci != cj {
+			return ci > cj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+type jsonChange struct {
+	Before  int      `json:"before"`
+	After   int      `json:"after"`
+	Change  int      `json:"change"`
+	Percent *float64 `json:"percent_change,omitempty"` // absent when before is 0
+}
+
+type jsonByteChange struct {
+	Before int64 `json:"before"`
+	After  int64 `json:"after"`
+}
+
+type jsonPeriod struct {
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+}
+
+type jsonComparison struct {
+	Before        jsonPeriod            `json:"before"`
+	After         jsonPeriod            `json:"after"`
+	Findings      jsonChange            `json:"findings"`
+	Blocked       jsonChange            `json:"blocked"`
+	Users         jsonChange            `json:"unique_users"`
+	Services      jsonChange            `json:"unique_services"`
+	BytesSent     jsonByteChange        `json:"bytes_sent"`
+	BytesReceived jsonByteChange        `json:"bytes_received"`
+	NewServices   []string              `json:"new_services"`
+	GoneServices  []string              `json:"disappeared_services"`
+	NewUsers      []string              `json:"new_users"`
+	GoneUsers     []string              `json:"disappeared_users"`
+	ByService     map[string]jsonChange `json:"by_service"`
+	ByCategory    map[string]jsonChange `json:"by_category"`
+	ByUser        map[string]jsonChange `json:"by_user"`
+}
+
+func comparisonJSON(c analyzer.Comparison, w io.Writer) error {
+	report := jsonComparison{
+		Before:        jsonPeriod{formatTime(c.OldFirstSeen), formatTime(c.OldLastSeen)},
+		After:         jsonPeriod{formatTime(c.NewFirstSeen), formatTime(c.NewLastSeen)},
+		Findings:      toJSONChange(c.Findings),
+		Blocked:       toJSONChange(c.Blocked),
+		Users:         toJSONChange(c.Users),
+		Services:      toJSONChange(c.Services),
+		BytesSent:     jsonByteChange{c.BytesSent[0], c.BytesSent[1]},
+		BytesReceived: jsonByteChange{c.BytesReceived[0], c.BytesReceived[1]},
+		NewServices:   nonNil(c.NewServices),
+		GoneServices:  nonNil(c.GoneServices),
+		NewUsers:      nonNil(c.NewUsers),
+		GoneUsers:     nonNil(c.GoneUsers),
+		ByService:     toJSONChanges(c.ByService),
+		ByCategory:    toJSONChanges(c.ByCategory),
+		ByUser:        toJSONChanges(c.ByUser),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func toJSONChange(c analyzer.Change) jsonChange {
+	out := jsonChange{Before: c.Old, After: c.New, Change: c.Diff()}
+	if pct, ok := c.Percent(); ok {
+		pct = math.Round(pct*10) / 10
+		out.Percent = &pct
+	}
+	return out
+}
+
+func toJSONChanges(m map[string]analyzer.Change) map[string]jsonChange {
+	out := make(map[string]jsonChange, len(m))
+	for k, c := range m {
+		out[k] = toJSONChange(c)
+	}
+	return out
+}
+
+// nonNil keeps empty lists as [] rather than null in JSON.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Error classes for files that could not be scanned cleanly.
+const (
+	ErrorUnreadable    = "unreadable"     // the file could not be opened or read
+	ErrorUnknownFormat = "unknown_format" // no parser could be chosen for the file
+	ErrorParse         = "parse_failed"   // the parser rejected the file as a whole
+	ErrorNoEntries     = "no_entries"     // every record was rejected; likely the wrong format
+	ErrorPartial       = "partial_parse"  // some records were rejected
+	ErrorInterrupted   = "interrupted"    // the scan was stopped before the end of the file
+)
+
+// ErrorReport is the errors.json artifact: collection problems kept apart
+// from findings so pipelines can alert on them separately.
+type ErrorReport struct {
+	Version      string         `json:"version"`
+	GeneratedAt  time.Time      `json:"generated_at"`
+	FilesScanned int            `json:"files_scanned"`
+	ByClass      map[string]int `json:"by_class"`
+	Files        []ScanFile     `json:"files"`
+}
+
+// Uncollected reports whether none of the file's records were collected:
+// it could not be read, no parser fit it, or every record was rejected.
+func (f ScanFile) Uncollected() bool {
+	switch f.Class {
+	case ErrorUnreadable, ErrorUnknownFormat, ErrorParse, ErrorNoEntries:
+		return true
+	}
+	return false
+}
+
+// NewErrorReport collects the files that have an error class.
+func NewErrorReport(version string, files []ScanFile) ErrorReport {
+	r := ErrorReport{
+		Version:      version,
+		GeneratedAt:  time.Now().UTC(),
+		FilesScanned: len(files),
+		ByClass:      make(map[string]int),
+		Files:        []ScanFile{},
+	}
+	for _, f := range files {
+		if f.Class == "" {
+			continue
+		}
+		r.ByClass[f.Class]++
+		r.Files = append(r.Files, f)
+	}
+	sort.SliceStable(r.Files, func(i, j int) bool { return r.Files[i].Path < r.Files[j].Path })
+	return r
+}
+
+// WriteErrorReport writes the report as indented JSON.
+func WriteErrorReport(r ErrorReport, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteErrorReportFile writes the report to path.
+func WriteErrorReportFile(r ErrorReport, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating error report: %w", err)
+	}
+	if err := WriteErrorReport(r, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
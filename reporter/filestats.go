@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+// ReportFileStats writes a per-file table of what each file yielded, with
+// totals, for the end of a scan.
+func ReportFileStats(files []ScanFile, w io.Writer) {
+	width := outputWidth(w)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  FILES")
+	fmt.Fprintln(w, rule("-", 60, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  FILE\tFORMAT\tENTRIES\tMALFORMED\tAI HITS\tTIME\tSTATUS\n")
+	var total ScanFile
+	for _, f := range files {
+		status := f.Class
+		if status == "" {
+			status = "ok"
+		}
+		format := f.Format
+		if format == "" {
+			format = "-"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\t%d\t%.1fs\t%s\n",
+			fit(filepath.Base(f.Path), 40), format, f.Entries, f.Malformed, f.Findings, f.Seconds, status)
+		total.Entries += f.Entries
+		total.Malformed += f.Malformed
+		total.Findings += f.Findings
+		total.Seconds += f.Seconds
+	}
+	if len(files) > 1 {
+		fmt.Fprintf(tw, "  %d files\t\t%d\t%d\t%d\t%.1fs\n",
+			len(files), total.Entries, total.Malformed, total.Findings, total.Seconds)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
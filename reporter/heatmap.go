@@ -0,0 +1,107 @@
+package reporter
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// heatDays orders heatmap rows for a working week, Monday first.
+var heatDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// heatShades runs from no hits to the busiest hour.
+const heatShades = ".:-=+*#%@"
+
+// heatLevel scales n against the busiest hour onto 0..levels-1, keeping any
+// hit above zero so quiet hours stay visible.
+func heatLevel(n, peak, levels int) int {
+	if n == 0 || peak == 0 {
+		return 0
+	}
+	return max(1, (n*(levels-1)+peak-1)/peak)
+}
+
+// heatmapsFor lists the overall heatmap, then one per service in order of
+// hits, at most top of those when top is set.
+func heatmapsFor(s analyzer.Summary, top int) (titles []string, maps []*analyzer.Heatmap) {
+	titles = append(titles, "all services")
+	maps = append(maps, &s.Heatmap)
+	for i, svc := range sortedMap(s.ByService) {
+		if top > 0 && i >= top {
+			break
+		}
+		if h := s.HeatmapByService[svc.Key]; h != nil {
+			titles = append(titles, svc.Key)
+			maps = append(maps, h)
+		}
+	}
+	return titles, maps
+}
+
+// writeHeatmaps draws each heatmap as a weekday by hour grid of shades.
+func writeHeatmaps(w io.Writer, width int, s analyzer.Summary) {
+	titles, maps := heatmapsFor(s, s.Layout.Top)
+	for i, h := range maps {
+		fmt.Fprintf(w, "\n  HITS BY WEEKDAY AND HOUR (%s)\n", titles[i])
+		fmt.Fprintln(w, rule("-", 60, width))
+		header := []byte(strings.Repeat(" ", 48))
+		for hour := 0; hour < 24; hour += 3 {
+			copy(header[hour*2:], fmt.Sprint(hour))
+		}
+		fmt.Fprintf(w, "       %s\n", strings.TrimRight(string(header), " "))
+		peak := h.Max()
+		for _, day := range heatDays {
+			var row strings.Builder
+			for hour := 0; hour < 24; hour++ {
+				row.WriteByte(heatShades[heatLevel(h[day][hour], peak, len(heatShades))])
+				row.WriteByte(' ')
+			}
+			fmt.Fprintf(w, "  %s  %s\n", day.String()[:3], strings.TrimSpace(row.String()))
+		}
+		fmt.Fprintf(w, "  %c none, %c to %c up to %d hits in an hour\n", heatShades[0], heatShades[1], heatShades[len(heatShades)-1], peak)
+	}
+}
+
+// htmlHeatmap is a heatmap laid out for the HTML template.
+type htmlHeatmap struct {
+	Title string
+	Peak  int
+	Rows  []htmlHeatRow
+}
+
+type htmlHeatRow struct {
+	Day   string
+	Cells []htmlHeatCell
+}
+
+type htmlHeatCell struct {
+	Hits  int
+	Style template.CSS
+}
+
+// htmlHeatmaps shades each cell by its share of the busiest hour.
+func htmlHeatmaps(s analyzer.Summary) []htmlHeatmap {
+	titles, maps := heatmapsFor(s, 0)
+	out := make([]htmlHeatmap, len(maps))
+	for i, h := range maps {
+		peak := h.Max()
+		out[i] = htmlHeatmap{Title: titles[i], Peak: peak}
+		for _, day := range heatDays {
+			row := htmlHeatRow{Day: day.String()[:3]}
+			for hour := 0; hour < 24; hour++ {
+				n := h[day][hour]
+				alpha := 0.0
+				if n > 0 {
+					alpha = 0.1 + 0.9*float64(n)/float64(peak)
+				}
+				row.Cells = append(row.Cells, htmlHeatCell{Hits: n, Style: template.CSS(fmt.Sprintf("background: rgba(192, 57, 43, %.2f)", alpha))})
+			}
+			out[i].Rows = append(out[i].Rows, row)
+		}
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package reporter
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// htmlProvider is a provider row with its host drill-down.
+type htmlProvider struct {
+	kv
+	Hosts []kv
+}
+
+// htmlSite is a site row with its services in order of hits.
+type htmlSite struct {
+	analyzer.SiteStats
+	Top []kv
+}
+
+// htmlView is the data handed to the HTML template.
+type htmlView struct {
+	Summary       analyzer.Summary
+	Scan          [][2]string
+	Users         []kv
+	Services      []kv
+	Providers     []htmlProvider
+	Sites         []htmlSite
+	Categories    []kv
+	Activities    []kv
+	OffHours      []kv
+	Cloud         []kv
+	ServiceVolume []analyzer.VolumeRow
+	UserVolume    []analyzer.VolumeRow
+	Heatmaps      []htmlHeatmap
+	Sessions      []analyzer.Session
+	Allowed       []analyzer.Finding
+	Blocked       []analyzer.Finding
+	Endpoint      bool // some finding names its process or device
+}
+
+func reportHTML(s analyzer.Summary, w io.Writer) error {
+	view := htmlView{
+		Summary:       s,
+		Scan:          scanRows(s),
+		Users:         sortedMap(s.ByUser),
+		Services:      sortedMap(s.ByService),
+		Categories:    sortedMap(s.ByCategory),
+		Activities:    sortedMap(activityCounts(s.ByActivity)),
+		OffHours:      sortedMap(s.OffHoursByUser),
+		Cloud:         sortedMap(s.CloudHosted),
+		ServiceVolume: analyzer.SortedVolumes(s.VolumeByService),
+		UserVolume:    analyzer.SortedVolumes(s.VolumeByUser),
+	}
+	if s.Layout.Heatmap {
+		view.Heatmaps = htmlHeatmaps(s)
+	}
+	for _, site := range s.Sites {
+		view.Sites = append(view.Sites, htmlSite{SiteStats: site, Top: sortedMap(site.Services)})
+	}
+	for _, p := range sortedMap(s.ByProvider) {
+		view.Providers = append(view.Providers, htmlProvider{kv: p, Hosts: sortedMap(s.ProviderEndpoints[p.Key])})
+	}
+	if s.Layout.SessionGap > 0 {
+		view.Sessions = analyzer.Sessions(s.Findings, s.Layout.SessionGap)
+		return htmlTemplate.Execute(w, view)
+	}
+	for _, f := range s.Findings {
+		view.Endpoint = view.Endpoint || f.ProcessName != "" || f.DeviceName != ""
+		if f.Blocked {
+			view.Blocked = append(view.Blocked, f)
+		} else {
+			view.Allowed = append(view.Allowed, f)
+		}
+	}
+	return htmlTemplate.Execute(w, view)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ts": func(f analyzer.Finding) string {
+		if f.Timestamp.IsZero() {
+			return "N/A"
+		}
+		return f.Timestamp.Format("2006-01-02 15:04:05")
+	},
+	"size": byteSize,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Shadow AI Hunter - Scan Results</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { border-bottom: 3px solid #333; padding-bottom: .3em; }
+h2 { margin-top: 1.6em; border-bottom: 1px solid #ccc; }
+table { border-collapse: collapse; margin-top: .5em; }
+th, td { text-align: left; padding: .25em .9em; border-bottom: 1px solid #eee; }
+table.heatmap th, table.heatmap td { padding: .2em; min-width: 1.8em; text-align: center; font-size: .75em; border: 1px solid #fff; }
+th { background: #f4f4f4; }
+.stats td:first-child { font-weight: bold; }
+.blocked { color: #888; }
+</style>
+</head>
+<body>
+<h1>Shadow AI Hunter - Scan Results</h1>
+<table class="stats">
+{{with .Summary.Tenant}}<tr><td>Tenant</td><td>{{.}}</td></tr>{{end}}
+{{if .Summary.Partial}}<tr><td>Partial results</td><td>The scan was stopped before all input was read</td></tr>{{end}}
+<tr><td>Logs scanned</td><td>{{.Summary.TotalLogsScanned}}</td></tr>
+{{with .Summary.MalformedLines}}<tr><td>Malformed lines</td><td>{{.}} (skipped)</td></tr>{{end}}
+<tr><td>AI hits found</td><td>{{.Summary.TotalFindings}} ({{.Summary.AllowedFindings}} allowed, {{.Summary.BlockedFindings}} blocked)</td></tr>
+{{if or .Summary.BytesSent .Summary.BytesReceived}}<tr><td>Uploaded / downloaded</td><td>{{size .Summary.BytesSent}} / {{size .Summary.BytesReceived}}</td></tr>{{end}}
+<tr><td>Unique users</td><td>{{.Summary.UniqueUsers}}</td></tr>
+<tr><td>Unique services</td><td>{{.Summary.UniqueServices}}</td></tr>
+</table>
+{{with .Summary.Sources}}<h2>Source Coverage</h2>
+<table><tr><th>Source</th><th>Format</th><th>Entries</th><th>Malformed</th><th>Domain</th><th>IP only</th><th>URL</th><th>Identity</th><th>AI hits</th></tr>
+{{range .}}<tr><td>{{.Source}}</td><td>{{.Format}}</td><td>{{.Entries}}</td><td>{{.Malformed}}</td><td>{{.Percent .WithDomain}}%</td><td>{{.Percent .IPOnly}}%</td><td>{{.Percent .WithURL}}%</td><td>{{.Percent .WithIdentity}}%</td><td>{{.Findings}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Scan}}<h2>Scan</h2>
+<table class="stats">
+{{range .}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{with $.Summary.Scan.Files}}<table><tr><th>File</th><th>Size</th><th>SHA-256</th></tr>
+{{range .}}<tr><td>{{.Path}}</td><td>{{size .Size}}</td><td>{{.SHA256}}</td></tr>
+{{end}}</table>{{end}}{{end}}
+{{with .Summary.DoHClients}}<h2>DNS over HTTPS Clients</h2>
+<p>These clients resolve names over HTTPS, out of sight of DNS logs, so their AI use may not appear in this report.</p>
+<table><tr><th>Source</th><th>Resolver</th><th>First seen</th><th>Last seen</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.User}}</td><td>{{.Resolver}}</td><td>{{if .FirstSeen.IsZero}}N/A{{else}}{{.FirstSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{if .LastSeen.IsZero}}N/A{{else}}{{.LastSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{.Hits}}</td></tr>
+{{end}}</table>{{end}}
+{{if eq .Summary.TotalFindings 0}}<p>No shadow AI activity detected.</p>{{else}}
+{{with .Sites}}<h2>Hits by Site</h2>
+<table><tr><th>Site</th><th>Hits</th><th>Blocked</th><th>Users</th><th>Services</th></tr>
+{{range .}}<tr><td>{{.Site}}</td><td>{{.Findings}}</td><td>{{.Blocked}}</td><td>{{.Users}}</td><td>{{range $i, $s := .Top}}{{if $i}}, {{end}}{{$s.Key}} ({{$s.Val}}){{end}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Users}}<h2>Top Users by AI Service Hits</h2>
+<table><tr><th>Source</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{.Val}}</td></tr>
+{{end}}</table>{{end}}
+<h2>Top AI Services Detected</h2>
+<table><tr><th>Service</th><th>Hits</th></tr>
+{{range .Services}}<tr><td>{{.Key}}</td><td>{{.Val}}</td></tr>
+{{end}}</table>
+{{with .Providers}}<h2>AI Providers</h2>
+<table><tr><th>Provider</th><th>Hits</th><th>Hosts</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{.Val}}</td><td>{{range $i, $h := .Hosts}}{{if $i}}, {{end}}{{$h.Key}} ({{$h.Val}}){{end}}</td></tr>
+{{end}}</table>{{end}}
+<h2>AI Categories Detected</h2>
+<table><tr><th>Category</th><th>Hits</th></tr>
+{{range .Categories}}<tr><td>{{.Key}}</td><td>{{.Val}}</td></tr>
+{{end}}</table>
+{{with .Cloud}}<h2>Cloud-Hosted AI</h2>
+<p>These services run in cloud provider accounts under enterprise terms. Check that the accounts behind them are sanctioned.</p>
+<table><tr><th>Service</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{.Val}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Activities}}<h2>Activity Breakdown</h2>
+<table><tr><th>Activity</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{.Val}}</td></tr>
+{{end}}</table>{{end}}
+{{with .ServiceVolume}}<h2>Data Volume by Service</h2>
+<table><tr><th>Service</th><th>Uploaded</th><th>Downloaded</th><th>Total</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{size .Sent}}</td><td>{{size .Received}}</td><td>{{size .Total}}</td></tr>
+{{end}}</table>{{end}}
+{{with .UserVolume}}<h2>Data Volume by User</h2>
+<table><tr><th>Source</th><th>Uploaded</th><th>Downloaded</th><th>Total</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td>{{size .Sent}}</td><td>{{size .Received}}</td><td>{{size .Total}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Heatmaps}}<h2>Hits by Weekday and Hour</h2>
+{{range .}}<h3>{{.Title}}</h3>
+<table class="heatmap"><tr><th></th>{{range $h, $_ := (index .Rows 0).Cells}}<th>{{$h}}</th>{{end}}</tr>
+{{range .Rows}}<tr><th>{{.Day}}</th>{{range .Cells}}<td style="{{.Style}}" title="{{.Hits}} hits">{{if .Hits}}{{.Hits}}{{end}}</td>{{end}}</tr>
+{{end}}</table>
+<p>Darker cells had more hits; the busiest hour had {{.Peak}}.</p>
+{{end}}{{end}}
+{{if .Summary.OffHoursFindings}}<h2>Off-Hours Activity</h2>
+<p>{{.Summary.OffHoursFindings}} hits outside business hours</p>
+<table><tr><th>Source</th><th>Hits</th></tr>
+{{range .OffHours}}<tr><td>{{.Key}}</td><td>{{.Val}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Summary.NewAdoptions}}<h2>New AI Adoption</h2>
+<table><tr><th>First seen</th><th>Source</th><th>Service</th></tr>
+{{range .}}<tr><td>{{if .FirstSeen.IsZero}}N/A{{else}}{{.FirstSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{.User}}</td><td>{{.Service}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Summary.ToolingInstalled}}<h2>AI Tooling Installed</h2>
+<table><tr><th>Source</th><th>Software</th><th>First seen</th><th>Last seen</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.User}}</td><td>{{.Service}}</td><td>{{if .FirstSeen.IsZero}}N/A{{else}}{{.FirstSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{if .LastSeen.IsZero}}N/A{{else}}{{.LastSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{.Hits}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Summary.VPNUsers}}<h2>VPN and Proxy Services</h2>
+<table><tr><th>Source</th><th>Service</th><th>First seen</th><th>Last seen</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.User}}</td><td>{{.Service}}</td><td>{{if .FirstSeen.IsZero}}N/A{{else}}{{.FirstSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{if .LastSeen.IsZero}}N/A{{else}}{{.LastSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{.Hits}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Summary.Bursts}}<h2>Automation Suspected</h2>
+<p>These sources made AI requests faster than a person browsing would, which suggests scripts or API clients.</p>
+<table><tr><th>Source</th><th>Peak rate</th><th>First seen</th><th>Last seen</th><th>Hits</th></tr>
+{{range .}}<tr><td>{{.User}}</td><td>{{printf "%.1f" .Rate}}/min</td><td>{{.FirstSeen.Format "2006-01-02 15:04:05"}}</td><td>{{.LastSeen.Format "2006-01-02 15:04:05"}}</td><td>{{.Hits}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Sessions}}<h2>Sessions</h2>
+<p>Split after {{$.Summary.Layout.SessionGap}} idle.</p>
+<table><tr><th>First seen</th><th>Last seen</th><th>Source</th><th>Service</th><th>Hits</th><th>Blocked</th><th>Bytes</th></tr>
+{{range .}}<tr><td>{{if .FirstSeen.IsZero}}N/A{{else}}{{.FirstSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{if .LastSeen.IsZero}}N/A{{else}}{{.LastSeen.Format "2006-01-02 15:04:05"}}{{end}}</td><td>{{.User}}</td><td>{{.Service}}</td><td>{{.Hits}}</td><td>{{.Blocked}}</td><td>{{.Bytes}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Allowed}}<h2>Detailed Findings</h2>
+{{if lt (len .) $.Summary.AllowedFindings}}<p>The first {{len .}} of {{$.Summary.AllowedFindings}}; the JSON and CSV reports list every finding.</p>{{end}}
+<table><tr><th>Timestamp</th><th>Source</th><th>Service</th><th>Category</th><th>Activity</th>{{if $.Endpoint}}<th>Device</th><th>Process</th>{{end}}<th>Domain</th></tr>
+{{range .}}<tr><td>{{ts .}}</td><td>{{.Identity}}</td><td>{{.ServiceName}}</td><td>{{.Category}}</td><td>{{or .Activity "-"}}</td>{{if $.Endpoint}}<td>{{or .DeviceName "-"}}</td><td>{{or .ProcessName "-"}}</td>{{end}}<td>{{.Domain}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Blocked}}<h2>Blocked Attempts</h2>
+{{if lt (len .) $.Summary.BlockedFindings}}<p>The first {{len .}} of {{$.Summary.BlockedFindings}}; the JSON and CSV reports list every finding.</p>{{end}}
+<table class="blocked"><tr><th>Timestamp</th><th>Source</th><th>Service</th><th>Category</th><th>Activity</th>{{if $.Endpoint}}<th>Device</th><th>Process</th>{{end}}<th>Domain</th></tr>
+{{range .}}<tr><td>{{ts .}}</td><td>{{.Identity}}</td><td>{{.ServiceName}}</td><td>{{.Category}}</td><td>{{or .Activity "-"}}</td>{{if $.Endpoint}}<td>{{or .DeviceName "-"}}</td><td>{{or .ProcessName "-"}}</td>{{end}}<td>{{.Domain}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Intel is shadow-AI detection intelligence for a threat-intel platform:
+// the domains of each AI service, with how often they were seen.
+type Intel struct {
+	Version     string
+	GeneratedAt time.Time
+	TLP         string // "clear", "green", "amber", or "red"
+	Services    []IntelService
+}
+
+// IntelService is one AI service's indicators and sightings.
+type IntelService struct {
+	Name         string
+	Provider     string
+	Category     string
+	Domains      []string // exact domains, matched with their subdomains
+	HostPatterns []string // hosts with a wildcard label, e.g. "bedrock-runtime.*.amazonaws.com"
+
+	// Sightings; zero when the service comes only from the database
+	Hits      int
+	Blocked   int
+	Users     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Seen reports whether the service appeared in the findings.
+func (s IntelService) Seen() bool { return s.Hits > 0 }
+
+// ParseTLP checks a Traffic Light Protocol level. "white" is accepted as
+// the TLP 1.0 name of "clear".
+func ParseTLP(s string) (string, error) {
+	switch s = strings.ToLower(s); s {
+	case "white":
+		return "clear", nil
+	case "clear", "green", "amber", "red":
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown TLP level %q (use clear, green, amber, or red)", s)
+}
+
+// BuildIntel gathers indicators per service: the domains seen in findings,
+// plus every domain and host pattern of services, which may be nil.
+func BuildIntel(findings []analyzer.Finding, services []analyzer.AIService) []IntelService {
+	byName := make(map[string]*IntelService)
+	get := func(name, provider, category string) *IntelService {
+		s, ok := byName[name]
+		if !ok {
+			s = &IntelService{Name: name, Provider: provider, Category: category}
+			byName[name] = s
+		}
+		return s
+	}
+
+	for _, svc := range services {
+		s := get(svc.Name, svc.ProviderName(), svc.Category)
+		s.Domains = append(s.Domains, svc.Domains...)
+		s.HostPatterns = append(s.HostPatterns, svc.HostPatterns...)
+	}
+
+	users := make(map[string]map[string]bool)
+	for _, f := range findings {
+		s := get(f.ServiceName, f.Provider, f.Category)
+		if s.Provider == "" {
+			s.Provider = f.Provider
+		}
+		if f.Domain != "" {
+			s.Domains = append(s.Domains, strings.ToLower(strings.TrimSuffix(f.Domain, ".")))
+		}
+		s.Hits++
+		if f.Blocked {
+			s.Blocked++
+		}
+		if users[s.Name] == nil {
+			users[s.Name] = make(map[string]bool)
+		}
+		users[s.Name][f.Identity()] = true
+		if !f.Timestamp.IsZero() {
+			if s.FirstSeen.IsZero() || f.Timestamp.Before(s.FirstSeen) {
+				s.FirstSeen = f.Timestamp
+			}
+			if f.Timestamp.After(s.LastSeen) {
+				s.LastSeen = f.Timestamp
+			}
+		}
+	}
+
+	out := make([]IntelService, 0, len(byName))
+	for _, s := range byName {
+		s.Users = len(users[s.Name])
+		sort.Strings(s.Domains)
+		s.Domains = slices.Compact(s.Domains)
+		sort.Strings(s.HostPatterns)
+		s.HostPatterns = slices.Compact(s.HostPatterns)
+		if s.Provider == "" {
+			s.Provider = s.Name
+		}
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out
+}
+
+// intelUUID derives a stable UUID (version 5) from name, so exporting the
+// same service twice updates one object instead of creating another.
+func intelUUID(namespace [16]byte, name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// intelNamespace scopes this tool's UUIDs.
+var intelNamespace = [16]byte{0x7b, 0x3e, 0x51, 0x0a, 0x2c, 0x94, 0x4d, 0x1f, 0x9a, 0x61, 0x0e, 0x58, 0xd2, 0xc3, 0x47, 0x8b}
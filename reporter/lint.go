@@ -0,0 +1,60 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// ReportLint outputs services database lint results in the requested format.
+func ReportLint(issues []analyzer.LintIssue, format Format, w io.Writer) error {
+	switch format {
+	case FormatTable:
+		return lintTable(issues, w)
+	case FormatJSON:
+		if issues == nil {
+			issues = []analyzer.LintIssue{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Issues []analyzer.LintIssue `json:"issues"`
+		}{issues})
+	default:
+		return fmt.Errorf("unsupported format for lint: %s", format)
+	}
+}
+
+func lintTable(issues []analyzer.LintIssue, w io.Writer) error {
+	errors := 0
+	for _, is := range issues {
+		if is.Severity == analyzer.LintError {
+			errors++
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  SHADOW AI HUNTER - Services Database Lint")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "  Errors:   %d\n", errors)
+	fmt.Fprintf(w, "  Warnings: %d\n", len(issues)-errors)
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	if len(issues) == 0 {
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  SEVERITY\tSOURCE\tSERVICE\tDOMAIN\tPROBLEM\n")
+	for _, is := range issues {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", is.Severity, is.Source, is.Service, is.Domain, is.Message)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	return nil
+}
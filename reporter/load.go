@@ -0,0 +1,140 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// LoadJSONReport reads a report previously written with -output json. When
+// the report lists individual findings the aggregates are rebuilt from them;
+// aggregate-only reports keep their stored counts.
+func LoadJSONReport(path string) (analyzer.Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return analyzer.Summary{}, fmt.Errorf("reading report: %w", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return analyzer.Summary{}, fmt.Errorf("parsing report %s: %w", path, err)
+	}
+
+	// DoH lookups are not findings, so clients are always read from the list
+	var doh []analyzer.DoHUse
+	for _, d := range report.DoHClients {
+		first, _ := time.Parse(time.RFC3339, d.FirstSeen)
+		last, _ := time.Parse(time.RFC3339, d.LastSeen)
+		doh = append(doh, analyzer.DoHUse{User: d.User, Resolver: d.Resolver, FirstSeen: first, LastSeen: last, Hits: d.Hits})
+	}
+
+	if len(report.Findings) == 0 {
+		var tooling []analyzer.ToolingInstall
+		for _, t := range report.ToolingInstalled {
+			first, _ := time.Parse(time.RFC3339, t.FirstSeen)
+			last, _ := time.Parse(time.RFC3339, t.LastSeen)
+			tooling = append(tooling, analyzer.ToolingInstall{User: t.User, Service: t.Service, FirstSeen: first, LastSeen: last, Hits: t.Hits})
+		}
+		var vpn []analyzer.VPNUse
+		for _, v := range report.VPNUsers {
+			first, _ := time.Parse(time.RFC3339, v.FirstSeen)
+			last, _ := time.Parse(time.RFC3339, v.LastSeen)
+			vpn = append(vpn, analyzer.VPNUse{User: v.User, Service: v.Service, FirstSeen: first, LastSeen: last, Hits: v.Hits})
+		}
+		var bursts []analyzer.Burst
+		for _, b := range report.Bursts {
+			first, _ := time.Parse(time.RFC3339, b.FirstSeen)
+			last, _ := time.Parse(time.RFC3339, b.LastSeen)
+			bursts = append(bursts, analyzer.Burst{User: b.User, FirstSeen: first, LastSeen: last, Hits: b.Hits, Rate: b.Rate})
+		}
+		var heat analyzer.Heatmap
+		var heatByService map[string]*analyzer.Heatmap
+		if report.Heatmap != nil {
+			heat, heatByService = report.Heatmap.AllServices, report.Heatmap.ByService
+		}
+		return analyzer.Summary{
+			TotalLogsScanned:  report.TotalLogsScanned,
+			TotalFindings:     report.TotalFindings,
+			AllowedFindings:   report.AllowedFindings,
+			BlockedFindings:   report.BlockedFindings,
+			BytesSent:         report.BytesSent,
+			BytesReceived:     report.BytesReceived,
+			UniqueUsers:       report.UniqueUsers,
+			UniqueServices:    report.UniqueServices,
+			ByUser:            report.ByUser,
+			ByService:         report.ByService,
+			ByProvider:        report.ByProvider,
+			ProviderEndpoints: report.ProviderEndpoints,
+			ByCategory:        report.ByCategory,
+			VolumeByService:   report.VolumeByService,
+			VolumeByUser:      report.VolumeByUser,
+			ByActivity:        make(map[analyzer.Activity]int),
+			OffHoursFindings:  report.OffHoursFindings,
+			OffHoursByUser:    report.OffHoursByUser,
+			Heatmap:           heat,
+			HeatmapByService:  heatByService,
+			ToolingInstalled:  tooling,
+			DoHClients:        doh,
+			VPNUsers:          vpn,
+			Bursts:            bursts,
+			Sites:             report.Sites,
+			CloudHosted:       report.CloudHosted,
+			Sources:           report.Sources,
+			Partial:           report.Partial,
+			Tenant:            report.Tenant,
+			Scan:              fromJSONScan(report.Scan),
+		}, nil
+	}
+
+	findings := make([]analyzer.Finding, 0, len(report.Findings))
+	for _, jf := range report.Findings {
+		findings = append(findings, fromJSONFinding(jf))
+	}
+	summary := analyzer.Summarize(findings, report.TotalLogsScanned)
+	summary.Sources = report.Sources
+	summary.Partial = report.Partial
+	summary.Tenant = report.Tenant
+	summary.Scan = fromJSONScan(report.Scan)
+	if len(doh) > 0 {
+		summary.DoHClients = doh
+	}
+	return summary, nil
+}
+
+func fromJSONFinding(jf jsonFinding) analyzer.Finding {
+	ts, _ := time.Parse(time.RFC3339, jf.Timestamp)
+	return analyzer.Finding{
+		Timestamp:     ts,
+		SourceIP:      jf.SourceIP,
+		User:          jf.User,
+		ServiceName:   jf.ServiceName,
+		Provider:      jf.Provider,
+		Category:      jf.Category,
+		Domain:        jf.Domain,
+		URL:           jf.URL,
+		Method:        jf.Method,
+		StatusCode:    jf.StatusCode,
+		Action:        jf.Action,
+		BytesSent:     jf.BytesSent,
+		BytesReceived: jf.BytesReceived,
+		CloudHosted:   jf.CloudHosted,
+		Activity:      analyzer.Activity(jf.Activity),
+		UserAgent:     jf.UserAgent,
+		JA3:           jf.JA3,
+		ProcessName:   jf.ProcessName,
+		DeviceName:    jf.DeviceName,
+		DetectedBy:    jf.DetectedBy,
+		Blocked:       jf.Blocked,
+		OffHours:      jf.OffHours,
+		NewAdoption:   jf.NewAdoption,
+		Automated:     jf.Automated,
+		Rate:          jf.Rate,
+		SourceFile:    jf.SourceFile,
+		LineNumber:    jf.LineNumber,
+		Tenant:        jf.Tenant,
+		Site:          jf.Site,
+	}
+}
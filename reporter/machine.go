@@ -0,0 +1,68 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// ScanInfo describes a run for machine-readable output.
+type ScanInfo struct {
+	Version    string     `json:"version"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+	ExitCode   int        `json:"exit_code"`
+	Files      []ScanFile `json:"files"`
+
+	// Filled in by ReportMachine from the summary's scan metadata.
+	ServicesDB      string  `json:"services_db_sha256,omitempty"`
+	DBVersion       string  `json:"services_db_version,omitempty"`
+	DBUpdated       string  `json:"services_db_updated,omitempty"`
+	Host            string  `json:"host,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	LogsFrom        string  `json:"logs_from,omitempty"`
+	LogsTo          string  `json:"logs_to,omitempty"`
+}
+
+// ScanFile is the outcome of scanning one file. Class is set when the file
+// could not be scanned cleanly (see ErrorReport).
+type ScanFile struct {
+	Path      string   `json:"path"`
+	Size      int64    `json:"size"`
+	SHA256    string   `json:"sha256,omitempty"` // of the first Size bytes
+	Format    string   `json:"format,omitempty"`
+	Encoding  string   `json:"encoding,omitempty"`
+	Lines     int      `json:"lines"`
+	Entries   int      `json:"entries"`
+	Malformed int      `json:"malformed"`
+	Findings  int      `json:"findings"`
+	Seconds   float64  `json:"seconds"`
+	Class     string   `json:"class,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Samples   []string `json:"samples,omitempty"`
+}
+
+// ReportMachine writes the whole run as a single JSON document: scan
+// metadata and per-file errors alongside the usual JSON report fields.
+func ReportMachine(info ScanInfo, s analyzer.Summary, w io.Writer) error {
+	if info.Files == nil {
+		info.Files = []ScanFile{}
+	}
+	if m := s.Scan; m != nil {
+		info.ServicesDB, info.Host = m.ServicesDB, m.Host
+		info.DBVersion, info.DBUpdated = m.DBInfo.Version, m.DBInfo.LastUpdated
+	}
+	info.DurationSeconds = info.FinishedAt.Sub(info.StartedAt).Seconds()
+	first, last := s.LogRange()
+	info.LogsFrom, info.LogsTo = formatTime(first), formatTime(last)
+	doc := struct {
+		Scan ScanInfo `json:"scan"`
+		jsonReport
+	}{info, newJSONReport(s)}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,92 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MISP event fields are strings in the import format even when numeric.
+const (
+	mispDistributionOrg = "0" // your organisation only
+	mispThreatLevelLow  = "3"
+	mispAnalysisDone    = "2"
+)
+
+type mispTag struct {
+	Name string `json:"name"`
+}
+
+type mispAttribute struct {
+	UUID      string    `json:"uuid"`
+	Type      string    `json:"type"`
+	Category  string    `json:"category"`
+	Value     string    `json:"value"`
+	ToIDS     bool      `json:"to_ids"`
+	Comment   string    `json:"comment,omitempty"`
+	FirstSeen string    `json:"first_seen,omitempty"`
+	LastSeen  string    `json:"last_seen,omitempty"`
+	Tag       []mispTag `json:"Tag,omitempty"`
+}
+
+// WriteMISP writes in as one MISP event in the JSON import format. Each
+// domain becomes a "domain" attribute, tagged with its service and
+// category; toIDS marks them for export to detection systems.
+func WriteMISP(in Intel, toIDS bool, w io.Writer) error {
+	if _, ok := tlpMarkings[in.TLP]; !ok {
+		return fmt.Errorf("unknown TLP level %q", in.TLP)
+	}
+	var attrs []mispAttribute
+	seen := 0
+	for _, s := range in.Services {
+		comment := fmt.Sprintf("%s (%s)", s.Name, s.Category)
+		if s.Seen() {
+			seen++
+			comment += fmt.Sprintf(": %d request(s) from %d user(s), %d blocked", s.Hits, s.Users, s.Blocked)
+		}
+		tags := []mispTag{
+			{Name: fmt.Sprintf("shadow-ai:service=%q", s.Name)},
+			{Name: fmt.Sprintf("shadow-ai:category=%q", s.Category)},
+		}
+		add := func(typ, value string) {
+			a := mispAttribute{
+				UUID:     intelUUID(intelNamespace, "misp/"+typ+"/"+value),
+				Type:     typ,
+				Category: "Network activity",
+				Value:    value,
+				ToIDS:    toIDS,
+				Comment:  comment,
+				Tag:      tags,
+			}
+			if !s.FirstSeen.IsZero() {
+				a.FirstSeen = s.FirstSeen.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+				a.LastSeen = s.LastSeen.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+			}
+			attrs = append(attrs, a)
+		}
+		for _, d := range s.Domains {
+			add("domain", d)
+		}
+		for _, p := range s.HostPatterns {
+			add("text", p) // MISP has no wildcard domain type
+		}
+	}
+	if attrs == nil {
+		attrs = []mispAttribute{}
+	}
+
+	event := map[string]any{
+		"uuid":            randomUUID(),
+		"info":            fmt.Sprintf("Shadow AI indicators: %d service(s), %d seen in logs", len(in.Services), seen),
+		"date":            in.GeneratedAt.UTC().Format("2006-01-02"),
+		"distribution":    mispDistributionOrg,
+		"threat_level_id": mispThreatLevelLow,
+		"analysis":        mispAnalysisDone,
+		"published":       false,
+		"Tag":             []mispTag{{Name: "tlp:" + in.TLP}, {Name: "shadow-ai"}},
+		"Attribute":       attrs,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{"Event": event})
+}
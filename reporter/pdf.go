@@ -0,0 +1,347 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// pdfTopN is how many rows each executive report chart shows.
+const pdfTopN = 10
+
+// reportPDF renders a short executive report: headline numbers, a risk
+// narrative, and top-10 charts. It is meant for leadership after an audit,
+// so it leaves out per-finding detail.
+func reportPDF(s analyzer.Summary, w io.Writer) error {
+	d := newPDFDoc()
+	d.text(pdfMargin, d.y, 20, true, "Shadow AI Audit - Executive Summary")
+	d.y -= 20
+	if s.Tenant != "" {
+		d.text(pdfMargin, d.y, 10, false, "Tenant: "+s.Tenant)
+		d.y -= 14
+	}
+	if !s.FirstSeen.IsZero() {
+		d.text(pdfMargin, d.y, 10, false, fmt.Sprintf("Activity from %s to %s", s.FirstSeen.Format("2006-01-02"), s.LastSeen.Format("2006-01-02")))
+		d.y -= 14
+	}
+	if s.Partial {
+		d.text(pdfMargin, d.y, 10, true, "Partial results: the scan was stopped before all input was read.")
+		d.y -= 14
+	}
+	d.y -= 10
+
+	d.heading("Key Figures")
+	stats := [][2]string{
+		{"Log entries scanned", fmt.Sprint(s.TotalLogsScanned)},
+		{"AI service hits", fmt.Sprintf("%d (%d allowed, %d blocked)", s.TotalFindings, s.AllowedFindings, s.BlockedFindings)},
+		{"Users", fmt.Sprint(s.UniqueUsers)},
+		{"AI services", fmt.Sprint(s.UniqueServices)},
+	}
+	if len(s.Sites) > 0 {
+		stats = append(stats, [2]string{"Sites", fmt.Sprint(len(s.Sites))})
+	}
+	if n := s.ByActivity[analyzer.ActivityUpload]; n > 0 {
+		stats = append(stats, [2]string{"Uploads to AI services", fmt.Sprint(n)})
+	}
+	if s.BytesSent > 0 || s.BytesReceived > 0 {
+		stats = append(stats, [2]string{"Uploaded / downloaded", byteSize(s.BytesSent) + " / " + byteSize(s.BytesReceived)})
+	}
+	if len(s.NewAdoptions) > 0 {
+		stats = append(stats, [2]string{"New user/service adoptions", fmt.Sprint(len(s.NewAdoptions))})
+	}
+	if len(s.Bursts) > 0 {
+		stats = append(stats, [2]string{"Sources suspected of automation", fmt.Sprint(len(s.Bursts))})
+	}
+	if s.OffHoursFindings > 0 {
+		stats = append(stats, [2]string{"Hits outside business hours", fmt.Sprint(s.OffHoursFindings)})
+	}
+	for _, st := range stats {
+		d.need(16)
+		d.text(pdfMargin, d.y, 11, true, st[0])
+		d.text(pdfMargin+200, d.y, 11, false, st[1])
+		d.y -= 16
+	}
+	d.y -= 8
+
+	d.heading("Risk Assessment")
+	for _, p := range riskNarrative(s) {
+		d.paragraph(p, 11)
+	}
+
+	if s.TotalFindings > 0 {
+		d.barChart("Top AI Services", sortedMap(s.ByService))
+		if len(s.ByUser) > 0 {
+			d.barChart("Top Users", sortedMap(s.ByUser))
+		}
+		d.barChart("AI Categories", sortedMap(s.ByCategory))
+	}
+
+	if rows := scanRows(s); rows != nil {
+		d.heading("Scan")
+		for _, r := range rows {
+			d.need(14)
+			d.text(pdfMargin, d.y, 9, true, r[0])
+			d.text(pdfMargin+100, d.y, 9, false, r[1])
+			d.y -= 14
+		}
+	}
+	return d.writeTo(w)
+}
+
+// riskNarrative summarizes the findings in plain sentences, rating overall
+// risk the way finding severities do: uploads and new adoption are high,
+// AI use that got through is moderate, and blocked attempts alone are low.
+func riskNarrative(s analyzer.Summary) []string {
+	if s.TotalFindings == 0 {
+		return []string{"Overall risk: none found. No traffic to known AI services was seen in the logs scanned."}
+	}
+
+	uploads := s.ByActivity[analyzer.ActivityUpload]
+	level := "LOW"
+	switch {
+	case uploads > 0 || len(s.NewAdoptions) > 0:
+		level = "HIGH"
+	case s.AllowedFindings > 0:
+		level = "MODERATE"
+	}
+
+	var out []string
+	out = append(out, fmt.Sprintf("Overall risk: %s. %s reached %s, with %s in total.",
+		level, plural(s.UniqueUsers, "user"), plural(s.UniqueServices, "AI service"), plural(s.TotalFindings, "hit")))
+	if top := sortedMap(s.ByService); len(top) > 0 {
+		out = append(out, fmt.Sprintf("The most used service was %s, with %d%% of all hits.", top[0].Key, top[0].Val*100/s.TotalFindings))
+	}
+	if len(s.Sites) > 1 {
+		out = append(out, fmt.Sprintf("The report combines %d sites. The busiest, %s, accounted for %d%% of hits.", len(s.Sites), s.Sites[0].Site, s.Sites[0].Findings*100/s.TotalFindings))
+	}
+	if uploads > 0 {
+		out = append(out, fmt.Sprintf("%s sent data to an AI service. Uploaded data may include confidential material and should be reviewed first.", plural(uploads, "request")))
+	}
+	if s.BytesSent > 0 {
+		top := analyzer.SortedVolumes(s.VolumeByService)
+		sort.SliceStable(top, func(i, j int) bool { return top[i].Sent > top[j].Sent })
+		out = append(out, fmt.Sprintf("%s was uploaded to AI services, the most of it (%s) to %s.", byteSize(s.BytesSent), byteSize(top[0].Sent), top[0].Key))
+	}
+	if n := len(s.NewAdoptions); n > 0 {
+		out = append(out, fmt.Sprintf("%s started using an AI service for the first time since the baseline.", plural(n, "user/service pair")))
+	}
+	if s.BlockedFindings > 0 {
+		out = append(out, fmt.Sprintf("Controls blocked %s. The other %d reached the service.", plural(s.BlockedFindings, "attempt"), s.AllowedFindings))
+	} else {
+		out = append(out, "No attempts were blocked: every AI request seen reached its service.")
+	}
+	if len(s.CloudHosted) > 0 {
+		out = append(out, "Some traffic went to AI hosted in cloud provider accounts. Check that those accounts are sanctioned.")
+	}
+	if n := len(s.Bursts); n > 0 {
+		out = append(out, fmt.Sprintf("%s made AI requests in bursts, peaking at %.1f a minute, which suggests scripted or API use.", plural(n, "source"), s.Bursts[0].Rate))
+	}
+	if s.OffHoursFindings > 0 {
+		out = append(out, fmt.Sprintf("%s fell outside business hours.", plural(s.OffHoursFindings, "hit")))
+	}
+	return out
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Page geometry in points: US Letter with 3/4 inch margins.
+const (
+	pdfWidth  = 612
+	pdfHeight = 792
+	pdfMargin = 54
+)
+
+// pdfDoc builds a minimal PDF of Helvetica text and filled rectangles,
+// which is all the executive report draws. Pages are added as the cursor y
+// runs into the bottom margin.
+type pdfDoc struct {
+	pages []*bytes.Buffer
+	y     float64 // baseline of the next line on the current page
+}
+
+func newPDFDoc() *pdfDoc {
+	d := &pdfDoc{}
+	d.newPage()
+	return d
+}
+
+func (d *pdfDoc) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfHeight - pdfMargin - 14
+}
+
+func (d *pdfDoc) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// need starts a new page unless h points fit above the bottom margin.
+func (d *pdfDoc) need(h float64) {
+	if d.y-h < pdfMargin {
+		d.newPage()
+	}
+}
+
+func (d *pdfDoc) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (d *pdfDoc) rect(x, y, w, h float64, gray float64) {
+	fmt.Fprintf(d.page(), "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, y, w, h)
+}
+
+func (d *pdfDoc) heading(s string) {
+	d.need(40)
+	d.text(pdfMargin, d.y, 14, true, s)
+	d.rect(pdfMargin, d.y-5, pdfWidth-2*pdfMargin, 0.8, 0.6)
+	d.y -= 24
+}
+
+// paragraph writes s word-wrapped to the text width.
+func (d *pdfDoc) paragraph(s string, size float64) {
+	width := float64(pdfWidth - 2*pdfMargin)
+	var line string
+	flush := func() {
+		d.need(size * 1.4)
+		d.text(pdfMargin, d.y, size, false, line)
+		d.y -= size * 1.4
+	}
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		if line != "" && textWidth(next, size) > width {
+			flush()
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		flush()
+	}
+	d.y -= size * 0.6
+}
+
+// barChart draws up to pdfTopN rows as horizontal bars scaled to the
+// largest value.
+func (d *pdfDoc) barChart(title string, rows []kv) {
+	if len(rows) > pdfTopN {
+		rows = rows[:pdfTopN]
+	}
+	if len(rows) == 0 {
+		return
+	}
+	const rowHeight, labelWidth, valueWidth = 16.0, 160.0, 50.0
+	d.need(40 + rowHeight*float64(len(rows)))
+	d.heading(title)
+
+	barMax := pdfWidth - 2*pdfMargin - labelWidth - valueWidth
+	for _, r := range rows {
+		label := r.Key
+		for textWidth(label, 10) > labelWidth-8 && len(label) > 1 {
+			label = label[:len(label)-2] + "~"
+		}
+		d.text(pdfMargin, d.y, 10, false, label)
+		bar := barMax * float64(r.Val) / float64(rows[0].Val)
+		d.rect(pdfMargin+labelWidth, d.y-2, max(bar, 1), rowHeight-6, 0.35)
+		d.text(pdfMargin+labelWidth+bar+6, d.y, 10, false, fmt.Sprint(r.Val))
+		d.y -= rowHeight
+	}
+	d.y -= 10
+}
+
+// writeTo serializes the document: catalog, page tree, the two fonts, then
+// a page and content stream per page, followed by the cross-reference table.
+func (d *pdfDoc) writeTo(w io.Writer) error {
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	const firstPage = 5 // objects 1-4 are the catalog, page tree, and fonts
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range d.pages {
+		footer := fmt.Sprintf("BT /F1 8 Tf %d %d Td (Shadow AI Hunter - page %d of %d) Tj ET\n", pdfMargin, pdfMargin/2, i+1, len(d.pages))
+		content := p.String() + footer
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfWidth, pdfHeight, firstPage+2*i+1))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString encodes s for a PDF string literal in WinAnsiEncoding. Latin-1
+// characters pass through; anything else becomes "?".
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths are the Helvetica glyph widths, in thousandths of the
+// font size, for ASCII 32 through 126.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth estimates the width of s in points at the given size. Glyphs
+// outside ASCII are counted at the width of a digit.
+func textWidth(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
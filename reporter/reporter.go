@@ -1,14 +1,20 @@
+// Package reporter renders analysis summaries as tables, JSON, CSV, and
+// HTML. Every report is written to a caller-supplied io.Writer.
 package reporter
 
 import (
+	"cmp"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/shadow-ai-hunter/analyzer"
 )
@@ -20,6 +26,8 @@ const (
 	FormatTable Format = "table"
 	FormatJSON  Format = "json"
 	FormatCSV   Format = "csv"
+	FormatHTML  Format = "html"
+	FormatPDF   Format = "pdf"
 )
 
 // Report outputs the analysis summary in the requested format.
@@ -31,6 +39,10 @@ func Report(summary analyzer.Summary, format Format, w io.Writer) error {
 		return reportJSON(summary, w)
 	case FormatCSV:
 		return reportCSV(summary, w)
+	case FormatHTML:
+		return reportHTML(summary, w)
+	case FormatPDF:
+		return reportPDF(summary, w)
 	default:
 		return fmt.Errorf("unknown format: %s", format)
 	}
@@ -47,150 +59,836 @@ func WriteToFile(summary analyzer.Summary, format Format, path string) error {
 }
 
 func reportTable(s analyzer.Summary, w io.Writer) error {
+	width := outputWidth(w)
+
 	// Header banner
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "  SHADOW AI HUNTER - Scan Results")
-	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintln(w, rule("=", 60, width))
+	if s.Tenant != "" {
+		fmt.Fprintf(w, "  Tenant:          %s\n", s.Tenant)
+	}
+	if s.Partial {
+		fmt.Fprintln(w, "  PARTIAL RESULTS: the scan was stopped before all input was read")
+	}
 	fmt.Fprintf(w, "  Logs scanned:    %d\n", s.TotalLogsScanned)
+	if n := s.MalformedLines(); n > 0 {
+		fmt.Fprintf(w, "  Malformed lines: %d (skipped)\n", n)
+	}
 	fmt.Fprintf(w, "  AI hits found:   %d\n", s.TotalFindings)
+	fmt.Fprintf(w, "    allowed:       %d\n", s.AllowedFindings)
+	fmt.Fprintf(w, "    blocked:       %d\n", s.BlockedFindings)
+	if s.BytesSent > 0 || s.BytesReceived > 0 {
+		fmt.Fprintf(w, "  Uploaded:        %s\n", byteSize(s.BytesSent))
+		fmt.Fprintf(w, "  Downloaded:      %s\n", byteSize(s.BytesReceived))
+	}
 	fmt.Fprintf(w, "  Unique users:    %d\n", s.UniqueUsers)
 	fmt.Fprintf(w, "  Unique services: %d\n", s.UniqueServices)
-	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintln(w, rule("=", 60, width))
+
+	if len(s.Sources) > 0 {
+		writeCoverage(w, width, s.Sources)
+	}
+	if s.Scan != nil {
+		writeScanMeta(w, width, s)
+	}
+
+	// Clients resolving names over HTTPS, whose lookups DNS logs miss. They
+	// are not AI hits, so they are listed even when nothing else was found.
+	if len(s.DoHClients) > 0 {
+		writeDoHClients(w, width, s)
+	}
 
 	if s.TotalFindings == 0 {
 		fmt.Fprintln(w, "\n  No shadow AI activity detected.")
 		return nil
 	}
 
-	// Top users
-	fmt.Fprintln(w, "\n  TOP USERS BY AI SERVICE HITS")
-	fmt.Fprintln(w, strings.Repeat("-", 40))
-	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
-	for _, kv := range sortedMap(s.ByUser) {
-		fmt.Fprintf(tw, "  %s\t%d hits\n", kv.key, kv.val)
+	// Per-site breakdown of a merged report
+	if len(s.Sites) > 0 {
+		writeSites(w, width, s)
+	}
+
+	// Top users (absent when the redaction profile removes users)
+	if len(s.ByUser) > 0 {
+		fmt.Fprintln(w, "\n  TOP USERS BY AI SERVICE HITS")
+		fmt.Fprintln(w, rule("-", 40, width))
+		tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		writeCounts(tw, sortedMap(s.ByUser), s.Layout.Top)
+		tw.Flush()
 	}
-	tw.Flush()
 
 	// Top services
 	fmt.Fprintln(w, "\n  TOP AI SERVICES DETECTED")
-	fmt.Fprintln(w, strings.Repeat("-", 40))
-	tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
-	for _, kv := range sortedMap(s.ByService) {
-		fmt.Fprintf(tw, "  %s\t%d hits\n", kv.key, kv.val)
-	}
+	fmt.Fprintln(w, rule("-", 40, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	writeCounts(tw, sortedMap(s.ByService), s.Layout.Top)
 	tw.Flush()
 
-	// Detailed findings
-	fmt.Fprintln(w, "\n  DETAILED FINDINGS")
-	fmt.Fprintln(w, strings.Repeat("-", 90))
+	// Providers, with the hosts behind each when several were hit
+	if len(s.ByProvider) > 0 {
+		writeProviders(w, width, s)
+	}
+
+	// Categories
+	fmt.Fprintln(w, "\n  AI CATEGORIES DETECTED")
+	fmt.Fprintln(w, rule("-", 40, width))
 	tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
-	fmt.Fprintf(tw, "  TIMESTAMP\tSOURCE IP\tSERVICE\tCATEGORY\tDOMAIN\n")
-	fmt.Fprintf(tw, "  ---------\t---------\t-------\t--------\t------\n")
+	writeCounts(tw, sortedMap(s.ByCategory), s.Layout.Top)
+	tw.Flush()
+
+	// Cloud-provider hosted AI is governed differently from consumer services
+	if len(s.CloudHosted) > 0 {
+		fmt.Fprintln(w, "\n  CLOUD-HOSTED AI")
+		fmt.Fprintln(w, rule("-", 40, width))
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		writeCounts(tw, sortedMap(s.CloudHosted), s.Layout.Top)
+		tw.Flush()
+		fmt.Fprintln(w, "  These run in cloud provider accounts under enterprise terms.")
+		fmt.Fprintln(w, "  Check that the accounts behind them are sanctioned.")
+	}
+
+	// Activity breakdown (only when URLs were available to classify)
+	if len(s.ByActivity) > 0 {
+		fmt.Fprintln(w, "\n  ACTIVITY BREAKDOWN")
+		fmt.Fprintln(w, rule("-", 40, width))
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		writeCounts(tw, sortedMap(activityCounts(s.ByActivity)), s.Layout.Top)
+		tw.Flush()
+	}
+
+	// Data volume, for logs that record sizes
+	if len(s.VolumeByService) > 0 {
+		fmt.Fprintln(w, "\n  DATA VOLUME BY SERVICE")
+		fmt.Fprintln(w, rule("-", 60, width))
+		writeVolumes(w, "SERVICE", s.VolumeByService, s.Layout.Top)
+		fmt.Fprintln(w, "\n  DATA VOLUME BY USER")
+		fmt.Fprintln(w, rule("-", 60, width))
+		writeVolumes(w, "USER", s.VolumeByUser, s.Layout.Top)
+	}
+
+	// When AI is used, by weekday and hour (-heatmap)
+	if s.Layout.Heatmap {
+		writeHeatmaps(w, width, s)
+	}
+
+	// Off-hours activity (only when business hours are configured)
+	if s.OffHoursFindings > 0 {
+		fmt.Fprintln(w, "\n  OFF-HOURS ACTIVITY")
+		fmt.Fprintln(w, rule("-", 40, width))
+		fmt.Fprintf(w, "  %d hits outside business hours\n", s.OffHoursFindings)
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		writeCounts(tw, sortedMap(s.OffHoursByUser), s.Layout.Top)
+		tw.Flush()
+	}
+
+	// First contact between a user and a service since the baseline
+	if len(s.NewAdoptions) > 0 {
+		fmt.Fprintln(w, "\n  NEW AI ADOPTION")
+		fmt.Fprintln(w, rule("-", 60, width))
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "  FIRST SEEN\tUSER\tSERVICE\n")
+		for i, a := range s.NewAdoptions {
+			if more(tw, i, len(s.NewAdoptions), s.Layout.Top) {
+				break
+			}
+			ts := a.FirstSeen.Format("2006-01-02 15:04:05")
+			if a.FirstSeen.IsZero() {
+				ts = "N/A"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", ts, a.User, a.Service)
+		}
+		tw.Flush()
+	}
+
+	// AI software seen checking for updates or sending telemetry
+	if len(s.ToolingInstalled) > 0 {
+		fmt.Fprintln(w, "\n  AI TOOLING INSTALLED")
+		fmt.Fprintln(w, rule("-", 60, width))
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "  USER\tSOFTWARE\tLAST SEEN\tHITS\n")
+		for i, t := range s.ToolingInstalled {
+			if more(tw, i, len(s.ToolingInstalled), s.Layout.Top) {
+				break
+			}
+			ts := t.LastSeen.Format("2006-01-02 15:04:05")
+			if t.LastSeen.IsZero() {
+				ts = "N/A"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\n", t.User, t.Service, ts, t.Hits)
+		}
+		tw.Flush()
+	}
+
+	// Clients tunnelling out through VPN and proxy services (-vpn)
+	if len(s.VPNUsers) > 0 {
+		fmt.Fprintln(w, "\n  VPN AND PROXY SERVICES")
+		fmt.Fprintln(w, rule("-", 60, width))
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "  USER\tSERVICE\tLAST SEEN\tHITS\n")
+		for i, v := range s.VPNUsers {
+			if more(tw, i, len(s.VPNUsers), s.Layout.Top) {
+				break
+			}
+			ts := v.LastSeen.Format("2006-01-02 15:04:05")
+			if v.LastSeen.IsZero() {
+				ts = "N/A"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\n", v.User, v.Service, ts, v.Hits)
+		}
+		tw.Flush()
+	}
+
+	// Sources making requests faster than a person would (-burst)
+	if len(s.Bursts) > 0 {
+		fmt.Fprintln(w, "\n  AUTOMATION SUSPECTED")
+		fmt.Fprintln(w, rule("-", 60, width))
+		tw = tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "  USER\tPEAK RATE\tFIRST SEEN\tLAST SEEN\tHITS\n")
+		for i, b := range s.Bursts {
+			if more(tw, i, len(s.Bursts), s.Layout.Top) {
+				break
+			}
+			fmt.Fprintf(tw, "  %s\t%.1f/min\t%s\t%s\t%d\n", b.User, b.Rate,
+				b.FirstSeen.Format("2006-01-02 15:04:05"), b.LastSeen.Format("2006-01-02 15:04:05"), b.Hits)
+		}
+		tw.Flush()
+	}
+
+	// Detailed findings (absent in aggregate-only reports)
+	if len(s.Findings) == 0 {
+		fmt.Fprintln(w)
+		return nil
+	}
+	if s.Layout.SessionGap > 0 {
+		writeSessionsTable(w, width, s)
+		fmt.Fprintln(w)
+		return nil
+	}
+	var allowed, blocked []analyzer.Finding
 	for _, f := range s.Findings {
+		if f.Blocked {
+			blocked = append(blocked, f)
+		} else {
+			allowed = append(allowed, f)
+		}
+	}
+	if len(allowed) > 0 {
+		writeFindingsTable(w, width, "DETAILED FINDINGS", allowed, s.AllowedFindings, s.Layout)
+	}
+	if len(blocked) > 0 {
+		writeFindingsTable(w, width, "BLOCKED ATTEMPTS", blocked, s.BlockedFindings, s.Layout)
+	}
+	fmt.Fprintln(w)
+
+	return nil
+}
+
+// maxProviderEndpoints caps the hosts listed under each provider in tables.
+const maxProviderEndpoints = 5
+
+// writeProviders rolls services and mirrored domains up to their provider,
+// listing the busiest hosts under each.
+func writeProviders(w io.Writer, width int, s analyzer.Summary) {
+	fmt.Fprintln(w, "\n  AI PROVIDERS")
+	fmt.Fprintln(w, rule("-", 40, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	providers := sortedMap(s.ByProvider)
+	for i, p := range providers {
+		if more(tw, i, len(providers), s.Layout.Top) {
+			break
+		}
+		fmt.Fprintf(tw, "  %s\t%d hits\n", p.Key, p.Val)
+		endpoints := sortedMap(s.ProviderEndpoints[p.Key])
+		if len(endpoints) < 2 {
+			continue
+		}
+		for i, e := range endpoints {
+			if i == maxProviderEndpoints {
+				fmt.Fprintf(tw, "    (+%d more hosts)\t\n", len(endpoints)-i)
+				break
+			}
+			fmt.Fprintf(tw, "    %s\t%d\n", e.Key, e.Val)
+		}
+	}
+	tw.Flush()
+}
+
+// writeDoHClients lists the clients seen reaching DoH resolvers.
+func writeDoHClients(w io.Writer, width int, s analyzer.Summary) {
+	fmt.Fprintln(w, "\n  DNS OVER HTTPS CLIENTS")
+	fmt.Fprintln(w, rule("-", 60, width))
+	fmt.Fprintln(w, "  These clients bypass DNS logs; their AI use may not show in this report.")
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  USER\tRESOLVER\tLAST SEEN\tHITS\n")
+	for i, d := range s.DoHClients {
+		if more(tw, i, len(s.DoHClients), s.Layout.Top) {
+			break
+		}
+		ts := d.LastSeen.Format("2006-01-02 15:04:05")
+		if d.LastSeen.IsZero() {
+			ts = "N/A"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\n", d.User, d.Resolver, ts, d.Hits)
+	}
+	tw.Flush()
+}
+
+// maxSiteServices caps the services named for each site in tables.
+const maxSiteServices = 3
+
+// writeSites lists each site of a merged report with its busiest services.
+func writeSites(w io.Writer, width int, s analyzer.Summary) {
+	fmt.Fprintln(w, "\n  HITS BY SITE")
+	fmt.Fprintln(w, rule("-", 60, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  SITE\tHITS\tBLOCKED\tUSERS\tTOP SERVICES\n")
+	for i, site := range s.Sites {
+		if more(tw, i, len(s.Sites), s.Layout.Top) {
+			break
+		}
+		var top []string
+		for _, svc := range sortedMap(site.Services) {
+			if len(top) == maxSiteServices {
+				break
+			}
+			top = append(top, fmt.Sprintf("%s (%d)", svc.Key, svc.Val))
+		}
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%d\t%s\n", site.Site, site.Findings, site.Blocked, site.Users, strings.Join(top, ", "))
+	}
+	tw.Flush()
+}
+
+// writeCoverage shows how much detection-relevant detail each source gave.
+func writeCoverage(w io.Writer, width int, sources []analyzer.SourceCoverage) {
+	fmt.Fprintln(w, "\n  SOURCE COVERAGE")
+	fmt.Fprintln(w, rule("-", 60, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  SOURCE\tFORMAT\tENTRIES\tMALFORMED\tDOMAIN\tIP-ONLY\tURL\tIDENTITY\tAI HITS\n")
+	for _, c := range sources {
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\t%d%%\t%d%%\t%d%%\t%d%%\t%d\n",
+			fit(filepath.Base(c.Source), 40), c.Format, c.Entries, c.Malformed,
+			c.Percent(c.WithDomain), c.Percent(c.IPOnly), c.Percent(c.WithURL), c.Percent(c.WithIdentity), c.Findings)
+	}
+	tw.Flush()
+}
+
+// writeFindingsTable lists findings one per row. With layout.MaxFindings
+// set, only that many rows are shown, followed by a count of the rest; total
+// counts findings that were never loaded, as in a spilled scan.
+func writeFindingsTable(w io.Writer, width int, title string, findings []analyzer.Finding, total int, layout analyzer.Layout) {
+	total = max(total, len(findings))
+	if limit := layout.MaxFindings; limit > 0 && total > limit {
+		findings = findings[:limit]
+	}
+
+	// Endpoint logs name the process and device behind each request; the
+	// columns are left out when no finding has them.
+	endpoint := false
+	for _, f := range findings {
+		endpoint = endpoint || f.ProcessName != "" || f.DeviceName != ""
+	}
+
+	// The domain is the last column; shorten it so rows fit the console.
+	used := 2 + len("2006-01-02 15:04:05") + 2
+	cols := make([]int, 7)
+	for _, f := range findings {
+		for i, v := range []string{f.Identity(), f.ServiceName, f.Category, string(f.Activity)} {
+			cols[i] = max(cols[i], len(v), 9)
+		}
+		if layout.ShowSource {
+			cols[4] = max(cols[4], len(findingSource(f)), 6)
+		}
+		if endpoint {
+			cols[5] = max(cols[5], len(f.DeviceName), 6)
+			cols[6] = max(cols[6], len(f.ProcessName), 7)
+		}
+	}
+	for _, c := range cols {
+		if c > 0 {
+			used += c + 2
+		}
+	}
+	domainWidth := max(width-used, 16)
+
+	fmt.Fprintf(w, "\n  %s\n", title)
+	fmt.Fprintln(w, rule("-", 90, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	header := []string{"TIMESTAMP", "USER", "SERVICE", "CATEGORY", "ACTIVITY"}
+	if layout.ShowSource {
+		header = append(header, "SOURCE")
+	}
+	if endpoint {
+		header = append(header, "DEVICE", "PROCESS")
+	}
+	header = append(header, "DOMAIN")
+	underline := make([]string, len(header))
+	for i, h := range header {
+		underline[i] = strings.Repeat("-", len(h))
+	}
+	fmt.Fprintf(tw, "  %s\n", strings.Join(header, "\t"))
+	fmt.Fprintf(tw, "  %s\n", strings.Join(underline, "\t"))
+	for _, f := range findings {
 		ts := f.Timestamp.Format("2006-01-02 15:04:05")
 		if f.Timestamp.IsZero() {
 			ts = "N/A"
 		}
-		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
-			ts, f.SourceIP, f.ServiceName, f.Category, f.Domain)
+		activity := string(f.Activity)
+		if activity == "" {
+			activity = "-"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t", ts, f.Identity(), f.ServiceName, f.Category, activity)
+		if layout.ShowSource {
+			fmt.Fprintf(tw, "%s\t", findingSource(f))
+		}
+		if endpoint {
+			fmt.Fprintf(tw, "%s\t%s\t", cmp.Or(f.DeviceName, "-"), cmp.Or(f.ProcessName, "-"))
+		}
+		fmt.Fprintf(tw, "%s\n", fit(f.Domain, domainWidth))
 	}
 	tw.Flush()
-	fmt.Fprintln(w)
+	writeTruncated(w, total-len(findings))
+}
 
-	return nil
+// findingSource is the file base name and line a finding was read from.
+func findingSource(f analyzer.Finding) string {
+	switch {
+	case f.SourceFile == "":
+		return "-"
+	case f.LineNumber == 0:
+		return filepath.Base(f.SourceFile)
+	default:
+		return fmt.Sprintf("%s:%d", filepath.Base(f.SourceFile), f.LineNumber)
+	}
+}
+
+// writeTruncated notes rows left out of a console table.
+func writeTruncated(w io.Writer, n int) {
+	if n > 0 {
+		fmt.Fprintf(w, "  ... and %d more (JSON and CSV output list every row)\n", n)
+	}
+}
+
+// writeCounts writes name/hits rows, stopping after top rows when top is set.
+func writeCounts(tw io.Writer, rows []kv, top int) {
+	for i, r := range rows {
+		if more(tw, i, len(rows), top) {
+			break
+		}
+		fmt.Fprintf(tw, "  %s\t%d hits\n", r.Key, r.Val)
+	}
+}
+
+// writeVolumes lists a volume table, most traffic first.
+func writeVolumes(w io.Writer, key string, m map[string]analyzer.Volume, top int) {
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  %s\tUPLOADED\tDOWNLOADED\tTOTAL\n", key)
+	rows := analyzer.SortedVolumes(m)
+	for i, r := range rows {
+		if more(tw, i, len(rows), top) {
+			break
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", r.Key, byteSize(r.Sent), byteSize(r.Received), byteSize(r.Total()))
+	}
+	tw.Flush()
+}
+
+// more writes a "(+N more)" row and reports true once row i of n passes
+// the top limit.
+func more(tw io.Writer, i, n, top int) bool {
+	if top <= 0 || i < top {
+		return false
+	}
+	fmt.Fprintf(tw, "  (+%d more)\t\n", n-i)
+	return true
+}
+
+// writeSessionsTable lists findings grouped into sessions.
+func writeSessionsTable(w io.Writer, width int, s analyzer.Summary) {
+	sessions := analyzer.Sessions(s.Findings, s.Layout.SessionGap)
+	fmt.Fprintf(w, "\n  SESSIONS (%d, split after %s idle)\n", len(sessions), s.Layout.SessionGap)
+	total := len(sessions)
+	if limit := s.Layout.MaxFindings; limit > 0 && total > limit {
+		sessions = sessions[:limit]
+	}
+	fmt.Fprintln(w, rule("-", 90, width))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  FIRST SEEN\tLAST SEEN\tUSER\tSERVICE\tHITS\tBLOCKED\tBYTES\n")
+	fmt.Fprintf(tw, "  ----------\t---------\t----\t-------\t----\t-------\t-----\n")
+	for _, ses := range sessions {
+		first, last := "N/A", "N/A"
+		if !ses.FirstSeen.IsZero() {
+			first = ses.FirstSeen.Format("2006-01-02 15:04:05")
+			last = ses.LastSeen.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%d\t%d\t%d\n", first, last, ses.User, ses.Service, ses.Hits, ses.Blocked, ses.Bytes)
+	}
+	tw.Flush()
+	writeTruncated(w, total-len(sessions))
 }
 
 // jsonReport mirrors the summary for clean JSON output.
 type jsonReport struct {
-	TotalLogsScanned int               `json:"total_logs_scanned"`
-	TotalFindings    int               `json:"total_findings"`
-	UniqueUsers      int               `json:"unique_users"`
-	UniqueServices   int               `json:"unique_services"`
-	ByUser           map[string]int    `json:"hits_by_user"`
-	ByService        map[string]int    `json:"hits_by_service"`
-	Findings         []jsonFinding     `json:"findings"`
+	Partial           bool                       `json:"partial,omitempty"`
+	Tenant            string                     `json:"tenant,omitempty"`
+	Scan              *jsonScan                  `json:"scan,omitempty"`
+	TotalLogsScanned  int                        `json:"total_logs_scanned"`
+	MalformedLines    int                        `json:"malformed_lines"`
+	TotalFindings     int                        `json:"total_findings"`
+	AllowedFindings   int                        `json:"allowed_findings"`
+	BlockedFindings   int                        `json:"blocked_findings"`
+	BytesSent         int64                      `json:"bytes_sent"`
+	BytesReceived     int64                      `json:"bytes_received"`
+	UniqueUsers       int                        `json:"unique_users"`
+	UniqueServices    int                        `json:"unique_services"`
+	ByUser            map[string]int             `json:"hits_by_user"`
+	ByService         map[string]int             `json:"hits_by_service"`
+	ByProvider        map[string]int             `json:"hits_by_provider"`
+	ProviderEndpoints map[string]map[string]int  `json:"provider_endpoints"`
+	ByCategory        map[string]int             `json:"hits_by_category"`
+	VolumeByService   map[string]analyzer.Volume `json:"bytes_by_service,omitempty"`
+	VolumeByUser      map[string]analyzer.Volume `json:"bytes_by_user,omitempty"`
+	ByActivity        map[string]int             `json:"hits_by_activity"`
+	OffHoursFindings  int                        `json:"off_hours_findings"`
+	OffHoursByUser    map[string]int             `json:"off_hours_by_user"`
+	Heatmap           *jsonHeatmap               `json:"hits_by_weekday_hour,omitempty"`
+	NewAdoptions      []jsonAdoption             `json:"new_adoptions"`
+	ToolingInstalled  []jsonToolingInstall       `json:"tooling_installed,omitempty"`
+	DoHClients        []jsonDoHUse               `json:"doh_clients,omitempty"`
+	VPNUsers          []jsonVPNUse               `json:"vpn_users,omitempty"`
+	Bursts            []jsonBurst                `json:"automation_suspected,omitempty"`
+	Sites             []analyzer.SiteStats       `json:"sites,omitempty"`
+	CloudHosted       map[string]int             `json:"cloud_hosted_by_service,omitempty"`
+	Sources           []analyzer.SourceCoverage  `json:"sources,omitempty"`
+	SessionGap        string                     `json:"session_gap,omitempty"`
+	Sessions          []jsonSession              `json:"sessions,omitempty"`
+	Findings          []jsonFinding              `json:"findings"`
+}
+
+// jsonHeatmap holds hit counts as 7 rows of 24, Sunday first.
+type jsonHeatmap struct {
+	AllServices analyzer.Heatmap             `json:"all_services"`
+	ByService   map[string]*analyzer.Heatmap `json:"by_service"`
+}
+
+type jsonSession struct {
+	User      string `json:"user"`
+	Service   string `json:"service"`
+	Category  string `json:"category"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+	Hits      int    `json:"hits"`
+	Blocked   int    `json:"blocked"`
+	Bytes     int64  `json:"bytes"`
+}
+
+type jsonAdoption struct {
+	User      string `json:"user"`
+	Service   string `json:"service"`
+	FirstSeen string `json:"first_seen"`
+}
+
+type jsonToolingInstall struct {
+	User      string `json:"user"`
+	Service   string `json:"service"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+	Hits      int    `json:"hits"`
+}
+
+type jsonDoHUse struct {
+	User      string `json:"user"`
+	Resolver  string `json:"resolver"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+	Hits      int    `json:"hits"`
+}
+
+type jsonVPNUse struct {
+	User      string `json:"user"`
+	Service   string `json:"service"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+	Hits      int    `json:"hits"`
+}
+
+type jsonBurst struct {
+	User      string  `json:"user"`
+	FirstSeen string  `json:"first_seen"`
+	LastSeen  string  `json:"last_seen"`
+	Hits      int     `json:"hits"`
+	Rate      float64 `json:"rate_per_minute"`
 }
 
 type jsonFinding struct {
-	Timestamp   string `json:"timestamp"`
-	SourceIP    string `json:"source_ip"`
-	ServiceName string `json:"service_name"`
-	Category    string `json:"category"`
-	Domain      string `json:"domain"`
-	URL         string `json:"url,omitempty"`
-	Method      string `json:"method,omitempty"`
-	StatusCode  string `json:"status_code,omitempty"`
-	BytesSent   int64  `json:"bytes_sent,omitempty"`
+	Timestamp     string  `json:"timestamp"`
+	SourceIP      string  `json:"source_ip"`
+	User          string  `json:"user,omitempty"`
+	ServiceName   string  `json:"service_name"`
+	Provider      string  `json:"provider,omitempty"`
+	Category      string  `json:"category"`
+	Domain        string  `json:"domain"`
+	URL           string  `json:"url,omitempty"`
+	Method        string  `json:"method,omitempty"`
+	StatusCode    string  `json:"status_code,omitempty"`
+	Action        string  `json:"action,omitempty"`
+	Blocked       bool    `json:"blocked"`
+	CloudHosted   bool    `json:"cloud_hosted,omitempty"`
+	OffHours      bool    `json:"off_hours"`
+	NewAdoption   bool    `json:"new_adoption"`
+	Automated     bool    `json:"automation_suspected,omitempty"`
+	Rate          float64 `json:"rate_per_minute,omitempty"`
+	BytesSent     int64   `json:"bytes_sent,omitempty"`
+	BytesReceived int64   `json:"bytes_received,omitempty"`
+	Activity      string  `json:"activity,omitempty"`
+	UserAgent     string  `json:"user_agent,omitempty"`
+	JA3           string  `json:"ja3,omitempty"`
+	ProcessName   string  `json:"process_name,omitempty"`
+	DeviceName    string  `json:"device_name,omitempty"`
+	DetectedBy    string  `json:"detected_by,omitempty"`
+	SourceFile    string  `json:"source_file,omitempty"`
+	LineNumber    int     `json:"line_number,omitempty"`
+	Tenant        string  `json:"tenant,omitempty"`
+	Site          string  `json:"site,omitempty"`
 }
 
 func reportJSON(s analyzer.Summary, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newJSONReport(s))
+}
+
+func newJSONReport(s analyzer.Summary) jsonReport {
 	report := jsonReport{
-		TotalLogsScanned: s.TotalLogsScanned,
-		TotalFindings:    s.TotalFindings,
-		UniqueUsers:      s.UniqueUsers,
-		UniqueServices:   s.UniqueServices,
-		ByUser:           s.ByUser,
-		ByService:        s.ByService,
+		Partial:           s.Partial,
+		Tenant:            s.Tenant,
+		Scan:              toJSONScan(s),
+		TotalLogsScanned:  s.TotalLogsScanned,
+		MalformedLines:    s.MalformedLines(),
+		TotalFindings:     s.TotalFindings,
+		AllowedFindings:   s.AllowedFindings,
+		BlockedFindings:   s.BlockedFindings,
+		BytesSent:         s.BytesSent,
+		BytesReceived:     s.BytesReceived,
+		UniqueUsers:       s.UniqueUsers,
+		UniqueServices:    s.UniqueServices,
+		ByUser:            s.ByUser,
+		ByService:         s.ByService,
+		ByProvider:        s.ByProvider,
+		ProviderEndpoints: s.ProviderEndpoints,
+		ByCategory:        s.ByCategory,
+		VolumeByService:   s.VolumeByService,
+		VolumeByUser:      s.VolumeByUser,
+		ByActivity:        activityCounts(s.ByActivity),
+		OffHoursFindings:  s.OffHoursFindings,
+		OffHoursByUser:    s.OffHoursByUser,
+		CloudHosted:       s.CloudHosted,
+		Sites:             s.Sites,
+		Sources:           s.Sources,
 	}
 
-	for _, f := range s.Findings {
-		ts := ""
-		if !f.Timestamp.IsZero() {
-			ts = f.Timestamp.Format("2006-01-02T15:04:05Z")
-		}
-		report.Findings = append(report.Findings, jsonFinding{
-			Timestamp:   ts,
-			SourceIP:    f.SourceIP,
-			ServiceName: f.ServiceName,
-			Category:    f.Category,
-			Domain:      f.Domain,
-			URL:         f.URL,
-			Method:      f.Method,
-			StatusCode:  f.StatusCode,
-			BytesSent:   f.BytesSent,
+	if s.Layout.Heatmap {
+		report.Heatmap = &jsonHeatmap{AllServices: s.Heatmap, ByService: s.HeatmapByService}
+	}
+	for _, a := range s.NewAdoptions {
+		report.NewAdoptions = append(report.NewAdoptions, jsonAdoption{
+			User:      a.User,
+			Service:   a.Service,
+			FirstSeen: formatTime(a.FirstSeen),
 		})
 	}
-
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(report)
+	for _, t := range s.ToolingInstalled {
+		report.ToolingInstalled = append(report.ToolingInstalled, jsonToolingInstall{
+			User:      t.User,
+			Service:   t.Service,
+			FirstSeen: formatTime(t.FirstSeen),
+			LastSeen:  formatTime(t.LastSeen),
+			Hits:      t.Hits,
+		})
+	}
+	for _, d := range s.DoHClients {
+		report.DoHClients = append(report.DoHClients, jsonDoHUse{
+			User:      d.User,
+			Resolver:  d.Resolver,
+			FirstSeen: formatTime(d.FirstSeen),
+			LastSeen:  formatTime(d.LastSeen),
+			Hits:      d.Hits,
+		})
+	}
+	for _, v := range s.VPNUsers {
+		report.VPNUsers = append(report.VPNUsers, jsonVPNUse{
+			User:      v.User,
+			Service:   v.Service,
+			FirstSeen: formatTime(v.FirstSeen),
+			LastSeen:  formatTime(v.LastSeen),
+			Hits:      v.Hits,
+		})
+	}
+	for _, b := range s.Bursts {
+		report.Bursts = append(report.Bursts, jsonBurst{
+			User:      b.User,
+			FirstSeen: formatTime(b.FirstSeen),
+			LastSeen:  formatTime(b.LastSeen),
+			Hits:      b.Hits,
+			Rate:      b.Rate,
+		})
+	}
+	if s.Layout.SessionGap > 0 {
+		report.SessionGap = s.Layout.SessionGap.String()
+		for _, ses := range analyzer.Sessions(s.Findings, s.Layout.SessionGap) {
+			report.Sessions = append(report.Sessions, toJSONSession(ses))
+		}
+	}
+	for _, f := range s.Findings {
+		report.Findings = append(report.Findings, toJSONFinding(f))
+	}
+	return report
 }
 
+// csvHeader lists the columns of CSV output, matching csvRow.
+var csvHeader = []string{"timestamp", "source_ip", "service_name", "category", "domain", "url", "method", "status_code", "bytes_sent", "activity", "action", "blocked", "off_hours", "new_adoption", "provider", "user", "user_agent", "detected_by", "ja3", "cloud_hosted", "source_file", "line_number", "tenant", "process_name", "device_name", "automation_suspected", "rate_per_minute", "bytes_received", "site"}
+
+// csvSessionHeader lists the columns of CSV output grouped into sessions.
+var csvSessionHeader = []string{"first_seen", "last_seen", "user", "service_name", "category", "hits", "blocked", "bytes"}
+
 func reportCSV(s analyzer.Summary, w io.Writer) error {
 	cw := csv.NewWriter(w)
 	defer cw.Flush()
 
-	header := []string{"timestamp", "source_ip", "service_name", "category", "domain", "url", "method", "status_code", "bytes_sent"}
-	if err := cw.Write(header); err != nil {
+	if s.Layout.SessionGap > 0 {
+		if err := cw.Write(csvSessionHeader); err != nil {
+			return err
+		}
+		for _, ses := range analyzer.Sessions(s.Findings, s.Layout.SessionGap) {
+			row := []string{formatTime(ses.FirstSeen), formatTime(ses.LastSeen), ses.User, ses.Service, ses.Category,
+				strconv.Itoa(ses.Hits), strconv.Itoa(ses.Blocked), strconv.FormatInt(ses.Bytes, 10)}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := cw.Write(csvHeader); err != nil {
 		return err
 	}
 
 	for _, f := range s.Findings {
-		ts := ""
-		if !f.Timestamp.IsZero() {
-			ts = f.Timestamp.Format("2006-01-02T15:04:05Z")
-		}
-		row := []string{
-			ts,
-			f.SourceIP,
-			f.ServiceName,
-			f.Category,
-			f.Domain,
-			f.URL,
-			f.Method,
-			f.StatusCode,
-			fmt.Sprintf("%d", f.BytesSent),
-		}
-		if err := cw.Write(row); err != nil {
+		if err := cw.Write(csvRow(f)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// formatTime renders timestamps for JSON/CSV output; zero times are blank.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func toJSONFinding(f analyzer.Finding) jsonFinding {
+	return jsonFinding{
+		Timestamp:     formatTime(f.Timestamp),
+		SourceIP:      f.SourceIP,
+		User:          f.User,
+		ServiceName:   f.ServiceName,
+		Provider:      f.Provider,
+		Category:      f.Category,
+		Domain:        f.Domain,
+		URL:           f.URL,
+		Method:        f.Method,
+		StatusCode:    f.StatusCode,
+		Action:        f.Action,
+		Blocked:       f.Blocked,
+		CloudHosted:   f.CloudHosted,
+		OffHours:      f.OffHours,
+		NewAdoption:   f.NewAdoption,
+		Automated:     f.Automated,
+		Rate:          f.Rate,
+		BytesSent:     f.BytesSent,
+		BytesReceived: f.BytesReceived,
+		Activity:      string(f.Activity),
+		UserAgent:     f.UserAgent,
+		JA3:           f.JA3,
+		ProcessName:   f.ProcessName,
+		DeviceName:    f.DeviceName,
+		DetectedBy:    f.DetectedBy,
+		SourceFile:    f.SourceFile,
+		LineNumber:    f.LineNumber,
+		Tenant:        f.Tenant,
+		Site:          f.Site,
+	}
+}
+
+func toJSONSession(s analyzer.Session) jsonSession {
+	return jsonSession{
+		User:      s.User,
+		Service:   s.Service,
+		Category:  s.Category,
+		FirstSeen: formatTime(s.FirstSeen),
+		LastSeen:  formatTime(s.LastSeen),
+		Hits:      s.Hits,
+		Blocked:   s.Blocked,
+		Bytes:     s.Bytes,
+	}
+}
+
+func csvRow(f analyzer.Finding) []string {
+	return []string{
+		formatTime(f.Timestamp),
+		f.SourceIP,
+		f.ServiceName,
+		f.Category,
+		f.Domain,
+		f.URL,
+		f.Method,
+		f.StatusCode,
+		fmt.Sprintf("%d", f.BytesSent),
+		string(f.Activity),
+		f.Action,
+		strconv.FormatBool(f.Blocked),
+		strconv.FormatBool(f.OffHours),
+		strconv.FormatBool(f.NewAdoption),
+		f.Provider,
+		f.User,
+		f.UserAgent,
+		f.DetectedBy,
+		f.JA3,
+		strconv.FormatBool(f.CloudHosted),
+		f.SourceFile,
+		strconv.Itoa(f.LineNumber),
+		f.Tenant,
+		f.ProcessName,
+		f.DeviceName,
+		strconv.FormatBool(f.Automated),
+		rate(f.Rate),
+		strconv.FormatInt(f.BytesReceived, 10),
+		f.Site,
+	}
+}
+
+// rate formats a requests-per-minute figure, empty when there is none.
+func rate(perMinute float64) string {
+	if perMinute == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(perMinute, 'f', 1, 64)
+}
+
+func activityCounts(m map[analyzer.Activity]int) map[string]int {
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[string(k)] = v
+	}
+	return out
+}
+
 type kv struct {
-	key string
-	val int
+	Key string
+	Val int
 }
 
 func sortedMap(m map[string]int) []kv {
@@ -199,7 +897,10 @@ func sortedMap(m map[string]int) []kv {
 		sorted = append(sorted, kv{k, v})
 	}
 	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].val > sorted[j].val
+		if sorted[i].Val != sorted[j].Val {
+			return sorted[i].Val > sorted[j].Val
+		}
+		return sorted[i].Key < sorted[j].Key
 	})
 	return sorted
 }
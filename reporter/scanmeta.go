@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// jsonScan is the scan metadata block of a JSON report.
+type jsonScan struct {
+	Version         string         `json:"version"`
+	ServicesDB      string         `json:"services_db_sha256,omitempty"`
+	DBVersion       string         `json:"services_db_version,omitempty"`
+	DBUpdated       string         `json:"services_db_updated,omitempty"`
+	Host            string         `json:"host,omitempty"`
+	StartedAt       string         `json:"started_at"`
+	FinishedAt      string         `json:"finished_at"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	LogsFrom        string         `json:"logs_from,omitempty"`
+	LogsTo          string         `json:"logs_to,omitempty"`
+	Files           []jsonScanFile `json:"files"`
+}
+
+type jsonScanFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+func toJSONScan(s analyzer.Summary) *jsonScan {
+	m := s.Scan
+	if m == nil {
+		return nil
+	}
+	first, last := s.LogRange()
+	out := &jsonScan{
+		Version:         m.Version,
+		ServicesDB:      m.ServicesDB,
+		DBVersion:       m.DBInfo.Version,
+		DBUpdated:       m.DBInfo.LastUpdated,
+		Host:            m.Host,
+		StartedAt:       formatTime(m.StartedAt),
+		FinishedAt:      formatTime(m.FinishedAt),
+		DurationSeconds: m.Duration().Seconds(),
+		LogsFrom:        formatTime(first),
+		LogsTo:          formatTime(last),
+		Files:           []jsonScanFile{},
+	}
+	for _, f := range m.Files {
+		out.Files = append(out.Files, jsonScanFile(f))
+	}
+	return out
+}
+
+func fromJSONScan(j *jsonScan) *analyzer.ScanMeta {
+	if j == nil {
+		return nil
+	}
+	m := &analyzer.ScanMeta{Version: j.Version, ServicesDB: j.ServicesDB, Host: j.Host}
+	m.DBInfo = analyzer.DBInfo{Version: j.DBVersion, LastUpdated: j.DBUpdated}
+	m.StartedAt, _ = time.Parse(time.RFC3339, j.StartedAt)
+	m.FinishedAt, _ = time.Parse(time.RFC3339, j.FinishedAt)
+	for _, f := range j.Files {
+		m.Files = append(m.Files, analyzer.ScannedFile(f))
+	}
+	return m
+}
+
+// scanRows lists the scan metadata as label/value pairs, for the reports
+// that show it as a block. The files appear as a count; each report lists
+// them itself, if at all.
+func scanRows(s analyzer.Summary) [][2]string {
+	m := s.Scan
+	if m == nil {
+		return nil
+	}
+	rows := [][2]string{{"Tool version", m.Version}}
+	if v := m.DBInfo.String(); v != "" {
+		rows = append(rows, [2]string{"Services DB", v})
+	}
+	if m.ServicesDB != "" {
+		rows = append(rows, [2]string{"DB SHA-256", m.ServicesDB})
+	}
+	if m.Host != "" {
+		rows = append(rows, [2]string{"Host", m.Host})
+	}
+	rows = append(rows,
+		[2]string{"Started", m.StartedAt.Format(time.RFC3339)},
+		[2]string{"Duration", m.Duration().Round(time.Millisecond).String()},
+	)
+	if first, last := s.LogRange(); !first.IsZero() {
+		rows = append(rows, [2]string{"Logs cover", first.Format(time.RFC3339) + " to " + last.Format(time.RFC3339)})
+	}
+	var total int64
+	for _, f := range m.Files {
+		total += f.Size
+	}
+	rows = append(rows, [2]string{"Files scanned", fmt.Sprintf("%d (%s)", len(m.Files), byteSize(total))})
+	return rows
+}
+
+// writeScanMeta shows what the scan read, with which tool and database.
+func writeScanMeta(w io.Writer, width int, s analyzer.Summary) {
+	fmt.Fprintln(w, "\n  SCAN")
+	fmt.Fprintln(w, rule("-", 60, width))
+	for _, r := range scanRows(s) {
+		fmt.Fprintf(w, "  %-16s %s\n", r[0]+":", r[1])
+	}
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	for i, f := range s.Scan.Files {
+		if more(tw, i, len(s.Scan.Files), s.Layout.Top) {
+			break
+		}
+		fmt.Fprintf(tw, "    %s\t%s\t%s\n", f.Path, byteSize(f.Size), f.SHA256)
+	}
+	tw.Flush()
+}
+
+// byteSize formats n bytes with a binary unit.
+func byteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// ReportServices outputs a list of AI services in the requested format.
+func ReportServices(services []analyzer.AIService, format Format, w io.Writer) error {
+	switch format {
+	case FormatTable:
+		return servicesTable(services, w)
+	case FormatJSON:
+		if services == nil {
+			services = []analyzer.AIService{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Services []analyzer.AIService `json:"services"`
+		}{services})
+	default:
+		return fmt.Errorf("unsupported format for service list: %s", format)
+	}
+}
+
+func servicesTable(services []analyzer.AIService, w io.Writer) error {
+	domains := 0
+	for _, svc := range services {
+		domains += len(svc.Domains)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  %d AI services, %d domains\n", len(services), domains)
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	if len(services) == 0 {
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  SERVICE\tPROVIDER\tCATEGORY\tDOMAINS\n")
+	for _, svc := range services {
+		// One domain per line keeps long lists readable at any width
+		for i, d := range svc.Domains {
+			if i == 0 {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", svc.Name, svc.ProviderName(), svc.Category, d)
+			} else {
+				fmt.Fprintf(tw, "  \t\t\t%s\n", d)
+			}
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	return nil
+}
@@ -0,0 +1,229 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Sigma log sources rules can be generated for.
+const (
+	SigmaProxy = "proxy"
+	SigmaDNS   = "dns"
+)
+
+// SigmaRule is one generated Sigma detection rule.
+type SigmaRule struct {
+	Name string // file name stem, e.g. "shadow_ai_openai_proxy"
+	YAML string
+}
+
+// SigmaRules turns the services database into Sigma rules for each of
+// sources. Every service gets a rule per source matching its domains and
+// subdomains; proxy rules also match its user agents, and services with
+// known upload endpoints get a separate high-level proxy rule for uploads.
+// Rule IDs are derived from the service, so regenerated rules keep theirs.
+func SigmaRules(services []analyzer.AIService, sources []string, date time.Time) []SigmaRule {
+	var rules []SigmaRule
+	for _, svc := range services {
+		hosts := sigmaHosts(svc)
+		for _, source := range sources {
+			switch source {
+			case SigmaProxy:
+				if hosts.empty() && len(svc.UserAgents) == 0 {
+					continue
+				}
+				rules = append(rules, sigmaUsageRule(svc, SigmaProxy, "cs-host", hosts, date))
+				if r, ok := sigmaUploadRule(svc, hosts, date); ok {
+					rules = append(rules, r)
+				}
+			case SigmaDNS:
+				if hosts.empty() {
+					continue
+				}
+				rules = append(rules, sigmaUsageRule(svc, SigmaDNS, "query", hosts, date))
+			}
+		}
+	}
+	return rules
+}
+
+// sigmaHostSet is a service's hosts as Sigma values: exact names (host
+// patterns keep their "*" wildcard) and suffixes for subdomains.
+type sigmaHostSet struct {
+	exact    []string
+	suffixes []string
+}
+
+// Domains already covered by a parent domain's suffix are left out.
+func sigmaHosts(svc analyzer.AIService) sigmaHostSet {
+	var h sigmaHostSet
+	for _, d := range svc.Domains {
+		if slices.ContainsFunc(svc.Domains, func(p string) bool { return strings.HasSuffix(d, "."+p) }) {
+			continue
+		}
+		h.exact = append(h.exact, d)
+		h.suffixes = append(h.suffixes, "."+d)
+	}
+	h.exact = append(h.exact, svc.HostPatterns...)
+	return h
+}
+
+func (h sigmaHostSet) empty() bool { return len(h.exact) == 0 }
+
+func sigmaUsageRule(svc analyzer.AIService, source, field string, hosts sigmaHostSet, date time.Time) SigmaRule {
+	var b sigmaBuilder
+	title := fmt.Sprintf("Shadow AI Use - %s", svc.Name)
+	desc := fmt.Sprintf("Detects %s %s (%s, provided by %s), an AI service that may not be sanctioned.", sigmaVerb(source), svc.Name, svc.Category, svc.ProviderName())
+	b.header(title+" ("+sigmaSourceName(source)+")", "usage/"+source+"/"+svc.Name, desc, date, source)
+
+	b.line("detection:")
+	selections := b.hosts(field, hosts)
+	if source == SigmaProxy {
+		var contains, regexes []string
+		for _, ua := range svc.UserAgents {
+			if ua.Regex != "" {
+				regexes = append(regexes, ua.Regex)
+			} else {
+				contains = append(contains, ua.Contains)
+			}
+		}
+		if len(contains) > 0 {
+			b.selection("selection_useragent", "c-useragent|contains", contains)
+			selections = append(selections, "selection_useragent")
+		}
+		if len(regexes) > 0 {
+			b.selection("selection_useragent_re", "c-useragent|re", regexes)
+			selections = append(selections, "selection_useragent_re")
+		}
+	}
+	b.condition(selections)
+	b.footer(svc.Name, "medium")
+	return SigmaRule{Name: "shadow_ai_" + sigmaSlug(svc.Name) + "_" + source, YAML: b.String()}
+}
+
+// sigmaUploadRule matches POSTs to the service's upload endpoints.
+func sigmaUploadRule(svc analyzer.AIService, hosts sigmaHostSet, date time.Time) (SigmaRule, bool) {
+	var paths []string
+	for _, e := range svc.Endpoints {
+		if e.Activity == analyzer.ActivityUpload {
+			paths = append(paths, e.Path)
+		}
+	}
+	if len(paths) == 0 || hosts.empty() {
+		return SigmaRule{}, false
+	}
+
+	var b sigmaBuilder
+	desc := fmt.Sprintf("Detects uploads to %s (%s), which may carry company data out of the organization.", svc.Name, svc.Category)
+	b.header("Shadow AI Upload - "+svc.Name+" (Proxy)", "upload/proxy/"+svc.Name, desc, date, SigmaProxy)
+	b.line("detection:")
+	host := strings.Join(b.hosts("cs-host", hosts), " or ")
+	b.line("    selection_upload:")
+	b.line("        cs-method: 'POST'")
+	b.list("c-uri|contains", paths)
+	b.line("    condition: (" + host + ") and selection_upload")
+	b.footer(svc.Name, "high")
+	return SigmaRule{Name: "shadow_ai_" + sigmaSlug(svc.Name) + "_upload", YAML: b.String()}, true
+}
+
+func sigmaSourceName(source string) string {
+	if source == SigmaDNS {
+		return "DNS"
+	}
+	return "Proxy"
+}
+
+func sigmaVerb(source string) string {
+	if source == SigmaDNS {
+		return "DNS lookups of"
+	}
+	return "web requests to"
+}
+
+// sigmaSlug makes a file-name-safe stem: "Azure OpenAI" -> "azure_openai".
+func sigmaSlug(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// sigmaBuilder writes rule YAML by hand, quoting every value, so the
+// output does not depend on a YAML library.
+type sigmaBuilder struct{ strings.Builder }
+
+func (b *sigmaBuilder) line(s string) {
+	b.WriteString(s)
+	b.WriteByte('\n')
+}
+
+func (b *sigmaBuilder) header(title, idName, desc string, date time.Time, source string) {
+	b.line("title: " + sigmaQuote(title))
+	b.line("id: " + intelUUID(intelNamespace, "sigma/"+idName))
+	b.line("status: experimental")
+	b.line("description: " + sigmaQuote(desc))
+	b.line("author: shadow-ai-hunter")
+	b.line("date: " + date.Format("2006-01-02"))
+	b.line("tags:")
+	b.line("    - attack.exfiltration")
+	b.line("    - attack.t1567")
+	b.line("logsource:")
+	b.line("    category: " + source)
+}
+
+// hosts writes the host selections and returns their names.
+func (b *sigmaBuilder) hosts(field string, hosts sigmaHostSet) []string {
+	var names []string
+	if len(hosts.exact) > 0 {
+		b.selection("selection_host", field, hosts.exact)
+		names = append(names, "selection_host")
+	}
+	if len(hosts.suffixes) > 0 {
+		b.selection("selection_subdomain", field+"|endswith", hosts.suffixes)
+		names = append(names, "selection_subdomain")
+	}
+	return names
+}
+
+func (b *sigmaBuilder) selection(name, field string, values []string) {
+	b.line("    " + name + ":")
+	b.list(field, values)
+}
+
+func (b *sigmaBuilder) list(field string, values []string) {
+	b.line("        " + field + ":")
+	for _, v := range values {
+		b.line("            - " + sigmaQuote(v))
+	}
+}
+
+func (b *sigmaBuilder) condition(selections []string) {
+	if len(selections) == 1 {
+		b.line("    condition: " + selections[0])
+		return
+	}
+	b.line("    condition: 1 of selection_*")
+}
+
+func (b *sigmaBuilder) footer(service, level string) {
+	b.line("falsepositives:")
+	b.line("    - " + sigmaQuote("Sanctioned use of "+service+"; exclude approved users or hosts"))
+	b.line("level: " + level)
+}
+
+// sigmaQuote single-quotes a YAML scalar.
+func sigmaQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
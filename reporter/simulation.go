@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shadow-ai-hunter/policy"
+)
+
+// ReportSimulation outputs a policy simulation in the requested format.
+func ReportSimulation(sim policy.Simulation, format Format, w io.Writer) error {
+	switch format {
+	case FormatTable:
+		return simulationTable(sim, w)
+	case FormatJSON:
+		return simulationJSON(sim, w)
+	default:
+		return fmt.Errorf("unsupported format for simulation: %s", format)
+	}
+}
+
+func simulationTable(sim policy.Simulation, w io.Writer) error {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  SHADOW AI HUNTER - Policy Simulation")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "  Stored findings:     %d\n", sim.TotalFindings)
+	fmt.Fprintf(w, "  Current policy:      %s (%d violations)\n", sim.CurrentPolicy, sim.Current)
+	fmt.Fprintf(w, "  Proposed policy:     %s (%d violations)\n", sim.ProposedPolicy, sim.Proposed)
+	fmt.Fprintf(w, "  Change:              %+d\n", sim.Proposed-sim.Current)
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	writeDeltas(w, "VIOLATIONS BY SERVICE", sim.ByService)
+	writeDeltas(w, "VIOLATIONS BY CATEGORY", sim.ByCategory)
+	fmt.Fprintln(w)
+	return nil
+}
+
+func writeDeltas(w io.Writer, title string, deltas map[string]policy.Delta) {
+	fmt.Fprintf(w, "\n  %s\n", title)
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  NAME\tCURRENT\tPROPOSED\tCHANGE\n")
+	for _, name := range sortedDeltaKeys(deltas) {
+		d := deltas[name]
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%+d\n", name, d.Current, d.Proposed, d.Change())
+	}
+	tw.Flush()
+}
+
+// sortedDeltaKeys orders names by the size of the change, largest first.
+func sortedDeltaKeys(deltas map[string]policy.Delta) []string {
+	keys := make([]string, 0, len(deltas))
+	for k := range deltas {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := abs(deltas[keys[i]].Change()), abs(deltas[keys[j]].Change())
+		if ci != cj {
+			return ci > cj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+type jsonDelta struct {
+	Current  int `json:"current"`
+	Proposed int `json:"proposed"`
+	Change   int `json:"change"`
+}
+
+type jsonSimulation struct {
+	CurrentPolicy  string               `json:"current_policy"`
+	ProposedPolicy string               `json:"proposed_policy"`
+	TotalFindings  int                  `json:"total_findings"`
+	Current        int                  `json:"current_violations"`
+	Proposed       int                  `json:"proposed_violations"`
+	Change         int                  `json:"change"`
+	ByService      map[string]jsonDelta `json:"by_service"`
+	ByCategory     map[string]jsonDelta `json:"by_category"`
+}
+
+func simulationJSON(sim policy.Simulation, w io.Writer) error {
+	report := jsonSimulation{
+		CurrentPolicy:  sim.CurrentPolicy,
+		ProposedPolicy: sim.ProposedPolicy,
+		TotalFindings:  sim.TotalFindings,
+		Current:        sim.Current,
+		Proposed:       sim.Proposed,
+		Change:         sim.Proposed - sim.Current,
+		ByService:      toJSONDeltas(sim.ByService),
+		ByCategory:     toJSONDeltas(sim.ByCategory),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func toJSONDeltas(deltas map[string]policy.Delta) map[string]jsonDelta {
+	out := make(map[string]jsonDelta, len(deltas))
+	for k, d := range deltas {
+		out[k] = jsonDelta{Current: d.Current, Proposed: d.Proposed, Change: d.Change()}
+	}
+	return out
+}
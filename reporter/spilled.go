@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// spilledPreview is how many findings of each kind tables and HTML show
+// from a spilled scan when -max-findings does not say.
+const spilledPreview = 1000
+
+// FindingSource calls fn with each finding in order, stopping at the first
+// error. It stands in for Summary.Findings when a scan has kept its
+// findings on disk.
+type FindingSource func(fn func(analyzer.Finding) error) error
+
+// ReportSpilled outputs a summary whose findings are read from src rather
+// than held in summary.Findings. JSON and CSV list every finding as it is
+// read; tables and HTML show the first few allowed and blocked findings
+// under totals for all of them. Sessions are not supported, since grouping
+// needs every finding at once.
+func ReportSpilled(summary analyzer.Summary, src FindingSource, format Format, w io.Writer) error {
+	switch format {
+	case FormatJSON:
+		return reportJSONSpilled(summary, src, w)
+	case FormatCSV:
+		return reportCSVSpilled(src, w)
+	case FormatTable, FormatHTML:
+		limit := summary.Layout.MaxFindings
+		if limit <= 0 {
+			limit = spilledPreview
+		}
+		preview, err := previewFindings(src, limit)
+		if err != nil {
+			return err
+		}
+		summary.Findings = preview
+		return Report(summary, format, w)
+	}
+	return Report(summary, format, w)
+}
+
+// WriteSpilledToFile writes a spilled report to a file instead of stdout.
+func WriteSpilledToFile(summary analyzer.Summary, src FindingSource, format Format, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer f.Close()
+	return ReportSpilled(summary, src, format, f)
+}
+
+// previewFindings keeps the first limit allowed and first limit blocked
+// findings.
+func previewFindings(src FindingSource, limit int) ([]analyzer.Finding, error) {
+	var out []analyzer.Finding
+	allowed, blocked := 0, 0
+	err := src(func(f analyzer.Finding) error {
+		n := &allowed
+		if f.Blocked {
+			n = &blocked
+		}
+		if *n < limit {
+			*n++
+			out = append(out, f)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// reportJSONSpilled writes the same document as reportJSON, streaming the
+// findings array.
+func reportJSONSpilled(s analyzer.Summary, src FindingSource, w io.Writer) error {
+	report := newJSONReport(s)
+	report.Findings = []jsonFinding{}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	head, ok := bytes.CutSuffix(data, []byte("[]\n}"))
+	if !ok {
+		return fmt.Errorf("findings are not the last field of the JSON report")
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(head)
+	n := 0
+	err = src(func(f analyzer.Finding) error {
+		row, err := json.MarshalIndent(toJSONFinding(f), "    ", "  ")
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			bw.WriteString("[\n    ")
+		} else {
+			bw.WriteString(",\n    ")
+		}
+		n++
+		_, err = bw.Write(row)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		bw.WriteString("null\n}\n")
+	} else {
+		bw.WriteString("\n  ]\n}\n")
+	}
+	return bw.Flush()
+}
+
+func reportCSVSpilled(src FindingSource, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	err := src(func(f analyzer.Finding) error {
+		return cw.Write(csvRow(f))
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
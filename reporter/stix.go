@@ -0,0 +1,153 @@
+package reporter
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// tlpMarkings are the TLP marking definitions fixed by the STIX 2.1
+// specification; "clear" uses the TLP 1.0 WHITE definition.
+var tlpMarkings = map[string]string{
+	"clear": "marking-definition--613f2e26-407d-48c7-9eca-b8e91df99dc9",
+	"green": "marking-definition--34098fce-860f-48ae-8e50-ebd3cc5e41da",
+	"amber": "marking-definition--f88d31f6-486f-44da-b317-01333bde0b82",
+	"red":   "marking-definition--5e57c739-391a-4eb3-b6be-7d15ca92d5ed",
+}
+
+// stixTime formats timestamps the way STIX requires: UTC, millisecond
+// precision.
+func stixTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// WriteSTIX writes in as a STIX 2.1 bundle. Each service becomes an
+// indicator whose pattern matches its domains, and each service that was
+// seen gets a sighting carrying the hit count and first and last times.
+func WriteSTIX(in Intel, w io.Writer) error {
+	marking, ok := tlpMarkings[in.TLP]
+	if !ok {
+		return fmt.Errorf("unknown TLP level %q", in.TLP)
+	}
+	now := stixTime(in.GeneratedAt)
+	identity := "identity--" + intelUUID(intelNamespace, "identity")
+	markings := []string{marking}
+
+	label := strings.ToUpper(in.TLP)
+	if in.TLP == "clear" {
+		label = "WHITE"
+	}
+	objects := []map[string]any{
+		{
+			"type":            "marking-definition",
+			"spec_version":    "2.1",
+			"id":              marking,
+			"created":         "2017-01-20T00:00:00.000Z",
+			"definition_type": "tlp",
+			"name":            "TLP:" + label,
+			"definition":      map[string]string{"tlp": strings.ToLower(label)},
+		},
+		{
+			"type":                "identity",
+			"spec_version":        "2.1",
+			"id":                  identity,
+			"created":             now,
+			"modified":            now,
+			"name":                "shadow-ai-hunter",
+			"description":         "Shadow AI detection from network and proxy logs, version " + in.Version,
+			"identity_class":      "system",
+			"object_marking_refs": markings,
+		},
+	}
+
+	for _, s := range in.Services {
+		pattern := stixPattern(s)
+		if pattern == "" {
+			continue // matched only by user agent or JA3, which STIX domain patterns cannot express
+		}
+		indicator := "indicator--" + intelUUID(intelNamespace, "indicator/"+s.Name)
+		validFrom := now
+		if s.Seen() && !s.FirstSeen.IsZero() {
+			validFrom = stixTime(s.FirstSeen)
+		}
+		objects = append(objects, map[string]any{
+			"type":                "indicator",
+			"spec_version":        "2.1",
+			"id":                  indicator,
+			"created":             now,
+			"modified":            now,
+			"created_by_ref":      identity,
+			"name":                "Shadow AI: " + s.Name,
+			"description":         fmt.Sprintf("Traffic to %s (%s, provided by %s). Use of unsanctioned AI services risks data leaving the organization.", s.Name, s.Category, s.Provider),
+			"indicator_types":     []string{"anomalous-activity"},
+			"pattern":             pattern,
+			"pattern_type":        "stix",
+			"valid_from":          validFrom,
+			"labels":              []string{"shadow-ai", strings.ToLower(s.Category)},
+			"object_marking_refs": markings,
+		})
+
+		if !s.Seen() {
+			continue
+		}
+		sighting := map[string]any{
+			"type":                "sighting",
+			"spec_version":        "2.1",
+			"id":                  "sighting--" + randomUUID(),
+			"created":             now,
+			"modified":            now,
+			"created_by_ref":      identity,
+			"sighting_of_ref":     indicator,
+			"where_sighted_refs":  []string{identity},
+			"count":               s.Hits,
+			"description":         fmt.Sprintf("%d request(s) from %d user(s), %d blocked", s.Hits, s.Users, s.Blocked),
+			"object_marking_refs": markings,
+		}
+		if !s.FirstSeen.IsZero() {
+			sighting["first_seen"] = stixTime(s.FirstSeen)
+			sighting["last_seen"] = stixTime(s.LastSeen)
+		}
+		objects = append(objects, sighting)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{
+		"type":    "bundle",
+		"id":      "bundle--" + randomUUID(),
+		"objects": objects,
+	})
+}
+
+// stixPattern matches a service's domains, their subdomains, and its host
+// patterns.
+func stixPattern(s IntelService) string {
+	var terms []string
+	for _, d := range s.Domains {
+		d = stixQuote(d)
+		terms = append(terms, fmt.Sprintf("domain-name:value = '%s'", d), fmt.Sprintf("domain-name:value LIKE '%%.%s'", d))
+	}
+	for _, p := range s.HostPatterns {
+		terms = append(terms, fmt.Sprintf("domain-name:value LIKE '%s'", strings.ReplaceAll(stixQuote(p), "*", "%")))
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(terms, " OR ") + "]"
+}
+
+func stixQuote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// randomUUID returns a version 4 UUID, for objects that are new each export.
+func randomUUID() string {
+	u := make([]byte, 16)
+	rand.Read(u)
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
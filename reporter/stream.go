@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Stream writes findings one at a time as they are detected, for follow mode.
+// JSON output is written as JSON Lines.
+type Stream struct {
+	format Format
+	w      io.Writer
+	cw     *csv.Writer
+	enc    *json.Encoder
+}
+
+// NewStream prepares a stream in the given format, writing any header.
+func NewStream(format Format, w io.Writer) (*Stream, error) {
+	s := &Stream{format: format, w: w}
+	switch format {
+	case FormatTable:
+	case FormatJSON:
+		s.enc = json.NewEncoder(w)
+	case FormatCSV:
+		s.cw = csv.NewWriter(w)
+		if err := s.cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		s.cw.Flush()
+	default:
+		return nil, fmt.Errorf("unsupported format for streaming: %s", format)
+	}
+	return s, nil
+}
+
+// Write outputs a single finding.
+func (s *Stream) Write(f analyzer.Finding) error {
+	switch s.format {
+	case FormatJSON:
+		return s.enc.Encode(toJSONFinding(f))
+	case FormatCSV:
+		if err := s.cw.Write(csvRow(f)); err != nil {
+			return err
+		}
+		s.cw.Flush()
+		return s.cw.Error()
+	default:
+		ts := f.Timestamp.Format("2006-01-02 15:04:05")
+		if f.Timestamp.IsZero() {
+			ts = "N/A"
+		}
+		status := ""
+		if f.Blocked {
+			status += " [blocked]"
+		}
+		if f.NewAdoption {
+			status += " [new adoption]"
+		}
+		if f.Automated {
+			status += fmt.Sprintf(" [automation suspected, %.1f/min]", f.Rate)
+		}
+		_, err := fmt.Fprintf(s.w, "  %s  %-15s  %-20s  %s%s\n", ts, f.Identity(), f.ServiceName, f.Domain, status)
+		return err
+	}
+}
+
+// MarshalFinding encodes one finding as the JSON object used in reports,
+// for sinks that publish findings one message at a time.
+func MarshalFinding(f analyzer.Finding) ([]byte, error) {
+	return json.Marshal(toJSONFinding(f))
+}
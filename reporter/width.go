@@ -0,0 +1,53 @@
+package reporter
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultWidth is used when the output is not a console (files, pipes).
+const defaultWidth = 120
+
+// outputWidth returns the usable column count for table output. COLUMNS
+// overrides detection; otherwise the console size is queried when writing to
+// a terminal.
+func outputWidth(w io.Writer) int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if f, ok := w.(*os.File); ok {
+		if n := consoleWidth(f); n > 0 {
+			return n
+		}
+	}
+	return defaultWidth
+}
+
+// rule returns a separator line of n characters, shortened to fit the width.
+func rule(ch string, n, width int) string {
+	if width > 2 && n > width-2 {
+		n = width - 2
+	}
+	return strings.Repeat(ch, n)
+}
+
+// fit truncates s to at most n characters, marking the cut with "...".
+// Characters are runes, as tabwriter counts them when aligning columns, so
+// a multi-byte name is neither cut mid-rune nor shortened more than needed.
+func fit(s string, n int) string {
+	if n <= 3 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	cut, runes := 0, 0
+	for i := range s {
+		if runes == n-3 {
+			cut = i
+			break
+		}
+		runes++
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || windows)
+
+package reporter
+
+import "os"
+
+// consoleWidth is not supported on this platform.
+func consoleWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+// peakRSS is only measured on Unix; elsewhere it is unknown.
+func peakRSS() int64 {
+	return 0
+}
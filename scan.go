@@ -0,0 +1,649 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/reporter"
+	"github.com/shadow-ai-hunter/spool"
+)
+
+// checkpointInterval is how often an in-progress scan is saved for -resume.
+const checkpointInterval = 30 * time.Second
+
+// checkpointLines is how many lines are parsed between checkpoint opportunities.
+const checkpointLines = 10000
+
+// maxSamples and maxSampleLen bound the rejected lines kept for the error report.
+const (
+	maxSamples   = 5
+	maxSampleLen = 512
+)
+
+// fileScanner parses and matches one file at a time.
+type fileScanner struct {
+	ctx         context.Context // cancelling it stops the scan with partial results
+	az          *analyzer.Analyzer
+	format      string // the -format flag
+	custom      []customParser
+	warnRatio   float64 // warn when a larger share of lines is malformed
+	years       yearRule
+	workers     int             // goroutines parsing a large file; 1 or less reads it sequentially
+	checkpoint  *scanCheckpoint // nil unless -resume is set
+	incremental *incremental    // nil unless only data added since the last scan is read
+	spool       *spool.Spool    // under -spill, findings go here instead of into results
+	spillErr    error           // the spool could not be written; the scan stops
+	progress    *progressBar
+}
+
+// yearRule dates timestamps from logs that record no year, such as dnsmasq.
+// Parsers infer the year from the clock; a fixed year or the file's
+// modification time can be used instead.
+type yearRule struct {
+	year     int  // -year; 0 keeps the inferred year
+	useMtime bool // -year-from-mtime
+}
+
+// apply re-dates e when its timestamp lacked a year.
+func (r yearRule) apply(e *parsers.LogEntry, modTime time.Time) {
+	if !e.NoYear || e.Timestamp.IsZero() {
+		return
+	}
+	t := e.Timestamp
+	switch {
+	case r.year > 0:
+		e.Timestamp = time.Date(r.year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	case r.useMtime && !modTime.IsZero():
+		e.Timestamp = parsers.InferYear(t, modTime)
+	}
+}
+
+// fileResult is what a scan learned from one file. It is also the unit of
+// the -resume checkpoint.
+type fileResult struct {
+	Size     int64                   `json:"size"`
+	ModTime  time.Time               `json:"mod_time"`
+	Offset   int64                   `json:"offset"`      // bytes consumed by an interrupted line scan
+	LineNo   int                     `json:"line_number"` // lines consumed up to Offset
+	Done     bool                    `json:"done"`
+	Format   string                  `json:"format"`
+	Encoding fsutil.Encoding         `json:"encoding"`
+	Coverage analyzer.SourceCoverage `json:"coverage"`
+	Findings []analyzer.Finding      `json:"findings"`
+	Spilled  int                     `json:"-"` // AI findings moved to the spool
+
+	// Records read and rejected; only counted for line formats.
+	Lines     int      `json:"lines"`
+	Malformed int      `json:"malformed"`
+	Samples   []string `json:"samples,omitempty"`
+	Seconds   float64  `json:"seconds"`          // time spent on the file, across resumed runs
+	SHA256    string   `json:"sha256,omitempty"` // of the first Size bytes, the file as the scan found it
+
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+// scan processes one file with p, resuming from the checkpoint when possible.
+func (s *fileScanner) scan(path string, p parsers.Parser) *fileResult {
+	res := &fileResult{Format: p.Name()}
+	if info, err := fsutil.Stat(path); err == nil {
+		res.Size, res.ModTime = info.Size(), info.ModTime().UTC()
+	}
+	if s.checkpoint != nil {
+		if prev := s.checkpoint.resume(path, res); prev != nil {
+			if prev.Done {
+				logger.Info("Skipping file completed before interruption", "file", path)
+				s.progress.fileDone(prev.Size, 0)
+				return prev
+			}
+			res = prev
+		}
+		s.checkpoint.Files[path] = res
+	}
+
+	logger.Info("Parsing", "file", path, "format", p.Name())
+	start := time.Now()
+	res.Encoding, _ = fsutil.DetectFileEncoding(path)
+	if res.Encoding != "" && res.Encoding != fsutil.UTF8 {
+		logger.Debug(fmt.Sprintf("transcoding %s input to UTF-8", res.Encoding), "file", path)
+	}
+
+	// Line formats are scanned incrementally, which counts rejected lines and,
+	// under -resume, loses at most one checkpoint interval to a crash. UTF-16
+	// offsets don't map to lines, so those files are parsed whole.
+	var err error
+	wholeLines := 0
+	_, framed := p.(*parsers.MultilineParser)
+	lp, isLine := p.(parsers.LineParser)
+	lineScan := isLine && !framed && res.Encoding != fsutil.UTF16LE && res.Encoding != fsutil.UTF16BE
+	if s.incremental != nil && res.Offset == 0 && !s.incremental.start(path, res, lineScan) {
+		logger.Debug("nothing new since the last scan", "file", path)
+		res.Done = true
+		res.Coverage.Source, res.Coverage.Format = path, res.Format
+		s.progress.fileDone(res.Size, 0)
+		return res
+	}
+	// The file is hashed alongside the scan rather than after it, over the
+	// bytes it held when the scan started, so a log still being written
+	// hashes to what was read.
+	sum := make(chan string, 1)
+	go func() {
+		digest, err := hashPrefix(s.ctx, path, res.Size)
+		if err != nil && s.ctx.Err() == nil {
+			logger.Warn("Error hashing file", "file", path, "err", err)
+		}
+		sum <- digest
+	}()
+	from := res.Offset
+	if lineScan {
+		if res.Offset > 0 {
+			logger.Debug("resuming from offset", "file", path, "offset", res.Offset)
+		}
+		if s.workers > 1 && res.Size-res.Offset >= parallelMinSize {
+			err = s.scanLinesParallel(path, lp, res)
+		} else {
+			err = s.scanLines(path, lp, res)
+		}
+	} else {
+		err = s.scanWhole(path, p, res)
+		wholeLines = res.Lines
+	}
+	res.SHA256 = <-sum
+	interrupted := err != nil && s.ctx.Err() != nil
+	if interrupted {
+		err = nil
+	}
+	if err != nil {
+		res.Error = err.Error()
+		res.ErrorClass = reporter.ErrorParse
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			res.ErrorClass = reporter.ErrorUnreadable
+		}
+	}
+
+	switch {
+	case res.Error != "":
+		logger.Warn("Error parsing file", "file", path, "class", res.ErrorClass, "err", res.Error)
+	case interrupted:
+		logger.Warn("Scan stopped partway through file", "file", path, "lines", res.Lines)
+	default:
+		if res.Coverage.Entries == 0 && from == 0 && strings.EqualFold(s.format, "auto") {
+			s.fallback(path, p, res)
+		}
+		logger.Debug("parsed", "file", path, "entries", res.Coverage.Entries, "malformed", res.Malformed, "findings", analyzer.CountAI(res.Findings)+res.Spilled)
+	}
+	s.spill(res)
+
+	res.Seconds += time.Since(start).Seconds()
+	res.Coverage.Source = path
+	res.Coverage.Format = res.Format
+	res.Coverage.Findings = analyzer.CountAI(res.Findings) + res.Spilled
+	res.Coverage.Malformed = res.Malformed
+	if ratio := res.Coverage.MalformedRatio(); s.warnRatio > 0 && ratio > s.warnRatio {
+		logger.Warn(fmt.Sprintf("%.0f%% of lines could not be parsed as %s; check -format", ratio*100, res.Format),
+			"file", path, "malformed", res.Malformed, "lines", res.Lines)
+	}
+	res.Done = !interrupted
+	// An interrupted line scan has its findings up to res.Offset, so it
+	// can continue from there too
+	if s.incremental != nil && res.Error == "" && (res.Done || lineScan) {
+		s.incremental.record(path, res, lineScan)
+	}
+	s.progress.fileDone(res.Size, wholeLines)
+	if s.checkpoint != nil {
+		if err := s.checkpoint.save(true); err != nil {
+			logger.Error("Error saving checkpoint", "err", err)
+		}
+	}
+	return res
+}
+
+// scanOutcome is what a set of files adds up to.
+type scanOutcome struct {
+	findings    []analyzer.Finding
+	sources     []analyzer.SourceCoverage
+	files       []reporter.ScanFile
+	logsScanned int
+	partial     bool // stopped before every file was read in full
+}
+
+// meta describes the scan for the metadata block of its reports.
+func (o scanOutcome) meta(az *analyzer.Analyzer, startedAt time.Time) *analyzer.ScanMeta {
+	host, _ := os.Hostname()
+	m := &analyzer.ScanMeta{
+		Version:    version,
+		ServicesDB: az.DBHash(),
+		DBInfo:     az.DBInfo(),
+		Host:       host,
+		StartedAt:  startedAt.UTC(),
+		FinishedAt: time.Now().UTC(),
+	}
+	for _, f := range o.files {
+		m.Files = append(m.Files, analyzer.ScannedFile{Path: f.Path, Size: f.Size, SHA256: f.SHA256})
+	}
+	return m
+}
+
+// scanAll scans each file with the parser its format selects. Files that
+// fail are recorded in files but contribute nothing else. Once the
+// scanner's context is cancelled, the outcome so far is returned as partial.
+func (s *fileScanner) scanAll(files []string, multiline *parsers.Multiline) scanOutcome {
+	var out scanOutcome
+	for _, f := range files {
+		if s.ctx.Err() != nil {
+			out.partial = true
+			break
+		}
+		p := withMultiline(selectParser(s.format, f, s.custom), multiline)
+		if p == nil {
+			logger.Warn("Skipping file: could not determine format", "file", f)
+			out.files = append(out.files, reporter.ScanFile{Path: f, Class: reporter.ErrorUnknownFormat, Error: "could not determine format"})
+			s.progress.fileDone(0, 0)
+			continue
+		}
+
+		var mark spool.Mark
+		if s.spool != nil {
+			mark = s.spool.Mark()
+		}
+		res := s.scan(f, p)
+		if s.spillErr != nil {
+			out.partial = true
+			break
+		}
+		out.files = append(out.files, res.scanFile(f))
+		if !res.Done {
+			out.partial = true
+		}
+		if res.Error != "" {
+			// Findings of a file that failed are dropped, spilled or not
+			if s.spool != nil {
+				if s.spillErr = s.spool.Reset(mark); s.spillErr != nil {
+					break
+				}
+			}
+			continue
+		}
+		out.sources = append(out.sources, res.Coverage)
+		out.findings = append(out.findings, res.Findings...)
+		out.logsScanned += res.Coverage.Entries
+	}
+	return out
+}
+
+// scanSource scans a source that is not a file, such as a journal unit or
+// a query. read returns its entries, counting the records it read and
+// rejecting those it could not parse in res; they are analyzed and added
+// to out as scanAll adds a file's.
+func (s *fileScanner) scanSource(out *scanOutcome, source, format string, read func(res *fileResult) ([]parsers.LogEntry, error)) {
+	if s.ctx.Err() != nil || s.spillErr != nil {
+		out.partial = true
+		return
+	}
+	start := time.Now()
+	res := &fileResult{Format: format}
+	entries, err := read(res)
+	interrupted := err != nil && s.ctx.Err() != nil
+	if err != nil && !interrupted {
+		res.Error, res.ErrorClass = err.Error(), reporter.ErrorUnreadable
+		logger.Warn("Error reading source", "source", source, "err", err)
+	}
+	if res.Error == "" {
+		for i := range entries {
+			entries[i].SourceFile = source
+			s.years.apply(&entries[i], start)
+		}
+		res.Findings, _, _ = s.az.MatchEntries(s.ctx, entries)
+		res.Coverage = analyzer.Coverage(source, format, entries)
+		s.spill(res)
+		res.Coverage.Findings = analyzer.CountAI(res.Findings) + res.Spilled
+		res.Coverage.Malformed = res.Malformed
+		if ratio := res.Coverage.MalformedRatio(); s.warnRatio > 0 && ratio > s.warnRatio {
+			logger.Warn(fmt.Sprintf("%.0f%% of records could not be parsed as %s; check -format", ratio*100, format),
+				"source", source, "malformed", res.Malformed, "records", res.Lines)
+		}
+	}
+	res.Seconds = time.Since(start).Seconds()
+	res.Done = !interrupted
+	s.progress.fileDone(0, res.Lines)
+
+	out.files = append(out.files, res.scanFile(source))
+	if !res.Done {
+		out.partial = true
+	}
+	if res.Error != "" {
+		return
+	}
+	out.sources = append(out.sources, res.Coverage)
+	out.findings = append(out.findings, res.Findings...)
+	out.logsScanned += res.Coverage.Entries
+}
+
+// scanWhole parses the entire file in one pass. When cancelled it keeps
+// whatever was parsed and returns the context's error.
+func (s *fileScanner) scanWhole(path string, p parsers.Parser, res *fileResult) error {
+	entries, err := parsers.ParseFile(s.ctx, p, path)
+	if err != nil && s.ctx.Err() == nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].SourceFile == "" { // formats added with parsers.Register
+			entries[i].SourceFile = path
+		}
+		s.years.apply(&entries[i], res.ModTime)
+	}
+	findings, _, aerr := s.az.MatchEntries(s.ctx, entries)
+	res.Coverage = analyzer.Coverage(path, p.Name(), entries)
+	res.Findings = findings
+	res.Lines = len(entries)
+	if err == nil {
+		err = aerr
+	}
+	return err
+}
+
+// fallback retries a file that yielded nothing with each custom parser.
+// A guessed format that parses nothing may belong to one of them.
+func (s *fileScanner) fallback(path string, p parsers.Parser, res *fileResult) {
+	for _, c := range s.custom {
+		if c.parser.Name() == p.Name() {
+			continue
+		}
+		if alt, err := parsers.ParseFile(s.ctx, c.parser, path); err == nil && len(alt) > 0 {
+			logger.Debug(fmt.Sprintf("no %s entries; falling back to %s format", p.Name(), c.parser.Name()), "file", path)
+			for i := range alt {
+				s.years.apply(&alt[i], res.ModTime)
+			}
+			res.Format = c.parser.Name()
+			res.Coverage = analyzer.Coverage(path, res.Format, alt)
+			res.Findings, _, _ = s.az.MatchEntries(s.ctx, alt)
+			res.Lines, res.Malformed, res.Samples = len(alt), 0, nil
+			return
+		}
+	}
+}
+
+// scanLines parses a line-oriented file from res.Offset, folding each batch
+// of lines into res and checkpointing as it goes.
+func (s *fileScanner) scanLines(path string, lp parsers.LineParser, res *fileResult) error {
+	file, err := fsutil.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(res.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking %s: %w", path, err)
+	}
+
+	offset, lineNo := res.Offset, res.LineNo
+	var batch []parsers.LogEntry
+	read := 0 // lines since the last flush
+	flush := func() error {
+		s.progress.advance(offset, read)
+		read = 0
+		res.Coverage.Add(batch)
+		found, _, _ := s.az.MatchEntries(context.Background(), batch)
+		res.Findings = append(res.Findings, found...)
+		res.Offset, res.LineNo = offset, lineNo
+		batch = batch[:0]
+		if s.checkpoint != nil {
+			if err := s.checkpoint.save(false); err != nil {
+				logger.Error("Error saving checkpoint", "err", err)
+			}
+		}
+		return s.spill(res)
+	}
+
+	r := bufio.NewReaderSize(file, 64*1024)
+	for n := 1; ; n++ {
+		raw, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if err == io.EOF && s.incremental != nil {
+			break // an unterminated last line may still be being written; the next scan reads it whole
+		}
+		if raw != "" {
+			offset += int64(len(raw))
+			lineNo++
+			read++
+			line := parsers.CleanLine(strings.TrimSuffix(raw, "\n"))
+			if res.Encoding == fsutil.Latin1 {
+				line = fsutil.DecodeLatin1(line)
+			}
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+				entry, perr := lp.ParseLine(line)
+				if !errors.Is(perr, parsers.ErrSkip) {
+					res.Lines++
+				}
+				if perr == nil {
+					entry.SourceFile, entry.LineNumber = path, lineNo
+					s.years.apply(&entry, res.ModTime)
+					batch = append(batch, entry)
+				} else if !errors.Is(perr, parsers.ErrSkip) {
+					res.reject(line)
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if n%checkpointLines == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if n%1024 == 0 && s.ctx.Err() != nil {
+			if err := flush(); err != nil {
+				return err
+			}
+			return s.ctx.Err()
+		}
+	}
+	return flush()
+}
+
+// spill moves the findings in res to the spool under -spill, so that no
+// file's findings are all held in memory at once. A write error is kept in
+// s.spillErr, which ends the scan.
+func (s *fileScanner) spill(res *fileResult) error {
+	if s.spool == nil || len(res.Findings) == 0 {
+		return s.spillErr
+	}
+	for _, f := range res.Findings {
+		if err := s.spool.Add(f); err != nil {
+			s.spillErr = err
+			return err
+		}
+	}
+	res.Spilled += analyzer.CountAI(res.Findings)
+	res.Findings = res.Findings[:0]
+	return nil
+}
+
+// reject counts a line the parser could not use, keeping the first few.
+func (r *fileResult) reject(line string) {
+	r.Malformed++
+	if len(r.Samples) < maxSamples {
+		if len(line) > maxSampleLen {
+			line = line[:maxSampleLen]
+		}
+		r.Samples = append(r.Samples, line)
+	}
+}
+
+// scanFile summarizes the result for machine-readable output, classifying
+// files that were not scanned cleanly.
+func (r *fileResult) scanFile(path string) reporter.ScanFile {
+	f := reporter.ScanFile{
+		Path:      path,
+		Size:      r.Size,
+		SHA256:    r.SHA256,
+		Format:    r.Format,
+		Encoding:  string(r.Encoding),
+		Lines:     r.Lines,
+		Entries:   r.Coverage.Entries,
+		Malformed: r.Malformed,
+		Findings:  analyzer.CountAI(r.Findings) + r.Spilled,
+		Seconds:   r.Seconds,
+		Class:     r.ErrorClass,
+		Error:     r.Error,
+		Samples:   r.Samples,
+	}
+	switch {
+	case f.Class != "":
+	case !r.Done:
+		f.Class = reporter.ErrorInterrupted
+		f.Error = fmt.Sprintf("scan stopped after %d records", r.Lines)
+	case r.Malformed > 0 && r.Coverage.Entries == 0:
+		f.Class = reporter.ErrorNoEntries
+		f.Error = fmt.Sprintf("none of %d records could be parsed as %s", r.Lines, r.Format)
+	case r.Malformed > 0:
+		f.Class = reporter.ErrorPartial
+		f.Error = fmt.Sprintf("%d of %d records could not be parsed as %s", r.Malformed, r.Lines, r.Format)
+	}
+	return f
+}
+
+// hashPrefix returns the hex SHA-256 of the first size bytes of the file
+// at path. It stops early, with the context's error, once ctx is done.
+func hashPrefix(ctx context.Context, path string, size int64) (string, error) {
+	f, err := fsutil.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	buf := make([]byte, 1<<20)
+	for done := int64(0); done < size; {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := io.CopyBuffer(h, io.LimitReader(f, min(size-done, int64(len(buf)))), buf)
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			return "", fmt.Errorf("%s shrank below %d bytes while being hashed", path, size)
+		}
+		done += n
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// scanCheckpoint is the in-progress state of a scan, saved periodically so a
+// crashed or killed run can be continued with -resume.
+type scanCheckpoint struct {
+	path  string
+	saved time.Time
+
+	// The database and -format the results were found with. Files done
+	// under others would be reported with stale findings.
+	ServicesDB string                 `json:"services_db_sha256,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+	Files      map[string]*fileResult `json:"files"`
+}
+
+// loadCheckpoint reads a checkpoint for a scan with the given database
+// hash and -format; a missing file starts a fresh scan, and so does one
+// written by a scan with another database or format.
+func loadCheckpoint(path, servicesDB, format string) (*scanCheckpoint, error) {
+	cp := &scanCheckpoint{path: path, saved: time.Now(), ServicesDB: servicesDB, Format: format, Files: make(map[string]*fileResult)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	// Checkpoints from before these were recorded are trusted
+	if (cp.ServicesDB != "" && cp.ServicesDB != servicesDB) || (cp.Format != "" && cp.Format != format) {
+		logger.Warn("Checkpoint is from a scan with another services database or -format; starting over", "checkpoint", path)
+		cp.Files = nil
+	}
+	cp.ServicesDB, cp.Format = servicesDB, format
+	if cp.Files == nil {
+		cp.Files = make(map[string]*fileResult)
+	}
+	return cp, nil
+}
+
+// resume returns the recorded progress for a file if it still applies: a
+// completed file must be unchanged, and a partial one must not have shrunk
+// below the recorded offset.
+func (c *scanCheckpoint) resume(path string, cur *fileResult) *fileResult {
+	prev, ok := c.Files[path]
+	if !ok {
+		return nil
+	}
+	if prev.Done {
+		if prev.Size == cur.Size && prev.ModTime.Equal(cur.ModTime) {
+			return prev
+		}
+		return nil
+	}
+	if prev.Error != "" || cur.Size < prev.Offset {
+		return nil
+	}
+	prev.Size, prev.ModTime = cur.Size, cur.ModTime
+	return prev
+}
+
+// save writes the checkpoint atomically. Unless forced, it writes at most
+// once per checkpointInterval.
+func (c *scanCheckpoint) save(force bool) error {
+	if !force && time.Since(c.saved) < checkpointInterval {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	c.saved = time.Now()
+	logger.Log(context.Background(), levelTrace, "checkpoint saved", "path", c.path, "files", len(c.Files))
+	return nil
+}
+
+// remove deletes the checkpoint once the scan it tracks has been reported.
+func (c *scanCheckpoint) remove() error {
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/shadow-ai-hunter/fsutil"
+	"github.com/shadow-ai-hunter/parsers"
+)
+
+// parallelMinSize is the unread size above which a line-oriented file is
+// split into chunks parsed concurrently. Below it, starting the workers
+// costs more than it saves.
+const parallelMinSize = 32 << 20
+
+// chunkSize is how much of a file a worker parses at a time. Chunks end on
+// a line boundary, so each is a little shorter.
+const chunkSize = 4 << 20
+
+// lineChunk is a run of whole lines from a file.
+type lineChunk struct {
+	seq       int
+	data      []byte
+	firstLine int   // line number of the first line, less one
+	end       int64 // file offset just past the chunk
+}
+
+// chunkResult is what a worker made of a chunk.
+type chunkResult struct {
+	seq int
+	res *fileResult
+}
+
+// scanLinesParallel is scanLines for large files: a reader cuts the file into
+// chunks on line boundaries, s.workers goroutines parse and match them, and
+// the results are folded into res in file order, so findings, offsets, and
+// checkpoints come out as they would from a sequential scan.
+func (s *fileScanner) scanLinesParallel(path string, lp parsers.LineParser, res *fileResult) error {
+	file, err := fsutil.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(res.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking %s: %w", path, err)
+	}
+	logger.Debug("parsing in parallel", "file", path, "workers", s.workers)
+
+	// Cancelling stops the reader; chunks already handed out are still
+	// parsed and folded, so res stays consistent with its offset
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	chunks := make(chan lineChunk, s.workers)
+	results := make(chan chunkResult, s.workers)
+
+	var readErr error
+	go func() {
+		defer close(chunks)
+		readErr = s.readChunks(ctx, file, res, chunks)
+	}()
+
+	var wg sync.WaitGroup
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunks {
+				results <- chunkResult{c.seq, s.parseChunk(path, lp, res, c)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]*fileResult)
+	next := 0
+	for r := range results {
+		pending[r.seq] = r.res
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			lines := r.LineNo - res.LineNo
+			res.merge(r)
+			s.progress.advance(res.Offset, lines)
+			if s.spill(res) != nil {
+				cancel() // the workers drain what was handed out
+			}
+			if s.checkpoint != nil {
+				if err := s.checkpoint.save(false); err != nil {
+					logger.Error("Error saving checkpoint", "err", err)
+				}
+			}
+		}
+	}
+	if readErr != nil {
+		return fmt.Errorf("reading %s: %w", path, readErr)
+	}
+	if s.spillErr != nil {
+		return s.spillErr
+	}
+	return s.ctx.Err()
+}
+
+// readChunks cuts the file into chunks of whole lines. A line longer than
+// chunkSize makes its chunk longer rather than being split.
+func (s *fileScanner) readChunks(ctx context.Context, file io.Reader, res *fileResult, out chan<- lineChunk) error {
+	offset, lineNo := res.Offset, res.LineNo
+	var carry []byte
+	for seq := 0; ; seq++ {
+		data := make([]byte, len(carry)+chunkSize)
+		copy(data, carry)
+		n, err := io.ReadFull(file, data[len(carry):])
+		data = data[:len(carry)+n]
+		last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !last {
+			return err
+		}
+
+		cut := bytes.LastIndexByte(data, '\n') + 1
+		if last && s.incremental == nil {
+			cut = len(data) // an unterminated last line is still a line
+		}
+		if cut == 0 && !last {
+			carry, seq = data, seq-1
+			continue
+		}
+		carry = append([]byte(nil), data[cut:]...)
+		chunk := lineChunk{seq: seq, data: data[:cut], firstLine: lineNo, end: offset + int64(cut)}
+		lineNo += bytes.Count(chunk.data, []byte{'\n'})
+		if cut > 0 && data[cut-1] != '\n' {
+			lineNo++
+		}
+		offset = chunk.end
+
+		select {
+		case out <- chunk:
+		case <-ctx.Done():
+			return nil
+		}
+		if last {
+			return nil
+		}
+		if seq%16 == 0 && ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// parseChunk parses and matches one chunk. The result holds the chunk's
+// counts and findings, with Offset and LineNo where the chunk ends.
+func (s *fileScanner) parseChunk(path string, lp parsers.LineParser, res *fileResult, c lineChunk) *fileResult {
+	out := &fileResult{Offset: c.end, LineNo: c.firstLine}
+	var entries []parsers.LogEntry
+	data := c.data
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		var raw []byte
+		if i < 0 {
+			raw, data = data, nil
+		} else {
+			raw, data = data[:i], data[i+1:]
+		}
+		out.LineNo++
+		line := parsers.CleanLine(string(raw))
+		if res.Encoding == fsutil.Latin1 {
+			line = fsutil.DecodeLatin1(line)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := lp.ParseLine(line)
+		if errors.Is(err, parsers.ErrSkip) {
+			continue
+		}
+		out.Lines++
+		if err != nil {
+			out.reject(line)
+			continue
+		}
+		entry.SourceFile, entry.LineNumber = path, out.LineNo
+		s.years.apply(&entry, res.ModTime)
+		entries = append(entries, entry)
+	}
+	out.Coverage.Add(entries)
+	for _, e := range entries {
+		if f, ok := s.az.Match(e); ok {
+			out.Findings = append(out.Findings, f)
+		}
+	}
+	return out
+}
+
+// merge folds the result of the next chunk into r.
+func (r *fileResult) merge(c *fileResult) {
+	r.Lines += c.Lines
+	r.Malformed += c.Malformed
+	for _, line := range c.Samples {
+		if len(r.Samples) < maxSamples {
+			r.Samples = append(r.Samples, line)
+		}
+	}
+	r.Coverage.Merge(c.Coverage)
+	r.Findings = append(r.Findings, c.Findings...)
+	r.Offset, r.LineNo = c.Offset, c.LineNo
+}
@@ -0,0 +1,213 @@
+// Package seal encrypts report files to age recipients and signs them, so
+// reports about employee activity can be stored safely and checked later.
+package seal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// SigSuffix is appended to a report's path to name its signature file.
+const SigSuffix = ".sig"
+
+// signOpts selects Ed25519ph (RFC 8032): the signature covers the SHA-512
+// of the file, so reports of any size are signed while they are written.
+var signOpts = &ed25519.Options{Hash: crypto.SHA512}
+
+// Sealer encrypts and signs the report files it creates. A nil Sealer
+// writes plain files.
+type Sealer struct {
+	recipients []age.Recipient
+	key        ed25519.PrivateKey
+}
+
+// New prepares a Sealer. Each recipient is an age public key ("age1...")
+// or the path of a file of them, one per line. signKey is the path of a
+// signing key written by GenerateKey. It returns nil when neither is given.
+func New(recipients []string, signKey string) (*Sealer, error) {
+	if len(recipients) == 0 && signKey == "" {
+		return nil, nil
+	}
+	s := &Sealer{}
+	for _, r := range recipients {
+		parsed, err := parseRecipients(r)
+		if err != nil {
+			return nil, err
+		}
+		s.recipients = append(s.recipients, parsed...)
+	}
+	if signKey != "" {
+		key, err := LoadPrivateKey(signKey)
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+	}
+	return s, nil
+}
+
+func parseRecipients(r string) ([]age.Recipient, error) {
+	if strings.HasPrefix(r, "age1") {
+		rec, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %s: %w", r, err)
+		}
+		return []age.Recipient{rec}, nil
+	}
+	f, err := os.Open(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading recipients: %w", err)
+	}
+	defer f.Close()
+	recs, err := age.ParseRecipients(f)
+	if err != nil {
+		return nil, fmt.Errorf("recipients file %s: %w", r, err)
+	}
+	return recs, nil
+}
+
+// Encrypts reports whether files are encrypted.
+func (s *Sealer) Encrypts() bool { return s != nil && len(s.recipients) > 0 }
+
+// Signs reports whether files are signed.
+func (s *Sealer) Signs() bool { return s != nil && s.key != nil }
+
+// WriteFile creates path and fills it with write, encrypted and signed as
+// the Sealer is configured. The signature goes to path+SigSuffix and covers
+// the file as stored, so it can be checked without decrypting.
+func (s *Sealer) WriteFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer f.Close()
+
+	var out io.Writer = f
+	var digest hash.Hash
+	if s.Signs() {
+		digest = sha512.New()
+		out = io.MultiWriter(f, digest)
+	}
+	buf := bufio.NewWriter(out)
+	var w io.Writer = buf
+	var enc io.WriteCloser
+	if s.Encrypts() {
+		if enc, err = age.Encrypt(buf, s.recipients...); err != nil {
+			return fmt.Errorf("encrypting report: %w", err)
+		}
+		w = enc
+	}
+
+	if err := write(w); err != nil {
+		return err
+	}
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("encrypting report: %w", err)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if digest != nil {
+		sig, err := s.key.Sign(nil, digest.Sum(nil), signOpts)
+		if err != nil {
+			return fmt.Errorf("signing report: %w", err)
+		}
+		encoded := base64.StdEncoding.EncodeToString(sig) + "\n"
+		if err := os.WriteFile(path+SigSuffix, []byte(encoded), 0o644); err != nil {
+			return fmt.Errorf("writing signature: %w", err)
+		}
+	}
+	return nil
+}
+
+// Verify checks the signature of the file at path against key. sigPath
+// defaults to path+SigSuffix.
+func Verify(path, sigPath string, key ed25519.PublicKey) error {
+	if sigPath == "" {
+		sigPath = path + SigSuffix
+	}
+	raw, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("reading signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%s is not a base64 ed25519 signature", sigPath)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	digest := sha512.New()
+	if _, err := io.Copy(digest, f); err != nil {
+		return err
+	}
+	if err := ed25519.VerifyWithOptions(key, digest.Sum(nil), sig, signOpts); err != nil {
+		return errors.New("signature does not match: the file was altered or signed with another key")
+	}
+	return nil
+}
+
+// GenerateKey creates a signing key, writes its private half to path
+// readable only by the owner, and returns the base64 public key.
+func GenerateKey(path string) (string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString(priv.Seed()) + "\n"
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("creating key file: %w", err)
+	}
+	if _, err := f.WriteString(encoded); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), nil
+}
+
+// LoadPrivateKey reads a base64 ed25519 private key: the 32-byte seed
+// GenerateKey writes, or the full 64-byte key.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading signing key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", path, err)
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("signing key %s must be %d or %d bytes, got %d", path, ed25519.SeedSize, ed25519.PrivateKeySize, len(key))
+	}
+}
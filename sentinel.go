@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/sentinel"
+)
+
+// sentinelQuery is a KQL query given with -sentinel-query, and the source
+// its findings are attributed to.
+type sentinelQuery struct {
+	source string
+	kql    string
+}
+
+// loadSentinelQueries reads the queries given as @file.
+func loadSentinelQueries(args []string) ([]sentinelQuery, error) {
+	queries := make([]sentinelQuery, 0, len(args))
+	for i, arg := range args {
+		q := sentinelQuery{source: fmt.Sprintf("sentinel:query%d", i+1), kql: arg}
+		if path, ok := strings.CutPrefix(arg, "@"); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("-sentinel-query: %w", err)
+			}
+			q = sentinelQuery{source: "sentinel:" + path, kql: string(data)}
+		}
+		if strings.TrimSpace(q.kql) == "" {
+			return nil, fmt.Errorf("-sentinel-query %s is empty", q.source)
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// scanSentinel adds the records each query returns to the outcome of a
+// file scan. Result columns are found by name as in a CSV export, so the
+// query should project them as timestamp, source_ip, destination, user,
+// and so on.
+func (s *fileScanner) scanSentinel(out *scanOutcome, q *sentinel.Querier, queries []sentinelQuery) {
+	for _, query := range queries {
+		s.scanSource(out, query.source, "kql", func(res *fileResult) ([]parsers.LogEntry, error) {
+			logger.Info("Querying Sentinel", "query", query.source)
+			table, err := q.Query(s.ctx, query.kql)
+			if err != nil || len(table.Rows) == 0 {
+				return nil, err
+			}
+			res.Lines = len(table.Rows)
+			return parsers.TableEntries(s.ctx, table.Columns, table.Rows, query.source, 1)
+		})
+	}
+}
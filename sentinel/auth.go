@@ -0,0 +1,102 @@
+// Package sentinel connects the hunter to Microsoft Sentinel: findings are
+// sent to a Log Analytics workspace through the Azure Monitor Logs
+// Ingestion API, and proxy or DNS records already in the workspace can be
+// read back with a KQL query.
+package sentinel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAuthority is the Microsoft Entra ID endpoint of the public cloud.
+const DefaultAuthority = "https://login.microsoftonline.com"
+
+// Credential gets Microsoft Entra ID access tokens for an app registration
+// with the client credentials flow, caching each until shortly before it
+// expires.
+type Credential struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	Authority    string // empty means DefaultAuthority
+
+	client *http.Client
+	mu     sync.Mutex
+	tokens map[string]token // by scope
+}
+
+type token struct {
+	value   string
+	expires time.Time
+}
+
+// Token returns an access token for scope, such as
+// https://monitor.azure.com//.default.
+func (c *Credential) Token(ctx context.Context, scope string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tokens[scope]; ok && time.Until(t.expires) > time.Minute {
+		return t.value, nil
+	}
+
+	authority := c.Authority
+	if authority == "" {
+		authority = DefaultAuthority
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"scope":         {scope},
+	}
+	endpoint := strings.TrimSuffix(authority, "/") + "/" + url.PathEscape(c.TenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.client == nil {
+		c.client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("getting Entra ID token: %w", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil && resp.StatusCode/100 == 2 {
+		return "", fmt.Errorf("getting Entra ID token: %w", err)
+	}
+	if resp.StatusCode/100 != 2 || body.AccessToken == "" {
+		if body.Error != "" {
+			return "", fmt.Errorf("getting Entra ID token: %s: %s", body.Error, firstLine(body.Description))
+		}
+		return "", fmt.Errorf("getting Entra ID token: %s", resp.Status)
+	}
+
+	if c.tokens == nil {
+		c.tokens = make(map[string]token)
+	}
+	c.tokens[scope] = token{value: body.AccessToken, expires: time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)}
+	return body.AccessToken, nil
+}
+
+// firstLine trims an Entra ID error description, which continues with
+// trace and correlation IDs, to its message.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+}
@@ -0,0 +1,184 @@
+package sentinel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+const (
+	// monitorScope is the token scope of the Logs Ingestion API.
+	monitorScope = "https://monitor.azure.com//.default"
+	// maxBatchBytes keeps each request under the API's 1 MB limit.
+	maxBatchBytes    = 1000 * 1000
+	ingestAPIVersion = "2023-01-01"
+)
+
+// Ingestor sends findings to a data collection rule (DCR) stream, which
+// routes them to a custom table such as ShadowAIFindings_CL.
+type Ingestor struct {
+	endpoint string // data collection endpoint or the DCR's logs ingestion URL
+	dcr      string // the rule's immutable ID, dcr-...
+	stream   string // e.g. Custom-ShadowAIFindings_CL
+	cred     *Credential
+	client   *http.Client
+}
+
+// NewIngestor returns an ingestor for the DCR stream behind endpoint.
+func NewIngestor(endpoint, dcr, stream string, cred *Credential) (*Ingestor, error) {
+	if !strings.HasPrefix(endpoint, "https://") {
+		return nil, fmt.Errorf("sentinel: ingestion endpoint %q must be an https:// URL", endpoint)
+	}
+	if dcr == "" || stream == "" {
+		return nil, fmt.Errorf("sentinel: ingestion needs dcr_id and stream")
+	}
+	return &Ingestor{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		dcr:      dcr,
+		stream:   stream,
+		cred:     cred,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Stream names the DCR stream findings are sent to.
+func (i *Ingestor) Stream() string {
+	return i.stream
+}
+
+// Record is a finding as a row of the custom table. Column names follow
+// Azure Monitor's convention; the DCR's stream declaration must list them.
+type Record struct {
+	TimeGenerated time.Time `json:"TimeGenerated"`
+	SourceIP      string    `json:"SourceIP"`
+	User          string    `json:"User"`
+	Service       string    `json:"Service"`
+	Provider      string    `json:"Provider"`
+	Category      string    `json:"Category"`
+	Severity      string    `json:"Severity"`
+	Domain        string    `json:"Domain"`
+	URL           string    `json:"Url"`
+	Method        string    `json:"Method"`
+	StatusCode    string    `json:"StatusCode"`
+	Action        string    `json:"Action"`
+	BytesSent     int64     `json:"BytesSent"`
+	BytesReceived int64     `json:"BytesReceived"`
+	Activity      string    `json:"Activity"`
+	UserAgent     string    `json:"UserAgent"`
+	JA3           string    `json:"JA3"`
+	ProcessName   string    `json:"ProcessName"`
+	DeviceName    string    `json:"DeviceName"`
+	DetectedBy    string    `json:"DetectedBy"`
+	Blocked       bool      `json:"Blocked"`
+	CloudHosted   bool      `json:"CloudHosted"`
+	OffHours      bool      `json:"OffHours"`
+	NewAdoption   bool      `json:"NewAdoption"`
+	Automated     bool      `json:"AutomationSuspected"`
+	RatePerMinute float64   `json:"RatePerMinute"`
+	SourceFile    string    `json:"SourceFile"`
+	LineNumber    int       `json:"LineNumber"`
+	Tenant        string    `json:"Tenant"`
+}
+
+// NewRecord maps a finding onto a table row. A finding without a time is
+// stamped with the current one, since TimeGenerated is required.
+func NewRecord(f analyzer.Finding) Record {
+	ts := f.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return Record{
+		TimeGenerated: ts.UTC(),
+		SourceIP:      f.SourceIP,
+		User:          f.User,
+		Service:       f.ServiceName,
+		Provider:      f.Provider,
+		Category:      f.Category,
+		Severity:      f.Severity().String(),
+		Domain:        f.Domain,
+		URL:           f.URL,
+		Method:        f.Method,
+		StatusCode:    f.StatusCode,
+		Action:        f.Action,
+		BytesSent:     f.BytesSent,
+		BytesReceived: f.BytesReceived,
+		Activity:      string(f.Activity),
+		UserAgent:     f.UserAgent,
+		JA3:           f.JA3,
+		ProcessName:   f.ProcessName,
+		DeviceName:    f.DeviceName,
+		DetectedBy:    f.DetectedBy,
+		Blocked:       f.Blocked,
+		CloudHosted:   f.CloudHosted,
+		OffHours:      f.OffHours,
+		NewAdoption:   f.NewAdoption,
+		Automated:     f.Automated,
+		RatePerMinute: f.Rate,
+		SourceFile:    f.SourceFile,
+		LineNumber:    f.LineNumber,
+		Tenant:        f.Tenant,
+	}
+}
+
+// Send uploads findings in as few requests as the size limit allows.
+func (i *Ingestor) Send(ctx context.Context, findings []analyzer.Finding) error {
+	var batch bytes.Buffer
+	for _, f := range findings {
+		row, err := json.Marshal(NewRecord(f))
+		if err != nil {
+			return err
+		}
+		if batch.Len() > 0 && batch.Len()+len(row)+2 > maxBatchBytes {
+			if err := i.post(ctx, &batch); err != nil {
+				return err
+			}
+		}
+		if batch.Len() == 0 {
+			batch.WriteByte('[')
+		} else {
+			batch.WriteByte(',')
+		}
+		batch.Write(row)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return i.post(ctx, &batch)
+}
+
+// post closes the JSON array in batch, sends it, and empties batch.
+func (i *Ingestor) post(ctx context.Context, batch *bytes.Buffer) error {
+	batch.WriteByte(']')
+	defer batch.Reset()
+	tok, err := i.cred.Token(ctx, monitorScope)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/dataCollectionRules/%s/streams/%s?api-version=%s",
+		i.endpoint, url.PathEscape(i.dcr), url.PathEscape(i.stream), ingestAPIVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(batch.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+tok)
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sentinel: stream %s: %s: %s", i.stream, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
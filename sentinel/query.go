@@ -0,0 +1,142 @@
+package sentinel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryScope is the token scope of the Log Analytics query API.
+const queryScope = "https://api.loganalytics.io/.default"
+
+// DefaultQueryEndpoint is the Log Analytics query API of the public cloud.
+const DefaultQueryEndpoint = "https://api.loganalytics.io"
+
+// Querier runs KQL queries against a Log Analytics workspace.
+type Querier struct {
+	endpoint  string
+	workspace string // workspace ID, a GUID
+	cred      *Credential
+	client    *http.Client
+}
+
+// NewQuerier returns a querier for the workspace. An empty endpoint means
+// DefaultQueryEndpoint.
+func NewQuerier(endpoint, workspace string, cred *Credential) (*Querier, error) {
+	if workspace == "" {
+		return nil, fmt.Errorf("sentinel: queries need workspace_id")
+	}
+	if endpoint == "" {
+		endpoint = DefaultQueryEndpoint
+	}
+	return &Querier{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		workspace: workspace,
+		cred:      cred,
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Table is a query result: column names and rows rendered as text, with
+// datetimes in RFC 3339 and empty cells for nulls.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Query runs kql and returns its primary result. The time range is the
+// query's own; filter on TimeGenerated to bound it.
+func (q *Querier) Query(ctx context.Context, kql string) (Table, error) {
+	tok, err := q.cred.Token(ctx, queryScope)
+	if err != nil {
+		return Table{}, err
+	}
+	body, err := json.Marshal(map[string]string{"query": kql})
+	if err != nil {
+		return Table{}, err
+	}
+	endpoint := q.endpoint + "/v1/workspaces/" + url.PathEscape(q.workspace) + "/query"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Table{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+tok)
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return Table{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error struct {
+				Message    string `json:"message"`
+				Innererror struct {
+					Message string `json:"message"`
+				} `json:"innererror"`
+			} `json:"error"`
+		}
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.TrimSpace(string(raw))
+		if json.Unmarshal(raw, &e) == nil && e.Error.Message != "" {
+			msg = e.Error.Message
+			if inner := e.Error.Innererror.Message; inner != "" {
+				msg += ": " + inner
+			}
+		}
+		return Table{}, fmt.Errorf("sentinel: query: %s: %s", resp.Status, msg)
+	}
+
+	var result struct {
+		Tables []struct {
+			Columns []struct {
+				Name string `json:"name"`
+			} `json:"columns"`
+			Rows [][]any `json:"rows"`
+		} `json:"tables"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return Table{}, fmt.Errorf("sentinel: reading query result: %w", err)
+	}
+	if len(result.Tables) == 0 {
+		return Table{}, nil
+	}
+	primary := result.Tables[0]
+	t := Table{Columns: make([]string, len(primary.Columns)), Rows: make([][]string, len(primary.Rows))}
+	for i, c := range primary.Columns {
+		t.Columns[i] = c.Name
+	}
+	for i, row := range primary.Rows {
+		cells := make([]string, len(row))
+		for j, v := range row {
+			cells[j] = cell(v)
+		}
+		t.Rows[i] = cells
+	}
+	return t, nil
+}
+
+// cell renders a result value as text.
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(v) // dynamic columns
+	return string(b)
+}
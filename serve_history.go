@@ -0,0 +1,196 @@
+package main
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/history"
+)
+
+// Limits for the history endpoints behind the dashboard.
+const (
+	historyTop         = 10  // rows in each top list
+	historyDays        = 90  // days in the per-day series
+	historySearchLimit = 100 // findings returned by default
+	historySearchMax   = 1000
+)
+
+// serveHistory is the store serve records jobs in and the dashboard reads.
+// Access is serialized, since not every backend is safe for concurrent use.
+type serveHistory struct {
+	mu    sync.Mutex
+	store *history.Store
+}
+
+func (h *serveHistory) append(scannedAt time.Time, findings []analyzer.Finding) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.store.Append(scannedAt, findings)
+}
+
+func (h *serveHistory) records() ([]history.Record, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.store.Records()
+}
+
+func (h *serveHistory) close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.store.Close()
+}
+
+// countRow is one entry of a ranked list or series.
+type countRow struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// historySummary is the body of GET /history/summary.
+type historySummary struct {
+	Findings    int        `json:"findings"`
+	Scans       int        `json:"scans"`
+	FirstSeen   *time.Time `json:"first_seen,omitempty"`
+	LastSeen    *time.Time `json:"last_seen,omitempty"`
+	LastScan    *time.Time `json:"last_scan,omitempty"`
+	TopUsers    []countRow `json:"top_users"`
+	TopServices []countRow `json:"top_services"`
+	ByDay       []countRow `json:"by_day"` // the last historyDays days with findings, oldest first
+}
+
+// summarizeHistory ranks the stored findings for the dashboard charts.
+func summarizeHistory(records []history.Record) historySummary {
+	findings := make([]analyzer.Finding, 0, len(records))
+	scans := make(map[time.Time]bool)
+	days := make(map[string]int)
+	var lastScan time.Time
+	for _, r := range records {
+		findings = append(findings, r.Finding)
+		scans[r.ScannedAt] = true
+		if r.ScannedAt.After(lastScan) {
+			lastScan = r.ScannedAt
+		}
+		day := r.Finding.Timestamp
+		if day.IsZero() {
+			day = r.ScannedAt
+		}
+		days[day.UTC().Format(time.DateOnly)]++
+	}
+	s := analyzer.Summarize(findings, 0)
+
+	out := historySummary{
+		Findings:    len(records),
+		Scans:       len(scans),
+		TopUsers:    topCounts(s.ByUser, historyTop),
+		TopServices: topCounts(s.ByService, historyTop),
+		ByDay:       []countRow{},
+	}
+	if !s.FirstSeen.IsZero() {
+		out.FirstSeen, out.LastSeen = &s.FirstSeen, &s.LastSeen
+	}
+	if !lastScan.IsZero() {
+		out.LastScan = &lastScan
+	}
+	for day, n := range days {
+		out.ByDay = append(out.ByDay, countRow{Name: day, Count: n})
+	}
+	slices.SortFunc(out.ByDay, func(a, b countRow) int { return strings.Compare(a.Name, b.Name) })
+	if len(out.ByDay) > historyDays {
+		out.ByDay = out.ByDay[len(out.ByDay)-historyDays:]
+	}
+	return out
+}
+
+// topCounts returns the n largest counts, ties by name.
+func topCounts(m map[string]int, n int) []countRow {
+	rows := make([]countRow, 0, len(m))
+	for name, count := range m {
+		rows = append(rows, countRow{Name: name, Count: count})
+	}
+	slices.SortFunc(rows, func(a, b countRow) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Name, b.Name))
+	})
+	return rows[:min(n, len(rows))]
+}
+
+// historySearch is the body of GET /history/findings.
+type historySearch struct {
+	Matched int              `json:"matched"`
+	Records []history.Record `json:"records"` // newest first, at most limit
+}
+
+// searchHistory returns the records matching every filter of the query:
+// q is a case-insensitive substring of the user, source IP, service,
+// domain, or URL; service and user must match exactly.
+func searchHistory(records []history.Record, q, service, user string, limit int) historySearch {
+	q = strings.ToLower(q)
+	out := historySearch{Records: []history.Record{}}
+	for i := len(records) - 1; i >= 0; i-- {
+		f := records[i].Finding
+		if service != "" && !strings.EqualFold(f.ServiceName, service) {
+			continue
+		}
+		if user != "" && f.Identity() != user {
+			continue
+		}
+		if q != "" && !slices.ContainsFunc([]string{f.User, f.SourceIP, f.ServiceName, f.Domain, f.URL}, func(s string) bool {
+			return strings.Contains(strings.ToLower(s), q)
+		}) {
+			continue
+		}
+		out.Matched++
+		if len(out.Records) < limit {
+			out.Records = append(out.Records, records[i])
+		}
+	}
+	return out
+}
+
+// handleHistory adds the history endpoints to mux. Both take ?tenant= to
+// narrow them to one business unit. Without a store they answer 404, which
+// the dashboard takes to mean there is no history.
+func handleHistory(mux *http.ServeMux, h *serveHistory) {
+	// load reads the records of the ?tenant= query, or all of them
+	load := func(w http.ResponseWriter, r *http.Request) ([]history.Record, bool) {
+		if h == nil {
+			writeError(w, http.StatusNotFound, "no -history store configured")
+			return nil, false
+		}
+		records, err := h.records()
+		if err != nil {
+			logger.Error("Error reading history", "err", err)
+			writeError(w, http.StatusInternalServerError, "reading history failed")
+			return nil, false
+		}
+		if tenant := r.URL.Query().Get("tenant"); tenant != "" {
+			records = slices.DeleteFunc(records, func(rec history.Record) bool { return rec.Finding.Tenant != tenant })
+		}
+		return records, true
+	}
+	mux.HandleFunc("GET /history/summary", func(w http.ResponseWriter, r *http.Request) {
+		if records, ok := load(w, r); ok {
+			writeJSON(w, http.StatusOK, summarizeHistory(records))
+		}
+	})
+	mux.HandleFunc("GET /history/findings", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		limit := historySearchLimit
+		if v := query.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > historySearchMax {
+				writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(historySearchMax))
+				return
+			}
+			limit = n
+		}
+		if records, ok := load(w, r); ok {
+			writeJSON(w, http.StatusOK, searchHistory(records, query.Get("q"), query.Get("service"), query.Get("user"), limit))
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"time"
+
+	"github.com/shadow-ai-hunter/analyzer"
+	"github.com/shadow-ai-hunter/history"
+	"github.com/shadow-ai-hunter/redact"
+	"github.com/shadow-ai-hunter/reporter"
+	"github.com/shadow-ai-hunter/spool"
+)
+
+// spillBatch is how many spilled findings are handed to the history store,
+// Kafka, or Sentinel at a time.
+const spillBatch = 10000
+
+// spillPass is what a -spill scan applies to its findings after reading
+// them: the steps the in-memory path takes on the whole summary, done here
+// one finding at a time.
+type spillPass struct {
+	baseline *analyzer.Baseline
+	history  *history.Store // nil without -history
+	keep     func(analyzer.Finding) bool
+	fail     failOn
+}
+
+// run reads the scanned findings from raw once, tagging new adoption and
+// recording every one in history, and writes those that pass keep to a new
+// spool. It returns their summary, with no Findings, the spool, and how
+// many of them count toward -fail-on.
+func (p spillPass) run(raw *spool.Spool, dir string, scanned analyzer.Summary) (analyzer.Summary, *spool.Spool, int, error) {
+	kept, err := spool.New(dir)
+	if err != nil {
+		return analyzer.Summary{}, nil, 0, err
+	}
+	tag := p.baseline != nil && !p.baseline.Empty()
+	scannedAt := time.Now().UTC()
+	agg := analyzer.NewAggregator()
+	failing := 0
+	var batch []analyzer.Finding
+	err = raw.Each(func(f analyzer.Finding) error {
+		if tag {
+			f.NewAdoption = !p.baseline.Known(f.Identity(), f.ServiceName)
+		}
+		if p.history != nil {
+			if batch = append(batch, f); len(batch) == spillBatch {
+				if err := p.history.Append(scannedAt, batch); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+		if f.IsDoH() {
+			agg.Add(f) // counted only as a DoH client
+			return nil
+		}
+		if !p.keep(f) {
+			return nil
+		}
+		agg.Add(f)
+		if p.fail.counts(f) {
+			failing++
+		}
+		return kept.Add(f)
+	})
+	if err == nil && len(batch) > 0 {
+		err = p.history.Append(scannedAt, batch)
+	}
+	if err != nil {
+		kept.Close()
+		return analyzer.Summary{}, nil, 0, err
+	}
+
+	summary := agg.Summary(scanned.TotalLogsScanned)
+	summary.Sources = scanned.Sources
+	summary.Partial = scanned.Partial
+	summary.Tenant = scanned.Tenant
+	summary.Scan = scanned.Scan
+	return summary, kept, failing, nil
+}
+
+// spooledFindings reads a spool's findings redacted by profile.
+func spooledFindings(sp *spool.Spool, profile redact.Profile) reporter.FindingSource {
+	return func(fn func(analyzer.Finding) error) error {
+		if profile.AggregateOnly {
+			return nil
+		}
+		return sp.Each(func(f analyzer.Finding) error {
+			return fn(profile.Finding(f))
+		})
+	}
+}
+
+// sendSpooled passes a spool's findings to send in batches, as they are
+// published to Kafka or Sentinel, returning how many were sent.
+func sendSpooled(src reporter.FindingSource, send func([]analyzer.Finding) error) (int, error) {
+	sent := 0
+	var batch []analyzer.Finding
+	err := src(func(f analyzer.Finding) error {
+		if batch = append(batch, f); len(batch) < spillBatch {
+			return nil
+		}
+		sent += len(batch)
+		err := send(batch)
+		batch = batch[:0]
+		return err
+	})
+	if err == nil && len(batch) > 0 {
+		sent += len(batch)
+		err = send(batch)
+	}
+	return sent, err
+}
@@ -0,0 +1,110 @@
+// Package spool keeps findings in a temporary file rather than in memory,
+// for scans that produce more of them than fit.
+package spool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shadow-ai-hunter/analyzer"
+)
+
+// Spool is an append-only list of findings stored as JSON Lines in a
+// temporary file. Where the system allows, the file is unlinked as soon as
+// it is created, so it cannot outlive the process; elsewhere Close removes it.
+type Spool struct {
+	file *os.File
+	w    *bufio.Writer
+	enc  *json.Encoder
+	size int64 // bytes written
+	n    int
+}
+
+// Mark is a position in a spool to roll back to.
+type Mark struct {
+	size int64
+	n    int
+}
+
+// New creates an empty spool in dir, or the system temporary directory
+// when dir is empty.
+func New(dir string) (*Spool, error) {
+	f, err := os.CreateTemp(dir, "shadow-hunter-spool-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("creating spool: %w", err)
+	}
+	os.Remove(f.Name()) // fails while open on Windows
+	s := &Spool{file: f, w: bufio.NewWriterSize(f, 256*1024)}
+	s.enc = json.NewEncoder(countWriter{s})
+	return s, nil
+}
+
+// countWriter tracks the spool's size so it can be rolled back.
+type countWriter struct{ s *Spool }
+
+func (c countWriter) Write(p []byte) (int, error) {
+	n, err := c.s.w.Write(p)
+	c.s.size += int64(n)
+	return n, err
+}
+
+// Add appends a finding.
+func (s *Spool) Add(f analyzer.Finding) error {
+	if err := s.enc.Encode(f); err != nil {
+		return fmt.Errorf("writing spool: %w", err)
+	}
+	s.n++
+	return nil
+}
+
+// Len returns the number of findings in the spool.
+func (s *Spool) Len() int { return s.n }
+
+// Mark returns the current end of the spool.
+func (s *Spool) Mark() Mark { return Mark{size: s.size, n: s.n} }
+
+// Reset drops the findings added since m.
+func (s *Spool) Reset(m Mark) error {
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("writing spool: %w", err)
+	}
+	if err := s.file.Truncate(m.size); err != nil {
+		return fmt.Errorf("truncating spool: %w", err)
+	}
+	if _, err := s.file.Seek(m.size, io.SeekStart); err != nil {
+		return fmt.Errorf("truncating spool: %w", err)
+	}
+	s.size, s.n = m.size, m.n
+	return nil
+}
+
+// Each calls fn with every finding in the order they were added, stopping
+// at the first error. Findings may be added again once it returns.
+func (s *Spool) Each(fn func(analyzer.Finding) error) error {
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("writing spool: %w", err)
+	}
+	dec := json.NewDecoder(bufio.NewReaderSize(io.NewSectionReader(s.file, 0, s.size), 256*1024))
+	for range s.n {
+		var f analyzer.Finding
+		if err := dec.Decode(&f); err != nil {
+			return fmt.Errorf("reading spool: %w", err)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close removes the spool file.
+func (s *Spool) Close() error {
+	s.file.Close()
+	if err := os.Remove(s.file.Name()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing spool: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shadow-ai-hunter/fsutil"
+)
+
+// FileState records how far a log file has been read.
+type FileState struct {
+	ID          fsutil.FileID `json:"id"`
+	Offset      int64         `json:"offset"`
+	Line        int           `json:"line,omitempty"`        // lines read up to Offset, where counted
+	Fingerprint string        `json:"fingerprint,omitempty"` // checksum of the file's first bytes
+}
+
+// Store persists per-file read offsets in a JSON file so that restarts
+// neither lose nor duplicate lines.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	Files map[string]FileState `json:"files"`
+}
+
+// Load reads the state file, returning an empty store if it does not exist.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, Files: make(map[string]FileState)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing state file: %w", err)
+	}
+	if s.Files == nil {
+		s.Files = make(map[string]FileState)
+	}
+	return s, nil
+}
+
+// Get returns the recorded state for a file path.
+func (s *Store) Get(path string) (FileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fs, ok := s.Files[path]
+	return fs, ok
+}
+
+// Set records the state for a file path. Call Save to persist it.
+func (s *Store) Set(path string, fs FileState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Files[path] = fs
+}
+
+// ByID returns the recorded state of the file with the given identity,
+// under whichever path it was last read.
+func (s *Store) ByID(id fsutil.FileID) (string, FileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path, fs := range s.Files {
+		if fs.ID == id {
+			return path, fs, true
+		}
+	}
+	return "", FileState{}, false
+}
+
+// Delete forgets a file path.
+func (s *Store) Delete(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Files, path)
+}
+
+// Save atomically writes the store to disk.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*")
+	if err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/shadow-ai-hunter/parsers"
+	"github.com/shadow-ai-hunter/workspace"
+)
+
+// workspaceSource is what findings from the Reports API are attributed to.
+const workspaceSource = "workspace:chrome"
+
+// scanWorkspace adds the Chrome browser events since the given time to the
+// outcome of a file scan.
+func (s *fileScanner) scanWorkspace(out *scanOutcome, c *workspace.Client, since time.Time) {
+	s.scanSource(out, workspaceSource, "workspace", func(res *fileResult) ([]parsers.LogEntry, error) {
+		logger.Info("Reading Chrome events from Google Workspace", "since", since.UTC().Format(time.RFC3339))
+		var entries []parsers.LogEntry
+		err := c.ChromeActivities(s.ctx, since, func(page io.Reader) error {
+			batch, err := parsers.ParseWorkspaceActivities(s.ctx, page)
+			entries = append(entries, batch...)
+			return err
+		})
+		res.Lines = len(entries)
+		return entries, err
+	})
+}
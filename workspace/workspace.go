@@ -0,0 +1,249 @@
+// Package workspace pulls Chrome browser events from the Google Workspace
+// Admin SDK Reports API, authenticating as a service account with
+// domain-wide delegation. Browser telemetry shows AI use on managed Chrome
+// that never crosses the proxy.
+package workspace
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	reportsScope    = "https://www.googleapis.com/auth/admin.reports.audit.readonly"
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+	activitiesPath  = "/admin/reports/v1/activity/users/all/applications/chrome"
+	// maxPageBytes bounds one page of activities.
+	maxPageBytes = 64 << 20
+)
+
+// Client reads the Reports API as an admin the service account is
+// delegated to act for.
+type Client struct {
+	email    string // the service account's
+	key      *rsa.PrivateKey
+	tokenURI string
+	subject  string // admin email
+	customer string // optional customer ID
+	endpoint string
+	client   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// DefaultAPI is the Admin SDK's base URL.
+const DefaultAPI = "https://admin.googleapis.com"
+
+// Options configures a Client.
+type Options struct {
+	KeyFile  string // service account key, as downloaded from the Google Cloud console
+	Subject  string // admin the service account acts for
+	Customer string // customer ID; empty means the admin's own
+	API      string // empty means DefaultAPI
+}
+
+// New loads the service account key for use on behalf of the admin.
+func New(opts Options) (*Client, error) {
+	keyFile, subject := opts.KeyFile, opts.Subject
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("workspace: %w", err)
+	}
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("workspace: reading %s: %w", keyFile, err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" {
+		return nil, fmt.Errorf("workspace: %s is not a service account key", keyFile)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("workspace: %s has no private key", keyFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("workspace: private key in %s: %w", keyFile, err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("workspace: private key in %s is not RSA", keyFile)
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("workspace: admin_email is required; the Reports API is read on behalf of an admin")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = defaultTokenURI
+	}
+	return &Client{
+		email:    key.ClientEmail,
+		key:      rsaKey,
+		tokenURI: key.TokenURI,
+		subject:  subject,
+		customer: opts.Customer,
+		endpoint: strings.TrimSuffix(cmp.Or(opts.API, DefaultAPI), "/") + activitiesPath,
+		client:   &http.Client{Timeout: 2 * time.Minute},
+	}, nil
+}
+
+// ChromeActivities passes each page of Chrome events since the given time
+// to fn, as the raw JSON response.
+func (c *Client) ChromeActivities(ctx context.Context, since time.Time, fn func(page io.Reader) error) error {
+	q := url.Values{
+		"startTime":  {since.UTC().Format(time.RFC3339)},
+		"maxResults": {"1000"},
+	}
+	if c.customer != "" {
+		q.Set("customerId", c.customer)
+	}
+	for {
+		page, next, err := c.page(ctx, q)
+		if err != nil {
+			return err
+		}
+		if err := fn(bytes.NewReader(page)); err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		q.Set("pageToken", next)
+	}
+}
+
+func (c *Client) page(ctx context.Context, q url.Values) ([]byte, string, error) {
+	tok, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+			msg = e.Error.Message
+		}
+		if len(msg) > 512 {
+			msg = msg[:512]
+		}
+		return nil, "", fmt.Errorf("workspace: Reports API: %s: %s", resp.Status, msg)
+	}
+	var next struct {
+		NextPageToken string `json:"nextPageToken"`
+	}
+	if err := json.Unmarshal(body, &next); err != nil {
+		return nil, "", fmt.Errorf("workspace: Reports API response: %w", err)
+	}
+	return body, next.NextPageToken, nil
+}
+
+// accessToken exchanges a signed JWT assertion for an access token, reusing
+// it until shortly before it expires.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expires) > time.Minute {
+		return c.token, nil
+	}
+	assertion, err := c.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("workspace: getting token: %w", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil && resp.StatusCode/100 == 2 {
+		return "", fmt.Errorf("workspace: getting token: %w", err)
+	}
+	if resp.StatusCode/100 != 2 || body.AccessToken == "" {
+		if body.Error != "" {
+			return "", fmt.Errorf("workspace: getting token: %s: %s", body.Error, body.Description)
+		}
+		return "", fmt.Errorf("workspace: getting token: %s", resp.Status)
+	}
+	c.token = body.AccessToken
+	c.expires = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	return c.token, nil
+}
+
+// assertion builds the RS256-signed JWT that asks for the Reports scope on
+// the admin's behalf.
+func (c *Client) assertion(now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iss":   c.email,
+		"sub":   c.subject,
+		"scope": reportsScope,
+		"aud":   c.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("workspace: signing token request: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}